@@ -2,40 +2,207 @@ package paypal
 
 import (
 	"fmt"
+	"time"
 )
 
-type AgreementRequest struct {
-	Note string `json:"note"`
+// Possible values for `type` in BillingAgreementTokenPlan
+const (
+	AgreementPlanTypeMerchantInitiatedBilling                string = "MERCHANT_INITIATED_BILLING"
+	AgreementPlanTypeMerchantInitiatedBillingSingleAgreement string = "MERCHANT_INITIATED_BILLING_SINGLE_AGREEMENT"
+	AgreementPlanTypeChannelInitiatedBilling                 string = "CHANNEL_INITIATED_BILLING"
+	AgreementPlanTypeChannelInitiatedBillingSingleAgreement  string = "CHANNEL_INITIATED_BILLING_SINGLE_AGREEMENT"
+)
+
+// billingAgreementsPath is the path of the v1 billing agreements, the only API to show, list the transactions of,
+// suspend, re-activate and cancel them
+const billingAgreementsPath = "/v1/payments/billing-agreements/"
+
+// Possible values for `accepted_pymt_type` in BillingAgreementMerchantPreferences
+const (
+	AcceptedPaymentTypeInstant   string = "INSTANT"
+	AcceptedPaymentTypeAny       string = "ANY"
+	AcceptedPaymentTypeUnbranded string = "UNBRANDED"
+)
+
+type (
+	// AgreementRequest represents the reason for suspending, re-activating or cancelling a billing agreement
+	AgreementRequest struct {
+		Note string `json:"note"` //max: 128
+	}
+
+	// CreateBillingAgreementTokenRequest represents body parameters needed to create a billing agreement token
+	// https://developer.paypal.com/docs/limited-release/reference-transactions/#create-billing-agreement-token
+	CreateBillingAgreementTokenRequest struct {
+		Description     string                     `json:"description,omitempty"`
+		Payer           *Payer                     `json:"payer"`
+		Plan            *BillingAgreementTokenPlan `json:"plan"`
+		ShippingAddress *ShippingAddress           `json:"shipping_address,omitempty"`
+	}
+
+	// BillingAgreementTokenPlan represents the plan of a billing agreement token
+	BillingAgreementTokenPlan struct {
+		Type                string                               `json:"type"` //default: MERCHANT_INITIATED_BILLING
+		MerchantPreferences *BillingAgreementMerchantPreferences `json:"merchant_preferences"`
+	}
+
+	// BillingAgreementMerchantPreferences represents the merchant preferences of a billing agreement token
+	BillingAgreementMerchantPreferences struct {
+		ReturnURL                string `json:"return_url"`
+		CancelURL                string `json:"cancel_url"`
+		NotifyURL                string `json:"notify_url,omitempty"`
+		AcceptedPaymentType      string `json:"accepted_pymt_type,omitempty"`
+		SkipShippingAddress      bool   `json:"skip_shipping_address,omitempty"`
+		ImmutableShippingAddress bool   `json:"immutable_shipping_address,omitempty"`
+	}
+
+	// Agreement represents a billing agreement created through the reference transactions flow
+	Agreement struct {
+		ID              string                     `json:"id"`
+		State           string                     `json:"state,omitempty"`
+		Description     string                     `json:"description,omitempty"`
+		Payer           *Payer                     `json:"payer,omitempty"`
+		Plan            *BillingAgreementTokenPlan `json:"plan,omitempty"`
+		ShippingAddress *ShippingAddress           `json:"shipping_address,omitempty"`
+		CreateTime      string                     `json:"create_time,omitempty"`
+		UpdateTime      string                     `json:"update_time,omitempty"`
+		Links           []*Link                    `json:"links,omitempty"`
+	}
+
+	// AgreementTransactionsRequest represents the date range of a billing agreement transactions listing
+	// Only the date part of StartDate and EndDate is sent, both are required
+	AgreementTransactionsRequest struct {
+		StartDate time.Time
+		EndDate   time.Time
+	}
+
+	// AgreementTransaction represents a single transaction made against a billing agreement
+	AgreementTransaction struct {
+		TransactionID   string        `json:"transaction_id"`
+		Status          string        `json:"status"`
+		TransactionType string        `json:"transaction_type"`
+		Amount          *AmountPayout `json:"amount,omitempty"`
+		FeeAmount       *AmountPayout `json:"fee_amount,omitempty"`
+		NetAmount       *AmountPayout `json:"net_amount,omitempty"`
+		PayerEmail      string        `json:"payer_email,omitempty"`
+		PayerName       string        `json:"payer_name,omitempty"`
+		TimeStamp       string        `json:"time_stamp"`
+		TimeZone        string        `json:"time_zone,omitempty"`
+	}
+
+	// AgreementTransactions represents the response of list billing agreement transactions
+	AgreementTransactions struct {
+		AgreementTransactionList []*AgreementTransaction `json:"agreement_transaction_list"`
+	}
+
+	// BillingAgreementToken represents the response of create billing agreement token
+	BillingAgreementToken struct {
+		TokenID string  `json:"token_id"`
+		Links   []*Link `json:"links,omitempty"`
+	}
+)
+
+// ApprovalURL returns the URL the payer must be redirected to in order to approve the billing agreement
+func (t *BillingAgreementToken) ApprovalURL() string {
+	if l := findLink(t.Links, LinkRelApprovalURL); l != nil {
+		return l.Href
+	}
+	return ""
 }
 
-// SuspendAgreement returns status code and message
-// pauses billing-agreement
-// Endpoint: GET /v1/payments/billing-agreements/subID/suspend"
-func (c *Client) SuspendAgreement(bAID string, agr AgreementRequest) (*DefaultResponse, error) {
+// CreateBillingAgreementToken creates a billing agreement token, the first step of the reference transactions flow.
+// Redirect the payer to ApprovalURL() and create the agreement from the approved token afterwards
+// Endpoint: POST /v1/billing-agreements/agreement-tokens
+func (c *Client) CreateBillingAgreementToken(body *CreateBillingAgreementTokenRequest) (*BillingAgreementToken, error) {
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/billing-agreements/agreement-tokens"), body)
+	resp := &BillingAgreementToken{}
 
-	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/billing-agreements/"+bAID+"/suspend", ), agr)
-	resp := &DefaultResponse{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// GetAgreement shows details for a billing agreement
+// Endpoint: GET /v1/payments/billing-agreements/{agreement_id}
+func (c *Client) GetAgreement(agreementID string) (*Agreement, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, billingAgreementsPath+agreementID), nil)
+	resp := &Agreement{}
+
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// ListAgreementTransactions lists the transactions of a billing agreement made between params.StartDate and params.EndDate
+// Endpoint: GET /v1/payments/billing-agreements/{agreement_id}/transactions
+func (c *Client) ListAgreementTransactions(agreementID string, params *AgreementTransactionsRequest) (*AgreementTransactions, error) {
+	if params == nil || params.StartDate.IsZero() || params.EndDate.IsZero() {
+		return nil, fmt.Errorf("paypal: StartDate and EndDate are required to list transactions of billing agreement %s", agreementID)
+	}
+	if params.EndDate.Before(params.StartDate) {
+		return nil, fmt.Errorf("paypal: EndDate is before StartDate for billing agreement %s", agreementID)
+	}
+
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, billingAgreementsPath+agreementID+"/transactions"), nil)
+	resp := &AgreementTransactions{}
 
 	if err != nil {
 		return resp, err
 	}
 
-	err = c.SendWithBasicAuth(req, resp)
+	q := req.URL.Query()
+	q.Add("start_date", params.StartDate.Format("2006-01-02"))
+	q.Add("end_date", params.EndDate.Format("2006-01-02"))
+	req.URL.RawQuery = q.Encode()
+
+	err = c.SendWithAuth(req, resp)
 	return resp, err
 }
 
-// ReActivateAgreement returns status code and message
-// re-activate billing-agreement
-// Endpoint: GET /v1/payments/billing-agreements/subID/suspend"
+// SuspendAgreement suspends a billing agreement
+// PayPal responds with 204 No Content, so the returned DefaultResponse is empty on success
+// Endpoint: POST /v1/payments/billing-agreements/{agreement_id}/suspend
+func (c *Client) SuspendAgreement(bAID string, agr AgreementRequest) (*DefaultResponse, error) {
+	return c.changeAgreementState(bAID, "suspend", agr)
+}
+
+// ReActivateAgreement re-activates a suspended billing agreement
+// PayPal responds with 204 No Content, so the returned DefaultResponse is empty on success
+// Endpoint: POST /v1/payments/billing-agreements/{agreement_id}/re-activate
 func (c *Client) ReActivateAgreement(bAID string, agr AgreementRequest) (*DefaultResponse, error) {
+	return c.changeAgreementState(bAID, "re-activate", agr)
+}
 
-	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/billing-agreements/"+bAID+"/re-activate", ), agr)
+// CancelAgreement cancels a billing agreement, a cancelled agreement cannot be re-activated
+// PayPal responds with 204 No Content, so the returned DefaultResponse is empty on success
+// Endpoint: POST /v1/payments/billing-agreements/{agreement_id}/cancel
+func (c *Client) CancelAgreement(bAID string, agr AgreementRequest) (*DefaultResponse, error) {
+	return c.changeAgreementState(bAID, "cancel", agr)
+}
+
+// changeAgreementState posts the note to one of the billing agreement state endpoints
+func (c *Client) changeAgreementState(bAID, action string, agr AgreementRequest) (*DefaultResponse, error) {
 	resp := &DefaultResponse{}
 
+	if err := c.validate(&agr); err != nil {
+		return resp, err
+	}
+
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, billingAgreementsPath+bAID+"/"+action), agr)
 	if err != nil {
 		return resp, err
 	}
 
-	err = c.SendWithBasicAuth(req, resp)
+	err = c.SendWithAuth(req, resp)
 	return resp, err
 }
+
+// Validate checks the note PayPal requires to change the state of a billing agreement
+func (r *AgreementRequest) Validate() error {
+	return checkLength("note", r.Note, 1, 128)
+}
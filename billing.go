@@ -2,10 +2,10 @@ package paypal
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
 )
 
 type (
@@ -15,8 +15,8 @@ type (
 		State               string              `json:"state,omitempty"`
 		PaymentDefinitions  []PaymentDefinition `json:"payment_definitions,omitempty"`
 		MerchantPreferences MerchantPreferences `json:"merchant_preferences,omitempty"`
-		CreateTime          time.Time           `json:"create_time,omitempty"`
-		UpdateTime          time.Time           `json:"update_time,omitempty"`
+		CreateTime          JSONTime            `json:"create_time,omitempty"`
+		UpdateTime          JSONTime            `json:"update_time,omitempty"`
 		Links               []Link              `json:"links,omitempty"`
 	}
 
@@ -26,7 +26,7 @@ type (
 		Description string      `json:"description,omitempty"`
 		Plan        BillingPlan `json:"plan,omitempty"`
 		Links       []Link      `json:"links,omitempty"`
-		StartTime   time.Time   `json:"start_time,omitempty"`
+		StartTime   JSONTime    `json:"start_time,omitempty"`
 	}
 
 	// BillingPlanListParams struct
@@ -131,3 +131,43 @@ func (c *Client) ListBillingPlans(bplp BillingPlanListParams) (*BillingPlanListR
 	err = c.SendWithAuth(req, response)
 	return response, err
 }
+
+// MarshalJSON leaves out the merchant preferences and the times when they are empty
+func (r CreateBillingResp) MarshalJSON() ([]byte, error) {
+	type createBillingResp CreateBillingResp
+	out := struct {
+		createBillingResp
+		MerchantPreferences *MerchantPreferences `json:"merchant_preferences,omitempty"`
+		CreateTime          *JSONTime            `json:"create_time,omitempty"`
+		UpdateTime          *JSONTime            `json:"update_time,omitempty"`
+	}{createBillingResp: createBillingResp(r)}
+
+	if r.MerchantPreferences != (MerchantPreferences{}) {
+		out.MerchantPreferences = &r.MerchantPreferences
+	}
+	if !r.CreateTime.Time().IsZero() {
+		out.CreateTime = &r.CreateTime
+	}
+	if !r.UpdateTime.Time().IsZero() {
+		out.UpdateTime = &r.UpdateTime
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON leaves out the plan and the start time when they are empty
+func (r CreateAgreementResp) MarshalJSON() ([]byte, error) {
+	type createAgreementResp CreateAgreementResp
+	out := struct {
+		createAgreementResp
+		Plan      *BillingPlan `json:"plan,omitempty"`
+		StartTime *JSONTime    `json:"start_time,omitempty"`
+	}{createAgreementResp: createAgreementResp(r)}
+
+	if !isZero(r.Plan) {
+		out.Plan = &r.Plan
+	}
+	if !r.StartTime.Time().IsZero() {
+		out.StartTime = &r.StartTime
+	}
+	return json.Marshal(out)
+}
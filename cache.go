@@ -0,0 +1,158 @@
+package paypal
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached responses are used when SetResponseCache is called without a TTL
+const DefaultCacheTTL = 5 * time.Minute
+
+type (
+	// ResponseCache stores the response bodies of ShowPlan, ShowProduct and ListWebhooks, see Client.SetResponseCache.
+	// Implement it on top of Redis or memcached to share the responses between instances
+	ResponseCache interface {
+		// Get returns the body stored for the key, ok is false when there is none or its TTL expired
+		Get(key string) (body []byte, ok bool, err error)
+		// Set stores the body for the key for ttl
+		Set(key string, body []byte, ttl time.Duration) error
+		// Delete removes the body stored for the key
+		Delete(key string) error
+	}
+
+	// MemoryResponseCache is a ResponseCache keeping the responses in memory, it is only suitable for a single instance
+	MemoryResponseCache struct {
+		// Clock expires the responses, the system clock is used when nil
+		Clock Clock
+
+		mu      sync.Mutex
+		entries map[string]cachedResponse
+		sets    int
+	}
+
+	// cachedResponse is a response body of MemoryResponseCache with its expiry
+	cachedResponse struct {
+		body      []byte
+		expiresAt time.Time
+	}
+)
+
+// NewMemoryResponseCache returns an empty MemoryResponseCache
+func NewMemoryResponseCache() *MemoryResponseCache {
+	return &MemoryResponseCache{entries: map[string]cachedResponse{}}
+}
+
+// Get returns the body stored for the key, ok is false when there is none or its TTL expired
+func (s *MemoryResponseCache) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !clockNow(s.Clock).Before(entry.expiresAt) {
+		return nil, false, nil
+	}
+	return entry.body, true, nil
+}
+
+// Set stores the body for the key for ttl, expired responses are purged every 1024 sets
+func (s *MemoryResponseCache) Set(key string, body []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := clockNow(s.Clock)
+	if s.entries == nil {
+		s.entries = map[string]cachedResponse{}
+	}
+	s.entries[key] = cachedResponse{body: body, expiresAt: now.Add(ttl)}
+
+	s.sets++
+	if s.sets%1024 == 0 {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the body stored for the key
+func (s *MemoryResponseCache) Delete(key string) error {
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// SetResponseCache makes ShowPlan, ShowProduct and ListWebhooks return the responses stored in cache for ttl,
+// DefaultCacheTTL when 0, instead of calling the API. Updating a plan, a product or a webhook with the client
+// removes its cached responses, changes made elsewhere show up once the TTL expired.
+// A failing cache is bypassed. nil disables the cache, merchant clients don't inherit it
+func (c *Client) SetResponseCache(cache ResponseCache, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	c.Lock()
+	c.cache = cache
+	c.cacheTTL = ttl
+	c.Unlock()
+}
+
+// sendCached sends the GET request with send unless the cache has a response for its URL
+func (c *Client) sendCached(req *http.Request, v interface{}, send func(req *http.Request, v interface{}) error) error {
+	c.Lock()
+	cache, ttl := c.cache, c.cacheTTL
+	c.Unlock()
+	if cache == nil {
+		return send(req, v)
+	}
+
+	key := c.cacheKey(req.URL.String())
+	if body, ok, err := cache.Get(key); err == nil && ok {
+		if err := decodeJSON(body, v); err == nil {
+			return nil
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := send(req, buf); err != nil {
+		return err
+	}
+	if err := decodeJSON(buf.Bytes(), v); err != nil {
+		return err
+	}
+	c.reportUnknownFields(req, buf.Bytes(), v)
+
+	cache.Set(key, buf.Bytes(), ttl)
+	return nil
+}
+
+// invalidateCache removes the cached responses of the URLs
+func (c *Client) invalidateCache(urls ...string) {
+	c.Lock()
+	cache := c.cache
+	c.Unlock()
+	if cache == nil {
+		return
+	}
+
+	for _, u := range urls {
+		cache.Delete(c.cacheKey(u))
+	}
+}
+
+// invalidateWebhooksCache removes the cached webhook lists of every anchor type
+func (c *Client) invalidateWebhooksCache() {
+	list := fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks")
+	c.invalidateCache(list, list+"?anchor_type="+AnchorTypeApplication, list+"?anchor_type="+AnchorTypeAccount)
+}
+
+// cacheKey returns the key of the response of the URL, responses differ between applications and
+// the merchants a client acts on behalf of
+func (c *Client) cacheKey(u string) string {
+	return fmt.Sprintf("paypal:%s:%s:%s", c.ClientID, c.authAssertion, u)
+}
@@ -1,6 +1,9 @@
 package paypal
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+)
 
 // ShowCapturedPayments shows details for a captured payment, by ID.
 // Endpoint: GET /v2/payments/captures/{capture_id}
@@ -37,3 +40,55 @@ func (c *Client) RefundCapturedPayment(captureID string, body *RefundRequest) (*
 
 	return resp, nil
 }
+
+// PlatformFeeRefund returns the platform fees to return when refunding amount of the capture, each fee of the
+// capture prorated to the refunded part of the capture amount. A nil amount refunds the whole capture and all
+// its fees. Pass the result in the PaymentInstruction of the RefundRequest
+func (c *Capture) PlatformFeeRefund(amount *Money) ([]PlatformFee, error) {
+	if c.SellerReceivableBreakdown == nil || len(c.SellerReceivableBreakdown.PlatformFees) == 0 {
+		return nil, nil
+	}
+	if c.Amount == nil {
+		return nil, fmt.Errorf("paypal: capture %s has no amount", c.ID)
+	}
+
+	currency := c.Amount.Currency
+	captured, err := parseMoney(c.Amount, currency, "capture amount")
+	if err != nil {
+		return nil, err
+	}
+	refunded := captured
+	if amount != nil {
+		if refunded, err = parseMoney(amount, currency, "refund amount"); err != nil {
+			return nil, err
+		}
+		if refunded.Cmp(captured) > 0 {
+			return nil, fmt.Errorf("paypal: refund amount %s is more than the captured %s", amount.Value, c.Amount.Value)
+		}
+	}
+	if captured.Sign() == 0 {
+		return nil, nil
+	}
+
+	fees := make([]PlatformFee, 0, len(c.SellerReceivableBreakdown.PlatformFees))
+	for i, fee := range c.SellerReceivableBreakdown.PlatformFees {
+		if fee == nil {
+			continue
+		}
+		value, err := parseMoney(fee.Amount, currency, fmt.Sprintf("platform fee %d", i))
+		if err != nil {
+			return nil, err
+		}
+
+		share := roundCurrency(new(big.Rat).Quo(new(big.Rat).Mul(value, refunded), captured), currency)
+		if share.Sign() == 0 {
+			continue
+		}
+		fees = append(fees, PlatformFee{
+			Amount: &Money{Currency: currency, Value: formatCurrency(share, currency)},
+			Payee:  fee.Payee,
+		})
+	}
+
+	return fees, nil
+}
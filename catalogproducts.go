@@ -3,8 +3,12 @@ package paypal
 import (
 	"fmt"
 	"strconv"
+	"sync"
 )
 
+// DefaultBulkUpdateProductsWorkers is the number of concurrent requests used by BulkUpdateProducts when workers is not set
+const DefaultBulkUpdateProductsWorkers = 5
+
 // CreateProduct - Use this call to create a catalog product
 // Endpoint: POST /v1/catalogs/products
 // Type represents the product type. Indicates whether the product is physical or tangible goods, or a service. The allowed values are:
@@ -16,6 +20,13 @@ import (
 func (c *Client) CreateProduct(product *CreateProductRequest) (*Product, error) {
 	resp := &Product{}
 
+	if product == nil {
+		return nil, fmt.Errorf("paypal: a product is required to create a product")
+	}
+	if err := c.validate(product); err != nil {
+		return nil, err
+	}
+
 	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/catalogs/products"), product)
 	if err != nil {
 		return nil, err
@@ -28,9 +39,9 @@ func (c *Client) CreateProduct(product *CreateProductRequest) (*Product, error)
 	return resp, nil
 }
 
-// ListAllProducts lists all products
+// ListProducts lists a single page of products
 // Endpoint: GET /v1/catalogs/products
-func (c *Client) ListAllProducts(params *ListProductsRequest) (*ListProductsResponse, error) {
+func (c *Client) ListProducts(params *ListProductsRequest) (*ListProductsResponse, error) {
 	resp := &ListProductsResponse{}
 
 	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/catalogs/products"), nil)
@@ -38,10 +49,19 @@ func (c *Client) ListAllProducts(params *ListProductsRequest) (*ListProductsResp
 		return nil, err
 	}
 
-	q := req.URL.Query()
-	q.Add("page_size", strconv.FormatUint(params.PageSize, 10))
-	q.Add("page", strconv.FormatUint(params.Page, 10))
-	q.Add("total_required", strconv.FormatBool(params.TotalRequired))
+	if params != nil {
+		q := req.URL.Query()
+		if params.PageSize > 0 {
+			q.Add("page_size", strconv.FormatUint(params.PageSize, 10))
+		}
+		if params.Page > 0 {
+			q.Add("page", strconv.FormatUint(params.Page, 10))
+		}
+		if params.TotalRequired {
+			q.Add("total_required", strconv.FormatBool(params.TotalRequired))
+		}
+		req.URL.RawQuery = q.Encode()
+	}
 
 	if err = c.SendWithBasicAuth(req, resp); err != nil {
 		return nil, err
@@ -50,6 +70,61 @@ func (c *Client) ListAllProducts(params *ListProductsRequest) (*ListProductsResp
 	return resp, nil
 }
 
+// ListAllProducts lists a single page of products
+// Endpoint: GET /v1/catalogs/products
+//
+// Deprecated: ListAllProducts returns only the page described by params, use ListProducts for a single page
+// and IterateProducts to follow the `next` links
+func (c *Client) ListAllProducts(params *ListProductsRequest) (*ListProductsResponse, error) {
+	return c.ListProducts(params)
+}
+
+// IterateProducts returns an iterator over the pages of products, starting at the page described by params
+func (c *Client) IterateProducts(params *ListProductsRequest) *ProductIterator {
+	return &ProductIterator{client: c, params: params, visited: map[string]bool{}}
+}
+
+// Next fetches the next page, it returns false when there are no more pages or the request failed
+func (it *ProductIterator) Next() bool {
+	if it.done {
+		return false
+	}
+
+	if it.page == nil {
+		it.page, it.err = it.client.ListProducts(it.params)
+	} else {
+		next := findLink(it.page.Links, LinkRelNext)
+		if next == nil || it.visited[next.Href] {
+			it.done = true
+			return false
+		}
+		it.visited[next.Href] = true
+
+		req, err := it.client.NewRequest("GET", next.Href, nil)
+		if err == nil {
+			it.page = &ListProductsResponse{}
+			err = it.client.SendWithBasicAuth(req, it.page)
+		}
+		it.err = err
+	}
+
+	if it.err != nil {
+		it.done = true
+		return false
+	}
+	return true
+}
+
+// Page returns the current page
+func (it *ProductIterator) Page() *ListProductsResponse {
+	return it.page
+}
+
+// Err returns the error that stopped the iteration
+func (it *ProductIterator) Err() error {
+	return it.err
+}
+
 // ShowProduct shows details for a product by ID
 // Endpoint: GET /v1/catalogs/products/{product_id}
 func (c *Client) ShowProduct(productID string) (*Product, error) {
@@ -60,7 +135,7 @@ func (c *Client) ShowProduct(productID string) (*Product, error) {
 		return nil, err
 	}
 
-	if err = c.SendWithBasicAuth(req, resp); err != nil {
+	if err = c.sendCached(req, resp, c.SendWithBasicAuth); err != nil {
 		return nil, err
 	}
 
@@ -79,10 +154,138 @@ func (c *Client) ShowProduct(productID string) (*Product, error) {
 // ----------------------------------------------
 // Endpoint: PATCH /v1/catalogs/products/{product_id}
 func (c *Client) UpdateProduct(productID string, body []*PatchObject) error {
+	if err := validateProductPatch(body); err != nil {
+		return err
+	}
+
 	req, err := c.NewRequest("PATCH", fmt.Sprintf("%s%s", c.APIBase, "/v1/catalogs/products/"+productID), body)
 	if err != nil {
 		return err
 	}
 
+	defer c.invalidateCache(req.URL.String())
 	return c.SendWithBasicAuth(req, nil)
 }
+
+// BulkUpdateProducts applies every patch set with UpdateProduct using at most workers concurrent requests.
+// It does not stop on the first failure, the returned results are in the same order as sets and
+// carry the error of every product that could not be updated
+func (c *Client) BulkUpdateProducts(sets []ProductPatchSet, workers int) ProductUpdateResults {
+	if workers <= 0 {
+		workers = DefaultBulkUpdateProductsWorkers
+	}
+
+	results := make(ProductUpdateResults, len(sets))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(sets); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = ProductUpdateResult{
+					ProductID: sets[i].ProductID,
+					Err:       c.UpdateProduct(sets[i].ProductID, sets[i].Patches),
+				}
+			}
+		}()
+	}
+
+	for i := range sets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// Failed returns the results of the products that could not be updated
+func (r ProductUpdateResults) Failed() []ProductUpdateResult {
+	var failed []ProductUpdateResult
+	for _, result := range r {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// productPatchOperations lists the operations PayPal allows for every patchable product path
+var productPatchOperations = map[string][]string{
+	ProductPathDescription: {OperationAdd, OperationReplace, OperationRemove},
+	ProductPathCategory:    {OperationAdd, OperationReplace, OperationRemove},
+	ProductPathImageURL:    {OperationAdd, OperationReplace, OperationRemove},
+	ProductPathHomeURL:     {OperationAdd, OperationReplace, OperationRemove},
+}
+
+// ProductPatchAdd returns a PatchObject adding value at one of the ProductPath* paths
+func ProductPatchAdd(path, value string) *PatchObject {
+	return &PatchObject{Operation: OperationAdd, Path: path, Value: value}
+}
+
+// ProductPatchReplace returns a PatchObject replacing the value at one of the ProductPath* paths
+func ProductPatchReplace(path, value string) *PatchObject {
+	return &PatchObject{Operation: OperationReplace, Path: path, Value: value}
+}
+
+// ProductPatchRemove returns a PatchObject removing the value at one of the ProductPath* paths
+func ProductPatchRemove(path string) *PatchObject {
+	return &PatchObject{Operation: OperationRemove, Path: path}
+}
+
+// validateProductPatch checks every patch against the documented product paths and their allowed operations
+func validateProductPatch(body []*PatchObject) error {
+	if len(body) == 0 {
+		return fmt.Errorf("paypal: at least one patch is required to update a product")
+	}
+
+	for i, patch := range body {
+		if patch == nil {
+			return fmt.Errorf("paypal: patch %d is nil", i)
+		}
+
+		operations, ok := productPatchOperations[patch.Path]
+		if !ok {
+			return fmt.Errorf("paypal: path %q cannot be patched on a product", patch.Path)
+		}
+
+		allowed := false
+		for _, op := range operations {
+			if op == patch.Operation {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("paypal: operation %q is not allowed on product path %s", patch.Operation, patch.Path)
+		}
+
+		if patch.Operation != OperationRemove && patch.Value == "" {
+			return fmt.Errorf("paypal: operation %q on product path %s requires a value", patch.Operation, patch.Path)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the product against the constraints documented by PayPal
+func (p *CreateProductRequest) Validate() error {
+	err := firstError(
+		checkLength("name", p.Name, 1, 127),
+		checkLength("description", p.Description, 0, 256),
+		checkLength("image_url", p.ImageUrl, 0, 2000),
+		checkLength("home_url", p.HomeUrl, 0, 2000),
+	)
+	if err != nil {
+		return err
+	}
+
+	switch p.Type {
+	case "", ProductTypePhysical, ProductTypeDigital, ProductTypeService:
+	default:
+		return &ValidationError{Field: "type", Reason: fmt.Sprintf("%q is not one of %s, %s and %s", p.Type, ProductTypePhysical, ProductTypeDigital, ProductTypeService)}
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package paypal
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +11,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -31,6 +35,10 @@ func NewClient(clientID string, secret string, APIBase string) (*Client, error)
 // No need to call SetAccessToken to apply new access token for current Client
 // Endpoint: POST /v1/oauth2/token
 func (c *Client) GetAccessToken() (*TokenResponse, error) {
+	if c.refreshToken != "" {
+		return c.getMerchantAccessToken()
+	}
+
 	buf := bytes.NewBuffer([]byte("grant_type=client_credentials"))
 	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/oauth2/token"), buf)
 	if err != nil {
@@ -44,13 +52,54 @@ func (c *Client) GetAccessToken() (*TokenResponse, error) {
 
 	// Set Token fur current Client
 	if response.Token != "" {
-		c.Token = response
-		c.tokenExpiresAt = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+		c.setToken(response)
 	}
 
 	return response, err
 }
 
+// TerminateAccessToken invalidates the access token of the client, e.g. on shutdown or after rotating the credentials.
+// Call GetAccessToken before making further calls with the client
+// Endpoint: POST /v1/oauth2/token/terminate
+func (c *Client) TerminateAccessToken() error {
+	c.Lock()
+	token := c.Token
+	c.Unlock()
+
+	if token == nil || token.Token == "" {
+		return nil
+	}
+
+	if err := c.TerminateToken(token.Token, TokenTypeHintAccessToken); err != nil {
+		return err
+	}
+
+	c.Lock()
+	if c.Token == token {
+		c.Token = nil
+		c.tokenExpiresAt = time.Time{}
+	}
+	c.Unlock()
+	return nil
+}
+
+// TerminateToken invalidates an access or refresh token obtained with the credentials of the client
+// Endpoint: POST /v1/oauth2/token/terminate
+func (c *Client) TerminateToken(token, tokenTypeHint string) error {
+	q := url.Values{}
+	q.Set("token", token)
+	q.Set("token_type_hint", tokenTypeHint)
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/oauth2/token/terminate"), strings.NewReader(q.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-type", "application/x-www-form-urlencoded")
+
+	return c.SendWithBasicAuth(req, nil)
+}
+
 // SetHTTPClient sets *http.Client to current client
 func (c *Client) SetHTTPClient(client *http.Client) {
 	c.Client = client
@@ -64,6 +113,37 @@ func (c *Client) SetAccessToken(token string) {
 	c.tokenExpiresAt = time.Time{}
 }
 
+// TokenExpiresIn returns the remaining lifetime of the access token. It is 0 when the client has no token or the
+// lifetime is unknown, as after SetAccessToken, and negative once the token expired.
+// SendWithAuth requests a new token when less than RequestNewTokenBeforeExpiresIn remains
+func (c *Client) TokenExpiresIn() time.Duration {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.Token == nil || c.tokenExpiresAt.IsZero() {
+		return 0
+	}
+	return c.tokenExpiresAt.Sub(clockNow(c.clock))
+}
+
+// SetTokenRefreshCallback sets a function called with every access token the client obtains, whether by
+// GetAccessToken or by SendWithAuth refreshing the token before it expires.
+// The callback may run while the client is locked, as when SendWithAuth refreshes the token, so it must not call the client
+func (c *Client) SetTokenRefreshCallback(callback func(token *TokenResponse)) {
+	c.Lock()
+	c.onTokenRefresh = callback
+	c.Unlock()
+}
+
+// setToken stores a new access token and reports it to the token refresh callback
+func (c *Client) setToken(token *TokenResponse) {
+	c.Token = token
+	c.tokenExpiresAt = clockNow(c.clock).Add(token.ExpiresIn.Duration())
+	if c.onTokenRefresh != nil {
+		c.onTokenRefresh(token)
+	}
+}
+
 // SetLog will set/change the output destination.
 // If log file is set paypal will log all requests and responses to this Writer
 func (c *Client) SetLog(log io.Writer) {
@@ -116,16 +196,26 @@ func (c *Client) Send(req *http.Request, v interface{}) error {
 
 		return errResp
 	}
-	if v == nil {
+	if v == nil || resp.StatusCode == http.StatusNoContent {
 		return nil
 	}
 
+	if d, ok := v.(streamDecoder); ok {
+		return d.decodeFrom(resp.Body)
+	}
 	if w, ok := v.(io.Writer); ok {
 		io.Copy(w, resp.Body)
 		return nil
 	}
 
-	return json.NewDecoder(resp.Body).Decode(v)
+	if data, err = ioutil.ReadAll(resp.Body); err != nil {
+		return err
+	}
+	if err = decodeJSON(data, v); err != nil {
+		return err
+	}
+	c.reportUnknownFields(req, data, v)
+	return nil
 }
 
 // SendWithAuth makes a request to the API and apply OAuth2 header automatically.
@@ -137,8 +227,16 @@ func (c *Client) SendWithAuth(req *http.Request, v interface{}) error {
 	// Note: Here we do not want to `defer c.Unlock()` because we need `c.Send(...)`
 	// to happen outside of the locked section.
 
+	if c.Token == nil && c.refreshToken != "" {
+		// Merchant clients mint their first access token on demand
+		if _, err := c.GetAccessToken(); err != nil {
+			c.Unlock()
+			return err
+		}
+	}
+
 	if c.Token != nil {
-		if !c.tokenExpiresAt.IsZero() && c.tokenExpiresAt.Sub(time.Now()) < RequestNewTokenBeforeExpiresIn {
+		if !c.tokenExpiresAt.IsZero() && c.tokenExpiresAt.Sub(clockNow(c.clock)) < RequestNewTokenBeforeExpiresIn {
 			// c.Token will be updated in GetAccessToken call
 			if _, err := c.GetAccessToken(); err != nil {
 				c.Unlock()
@@ -148,6 +246,9 @@ func (c *Client) SendWithAuth(req *http.Request, v interface{}) error {
 
 		req.Header.Set("Authorization", "Bearer "+c.Token.Token)
 	}
+	if c.authAssertion != "" {
+		req.Header.Set("PayPal-Auth-Assertion", c.authAssertion)
+	}
 
 	// Unlock the client mutex before sending the request, this allows multiple requests
 	// to be in progress at the same time.
@@ -194,3 +295,12 @@ func (c *Client) log(r *http.Request, resp *http.Response) {
 		c.Log.Write([]byte(fmt.Sprintf("Request: %s\nResponse: %s\n", reqDump, string(respDump))))
 	}
 }
+
+// newRequestID returns a random value for the PayPal-Request-Id idempotency header
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,34 @@
+package paypal
+
+import "time"
+
+type (
+	// Clock tells the time for token expiry and the other time-based logic of the package.
+	// Tests replace the system clock with one they move forward, see Client.SetClock
+	Clock interface {
+		Now() time.Time
+	}
+
+	// systemClock is the Clock of the system time, used when no clock is set
+	systemClock struct{}
+)
+
+// Now returns the current system time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// clockNow returns the time of clock, or the system time when clock is nil
+func clockNow(clock Clock) time.Time {
+	if clock == nil {
+		return systemClock{}.Now()
+	}
+	return clock.Now()
+}
+
+// SetClock sets the clock the client tracks the expiry of its access token with, nil restores the system clock
+func (c *Client) SetClock(clock Clock) {
+	c.Lock()
+	c.clock = clock
+	c.Unlock()
+}
@@ -0,0 +1,92 @@
+package paypal
+
+import "reflect"
+
+// Clone returns a deep copy of the purchase unit, the copy can be changed without changing u,
+// e.g. to keep a prototype and customize it for each order
+func (u *PurchaseUnitRequest) Clone() *PurchaseUnitRequest {
+	return deepCopy(u).(*PurchaseUnitRequest)
+}
+
+// Clone returns a deep copy of the application context, see PurchaseUnitRequest.Clone
+func (a *ApplicationContext) Clone() *ApplicationContext {
+	return deepCopy(a).(*ApplicationContext)
+}
+
+// Clone returns a deep copy of the payer, see PurchaseUnitRequest.Clone
+func (p *CreateOrderPayer) Clone() *CreateOrderPayer {
+	return deepCopy(p).(*CreateOrderPayer)
+}
+
+// Clone returns a deep copy of the payout, see PurchaseUnitRequest.Clone
+func (p *Payout) Clone() *Payout {
+	return deepCopy(p).(*Payout)
+}
+
+// Clone returns a deep copy of the plan, see PurchaseUnitRequest.Clone
+func (p *CreatePlan) Clone() *CreatePlan {
+	return deepCopy(p).(*CreatePlan)
+}
+
+// Clone returns a deep copy of the subscription, see PurchaseUnitRequest.Clone
+func (r *CreateSubscriptionRequest) Clone() *CreateSubscriptionRequest {
+	return deepCopy(r).(*CreateSubscriptionRequest)
+}
+
+// deepCopy copies the struct v points to along with the pointers, slices, maps and interfaces it holds.
+// Unexported fields, e.g. the ones of time.Time, are copied as they are
+func deepCopy(v interface{}) interface{} {
+	src := reflect.ValueOf(v)
+	if src.IsNil() {
+		return v
+	}
+
+	dst := reflect.New(src.Elem().Type())
+	copyValue(dst.Elem(), src.Elem())
+	return dst.Interface()
+}
+
+func copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Elem().Type()))
+		copyValue(dst.Elem(), src.Elem())
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				copyValue(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		for _, key := range src.MapKeys() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			copyValue(value, src.MapIndex(key))
+			dst.SetMapIndex(key, value)
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		value := reflect.New(src.Elem().Type()).Elem()
+		copyValue(value, src.Elem())
+		dst.Set(value)
+	default:
+		dst.Set(src)
+	}
+}
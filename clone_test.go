@@ -0,0 +1,84 @@
+package paypal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClonePurchaseUnitRequest(t *testing.T) {
+	prototype := &PurchaseUnitRequest{
+		ReferenceID: "default",
+		Amount: &PurchaseUnitAmount{
+			Currency:  "USD",
+			Value:     "19.99",
+			Breakdown: &PurchaseUnitAmountBreakdown{ItemTotal: &Money{Currency: "USD", Value: "19.99"}},
+		},
+		Items: []Item{{Name: "Yoga Mat", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "19.99"}}},
+		PaymentInstruction: &PaymentInstruction{
+			PlatformFees: []PlatformFee{{Amount: &Money{Currency: "USD", Value: "1.00"}, Payee: &PayeeBase{MerchantID: "C7CYMKZDG8D6E"}}},
+		},
+	}
+
+	unit := prototype.Clone()
+	if !reflect.DeepEqual(unit, prototype) {
+		t.Fatalf("expected an equal copy, got %+v", unit)
+	}
+
+	unit.CustomID = "order-1001"
+	unit.Amount.Value = "39.98"
+	unit.Amount.Breakdown.ItemTotal.Value = "39.98"
+	unit.Items[0].Quantity = "2"
+	unit.Items = append(unit.Items, Item{Name: "Yoga Block"})
+	unit.PaymentInstruction.PlatformFees[0].Payee.MerchantID = "2J6QB8YJQSJRJ"
+
+	if prototype.CustomID != "" || prototype.Amount.Value != "19.99" || prototype.Amount.Breakdown.ItemTotal.Value != "19.99" ||
+		len(prototype.Items) != 1 || prototype.Items[0].Quantity != "1" ||
+		prototype.PaymentInstruction.PlatformFees[0].Payee.MerchantID != "C7CYMKZDG8D6E" {
+		t.Errorf("expected the prototype to be untouched, got %+v", prototype)
+	}
+
+	var nilUnit *PurchaseUnitRequest
+	if nilUnit.Clone() != nil {
+		t.Errorf("expected nil for a nil purchase unit")
+	}
+}
+
+func TestCloneRequests(t *testing.T) {
+	payout := &Payout{
+		SenderBatchHeader: &SenderBatchHeader{EmailSubject: "You have a payout!"},
+		Items:             []PayoutItem{{RecipientType: PayoutRecipientTypeEmail, Receiver: "receiver@example.com", Amount: &AmountPayout{Currency: "USD", Value: "9.87"}}},
+	}
+	payoutCopy := payout.Clone()
+	payoutCopy.SenderBatchHeader.SenderBatchID = "Payouts_2018_100007"
+	payoutCopy.Items[0].Amount.Value = "1.00"
+	if payout.SenderBatchHeader.SenderBatchID != "" || payout.Items[0].Amount.Value != "9.87" {
+		t.Errorf("expected the payout to be untouched, got %+v", payout)
+	}
+
+	plan := &CreatePlan{
+		ProductID: "PROD-XXCD1234QWER65782",
+		Name:      "Monthly plan",
+		BillingCycles: []*BillingCycle{{
+			Frequency:     &Frequency{IntervalUnit: IntervalUnitMonth, IntervalCount: 1},
+			TenureType:    TenureTypeRegular,
+			Sequence:      1,
+			PricingScheme: &PricingScheme{FixedPrice: &Money{Currency: "USD", Value: "10.00"}},
+		}},
+		PaymentPreferences: &PaymentPreferences{PaymentFailureThreshold: 3},
+	}
+	planCopy := plan.Clone()
+	planCopy.BillingCycles[0].PricingScheme.FixedPrice.Value = "12.00"
+	planCopy.BillingCycles[0].Frequency.IntervalUnit = IntervalUnitYear
+	planCopy.PaymentPreferences.PaymentFailureThreshold = 1
+	if plan.BillingCycles[0].PricingScheme.FixedPrice.Value != "10.00" || plan.BillingCycles[0].Frequency.IntervalUnit != IntervalUnitMonth ||
+		plan.PaymentPreferences.PaymentFailureThreshold != 3 {
+		t.Errorf("expected the plan to be untouched, got %+v", plan)
+	}
+
+	appContext := &ApplicationContext{BrandName: "YeowZa!", PaymentMethod: &PaymentMethod{PayeePreferred: "IMMEDIATE_PAYMENT_REQUIRED"}}
+	appContextCopy := appContext.Clone()
+	appContextCopy.PaymentMethod.PayeePreferred = "UNRESTRICTED"
+	if appContext.PaymentMethod.PayeePreferred != "IMMEDIATE_PAYMENT_REQUIRED" {
+		t.Errorf("expected the application context to be untouched, got %+v", appContext.PaymentMethod)
+	}
+}
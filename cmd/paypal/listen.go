@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/inplayer-org/paypal"
+	"github.com/inplayer-org/paypal/paypaltest"
+)
+
+// listener polls the webhook events of the sandbox application and forwards the new ones to a local URL.
+// The events are read from the authenticated events API, so they come from PayPal, and are signed again by a
+// local WebhookSigner as PayPal signatures can't be replayed: the handler verifies them with a verifier from
+// paypaltest.VerifierFromTrustBundle
+type listener struct {
+	client     *paypal.Client
+	signer     *paypaltest.WebhookSigner
+	forwardTo  string
+	eventTypes map[string]bool // every event type is forwarded when it has "*"
+	httpClient *http.Client
+	out        io.Writer
+
+	since time.Time
+	seen  map[string]time.Time // create time of the forwarded events, pruned once they are older than since
+}
+
+// pollOverlap is how far before the newest event polls start, events PayPal records late are still listed
+const pollOverlap = time.Minute
+
+// listen runs `paypal webhook listen`: it registers a temporary sandbox webhook so PayPal records the events,
+// forwards them until interrupted and deletes the webhook
+func listen(args []string) error {
+	flags := flag.NewFlagSet("webhook listen", flag.ContinueOnError)
+	forwardTo := flags.String("forward-to", "", "local URL the events are forwarded to, required")
+	events := flags.String("events", "*", "comma separated event types to forward")
+	interval := flags.Duration("interval", 5*time.Second, "how often the events are polled")
+	webhookURL := flags.String("webhook-url", "https://example.com/paypal-webhook-listen", "URL of the temporary webhook, PayPal never has to reach it")
+	bundlePath := flags.String("trust-bundle", "paypal-listen.pem", "file the trust bundle for paypaltest.VerifierFromTrustBundle is written to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *forwardTo == "" {
+		return errors.New("-forward-to is required")
+	}
+
+	clientID, secret := os.Getenv("PAYPAL_CLIENT_ID"), os.Getenv("PAYPAL_SECRET")
+	if clientID == "" || secret == "" {
+		return errors.New("PAYPAL_CLIENT_ID and PAYPAL_SECRET are required")
+	}
+	c, err := paypal.NewClient(clientID, secret, paypal.APIBaseSandBox)
+	if err != nil {
+		return err
+	}
+	if _, err := c.GetAccessToken(); err != nil {
+		return err
+	}
+
+	eventTypes := strings.Split(*events, ",")
+	subscribed := make([]*paypal.EventType, 0, len(eventTypes))
+	for i := range eventTypes {
+		eventTypes[i] = strings.TrimSpace(eventTypes[i])
+		subscribed = append(subscribed, &paypal.EventType{Name: eventTypes[i]})
+	}
+	webhook, err := c.CreateWebhook(&paypal.CreateWebhookRequest{
+		URL:        *webhookURL + "/" + strconv.FormatInt(time.Now().UnixNano(), 36),
+		EventTypes: subscribed,
+	})
+	if err != nil {
+		return fmt.Errorf("registering the temporary webhook: %v", err)
+	}
+	defer c.DeleteWebhook(webhook.ID)
+
+	signer, err := paypaltest.NewWebhookSigner(webhook.ID)
+	if err != nil {
+		return err
+	}
+	defer signer.Close()
+	if err := ioutil.WriteFile(*bundlePath, signer.TrustBundle(), 0644); err != nil {
+		return err
+	}
+	defer os.Remove(*bundlePath)
+
+	l := newListener(c, signer, *forwardTo, eventTypes, os.Stdout)
+	fmt.Fprintf(l.out, "Forwarding the events of webhook %s to %s, press Ctrl+C to stop\n", webhook.ID, *forwardTo)
+	fmt.Fprintf(l.out, "Verify the deliveries with paypaltest.VerifierFromTrustBundle(%q, <contents of %s>)\n", webhook.ID, *bundlePath)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		if err := l.poll(); err != nil {
+			fmt.Fprintf(l.out, "polling the events failed: %v\n", err)
+		}
+
+		select {
+		case <-stop:
+			fmt.Fprintf(l.out, "Deleting webhook %s\n", webhook.ID)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// newListener returns a listener forwarding the events of the types created from now on
+func newListener(c *paypal.Client, signer *paypaltest.WebhookSigner, forwardTo string, eventTypes []string, out io.Writer) *listener {
+	l := &listener{
+		client:     c,
+		signer:     signer,
+		forwardTo:  forwardTo,
+		eventTypes: map[string]bool{},
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		out:        out,
+		since:      time.Now().Add(-pollOverlap),
+		seen:       map[string]time.Time{},
+	}
+	for _, eventType := range eventTypes {
+		l.eventTypes[eventType] = true
+	}
+	return l
+}
+
+// poll forwards the events that were not forwarded yet, oldest first
+func (l *listener) poll() error {
+	events, err := l.client.ListAllWebhookEvents(&paypal.ListWebhookEventsRequest{StartTime: l.since, PageSize: 300})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreateTime < events[j].CreateTime
+	})
+
+	newest := l.since
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, event.CreateTime)
+		if err != nil {
+			created = time.Now()
+		} else if created.After(newest) {
+			newest = created
+		}
+
+		if _, seen := l.seen[event.ID]; seen || !(l.eventTypes["*"] || l.eventTypes[event.EventType]) {
+			continue
+		}
+		l.seen[event.ID] = created
+
+		status, err := l.forward(event)
+		if err != nil {
+			fmt.Fprintf(l.out, "%s  %s %s  forwarding failed: %v\n", time.Now().Format("15:04:05"), event.EventType, event.ID, err)
+			continue
+		}
+		fmt.Fprintf(l.out, "%s  %s %s  --> %d\n", time.Now().Format("15:04:05"), event.EventType, event.ID, status)
+	}
+
+	// The next poll starts shortly before the newest event, the events before it are not listed again
+	if since := newest.Add(-pollOverlap); since.After(l.since) {
+		l.since = since
+	}
+	for id, created := range l.seen {
+		if created.Before(l.since) {
+			delete(l.seen, id)
+		}
+	}
+	return nil
+}
+
+// forward posts the event to the local URL signed by the signer and returns the status code of the handler
+func (l *listener) forward(event *paypal.Event) (int, error) {
+	body := []byte(event.Raw())
+	if len(body) == 0 {
+		var err error
+		if body, err = json.Marshal(event); err != nil {
+			return 0, err
+		}
+	}
+	header, err := l.signer.Sign(body)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest("POST", l.forwardTo, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header = header
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
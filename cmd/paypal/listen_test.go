@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inplayer-org/paypal"
+	"github.com/inplayer-org/paypal/paypaltest"
+)
+
+func TestListenerForwardsVerifiedEvents(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/oauth2/token":
+			w.Write([]byte(`{"access_token":"A21AAF","token_type":"Bearer","expires_in":32400}`))
+		case "/v1/notifications/webhooks-events":
+			// newest first, like PayPal
+			w.Write([]byte(`{"events":[
+				{"id":"WH-2","create_time":"2020-01-15T10:01:00Z","resource_type":"refund","event_type":"PAYMENT.CAPTURE.REFUNDED","resource":{"id":"1Y107995YT783435V"}},
+				{"id":"WH-1","create_time":"2020-01-15T10:00:00Z","resource_type":"capture","event_type":"PAYMENT.CAPTURE.COMPLETED","resource":{"id":"42311647XV020574X"}}
+			],"count":2}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+
+	signer, err := paypaltest.NewWebhookSigner("8PT597110X687430LKGECATA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+
+	verifier, err := paypaltest.VerifierFromTrustBundle(signer.WebhookID, signer.TrustBundle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var handled []string
+	opts := (&paypal.WebhookHandlerOptions{Verifier: verifier}).On("*", func(r *http.Request, event *paypal.Event) error {
+		handled = append(handled, event.ID)
+		return nil
+	})
+	local := httptest.NewServer(paypal.WebhookHandler(nil, signer.WebhookID, opts))
+	defer local.Close()
+
+	c, _ := paypal.NewClient("foo", "bar", api.URL)
+	var out bytes.Buffer
+	l := newListener(c, signer, local.URL, []string{"*"}, &out)
+	l.since = time.Date(2020, 1, 15, 9, 59, 0, 0, time.UTC)
+	if err := l.poll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handled) != 2 || handled[0] != "WH-1" || handled[1] != "WH-2" {
+		t.Errorf("expected both events verified and forwarded once oldest first, got %v", handled)
+	}
+	if !strings.Contains(out.String(), "PAYMENT.CAPTURE.COMPLETED WH-1  --> 200") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	if want := time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC); !l.since.Equal(want) {
+		t.Errorf("expected the next poll to start %s before the newest event, got %s", pollOverlap, l.since)
+	}
+	if len(l.seen) != 2 {
+		t.Errorf("expected the events within the overlap to be remembered, got %v", l.seen)
+	}
+
+	l = newListener(c, signer, local.URL, []string{paypal.EventPaymentCaptureRefunded}, &out)
+	l.since = time.Date(2020, 1, 15, 9, 59, 0, 0, time.UTC)
+	handled = nil
+	l.poll()
+	if len(handled) != 1 || handled[0] != "WH-2" {
+		t.Errorf("expected only the refund to be forwarded, got %v", handled)
+	}
+}
+
+func TestListenerPrunesSeenEvents(t *testing.T) {
+	var startTimes []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/oauth2/token":
+			w.Write([]byte(`{"access_token":"A21AAF","token_type":"Bearer","expires_in":32400}`))
+		case "/v1/notifications/webhooks-events":
+			startTimes = append(startTimes, r.URL.Query().Get("start_time"))
+			if len(startTimes) == 1 {
+				w.Write([]byte(`{"events":[{"id":"WH-1","create_time":"2020-01-15T10:00:00Z","resource_type":"capture","event_type":"PAYMENT.CAPTURE.COMPLETED","resource":{}}],"count":1}`))
+				return
+			}
+			w.Write([]byte(`{"events":[{"id":"WH-2","create_time":"2020-01-15T10:05:00Z","resource_type":"capture","event_type":"PAYMENT.CAPTURE.COMPLETED","resource":{}}],"count":1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+
+	signer, err := paypaltest.NewWebhookSigner("8PT597110X687430LKGECATA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer local.Close()
+
+	c, _ := paypal.NewClient("foo", "bar", api.URL)
+	l := newListener(c, signer, local.URL, []string{"*"}, ioutil.Discard)
+	l.since = time.Date(2020, 1, 15, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		if err := l.poll(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(startTimes) != 2 || startTimes[0] != "2020-01-15T09:00:00Z" || startTimes[1] != "2020-01-15T09:59:00Z" {
+		t.Errorf("expected the second poll to start before the newest event, got %v", startTimes)
+	}
+	if _, ok := l.seen["WH-1"]; ok || len(l.seen) != 1 {
+		t.Errorf("expected the events before the overlap to be forgotten, got %v", l.seen)
+	}
+}
@@ -0,0 +1,33 @@
+// Command paypal is a development tool for applications built on the paypal package.
+//
+//	paypal webhook listen -forward-to http://localhost:8080/webhook
+//
+// forwards the webhook events of the sandbox application to a local handler, see listen.go.
+// The credentials of the sandbox application are read from PAYPAL_CLIENT_ID and PAYPAL_SECRET
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: paypal <command> [flags]
+
+Commands:
+  webhook listen    forward the webhook events of the sandbox application to a local URL
+
+The credentials of the sandbox application are read from PAYPAL_CLIENT_ID and PAYPAL_SECRET.
+Run "paypal <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 3 || os.Args[1] != "webhook" || os.Args[2] != "listen" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := listen(os.Args[3:]); err != nil {
+		fmt.Fprintf(os.Stderr, "paypal: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,93 @@
+package paypal
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Possible values for `flowEntry` in ConnectURLParams
+const (
+	ConnectFlowEntryStatic string = "static"
+	ConnectFlowEntryLogin  string = "login"
+)
+
+// ConnectURLParams represents the parameters of a Connect with PayPal (Log in with PayPal) authorization URL.
+// RedirectURI must match a return URL configured for the app, State is required and is checked by
+// ParseConnectReturn, use NewConnectState to generate it
+type ConnectURLParams struct {
+	Scopes      []string
+	RedirectURI string
+	State       string
+	FlowEntry   string //default: static
+	Nonce       string
+}
+
+// ConnectURL returns the URL to send the user to for consenting to the scopes, the user is then redirected to
+// RedirectURI with an authorization code to exchange with GrantNewAccessTokenFromAuthCode
+func (c *Client) ConnectURL(params *ConnectURLParams) (string, error) {
+	if params == nil || params.RedirectURI == "" || params.State == "" {
+		return "", fmt.Errorf("paypal: redirect_uri and state are required to build the connect URL")
+	}
+
+	scopes := params.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{ScopeOpenID}
+	}
+	flowEntry := params.FlowEntry
+	if flowEntry == "" {
+		flowEntry = ConnectFlowEntryStatic
+	}
+
+	q := url.Values{}
+	q.Set("flowEntry", flowEntry)
+	q.Set("client_id", c.ClientID)
+	q.Set("response_type", "code")
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("redirect_uri", params.RedirectURI)
+	q.Set("state", params.State)
+	if params.Nonce != "" {
+		q.Set("nonce", params.Nonce)
+	}
+
+	// Percent-encode spaces, PayPal does not accept + in the scope
+	return fmt.Sprintf("%s/connect?%s", c.webBase(), strings.Replace(q.Encode(), "+", "%20", -1)), nil
+}
+
+// NewConnectState returns a random state to pass to ConnectURL, store it in the user's session
+func NewConnectState() (string, error) {
+	return newRequestID()
+}
+
+// ParseConnectReturn validates the request PayPal redirects the user to after consent and returns the
+// authorization code. The state must match the one passed to ConnectURL, a user declining the consent
+// is reported as an error
+func ParseConnectReturn(r *http.Request, state string) (string, error) {
+	q := r.URL.Query()
+
+	if state == "" || subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(state)) != 1 {
+		return "", fmt.Errorf("paypal: connect return state does not match")
+	}
+	if e := q.Get("error"); e != "" {
+		return "", fmt.Errorf("paypal: connect consent failed: %s %s", e, q.Get("error_description"))
+	}
+
+	code := q.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("paypal: connect return has no authorization code")
+	}
+	return code, nil
+}
+
+// webBase returns the PayPal website matching the API base, e.g. https://www.sandbox.paypal.com for the sandbox
+func (c *Client) webBase() string {
+	switch c.APIBase {
+	case APIBaseSandBox:
+		return "https://www.sandbox.paypal.com"
+	case APIBaseLive:
+		return "https://www.paypal.com"
+	}
+	return c.APIBase
+}
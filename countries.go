@@ -0,0 +1,606 @@
+package paypal
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Possible values for `country_code` in Address, AddressPortable and ShippingDetailAddressPortable, the ISO-3166-1
+// alpha-2 codes and C2, which PayPal uses for China worldwide
+//
+// https://developer.paypal.com/reference/country-codes/
+const (
+	CountryCodeAD string = "AD"
+	CountryCodeAE string = "AE"
+	CountryCodeAF string = "AF"
+	CountryCodeAG string = "AG"
+	CountryCodeAI string = "AI"
+	CountryCodeAL string = "AL"
+	CountryCodeAM string = "AM"
+	CountryCodeAO string = "AO"
+	CountryCodeAQ string = "AQ"
+	CountryCodeAR string = "AR"
+	CountryCodeAS string = "AS"
+	CountryCodeAT string = "AT"
+	CountryCodeAU string = "AU"
+	CountryCodeAW string = "AW"
+	CountryCodeAX string = "AX"
+	CountryCodeAZ string = "AZ"
+	CountryCodeBA string = "BA"
+	CountryCodeBB string = "BB"
+	CountryCodeBD string = "BD"
+	CountryCodeBE string = "BE"
+	CountryCodeBF string = "BF"
+	CountryCodeBG string = "BG"
+	CountryCodeBH string = "BH"
+	CountryCodeBI string = "BI"
+	CountryCodeBJ string = "BJ"
+	CountryCodeBL string = "BL"
+	CountryCodeBM string = "BM"
+	CountryCodeBN string = "BN"
+	CountryCodeBO string = "BO"
+	CountryCodeBQ string = "BQ"
+	CountryCodeBR string = "BR"
+	CountryCodeBS string = "BS"
+	CountryCodeBT string = "BT"
+	CountryCodeBV string = "BV"
+	CountryCodeBW string = "BW"
+	CountryCodeBY string = "BY"
+	CountryCodeBZ string = "BZ"
+	CountryCodeCA string = "CA"
+	CountryCodeCC string = "CC"
+	CountryCodeCD string = "CD"
+	CountryCodeCF string = "CF"
+	CountryCodeCG string = "CG"
+	CountryCodeCH string = "CH"
+	CountryCodeCI string = "CI"
+	CountryCodeCK string = "CK"
+	CountryCodeCL string = "CL"
+	CountryCodeCM string = "CM"
+	CountryCodeCN string = "CN"
+	CountryCodeCO string = "CO"
+	CountryCodeCR string = "CR"
+	CountryCodeCU string = "CU"
+	CountryCodeCV string = "CV"
+	CountryCodeCW string = "CW"
+	CountryCodeCX string = "CX"
+	CountryCodeCY string = "CY"
+	CountryCodeCZ string = "CZ"
+	CountryCodeDE string = "DE"
+	CountryCodeDJ string = "DJ"
+	CountryCodeDK string = "DK"
+	CountryCodeDM string = "DM"
+	CountryCodeDO string = "DO"
+	CountryCodeDZ string = "DZ"
+	CountryCodeEC string = "EC"
+	CountryCodeEE string = "EE"
+	CountryCodeEG string = "EG"
+	CountryCodeEH string = "EH"
+	CountryCodeER string = "ER"
+	CountryCodeES string = "ES"
+	CountryCodeET string = "ET"
+	CountryCodeFI string = "FI"
+	CountryCodeFJ string = "FJ"
+	CountryCodeFK string = "FK"
+	CountryCodeFM string = "FM"
+	CountryCodeFO string = "FO"
+	CountryCodeFR string = "FR"
+	CountryCodeGA string = "GA"
+	CountryCodeGB string = "GB"
+	CountryCodeGD string = "GD"
+	CountryCodeGE string = "GE"
+	CountryCodeGF string = "GF"
+	CountryCodeGG string = "GG"
+	CountryCodeGH string = "GH"
+	CountryCodeGI string = "GI"
+	CountryCodeGL string = "GL"
+	CountryCodeGM string = "GM"
+	CountryCodeGN string = "GN"
+	CountryCodeGP string = "GP"
+	CountryCodeGQ string = "GQ"
+	CountryCodeGR string = "GR"
+	CountryCodeGS string = "GS"
+	CountryCodeGT string = "GT"
+	CountryCodeGU string = "GU"
+	CountryCodeGW string = "GW"
+	CountryCodeGY string = "GY"
+	CountryCodeHK string = "HK"
+	CountryCodeHM string = "HM"
+	CountryCodeHN string = "HN"
+	CountryCodeHR string = "HR"
+	CountryCodeHT string = "HT"
+	CountryCodeHU string = "HU"
+	CountryCodeID string = "ID"
+	CountryCodeIE string = "IE"
+	CountryCodeIL string = "IL"
+	CountryCodeIM string = "IM"
+	CountryCodeIN string = "IN"
+	CountryCodeIO string = "IO"
+	CountryCodeIQ string = "IQ"
+	CountryCodeIR string = "IR"
+	CountryCodeIS string = "IS"
+	CountryCodeIT string = "IT"
+	CountryCodeJE string = "JE"
+	CountryCodeJM string = "JM"
+	CountryCodeJO string = "JO"
+	CountryCodeJP string = "JP"
+	CountryCodeKE string = "KE"
+	CountryCodeKG string = "KG"
+	CountryCodeKH string = "KH"
+	CountryCodeKI string = "KI"
+	CountryCodeKM string = "KM"
+	CountryCodeKN string = "KN"
+	CountryCodeKP string = "KP"
+	CountryCodeKR string = "KR"
+	CountryCodeKW string = "KW"
+	CountryCodeKY string = "KY"
+	CountryCodeKZ string = "KZ"
+	CountryCodeLA string = "LA"
+	CountryCodeLB string = "LB"
+	CountryCodeLC string = "LC"
+	CountryCodeLI string = "LI"
+	CountryCodeLK string = "LK"
+	CountryCodeLR string = "LR"
+	CountryCodeLS string = "LS"
+	CountryCodeLT string = "LT"
+	CountryCodeLU string = "LU"
+	CountryCodeLV string = "LV"
+	CountryCodeLY string = "LY"
+	CountryCodeMA string = "MA"
+	CountryCodeMC string = "MC"
+	CountryCodeMD string = "MD"
+	CountryCodeME string = "ME"
+	CountryCodeMF string = "MF"
+	CountryCodeMG string = "MG"
+	CountryCodeMH string = "MH"
+	CountryCodeMK string = "MK"
+	CountryCodeML string = "ML"
+	CountryCodeMM string = "MM"
+	CountryCodeMN string = "MN"
+	CountryCodeMO string = "MO"
+	CountryCodeMP string = "MP"
+	CountryCodeMQ string = "MQ"
+	CountryCodeMR string = "MR"
+	CountryCodeMS string = "MS"
+	CountryCodeMT string = "MT"
+	CountryCodeMU string = "MU"
+	CountryCodeMV string = "MV"
+	CountryCodeMW string = "MW"
+	CountryCodeMX string = "MX"
+	CountryCodeMY string = "MY"
+	CountryCodeMZ string = "MZ"
+	CountryCodeNA string = "NA"
+	CountryCodeNC string = "NC"
+	CountryCodeNE string = "NE"
+	CountryCodeNF string = "NF"
+	CountryCodeNG string = "NG"
+	CountryCodeNI string = "NI"
+	CountryCodeNL string = "NL"
+	CountryCodeNO string = "NO"
+	CountryCodeNP string = "NP"
+	CountryCodeNR string = "NR"
+	CountryCodeNU string = "NU"
+	CountryCodeNZ string = "NZ"
+	CountryCodeOM string = "OM"
+	CountryCodePA string = "PA"
+	CountryCodePE string = "PE"
+	CountryCodePF string = "PF"
+	CountryCodePG string = "PG"
+	CountryCodePH string = "PH"
+	CountryCodePK string = "PK"
+	CountryCodePL string = "PL"
+	CountryCodePM string = "PM"
+	CountryCodePN string = "PN"
+	CountryCodePR string = "PR"
+	CountryCodePS string = "PS"
+	CountryCodePT string = "PT"
+	CountryCodePW string = "PW"
+	CountryCodePY string = "PY"
+	CountryCodeQA string = "QA"
+	CountryCodeRE string = "RE"
+	CountryCodeRO string = "RO"
+	CountryCodeRS string = "RS"
+	CountryCodeRU string = "RU"
+	CountryCodeRW string = "RW"
+	CountryCodeSA string = "SA"
+	CountryCodeSB string = "SB"
+	CountryCodeSC string = "SC"
+	CountryCodeSD string = "SD"
+	CountryCodeSE string = "SE"
+	CountryCodeSG string = "SG"
+	CountryCodeSH string = "SH"
+	CountryCodeSI string = "SI"
+	CountryCodeSJ string = "SJ"
+	CountryCodeSK string = "SK"
+	CountryCodeSL string = "SL"
+	CountryCodeSM string = "SM"
+	CountryCodeSN string = "SN"
+	CountryCodeSO string = "SO"
+	CountryCodeSR string = "SR"
+	CountryCodeSS string = "SS"
+	CountryCodeST string = "ST"
+	CountryCodeSV string = "SV"
+	CountryCodeSX string = "SX"
+	CountryCodeSY string = "SY"
+	CountryCodeSZ string = "SZ"
+	CountryCodeTC string = "TC"
+	CountryCodeTD string = "TD"
+	CountryCodeTF string = "TF"
+	CountryCodeTG string = "TG"
+	CountryCodeTH string = "TH"
+	CountryCodeTJ string = "TJ"
+	CountryCodeTK string = "TK"
+	CountryCodeTL string = "TL"
+	CountryCodeTM string = "TM"
+	CountryCodeTN string = "TN"
+	CountryCodeTO string = "TO"
+	CountryCodeTR string = "TR"
+	CountryCodeTT string = "TT"
+	CountryCodeTV string = "TV"
+	CountryCodeTW string = "TW"
+	CountryCodeTZ string = "TZ"
+	CountryCodeUA string = "UA"
+	CountryCodeUG string = "UG"
+	CountryCodeUM string = "UM"
+	CountryCodeUS string = "US"
+	CountryCodeUY string = "UY"
+	CountryCodeUZ string = "UZ"
+	CountryCodeVA string = "VA"
+	CountryCodeVC string = "VC"
+	CountryCodeVE string = "VE"
+	CountryCodeVG string = "VG"
+	CountryCodeVI string = "VI"
+	CountryCodeVN string = "VN"
+	CountryCodeVU string = "VU"
+	CountryCodeWF string = "WF"
+	CountryCodeWS string = "WS"
+	CountryCodeYE string = "YE"
+	CountryCodeYT string = "YT"
+	CountryCodeZA string = "ZA"
+	CountryCodeZM string = "ZM"
+	CountryCodeZW string = "ZW"
+	CountryCodeC2 string = "C2"
+)
+
+// countryCodes indexes the CountryCode* values
+var countryCodes = map[string]bool{
+	CountryCodeAD: true,
+	CountryCodeAE: true,
+	CountryCodeAF: true,
+	CountryCodeAG: true,
+	CountryCodeAI: true,
+	CountryCodeAL: true,
+	CountryCodeAM: true,
+	CountryCodeAO: true,
+	CountryCodeAQ: true,
+	CountryCodeAR: true,
+	CountryCodeAS: true,
+	CountryCodeAT: true,
+	CountryCodeAU: true,
+	CountryCodeAW: true,
+	CountryCodeAX: true,
+	CountryCodeAZ: true,
+	CountryCodeBA: true,
+	CountryCodeBB: true,
+	CountryCodeBD: true,
+	CountryCodeBE: true,
+	CountryCodeBF: true,
+	CountryCodeBG: true,
+	CountryCodeBH: true,
+	CountryCodeBI: true,
+	CountryCodeBJ: true,
+	CountryCodeBL: true,
+	CountryCodeBM: true,
+	CountryCodeBN: true,
+	CountryCodeBO: true,
+	CountryCodeBQ: true,
+	CountryCodeBR: true,
+	CountryCodeBS: true,
+	CountryCodeBT: true,
+	CountryCodeBV: true,
+	CountryCodeBW: true,
+	CountryCodeBY: true,
+	CountryCodeBZ: true,
+	CountryCodeCA: true,
+	CountryCodeCC: true,
+	CountryCodeCD: true,
+	CountryCodeCF: true,
+	CountryCodeCG: true,
+	CountryCodeCH: true,
+	CountryCodeCI: true,
+	CountryCodeCK: true,
+	CountryCodeCL: true,
+	CountryCodeCM: true,
+	CountryCodeCN: true,
+	CountryCodeCO: true,
+	CountryCodeCR: true,
+	CountryCodeCU: true,
+	CountryCodeCV: true,
+	CountryCodeCW: true,
+	CountryCodeCX: true,
+	CountryCodeCY: true,
+	CountryCodeCZ: true,
+	CountryCodeDE: true,
+	CountryCodeDJ: true,
+	CountryCodeDK: true,
+	CountryCodeDM: true,
+	CountryCodeDO: true,
+	CountryCodeDZ: true,
+	CountryCodeEC: true,
+	CountryCodeEE: true,
+	CountryCodeEG: true,
+	CountryCodeEH: true,
+	CountryCodeER: true,
+	CountryCodeES: true,
+	CountryCodeET: true,
+	CountryCodeFI: true,
+	CountryCodeFJ: true,
+	CountryCodeFK: true,
+	CountryCodeFM: true,
+	CountryCodeFO: true,
+	CountryCodeFR: true,
+	CountryCodeGA: true,
+	CountryCodeGB: true,
+	CountryCodeGD: true,
+	CountryCodeGE: true,
+	CountryCodeGF: true,
+	CountryCodeGG: true,
+	CountryCodeGH: true,
+	CountryCodeGI: true,
+	CountryCodeGL: true,
+	CountryCodeGM: true,
+	CountryCodeGN: true,
+	CountryCodeGP: true,
+	CountryCodeGQ: true,
+	CountryCodeGR: true,
+	CountryCodeGS: true,
+	CountryCodeGT: true,
+	CountryCodeGU: true,
+	CountryCodeGW: true,
+	CountryCodeGY: true,
+	CountryCodeHK: true,
+	CountryCodeHM: true,
+	CountryCodeHN: true,
+	CountryCodeHR: true,
+	CountryCodeHT: true,
+	CountryCodeHU: true,
+	CountryCodeID: true,
+	CountryCodeIE: true,
+	CountryCodeIL: true,
+	CountryCodeIM: true,
+	CountryCodeIN: true,
+	CountryCodeIO: true,
+	CountryCodeIQ: true,
+	CountryCodeIR: true,
+	CountryCodeIS: true,
+	CountryCodeIT: true,
+	CountryCodeJE: true,
+	CountryCodeJM: true,
+	CountryCodeJO: true,
+	CountryCodeJP: true,
+	CountryCodeKE: true,
+	CountryCodeKG: true,
+	CountryCodeKH: true,
+	CountryCodeKI: true,
+	CountryCodeKM: true,
+	CountryCodeKN: true,
+	CountryCodeKP: true,
+	CountryCodeKR: true,
+	CountryCodeKW: true,
+	CountryCodeKY: true,
+	CountryCodeKZ: true,
+	CountryCodeLA: true,
+	CountryCodeLB: true,
+	CountryCodeLC: true,
+	CountryCodeLI: true,
+	CountryCodeLK: true,
+	CountryCodeLR: true,
+	CountryCodeLS: true,
+	CountryCodeLT: true,
+	CountryCodeLU: true,
+	CountryCodeLV: true,
+	CountryCodeLY: true,
+	CountryCodeMA: true,
+	CountryCodeMC: true,
+	CountryCodeMD: true,
+	CountryCodeME: true,
+	CountryCodeMF: true,
+	CountryCodeMG: true,
+	CountryCodeMH: true,
+	CountryCodeMK: true,
+	CountryCodeML: true,
+	CountryCodeMM: true,
+	CountryCodeMN: true,
+	CountryCodeMO: true,
+	CountryCodeMP: true,
+	CountryCodeMQ: true,
+	CountryCodeMR: true,
+	CountryCodeMS: true,
+	CountryCodeMT: true,
+	CountryCodeMU: true,
+	CountryCodeMV: true,
+	CountryCodeMW: true,
+	CountryCodeMX: true,
+	CountryCodeMY: true,
+	CountryCodeMZ: true,
+	CountryCodeNA: true,
+	CountryCodeNC: true,
+	CountryCodeNE: true,
+	CountryCodeNF: true,
+	CountryCodeNG: true,
+	CountryCodeNI: true,
+	CountryCodeNL: true,
+	CountryCodeNO: true,
+	CountryCodeNP: true,
+	CountryCodeNR: true,
+	CountryCodeNU: true,
+	CountryCodeNZ: true,
+	CountryCodeOM: true,
+	CountryCodePA: true,
+	CountryCodePE: true,
+	CountryCodePF: true,
+	CountryCodePG: true,
+	CountryCodePH: true,
+	CountryCodePK: true,
+	CountryCodePL: true,
+	CountryCodePM: true,
+	CountryCodePN: true,
+	CountryCodePR: true,
+	CountryCodePS: true,
+	CountryCodePT: true,
+	CountryCodePW: true,
+	CountryCodePY: true,
+	CountryCodeQA: true,
+	CountryCodeRE: true,
+	CountryCodeRO: true,
+	CountryCodeRS: true,
+	CountryCodeRU: true,
+	CountryCodeRW: true,
+	CountryCodeSA: true,
+	CountryCodeSB: true,
+	CountryCodeSC: true,
+	CountryCodeSD: true,
+	CountryCodeSE: true,
+	CountryCodeSG: true,
+	CountryCodeSH: true,
+	CountryCodeSI: true,
+	CountryCodeSJ: true,
+	CountryCodeSK: true,
+	CountryCodeSL: true,
+	CountryCodeSM: true,
+	CountryCodeSN: true,
+	CountryCodeSO: true,
+	CountryCodeSR: true,
+	CountryCodeSS: true,
+	CountryCodeST: true,
+	CountryCodeSV: true,
+	CountryCodeSX: true,
+	CountryCodeSY: true,
+	CountryCodeSZ: true,
+	CountryCodeTC: true,
+	CountryCodeTD: true,
+	CountryCodeTF: true,
+	CountryCodeTG: true,
+	CountryCodeTH: true,
+	CountryCodeTJ: true,
+	CountryCodeTK: true,
+	CountryCodeTL: true,
+	CountryCodeTM: true,
+	CountryCodeTN: true,
+	CountryCodeTO: true,
+	CountryCodeTR: true,
+	CountryCodeTT: true,
+	CountryCodeTV: true,
+	CountryCodeTW: true,
+	CountryCodeTZ: true,
+	CountryCodeUA: true,
+	CountryCodeUG: true,
+	CountryCodeUM: true,
+	CountryCodeUS: true,
+	CountryCodeUY: true,
+	CountryCodeUZ: true,
+	CountryCodeVA: true,
+	CountryCodeVC: true,
+	CountryCodeVE: true,
+	CountryCodeVG: true,
+	CountryCodeVI: true,
+	CountryCodeVN: true,
+	CountryCodeVU: true,
+	CountryCodeWF: true,
+	CountryCodeWS: true,
+	CountryCodeYE: true,
+	CountryCodeYT: true,
+	CountryCodeZA: true,
+	CountryCodeZM: true,
+	CountryCodeZW: true,
+	CountryCodeC2: true,
+}
+
+// addressRule holds the address requirements of a country PayPal enforces or the postal services reject
+type addressRule struct {
+	stateRequired bool
+	postalCode    *regexp.Regexp // nil when the country has no postal code format to check
+}
+
+// addressRules lists the countries with address requirements, other countries only need a country code
+var addressRules = map[string]addressRule{
+	CountryCodeAU: {stateRequired: true, postalCode: regexp.MustCompile(`^\d{4}$`)},
+	CountryCodeBR: {stateRequired: true, postalCode: regexp.MustCompile(`^\d{5}-?\d{3}$`)},
+	CountryCodeCA: {stateRequired: true, postalCode: regexp.MustCompile(`^[A-Za-z]\d[A-Za-z] ?\d[A-Za-z]\d$`)},
+	CountryCodeCN: {stateRequired: true, postalCode: regexp.MustCompile(`^\d{6}$`)},
+	CountryCodeDE: {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	CountryCodeES: {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	CountryCodeFR: {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	CountryCodeGB: {postalCode: regexp.MustCompile(`^[A-Za-z]{1,2}\d[A-Za-z\d]? ?\d[A-Za-z]{2}$`)},
+	CountryCodeIN: {stateRequired: true, postalCode: regexp.MustCompile(`^\d{6}$`)},
+	CountryCodeIT: {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	CountryCodeJP: {stateRequired: true, postalCode: regexp.MustCompile(`^\d{3}-?\d{4}$`)},
+	CountryCodeMX: {stateRequired: true, postalCode: regexp.MustCompile(`^\d{5}$`)},
+	CountryCodeNL: {postalCode: regexp.MustCompile(`^\d{4} ?[A-Za-z]{2}$`)},
+	CountryCodeUS: {stateRequired: true, postalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`)},
+}
+
+// IsCountryCode reports whether code is one of the CountryCode* values
+func IsCountryCode(code string) bool {
+	return countryCodes[code]
+}
+
+// Validate checks the address against the requirements of its country, e.g. the state and ZIP code of US addresses
+func (a *Address) Validate() error {
+	err := firstError(
+		checkLength("line1", a.Line1, 1, 100),
+		checkLength("city", a.City, 1, 64),
+	)
+	if err != nil {
+		return err
+	}
+	return validateAddress(a.CountryCode, a.State, a.PostalCode, "country_code", "state", "postal_code")
+}
+
+// Validate checks the address against the requirements of its country, e.g. the state and ZIP code of US addresses
+func (a *AddressPortable) Validate() error {
+	err := firstError(
+		checkLength("address_line_1", a.AddressLine1, 0, 300),
+		checkLength("admin_area_2", a.AdminArea2, 0, 120),
+		checkLength("admin_area_1", a.AdminArea1, 0, 300),
+	)
+	if err != nil {
+		return err
+	}
+	return validateAddress(a.CountryCode, a.AdminArea1, a.PostalCode, "country_code", "admin_area_1", "postal_code")
+}
+
+// Validate checks the address against the requirements of its country, e.g. the state and ZIP code of US addresses
+func (a *ShippingDetailAddressPortable) Validate() error {
+	err := firstError(
+		checkLength("address_line_1", a.AddressLine1, 0, 300),
+		checkLength("admin_area_2", a.AdminArea2, 0, 120),
+		checkLength("admin_area_1", a.AdminArea1, 0, 300),
+	)
+	if err != nil {
+		return err
+	}
+	return validateAddress(a.CountryCode, a.AdminArea1, a.PostalCode, "country_code", "admin_area_1", "postal_code")
+}
+
+// validateAddress checks the country specific fields of an address, the field names differ between API versions
+func validateAddress(countryCode, state, postalCode, countryField, stateField, postalCodeField string) error {
+	if !IsCountryCode(countryCode) {
+		return &ValidationError{Field: countryField, Reason: fmt.Sprintf("%q is not an ISO-3166 country code", countryCode)}
+	}
+	if err := checkLength(postalCodeField, postalCode, 0, 60); err != nil {
+		return err
+	}
+
+	rule, ok := addressRules[countryCode]
+	if !ok {
+		return nil
+	}
+	if rule.stateRequired && state == "" {
+		return &ValidationError{Field: stateField, Reason: fmt.Sprintf("is required for %s addresses", countryCode)}
+	}
+	if rule.postalCode != nil && !rule.postalCode.MatchString(postalCode) {
+		return &ValidationError{Field: postalCodeField, Reason: fmt.Sprintf("%q is not a valid %s postal code", postalCode, countryCode)}
+	}
+	return nil
+}
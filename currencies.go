@@ -0,0 +1,224 @@
+package paypal
+
+import "fmt"
+
+type (
+	// CurrencyInfo describes an active ISO-4217 currency and whether the PayPal REST APIs accept it.
+	// Decimals is the number of fraction digits PayPal accepts, which is 0 for HUF and TWD although ISO-4217 defines 2
+	CurrencyInfo struct {
+		Code          string
+		Decimals      int
+		PayPal        bool // accepted by the PayPal REST APIs
+		InCountryOnly bool // accepted for payments between accounts of the currency's country only
+	}
+)
+
+// currencyTable lists the active ISO-4217 currencies, fund codes and precious metals are left out
+//
+// https://www.iso.org/iso-4217-currency-codes.html
+// https://developer.paypal.com/reference/currency-codes/
+var currencyTable = []CurrencyInfo{
+	{"AED", 2, false, false},
+	{"AFN", 2, false, false},
+	{"ALL", 2, false, false},
+	{"AMD", 2, false, false},
+	{"AOA", 2, false, false},
+	{"ARS", 2, false, false},
+	{"AUD", 2, true, false},
+	{"AWG", 2, false, false},
+	{"AZN", 2, false, false},
+	{"BAM", 2, false, false},
+	{"BBD", 2, false, false},
+	{"BDT", 2, false, false},
+	{"BGN", 2, false, false},
+	{"BHD", 3, false, false},
+	{"BIF", 0, false, false},
+	{"BMD", 2, false, false},
+	{"BND", 2, false, false},
+	{"BOB", 2, false, false},
+	{"BRL", 2, true, true},
+	{"BSD", 2, false, false},
+	{"BTN", 2, false, false},
+	{"BWP", 2, false, false},
+	{"BYN", 2, false, false},
+	{"BZD", 2, false, false},
+	{"CAD", 2, true, false},
+	{"CDF", 2, false, false},
+	{"CHF", 2, true, false},
+	{"CLP", 0, false, false},
+	{"CNY", 2, true, true},
+	{"COP", 2, false, false},
+	{"CRC", 2, false, false},
+	{"CUP", 2, false, false},
+	{"CVE", 2, false, false},
+	{"CZK", 2, true, false},
+	{"DJF", 0, false, false},
+	{"DKK", 2, true, false},
+	{"DOP", 2, false, false},
+	{"DZD", 2, false, false},
+	{"EGP", 2, false, false},
+	{"ERN", 2, false, false},
+	{"ETB", 2, false, false},
+	{"EUR", 2, true, false},
+	{"FJD", 2, false, false},
+	{"FKP", 2, false, false},
+	{"GBP", 2, true, false},
+	{"GEL", 2, false, false},
+	{"GHS", 2, false, false},
+	{"GIP", 2, false, false},
+	{"GMD", 2, false, false},
+	{"GNF", 0, false, false},
+	{"GTQ", 2, false, false},
+	{"GYD", 2, false, false},
+	{"HKD", 2, true, false},
+	{"HNL", 2, false, false},
+	{"HTG", 2, false, false},
+	{"HUF", 0, true, false},
+	{"IDR", 2, false, false},
+	{"ILS", 2, true, false},
+	{"INR", 2, false, false},
+	{"IQD", 3, false, false},
+	{"IRR", 2, false, false},
+	{"ISK", 0, false, false},
+	{"JMD", 2, false, false},
+	{"JOD", 3, false, false},
+	{"JPY", 0, true, false},
+	{"KES", 2, false, false},
+	{"KGS", 2, false, false},
+	{"KHR", 2, false, false},
+	{"KMF", 0, false, false},
+	{"KPW", 2, false, false},
+	{"KRW", 0, false, false},
+	{"KWD", 3, false, false},
+	{"KYD", 2, false, false},
+	{"KZT", 2, false, false},
+	{"LAK", 2, false, false},
+	{"LBP", 2, false, false},
+	{"LKR", 2, false, false},
+	{"LRD", 2, false, false},
+	{"LSL", 2, false, false},
+	{"LYD", 3, false, false},
+	{"MAD", 2, false, false},
+	{"MDL", 2, false, false},
+	{"MGA", 2, false, false},
+	{"MKD", 2, false, false},
+	{"MMK", 2, false, false},
+	{"MNT", 2, false, false},
+	{"MOP", 2, false, false},
+	{"MRU", 2, false, false},
+	{"MUR", 2, false, false},
+	{"MVR", 2, false, false},
+	{"MWK", 2, false, false},
+	{"MXN", 2, true, false},
+	{"MYR", 2, true, true},
+	{"MZN", 2, false, false},
+	{"NAD", 2, false, false},
+	{"NGN", 2, false, false},
+	{"NIO", 2, false, false},
+	{"NOK", 2, true, false},
+	{"NPR", 2, false, false},
+	{"NZD", 2, true, false},
+	{"OMR", 3, false, false},
+	{"PAB", 2, false, false},
+	{"PEN", 2, false, false},
+	{"PGK", 2, false, false},
+	{"PHP", 2, true, false},
+	{"PKR", 2, false, false},
+	{"PLN", 2, true, false},
+	{"PYG", 0, false, false},
+	{"QAR", 2, false, false},
+	{"RON", 2, false, false},
+	{"RSD", 2, false, false},
+	{"RUB", 2, false, false},
+	{"RWF", 0, false, false},
+	{"SAR", 2, false, false},
+	{"SBD", 2, false, false},
+	{"SCR", 2, false, false},
+	{"SDG", 2, false, false},
+	{"SEK", 2, true, false},
+	{"SGD", 2, true, false},
+	{"SHP", 2, false, false},
+	{"SLE", 2, false, false},
+	{"SOS", 2, false, false},
+	{"SRD", 2, false, false},
+	{"SSP", 2, false, false},
+	{"STN", 2, false, false},
+	{"SVC", 2, false, false},
+	{"SYP", 2, false, false},
+	{"SZL", 2, false, false},
+	{"THB", 2, true, false},
+	{"TJS", 2, false, false},
+	{"TMT", 2, false, false},
+	{"TND", 3, false, false},
+	{"TOP", 2, false, false},
+	{"TRY", 2, false, false},
+	{"TTD", 2, false, false},
+	{"TWD", 0, true, false},
+	{"TZS", 2, false, false},
+	{"UAH", 2, false, false},
+	{"UGX", 0, false, false},
+	{"USD", 2, true, false},
+	{"UYU", 2, false, false},
+	{"UZS", 2, false, false},
+	{"VED", 2, false, false},
+	{"VES", 2, false, false},
+	{"VND", 0, false, false},
+	{"VUV", 0, false, false},
+	{"WST", 2, false, false},
+	{"XAF", 0, false, false},
+	{"XCD", 2, false, false},
+	{"XCG", 2, false, false},
+	{"XOF", 0, false, false},
+	{"XPF", 0, false, false},
+	{"YER", 2, false, false},
+	{"ZAR", 2, false, false},
+	{"ZMW", 2, false, false},
+	{"ZWG", 2, false, false},
+}
+
+// currencies indexes currencyTable by code
+var currencies = func() map[string]CurrencyInfo {
+	index := make(map[string]CurrencyInfo, len(currencyTable))
+	for _, c := range currencyTable {
+		index[c.Code] = c
+	}
+	return index
+}()
+
+// LookupCurrency returns the currency with the ISO-4217 code, codes are upper case
+func LookupCurrency(code string) (CurrencyInfo, bool) {
+	c, ok := currencies[code]
+	return c, ok
+}
+
+// PayPalCurrencies returns the currencies the PayPal REST APIs accept
+func PayPalCurrencies() []CurrencyInfo {
+	var supported []CurrencyInfo
+	for _, c := range currencyTable {
+		if c.PayPal {
+			supported = append(supported, c)
+		}
+	}
+	return supported
+}
+
+// CurrencyDecimals returns the number of fraction digits PayPal accepts for the currency, e.g. 0 for JPY,
+// 2 for USD and 3 for TND. Unknown currencies get 2
+func CurrencyDecimals(currency string) int {
+	if c, ok := currencies[currency]; ok {
+		return c.Decimals
+	}
+	return 2
+}
+
+// checkCurrency checks the code is an ISO-4217 currency the PayPal REST APIs accept
+func checkCurrency(field, code string) error {
+	c, ok := currencies[code]
+	switch {
+	case !ok:
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("%q is not an ISO-4217 currency code", code)}
+	case !c.PayPal:
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("%s is not a currency PayPal supports", code)}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package paypal
+
+import (
+	"fmt"
+	"sort"
+)
+
+type (
+	// CurrencyPlans represents a plan sold in several currencies. PayPal plans have a single currency, so Plan
+	// is created once per currency of Prices under the same product, with the prices of the currency
+	CurrencyPlans struct {
+		// Plan is the definition shared by the plans, its prices and setup fee are replaced for each currency
+		Plan   *CreatePlan
+		Prices map[string]*CurrencyPrice
+		// PlanName returns the name of the plan of the currency, "<Plan.Name> (<currency>)" when nil.
+		// The existing plans of the product are matched by this name
+		PlanName func(currency string) string
+	}
+
+	// CurrencyPrice represents the prices of a plan in a currency
+	CurrencyPrice struct {
+		// Cycles has the price of every billing cycle with a pricing scheme by its sequence
+		Cycles map[uint64]string
+		// SetupFee is only set on creation, the setup fee of existing plans is left as is
+		SetupFee string
+	}
+)
+
+// Currencies returns the currencies of the prices, sorted
+func (p *CurrencyPlans) Currencies() []string {
+	currencies := make([]string, 0, len(p.Prices))
+	for currency := range p.Prices {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// Name returns the name of the plan of the currency
+func (p *CurrencyPlans) Name(currency string) string {
+	if p.PlanName != nil {
+		return p.PlanName(currency)
+	}
+	return fmt.Sprintf("%s (%s)", p.Plan.Name, currency)
+}
+
+// CurrencyPlan returns the plan to create for the currency: a copy of Plan with its name, the prices of its
+// billing cycles and setup fee in the currency. Every billing cycle with a pricing scheme needs a price
+func (p *CurrencyPlans) CurrencyPlan(currency string) (*CreatePlan, error) {
+	if p.Plan == nil {
+		return nil, fmt.Errorf("paypal: a plan is required to create plans per currency")
+	}
+	prices := p.Prices[currency]
+	if prices == nil {
+		return nil, fmt.Errorf("paypal: no prices in %s", currency)
+	}
+
+	plan := p.Plan.Clone()
+	plan.Name = p.Name(currency)
+	for _, cycle := range plan.BillingCycles {
+		if cycle == nil || cycle.PricingScheme == nil {
+			continue
+		}
+		price, ok := prices.Cycles[cycle.Sequence]
+		if !ok {
+			return nil, fmt.Errorf("paypal: no %s price for billing cycle %d", currency, cycle.Sequence)
+		}
+		cycle.PricingScheme.FixedPrice = &Money{Currency: currency, Value: price}
+	}
+
+	if plan.PaymentPreferences != nil {
+		plan.PaymentPreferences.SetupFee = nil
+		if prices.SetupFee != "" {
+			plan.PaymentPreferences.SetupFee = &Money{Currency: currency, Value: prices.SetupFee}
+		}
+	} else if prices.SetupFee != "" {
+		plan.PaymentPreferences = &PaymentPreferences{SetupFee: &Money{Currency: currency, Value: prices.SetupFee}}
+	}
+
+	return plan, nil
+}
+
+// SyncCurrencyPlans creates the plan of every currency of plans that the product does not have yet and updates
+// the prices of the billing cycles of the existing ones when they changed. It returns the plan ID of every
+// currency, the plans synced before a failure are in the map returned with the error
+// Endpoint: GET /v1/billing/plans, POST /v1/billing/plans, POST /v1/billing/plans/{plan_id}/update-pricing-schemes
+func (c *Client) SyncCurrencyPlans(plans *CurrencyPlans) (map[string]string, error) {
+	planIDs := map[string]string{}
+	if plans == nil || plans.Plan == nil || plans.Plan.ProductID == "" {
+		return planIDs, fmt.Errorf("paypal: a plan with a product ID is required to sync plans per currency")
+	}
+
+	existing, err := c.ListAllPlansConcurrently(&ListPlansParams{ProductID: plans.Plan.ProductID, PageSize: 20}, 0)
+	if err != nil {
+		return planIDs, err
+	}
+	byName := map[string]*Plan{}
+	for _, plan := range existing {
+		if plan != nil && plan.Status != PlanStatusInactive {
+			byName[plan.Name] = plan
+		}
+	}
+
+	for _, currency := range plans.Currencies() {
+		plan, err := plans.CurrencyPlan(currency)
+		if err != nil {
+			return planIDs, err
+		}
+
+		current, ok := byName[plan.Name]
+		if !ok {
+			created, err := c.CreatePlan(plan)
+			if err != nil {
+				return planIDs, err
+			}
+			planIDs[currency] = created.ID
+			continue
+		}
+
+		if err := c.syncPlanPrices(current.ID, plan); err != nil {
+			return planIDs, err
+		}
+		planIDs[currency] = current.ID
+	}
+
+	return planIDs, nil
+}
+
+// syncPlanPrices updates the pricing schemes of the plan that differ from the ones of want
+func (c *Client) syncPlanPrices(planID string, want *CreatePlan) error {
+	current, err := c.ShowPlan(planID)
+	if err != nil {
+		return err
+	}
+
+	prices := map[uint64]*Money{}
+	for _, cycle := range current.BillingCycles {
+		if cycle != nil && cycle.PricingScheme != nil {
+			prices[cycle.Sequence] = cycle.PricingScheme.FixedPrice
+		}
+	}
+
+	update := UpdatePricingSchemasListRequest{}
+	for _, cycle := range want.BillingCycles {
+		if cycle == nil || cycle.PricingScheme == nil {
+			continue
+		}
+		if moneyEqual(prices[cycle.Sequence], cycle.PricingScheme.FixedPrice) {
+			continue
+		}
+		update.PricingSchemes = append(update.PricingSchemes, &UpdatePricingSchemaRequest{
+			BillingCycleSequence: cycle.Sequence,
+			PricingScheme:        &PricingScheme{FixedPrice: cycle.PricingScheme.FixedPrice},
+		})
+	}
+	if len(update.PricingSchemes) == 0 {
+		return nil
+	}
+
+	return c.UpdatePricing(planID, update)
+}
@@ -0,0 +1,101 @@
+package paypal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newCurrencyPlans() *CurrencyPlans {
+	return &CurrencyPlans{
+		Plan: &CreatePlan{
+			ProductID: "PROD-XXCD1234QWER65782",
+			Name:      "Pro",
+			Status:    PlanStatusActive,
+			BillingCycles: []*BillingCycle{
+				{Frequency: &Frequency{IntervalUnit: IntervalUnitMonth, IntervalCount: 1}, TenureType: TenureTypeTrial, Sequence: 1, TotalCycles: 1},
+				{PricingScheme: &PricingScheme{}, Frequency: &Frequency{IntervalUnit: IntervalUnitMonth, IntervalCount: 1}, TenureType: TenureTypeRegular, Sequence: 2},
+			},
+			PaymentPreferences: &PaymentPreferences{AutoBillOutstanding: true},
+		},
+		Prices: map[string]*CurrencyPrice{
+			"USD": {Cycles: map[uint64]string{2: "10.99"}},
+			"EUR": {Cycles: map[uint64]string{2: "9.99"}, SetupFee: "5.00"},
+		},
+	}
+}
+
+func TestCurrencyPlans_CurrencyPlan(t *testing.T) {
+	plans := newCurrencyPlans()
+
+	plan, err := plans.CurrencyPlan("EUR")
+	if err != nil {
+		t.Fatalf("Not expected error for CurrencyPlan, got %v", err)
+	}
+	if plan.Name != "Pro (EUR)" || plan.BillingCycles[0].PricingScheme != nil ||
+		plan.BillingCycles[1].PricingScheme.FixedPrice.Value != "9.99" || plan.BillingCycles[1].PricingScheme.FixedPrice.Currency != "EUR" ||
+		plan.PaymentPreferences.SetupFee.Value != "5.00" {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+	if plans.Plan.BillingCycles[1].PricingScheme.FixedPrice != nil || plans.Plan.Name != "Pro" {
+		t.Errorf("expected the base plan to be left as is")
+	}
+
+	if _, err := plans.CurrencyPlan("GBP"); err == nil {
+		t.Errorf("expected an error for a currency without prices")
+	}
+	plans.Prices["GBP"] = &CurrencyPrice{Cycles: map[uint64]string{1: "8.99"}}
+	if _, err := plans.CurrencyPlan("GBP"); err == nil {
+		t.Errorf("expected an error for a billing cycle without price")
+	}
+}
+
+func TestSyncCurrencyPlans(t *testing.T) {
+	var created []*CreatePlan
+	var updates []UpdatePricingSchemasListRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1/billing/plans":
+			if r.URL.Query().Get("product_id") != "PROD-XXCD1234QWER65782" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"plans":[{"id":"P-USD","product_id":"PROD-XXCD1234QWER65782","name":"Pro (USD)","status":"ACTIVE"},{"id":"P-OLD","name":"Pro (EUR)","status":"INACTIVE"}],"total_pages":1}`)
+		case r.Method == "GET" && r.URL.Path == "/v1/billing/plans/P-USD":
+			fmt.Fprint(w, `{"id":"P-USD","name":"Pro (USD)","status":"ACTIVE","billing_cycles":[{"frequency":{"interval_unit":"MONTH","interval_count":1},"tenure_type":"TRIAL","sequence":1,"total_cycles":1},{"pricing_scheme":{"version":1,"fixed_price":{"currency_code":"USD","value":"9.99"}},"frequency":{"interval_unit":"MONTH","interval_count":1},"tenure_type":"REGULAR","sequence":2}]}`)
+		case r.Method == "POST" && r.URL.Path == "/v1/billing/plans":
+			plan := &CreatePlan{}
+			json.NewDecoder(r.Body).Decode(plan)
+			created = append(created, plan)
+			fmt.Fprint(w, `{"id":"P-EUR","status":"ACTIVE"}`)
+		case r.Method == "POST" && r.URL.Path == "/v1/billing/plans/P-USD/update-pricing-schemes":
+			update := UpdatePricingSchemasListRequest{}
+			json.NewDecoder(r.Body).Decode(&update)
+			updates = append(updates, update)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	planIDs, err := c.SyncCurrencyPlans(newCurrencyPlans())
+	if err != nil {
+		t.Fatalf("Not expected error for SyncCurrencyPlans, got %v", err)
+	}
+	if planIDs["USD"] != "P-USD" || planIDs["EUR"] != "P-EUR" || len(planIDs) != 2 {
+		t.Errorf("unexpected plan IDs %v", planIDs)
+	}
+	if len(created) != 1 || created[0].Name != "Pro (EUR)" || created[0].BillingCycles[1].PricingScheme.FixedPrice.Value != "9.99" {
+		t.Errorf("expected the EUR plan to be created, got %+v", created)
+	}
+	if len(updates) != 1 || len(updates[0].PricingSchemes) != 1 || updates[0].PricingSchemes[0].BillingCycleSequence != 2 ||
+		updates[0].PricingSchemes[0].PricingScheme.FixedPrice.Value != "10.99" {
+		t.Errorf("expected the USD price to be updated, got %+v", updates)
+	}
+}
@@ -0,0 +1,94 @@
+package paypal
+
+import "fmt"
+
+// DigitalGoodsOrder represents the simplest checkout: a single digital item or donation paid at once, without
+// shipping. Only Amount, Currency and Description are required, the item is named after Description when Name
+// is empty. ReturnURL and CancelURL are needed when the buyer is redirected to approve the order
+type DigitalGoodsOrder struct {
+	Amount      string
+	Currency    string
+	Description string
+	Name        string
+	SKU         string
+	CustomID    string
+	InvoiceID   string
+	BrandName   string
+	ReturnURL   string
+	CancelURL   string
+}
+
+// NewDigitalGoodsOrder returns a DigitalGoodsOrder of amount in currency, e.g. "10.00" and "USD"
+func NewDigitalGoodsOrder(amount, currency, description string) *DigitalGoodsOrder {
+	return &DigitalGoodsOrder{Amount: amount, Currency: currency, Description: description}
+}
+
+// PurchaseUnit builds the purchase unit to pass to CreateOrder: one DIGITAL_GOODS item of the amount with the
+// item_total breakdown PayPal requires for items, the amount formatted with the precision of the currency
+func (o *DigitalGoodsOrder) PurchaseUnit() (PurchaseUnitRequest, error) {
+	if o.Currency == "" {
+		return PurchaseUnitRequest{}, fmt.Errorf("paypal: digital goods order currency is required")
+	}
+	if o.Description == "" && o.Name == "" {
+		return PurchaseUnitRequest{}, fmt.Errorf("paypal: digital goods order description is required")
+	}
+
+	amount, err := parseMoney(&Money{Currency: o.Currency, Value: o.Amount}, o.Currency, "digital goods order amount")
+	if err != nil {
+		return PurchaseUnitRequest{}, err
+	}
+	if amount.Sign() == 0 {
+		return PurchaseUnitRequest{}, fmt.Errorf("paypal: digital goods order amount must be greater than 0")
+	}
+
+	name := o.Name
+	if name == "" {
+		name = o.Description
+	}
+	value := formatCurrency(amount, o.Currency)
+
+	return PurchaseUnitRequest{
+		Amount: &PurchaseUnitAmount{
+			Currency: o.Currency,
+			Value:    value,
+			Breakdown: &PurchaseUnitAmountBreakdown{
+				ItemTotal: &Money{Currency: o.Currency, Value: value},
+			},
+		},
+		Description: o.Description,
+		CustomID:    o.CustomID,
+		InvoiceID:   o.InvoiceID,
+		Items: []Item{{
+			Name:        name,
+			UnitAmount:  &Money{Currency: o.Currency, Value: value},
+			Quantity:    "1",
+			Description: o.Description,
+			SKU:         o.SKU,
+			Category:    ItemCategoryDigitalGood,
+		}},
+	}, nil
+}
+
+// ApplicationContext returns the application context of the checkout: no shipping address is asked for and the
+// buyer pays on PayPal with the "Pay Now" button instead of reviewing the order on your site first
+func (o *DigitalGoodsOrder) ApplicationContext() *ApplicationContext {
+	return &ApplicationContext{
+		BrandName:          o.BrandName,
+		ShippingPreference: ShippingPreferenceNoShipping,
+		UserAction:         UserActionPayNow,
+		ReturnURL:          o.ReturnURL,
+		CancelURL:          o.CancelURL,
+	}
+}
+
+// CreateDigitalGoodsOrder creates an order to capture for the digital item or donation, see
+// DigitalGoodsOrder.PurchaseUnit and DigitalGoodsOrder.ApplicationContext
+// Endpoint: POST /v2/checkout/orders
+func (c *Client) CreateDigitalGoodsOrder(order *DigitalGoodsOrder) (*Order, error) {
+	unit, err := order.PurchaseUnit()
+	if err != nil {
+		return &Order{}, err
+	}
+
+	return c.CreateOrder(OrderIntentCapture, []PurchaseUnitRequest{unit}, nil, order.ApplicationContext())
+}
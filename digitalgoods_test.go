@@ -0,0 +1,74 @@
+package paypal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDigitalGoodsOrder_PurchaseUnit(t *testing.T) {
+	unit, err := NewDigitalGoodsOrder("5", "USD", "Donation to the Wikimedia Foundation").PurchaseUnit()
+	if err != nil {
+		t.Fatalf("Not expected error for PurchaseUnit, got %v", err)
+	}
+	if unit.Amount.Value != "5.00" || unit.Amount.Breakdown.ItemTotal.Value != "5.00" || len(unit.Items) != 1 {
+		t.Fatalf("unexpected purchase unit %+v", unit)
+	}
+	if item := unit.Items[0]; item.Name != "Donation to the Wikimedia Foundation" || item.Quantity != "1" ||
+		item.Category != ItemCategoryDigitalGood || item.UnitAmount.Value != "5.00" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if err := unit.Validate(); err != nil {
+		t.Errorf("Not expected validation error, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		order *DigitalGoodsOrder
+	}{
+		{"no currency", NewDigitalGoodsOrder("5.00", "", "Donation")},
+		{"no description", NewDigitalGoodsOrder("5.00", "USD", "")},
+		{"invalid amount", NewDigitalGoodsOrder("five", "USD", "Donation")},
+		{"zero amount", NewDigitalGoodsOrder("0", "USD", "Donation")},
+		{"negative amount", NewDigitalGoodsOrder("-5", "USD", "Donation")},
+		{"too precise amount", NewDigitalGoodsOrder("500.5", "JPY", "Donation")},
+	}
+	for _, tt := range tests {
+		if _, err := tt.order.PurchaseUnit(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestCreateDigitalGoodsOrder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Intent             OrderIntent           `json:"intent"`
+			PurchaseUnits      []PurchaseUnitRequest `json:"purchase_units"`
+			ApplicationContext *ApplicationContext   `json:"application_context"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Intent != OrderIntentCapture || len(body.PurchaseUnits) != 1 || body.PurchaseUnits[0].Amount.Value != "1200" {
+			t.Errorf("unexpected order %+v", body)
+		}
+		if ctx := body.ApplicationContext; ctx == nil || ctx.ShippingPreference != ShippingPreferenceNoShipping ||
+			ctx.UserAction != UserActionPayNow || ctx.ReturnURL != "https://example.com/return" {
+			t.Errorf("unexpected application context %+v", body.ApplicationContext)
+		}
+		fmt.Fprint(w, `{"id":"5O190127TN364715T","status":"CREATED"}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	order := NewDigitalGoodsOrder("1200", "JPY", "E-book: The Go Programming Language")
+	order.ReturnURL = "https://example.com/return"
+	order.CancelURL = "https://example.com/cancel"
+	created, err := c.CreateDigitalGoodsOrder(order)
+	if err != nil || created.ID != "5O190127TN364715T" {
+		t.Errorf("unexpected order %+v, %v", created, err)
+	}
+}
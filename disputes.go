@@ -0,0 +1,582 @@
+package paypal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Possible values for `reason` in Dispute and DisputeSummary
+const (
+	DisputeReasonMerchandiseOrServiceNotReceived    string = "MERCHANDISE_OR_SERVICE_NOT_RECEIVED"
+	DisputeReasonMerchandiseOrServiceNotAsDescribed string = "MERCHANDISE_OR_SERVICE_NOT_AS_DESCRIBED"
+	DisputeReasonUnauthorised                       string = "UNAUTHORISED"
+	DisputeReasonCreditNotProcessed                 string = "CREDIT_NOT_PROCESSED"
+	DisputeReasonDuplicateTransaction               string = "DUPLICATE_TRANSACTION"
+	DisputeReasonIncorrectAmount                    string = "INCORRECT_AMOUNT"
+	DisputeReasonPaymentByOtherMeans                string = "PAYMENT_BY_OTHER_MEANS"
+	DisputeReasonCanceledRecurringBilling           string = "CANCELED_RECURRING_BILLING"
+	DisputeReasonProblemWithRemittance              string = "PROBLEM_WITH_REMITTANCE"
+	DisputeReasonOther                              string = "OTHER"
+)
+
+// Possible values for `status` in Dispute and DisputeSummary
+const (
+	DisputeStatusOpen                     string = "OPEN"
+	DisputeStatusWaitingForBuyerResponse  string = "WAITING_FOR_BUYER_RESPONSE"
+	DisputeStatusWaitingForSellerResponse string = "WAITING_FOR_SELLER_RESPONSE"
+	DisputeStatusUnderReview              string = "UNDER_REVIEW"
+	DisputeStatusResolved                 string = "RESOLVED"
+	DisputeStatusOther                    string = "OTHER"
+)
+
+// Possible values for `dispute_state` in ListDisputesRequest and DisputeSummary
+const (
+	DisputeStateRequiredAction           string = "REQUIRED_ACTION"
+	DisputeStateRequiredOtherPartyAction string = "REQUIRED_OTHER_PARTY_ACTION"
+	DisputeStateUnderPayPalReview        string = "UNDER_PAYPAL_REVIEW"
+	DisputeStateResolved                 string = "RESOLVED"
+	DisputeStateOpenInquiries            string = "OPEN_INQUIRIES"
+	DisputeStateAppealable               string = "APPEALABLE"
+)
+
+// Possible values for `dispute_life_cycle_stage` in Dispute and DisputeSummary
+const (
+	DisputeLifeCycleStageInquiry        string = "INQUIRY"
+	DisputeLifeCycleStageChargeback     string = "CHARGEBACK"
+	DisputeLifeCycleStagePreArbitration string = "PRE_ARBITRATION"
+	DisputeLifeCycleStageArbitration    string = "ARBITRATION"
+)
+
+// Possible values for `dispute_channel` in Dispute and DisputeSummary
+const (
+	DisputeChannelInternal string = "INTERNAL"
+	DisputeChannelExternal string = "EXTERNAL"
+)
+
+// Possible values for `outcome_code` in DisputeOutcome
+const (
+	DisputeOutcomeResolvedBuyerFavour  string = "RESOLVED_BUYER_FAVOUR"
+	DisputeOutcomeResolvedSellerFavour string = "RESOLVED_SELLER_FAVOUR"
+	DisputeOutcomeResolvedWithPayout   string = "RESOLVED_WITH_PAYOUT"
+	DisputeOutcomeCanceledByBuyer      string = "CANCELED_BY_BUYER"
+	DisputeOutcomeAccepted             string = "ACCEPTED"
+	DisputeOutcomeDenied               string = "DENIED"
+	DisputeOutcomeNone                 string = "NONE"
+)
+
+// Possible values for `accept_claim_reason` in AcceptClaimRequest
+const (
+	AcceptClaimReasonDidNotShipItem   string = "DID_NOT_SHIP_ITEM"
+	AcceptClaimReasonTooTimeConsuming string = "TOO_TIME_CONSUMING"
+	AcceptClaimReasonLostInMail       string = "LOST_IN_MAIL"
+	AcceptClaimReasonNotAbleToWin     string = "NOT_ABLE_TO_WIN"
+	AcceptClaimReasonCompanyPolicy    string = "COMPANY_POLICY"
+	AcceptClaimReasonNotSet           string = "REASON_NOT_SET"
+)
+
+// Possible values for `accept_claim_type` in AcceptClaimRequest
+const (
+	AcceptClaimTypeRefund           string = "REFUND"
+	AcceptClaimTypeRefundWithReturn string = "REFUND_WITH_RETURN"
+)
+
+// Possible values for `posted_by` in DisputeMessage
+const (
+	DisputeMessagePostedByBuyer  string = "BUYER"
+	DisputeMessagePostedBySeller string = "SELLER"
+)
+
+// Possible values for `action` in RequireEvidenceRequest
+const (
+	RequireEvidenceActionBuyerEvidence  string = "BUYER_EVIDENCE"
+	RequireEvidenceActionSellerEvidence string = "SELLER_EVIDENCE"
+)
+
+// Possible values for `offer_type` in MakeOfferRequest
+const (
+	OfferTypeRefund                   string = "REFUND"
+	OfferTypeRefundWithReturn         string = "REFUND_WITH_RETURN"
+	OfferTypeRefundWithReplacement    string = "REFUND_WITH_REPLACEMENT"
+	OfferTypeReplacementWithoutRefund string = "REPLACEMENT_WITHOUT_REFUND"
+)
+
+// Possible values for `evidence_type` in Evidence
+const (
+	EvidenceTypeProofOfFulfillment         string = "PROOF_OF_FULFILLMENT"
+	EvidenceTypeProofOfRefund              string = "PROOF_OF_REFUND"
+	EvidenceTypeProofOfDeliverySignature   string = "PROOF_OF_DELIVERY_SIGNATURE"
+	EvidenceTypeProofOfReceiptCopy         string = "PROOF_OF_RECEIPT_COPY"
+	EvidenceTypeReturnPolicy               string = "RETURN_POLICY"
+	EvidenceTypeBillingAgreement           string = "BILLING_AGREEMENT"
+	EvidenceTypeProofOfReshipment          string = "PROOF_OF_RESHIPMENT"
+	EvidenceTypeItemDescription            string = "ITEM_DESCRIPTION"
+	EvidenceTypePoliceReport               string = "POLICE_REPORT"
+	EvidenceTypeAffidavit                  string = "AFFIDAVIT"
+	EvidenceTypePaidWithOtherMethod        string = "PAID_WITH_OTHER_METHOD"
+	EvidenceTypeCopyOfContract             string = "COPY_OF_CONTRACT"
+	EvidenceTypeProofOfReturn              string = "PROOF_OF_RETURN"
+	EvidenceTypeProofOfRefundOutsidePayPal string = "PROOF_OF_REFUND_OUTSIDE_PAYPAL"
+	EvidenceTypeProofOfShipmentPostage     string = "PROOF_OF_SHIPMENT_POSTAGE"
+	EvidenceTypeProofOfTracking            string = "PROOF_OF_TRACKING"
+	EvidenceTypeOther                      string = "OTHER"
+)
+
+type (
+	// Dispute represents a customer dispute
+	// https://developer.paypal.com/docs/api/customer-disputes/v1/#definition-dispute
+	Dispute struct {
+		DisputeID             string                 `json:"dispute_id"`
+		CreateTime            string                 `json:"create_time,omitempty"`
+		UpdateTime            string                 `json:"update_time,omitempty"`
+		DisputedTransactions  []*DisputedTransaction `json:"disputed_transactions,omitempty"`
+		Reason                string                 `json:"reason,omitempty"`
+		Status                string                 `json:"status,omitempty"`
+		DisputeAmount         *Money                 `json:"dispute_amount,omitempty"`
+		DisputeOutcome        *DisputeOutcome        `json:"dispute_outcome,omitempty"`
+		DisputeLifeCycleStage string                 `json:"dispute_life_cycle_stage,omitempty"`
+		DisputeChannel        string                 `json:"dispute_channel,omitempty"`
+		SellerResponseDueDate string                 `json:"seller_response_due_date,omitempty"`
+		BuyerResponseDueDate  string                 `json:"buyer_response_due_date,omitempty"`
+		Messages              []*DisputeMessage      `json:"messages,omitempty"`
+		Links                 []*Link                `json:"links,omitempty"`
+	}
+
+	// DisputeMessage represents a message in the thread between the buyer and the merchant
+	// https://developer.paypal.com/docs/api/customer-disputes/v1/#definition-message
+	DisputeMessage struct {
+		PostedBy   string             `json:"posted_by"`
+		TimePosted string             `json:"time_posted"`
+		Content    string             `json:"content"`
+		Documents  []*DisputeDocument `json:"documents,omitempty"`
+	}
+
+	// SendMessageRequest represents body parameters needed to send a message to the buyer
+	SendMessageRequest struct {
+		Message string `json:"message"` //max: 2000
+	}
+
+	// DisputedTransaction represents a transaction the buyer disputes
+	// https://developer.paypal.com/docs/api/customer-disputes/v1/#definition-transaction_info
+	DisputedTransaction struct {
+		BuyerTransactionID       string        `json:"buyer_transaction_id,omitempty"`
+		SellerTransactionID      string        `json:"seller_transaction_id,omitempty"`
+		CreateTime               string        `json:"create_time,omitempty"`
+		TransactionStatus        string        `json:"transaction_status,omitempty"`
+		GrossAmount              *Money        `json:"gross_amount,omitempty"`
+		InvoiceNumber            string        `json:"invoice_number,omitempty"`
+		Custom                   string        `json:"custom,omitempty"`
+		Buyer                    *DisputeParty `json:"buyer,omitempty"`
+		Seller                   *DisputeParty `json:"seller,omitempty"`
+		SellerProtectionEligible bool          `json:"seller_protection_eligible,omitempty"`
+	}
+
+	// DisputeParty represents the buyer or the seller of a disputed transaction
+	DisputeParty struct {
+		MerchantID string `json:"merchant_id,omitempty"`
+		Email      string `json:"email,omitempty"`
+		Name       string `json:"name,omitempty"`
+	}
+
+	// DisputeOutcome represents the outcome of a resolved dispute
+	DisputeOutcome struct {
+		OutcomeCode    string `json:"outcome_code"`
+		AmountRefunded *Money `json:"amount_refunded,omitempty"`
+	}
+
+	// DisputeSummary represents a dispute in the list of disputes, use GetDispute for the details
+	// https://developer.paypal.com/docs/api/customer-disputes/v1/#definition-dispute_info
+	DisputeSummary struct {
+		DisputeID             string  `json:"dispute_id"`
+		CreateTime            string  `json:"create_time,omitempty"`
+		UpdateTime            string  `json:"update_time,omitempty"`
+		Reason                string  `json:"reason,omitempty"`
+		Status                string  `json:"status,omitempty"`
+		DisputeState          string  `json:"dispute_state,omitempty"`
+		DisputeAmount         *Money  `json:"dispute_amount,omitempty"`
+		DisputeLifeCycleStage string  `json:"dispute_life_cycle_stage,omitempty"`
+		DisputeChannel        string  `json:"dispute_channel,omitempty"`
+		SellerResponseDueDate string  `json:"seller_response_due_date,omitempty"`
+		Links                 []*Link `json:"links,omitempty"`
+	}
+
+	// ListDisputesRequest represents the filters of list disputes, zero values are not sent.
+	// StartTime and DisputedTransactionID can not be combined
+	ListDisputesRequest struct {
+		StartTime             time.Time
+		DisputedTransactionID string
+		DisputeState          string
+		NextPageToken         string
+		PageSize              uint64 //default: 10, max: 50
+	}
+
+	// ListDisputesResponse represents a page of disputes
+	ListDisputesResponse struct {
+		Items []*DisputeSummary `json:"items"`
+		Links []*Link           `json:"links,omitempty"`
+	}
+
+	// AcceptClaimRequest represents body parameters needed to accept a claim, Note is required
+	// https://developer.paypal.com/docs/api/customer-disputes/v1/#disputes-actions_accept-claim
+	AcceptClaimRequest struct {
+		Note                  string           `json:"note"` //max: 2000
+		AcceptClaimReason     string           `json:"accept_claim_reason,omitempty"`
+		AcceptClaimType       string           `json:"accept_claim_type,omitempty"`
+		InvoiceID             string           `json:"invoice_id,omitempty"`
+		RefundAmount          *Money           `json:"refund_amount,omitempty"`
+		ReturnShippingAddress *AddressPortable `json:"return_shipping_address,omitempty"`
+	}
+
+	// MakeOfferRequest represents body parameters needed to offer the buyer a settlement, Note and OfferType are required.
+	// OfferAmount is required for every offer type but OfferTypeReplacementWithoutRefund
+	// https://developer.paypal.com/docs/api/customer-disputes/v1/#disputes-actions_make-offer
+	MakeOfferRequest struct {
+		Note                  string           `json:"note"` //max: 2000
+		OfferType             string           `json:"offer_type"`
+		OfferAmount           *Money           `json:"offer_amount,omitempty"`
+		InvoiceID             string           `json:"invoice_id,omitempty"`
+		ReturnShippingAddress *AddressPortable `json:"return_shipping_address,omitempty"`
+	}
+
+	// DisputeNoteRequest represents the note sent with a dispute action, e.g. to accept or deny an offer
+	DisputeNoteRequest struct {
+		Note string `json:"note"` //max: 2000
+	}
+
+	// RequireEvidenceRequest represents body parameters needed to move a dispute to the evidence required state
+	RequireEvidenceRequest struct {
+		Action string `json:"action"`
+	}
+
+	// Evidence represents evidence supporting the merchant's position in a dispute
+	// https://developer.paypal.com/docs/api/customer-disputes/v1/#definition-evidence
+	Evidence struct {
+		EvidenceType string             `json:"evidence_type"`
+		EvidenceInfo *EvidenceInfo      `json:"evidence_info,omitempty"`
+		Documents    []*DisputeDocument `json:"documents,omitempty"` //Read only
+		Notes        string             `json:"notes,omitempty"`     //max: 2000
+		ItemID       string             `json:"item_id,omitempty"`
+	}
+
+	// EvidenceInfo represents the tracking and refund details of an evidence
+	EvidenceInfo struct {
+		TrackingInfo []*DisputeTrackingInfo `json:"tracking_info,omitempty"`
+		RefundIDs    []string               `json:"refund_ids,omitempty"`
+	}
+
+	// DisputeTrackingInfo represents the shipment tracking details of an evidence
+	DisputeTrackingInfo struct {
+		CarrierName      string `json:"carrier_name"`
+		CarrierNameOther string `json:"carrier_name_other,omitempty"`
+		TrackingURL      string `json:"tracking_url,omitempty"`
+		TrackingNumber   string `json:"tracking_number"`
+	}
+
+	// DisputeDocument represents a document uploaded to a dispute
+	DisputeDocument struct {
+		Name string `json:"name"`
+		URL  string `json:"url,omitempty"`
+	}
+
+	// EvidenceFile represents a document uploaded along with evidences, JPG, GIF, PNG or PDF up to 10MB
+	EvidenceFile struct {
+		Name    string
+		Content io.Reader
+	}
+
+	// DisputeEvidenceRequest represents the evidences and the documents sent to appeal or provide evidence
+	DisputeEvidenceRequest struct {
+		Evidences []*Evidence    `json:"evidences"`
+		Files     []EvidenceFile `json:"-"`
+	}
+
+	// DisputeActionResponse represents the response of a dispute action, the links point to the dispute
+	DisputeActionResponse struct {
+		Links []*Link `json:"links"`
+	}
+
+	// DisputeIterator iterates over the pages of disputes, following the `next` links
+	//
+	//	it := c.IterateDisputes(&paypal.ListDisputesRequest{DisputeState: paypal.DisputeStateRequiredAction})
+	//	for it.Next() {
+	//		for _, dispute := range it.Page().Items { ... }
+	//	}
+	//	if err := it.Err(); err != nil { ... }
+	DisputeIterator struct {
+		client  *Client
+		params  *ListDisputesRequest
+		page    *ListDisputesResponse
+		visited map[string]bool
+		err     error
+		done    bool
+	}
+)
+
+// ListDisputes lists a single page of disputes, newest first
+// Endpoint: GET /v1/customer/disputes
+func (c *Client) ListDisputes(params *ListDisputesRequest) (*ListDisputesResponse, error) {
+	resp := &ListDisputesResponse{}
+
+	if params != nil && !params.StartTime.IsZero() && params.DisputedTransactionID != "" {
+		return resp, fmt.Errorf("paypal: start_time and disputed_transaction_id can not be combined")
+	}
+
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/customer/disputes"), nil)
+	if err != nil {
+		return resp, err
+	}
+
+	if params != nil {
+		q := req.URL.Query()
+		if !params.StartTime.IsZero() {
+			q.Add("start_time", params.StartTime.UTC().Format(time.RFC3339))
+		}
+		if params.DisputedTransactionID != "" {
+			q.Add("disputed_transaction_id", params.DisputedTransactionID)
+		}
+		if params.DisputeState != "" {
+			q.Add("dispute_state", params.DisputeState)
+		}
+		if params.NextPageToken != "" {
+			q.Add("next_page_token", params.NextPageToken)
+		}
+		if params.PageSize > 0 {
+			q.Add("page_size", strconv.FormatUint(params.PageSize, 10))
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// GetDispute shows the details of a dispute
+// Endpoint: GET /v1/customer/disputes/ID
+func (c *Client) GetDispute(disputeID string) (*Dispute, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/customer/disputes/", disputeID), nil)
+	dispute := &Dispute{}
+	if err != nil {
+		return dispute, err
+	}
+
+	err = c.SendWithAuth(req, dispute)
+	return dispute, err
+}
+
+// AcceptClaim accepts liability for the claim and refunds the buyer, RefundAmount defaults to the disputed amount
+// Endpoint: POST /v1/customer/disputes/ID/accept-claim
+func (c *Client) AcceptClaim(disputeID string, acceptClaimRequest *AcceptClaimRequest) (*DisputeActionResponse, error) {
+	if acceptClaimRequest == nil || acceptClaimRequest.Note == "" {
+		return &DisputeActionResponse{}, fmt.Errorf("paypal: a note is required to accept the claim of dispute %s", disputeID)
+	}
+
+	return c.disputeAction(disputeID, "accept-claim", acceptClaimRequest)
+}
+
+// SendDisputeMessage sends a message to the buyer in the dispute thread
+// Endpoint: POST /v1/customer/disputes/ID/send-message
+func (c *Client) SendDisputeMessage(disputeID string, message string) (*DisputeActionResponse, error) {
+	if message == "" {
+		return &DisputeActionResponse{}, fmt.Errorf("paypal: a message is required to send to dispute %s", disputeID)
+	}
+
+	return c.disputeAction(disputeID, "send-message", &SendMessageRequest{Message: message})
+}
+
+// ListDisputeMessages lists the messages between the buyer and the merchant, oldest first
+// Endpoint: GET /v1/customer/disputes/ID
+func (c *Client) ListDisputeMessages(disputeID string) ([]*DisputeMessage, error) {
+	dispute, err := c.GetDispute(disputeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dispute.Messages, nil
+}
+
+// MakeOffer offers the buyer a refund, a return or a replacement to settle the dispute before it is escalated
+// Endpoint: POST /v1/customer/disputes/ID/make-offer
+func (c *Client) MakeOffer(disputeID string, makeOfferRequest *MakeOfferRequest) (*DisputeActionResponse, error) {
+	if makeOfferRequest == nil || makeOfferRequest.Note == "" || makeOfferRequest.OfferType == "" {
+		return &DisputeActionResponse{}, fmt.Errorf("paypal: a note and an offer type are required to make an offer for dispute %s", disputeID)
+	}
+	if makeOfferRequest.OfferAmount == nil && makeOfferRequest.OfferType != OfferTypeReplacementWithoutRefund {
+		return &DisputeActionResponse{}, fmt.Errorf("paypal: an offer amount is required for %s offers", makeOfferRequest.OfferType)
+	}
+
+	return c.disputeAction(disputeID, "make-offer", makeOfferRequest)
+}
+
+// AcceptOffer accepts the merchant's offer on behalf of the buyer, the dispute is resolved
+// Endpoint: POST /v1/customer/disputes/ID/accept-offer
+func (c *Client) AcceptOffer(disputeID string, note string) (*DisputeActionResponse, error) {
+	return c.disputeNoteAction(disputeID, "accept-offer", note)
+}
+
+// DenyOffer denies the merchant's offer on behalf of the buyer
+// Endpoint: POST /v1/customer/disputes/ID/deny-offer
+func (c *Client) DenyOffer(disputeID string, note string) (*DisputeActionResponse, error) {
+	return c.disputeNoteAction(disputeID, "deny-offer", note)
+}
+
+// EscalateDispute escalates a dispute in the inquiry stage to a claim, PayPal then decides the outcome
+// Endpoint: POST /v1/customer/disputes/ID/escalate
+func (c *Client) EscalateDispute(disputeID string, note string) (*DisputeActionResponse, error) {
+	return c.disputeNoteAction(disputeID, "escalate", note)
+}
+
+// RequireEvidence moves the dispute to the state waiting for evidence of the buyer or the seller,
+// use RequireEvidenceActionBuyerEvidence or RequireEvidenceActionSellerEvidence. Available in the sandbox only
+// Endpoint: POST /v1/customer/disputes/ID/require-evidence
+func (c *Client) RequireEvidence(disputeID string, action string) (*DisputeActionResponse, error) {
+	if action != RequireEvidenceActionBuyerEvidence && action != RequireEvidenceActionSellerEvidence {
+		return &DisputeActionResponse{}, fmt.Errorf("paypal: invalid require evidence action %q for dispute %s", action, disputeID)
+	}
+
+	return c.disputeAction(disputeID, "require-evidence", &RequireEvidenceRequest{Action: action})
+}
+
+// AppealDispute appeals an adjudication the merchant disagrees with, along with evidences and documents
+// Endpoint: POST /v1/customer/disputes/ID/appeal
+func (c *Client) AppealDispute(disputeID string, evidence *DisputeEvidenceRequest) (*DisputeActionResponse, error) {
+	resp := &DisputeActionResponse{}
+
+	if evidence == nil || len(evidence.Evidences) == 0 {
+		return resp, fmt.Errorf("paypal: at least one evidence is required to appeal dispute %s", disputeID)
+	}
+
+	req, err := c.newEvidenceRequest(fmt.Sprintf("%s%s%s%s", c.APIBase, "/v1/customer/disputes/", disputeID, "/appeal"), evidence)
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// newEvidenceRequest constructs a multipart/form-data request with the evidences as the `input` part
+// followed by an `evidence-file` part per file
+func (c *Client) newEvidenceRequest(url string, evidence *DisputeEvidenceRequest) (*http.Request, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="input"`)
+	header.Set("Content-Type", "application/json")
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.NewEncoder(part).Encode(evidence); err != nil {
+		return nil, err
+	}
+
+	for _, file := range evidence.Files {
+		part, err := w.CreateFormFile("evidence-file", file.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(part, file.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-type", w.FormDataContentType())
+	return req, nil
+}
+
+// disputeNoteAction posts a required note to one of the dispute action endpoints
+func (c *Client) disputeNoteAction(disputeID, action, note string) (*DisputeActionResponse, error) {
+	if note == "" {
+		return &DisputeActionResponse{}, fmt.Errorf("paypal: a note is required to %s dispute %s", action, disputeID)
+	}
+
+	return c.disputeAction(disputeID, action, &DisputeNoteRequest{Note: note})
+}
+
+// disputeAction posts the payload to one of the dispute action endpoints
+func (c *Client) disputeAction(disputeID, action string, payload interface{}) (*DisputeActionResponse, error) {
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s%s/%s", c.APIBase, "/v1/customer/disputes/", disputeID, action), payload)
+	resp := &DisputeActionResponse{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// NextPageToken returns the `next_page_token` of the next page, empty on the last page
+func (r *ListDisputesResponse) NextPageToken() string {
+	next := findLink(r.Links, LinkRelNext)
+	if next == nil {
+		return ""
+	}
+
+	u, err := url.Parse(next.Href)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("next_page_token")
+}
+
+// IterateDisputes returns an iterator over the pages of disputes matching params
+func (c *Client) IterateDisputes(params *ListDisputesRequest) *DisputeIterator {
+	return &DisputeIterator{client: c, params: params, visited: map[string]bool{}}
+}
+
+// Next fetches the next page, it returns false when there are no more pages or the request failed
+func (it *DisputeIterator) Next() bool {
+	if it.done {
+		return false
+	}
+
+	if it.page == nil {
+		it.page, it.err = it.client.ListDisputes(it.params)
+	} else {
+		next := findLink(it.page.Links, LinkRelNext)
+		if next == nil || it.visited[next.Href] {
+			it.done = true
+			return false
+		}
+		it.visited[next.Href] = true
+
+		req, err := it.client.NewRequest("GET", next.Href, nil)
+		if err == nil {
+			it.page = &ListDisputesResponse{}
+			err = it.client.SendWithAuth(req, it.page)
+		}
+		it.err = err
+	}
+
+	if it.err != nil {
+		it.done = true
+		return false
+	}
+	return true
+}
+
+// Page returns the current page
+func (it *DisputeIterator) Page() *ListDisputesResponse {
+	return it.page
+}
+
+// Err returns the error that stopped the iteration
+func (it *DisputeIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,271 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Possible values for the action of a Dunning callback
+const (
+	DunningActionRetry   string = "RETRY"
+	DunningActionSuspend string = "SUSPEND"
+	DunningActionCancel  string = "CANCEL"
+)
+
+type (
+	// DunningPolicy decides what happens to a subscription after failed payments. PayPal keeps retrying the
+	// payment of a suspended subscription once it is reactivated, a cancelled one is gone for good
+	DunningPolicy struct {
+		// SuspendAfter suspends the subscription once this many payments in a row failed, never when 0
+		SuspendAfter uint64
+		// CancelAfter cancels the subscription when its payments have been failing for this long, never when 0
+		CancelAfter time.Duration
+		// SuspendReason and CancelReason are the reasons sent to PayPal
+		SuspendReason string
+		CancelReason  string
+	}
+
+	// DunningStore remembers when the payments of a subscription started failing
+	DunningStore interface {
+		// RecordFailure records a failed payment of the subscription at t, the first failure is kept
+		RecordFailure(subscriptionID string, t time.Time) error
+		// FirstFailure returns the first failure recorded for the subscription, zero when there is none
+		FirstFailure(subscriptionID string) (time.Time, error)
+		// Reset forgets the failures of the subscription
+		Reset(subscriptionID string) error
+		// Failures returns the first failure of every subscription with failures recorded
+		Failures() (map[string]time.Time, error)
+	}
+
+	// MemoryDunningStore is a DunningStore keeping the failures in memory, it is only suitable for a single instance
+	MemoryDunningStore struct {
+		mu       sync.Mutex
+		failures map[string]time.Time
+	}
+
+	// Dunning applies a DunningPolicy to the subscriptions whose payments fail, suspending or cancelling them
+	// and calling Notify, e.g. to email the subscriber. Register it on an EventRouter and run CancelOverdue
+	// periodically, suspended subscriptions are not billed anymore so no failed payment comes to cancel them:
+	//
+	//	dunning := paypal.NewDunning(c, paypal.DunningPolicy{SuspendAfter: 3, CancelAfter: 30 * 24 * time.Hour})
+	//	dunning.Notify = func(ctx context.Context, action string, subscription *paypal.Subscription) error { ... }
+	//	dunning.Register(router)
+	//	cancelled, err := dunning.CancelOverdue(ctx, time.Now()) // e.g. daily
+	Dunning struct {
+		Client *Client
+		Policy DunningPolicy
+		Store  DunningStore
+		// Clock dates the failures without a time, the system clock is used when nil
+		Clock Clock
+		// Notify is called with the DunningAction* applied to the subscription after every failed payment
+		Notify func(ctx context.Context, action string, subscription *Subscription) error
+	}
+)
+
+// NewMemoryDunningStore returns an empty MemoryDunningStore
+func NewMemoryDunningStore() *MemoryDunningStore {
+	return &MemoryDunningStore{failures: map[string]time.Time{}}
+}
+
+// RecordFailure records a failed payment of the subscription at t, the first failure is kept
+func (s *MemoryDunningStore) RecordFailure(subscriptionID string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures == nil {
+		s.failures = map[string]time.Time{}
+	}
+	if first, ok := s.failures[subscriptionID]; !ok || t.Before(first) {
+		s.failures[subscriptionID] = t
+	}
+	return nil
+}
+
+// FirstFailure returns the first failure recorded for the subscription, zero when there is none
+func (s *MemoryDunningStore) FirstFailure(subscriptionID string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.failures[subscriptionID], nil
+}
+
+// Reset forgets the failures of the subscription
+func (s *MemoryDunningStore) Reset(subscriptionID string) error {
+	s.mu.Lock()
+	delete(s.failures, subscriptionID)
+	s.mu.Unlock()
+	return nil
+}
+
+// Failures returns the first failure of every subscription with failures recorded
+func (s *MemoryDunningStore) Failures() (map[string]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures := make(map[string]time.Time, len(s.failures))
+	for id, first := range s.failures {
+		failures[id] = first
+	}
+	return failures, nil
+}
+
+// NewDunning returns a Dunning applying policy with c and a MemoryDunningStore
+func NewDunning(c *Client, policy DunningPolicy) *Dunning {
+	return &Dunning{Client: c, Policy: policy, Store: NewMemoryDunningStore()}
+}
+
+// Register makes the router pass the failed payments of subscriptions to HandlePaymentFailed and forget
+// the failures of subscriptions that are paid, reactivated or cancelled
+func (d *Dunning) Register(router *EventRouter) *EventRouter {
+	router.OnBillingSubscriptionPaymentFailed(d.HandlePaymentFailed)
+	router.OnBillingSubscriptionActivated(d.reset)
+	router.OnBillingSubscriptionCancelled(d.reset)
+	router.OnPaymentSaleCompleted(func(ctx context.Context, sale *Sale) error {
+		if sale.BillingAgreementID == "" {
+			return nil
+		}
+		return d.Store.Reset(sale.BillingAgreementID)
+	})
+	return router
+}
+
+// HandlePaymentFailed applies the policy to the subscription of a BILLING.SUBSCRIPTION.PAYMENT.FAILED event:
+// it is cancelled once its payments have been failing for Policy.CancelAfter, suspended once
+// Policy.SuspendAfter payments failed in a row, and left for PayPal to retry otherwise.
+// Notify is then called with the action, cancelled and expired subscriptions are skipped
+func (d *Dunning) HandlePaymentFailed(ctx context.Context, subscription *Subscription) error {
+	if subscription == nil || subscription.ID == "" {
+		return fmt.Errorf("paypal: a subscription is required to handle a failed payment")
+	}
+	if subscriptionEnded(subscription) {
+		return nil
+	}
+
+	now := clockNow(d.Clock)
+	failures := uint64(1)
+	failedAt := now
+	if info := subscription.BillingInfo; info != nil {
+		if info.FailedPaymentsCount > 0 {
+			failures = info.FailedPaymentsCount
+		}
+		if t, err := parseTimestamp(info.LastFailedPayment.Time); err == nil && !t.IsZero() {
+			failedAt = t
+		}
+	}
+
+	if err := d.Store.RecordFailure(subscription.ID, failedAt); err != nil {
+		return err
+	}
+	first, err := d.Store.FirstFailure(subscription.ID)
+	if err != nil {
+		return err
+	}
+
+	action := DunningActionRetry
+	switch {
+	case d.Policy.CancelAfter > 0 && !first.IsZero() && now.Sub(first) >= d.Policy.CancelAfter:
+		action = DunningActionCancel
+		if _, err := d.cancel(ctx, subscription); err != nil {
+			return err
+		}
+	case d.Policy.SuspendAfter > 0 && failures >= d.Policy.SuspendAfter:
+		action = DunningActionSuspend
+		if subscription.Status != SubscriptionStatusSuspended {
+			if err := d.Client.SuspendSubscriptionContext(ctx, subscription.ID, &UpdateSubscriptionStatusRequest{Reason: dunningReason(d.Policy.SuspendReason, "Too many failed payments")}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.Notify != nil {
+		return d.Notify(ctx, action, subscription)
+	}
+	return nil
+}
+
+// CancelOverdue cancels the subscriptions whose payments have been failing for Policy.CancelAfter at now and
+// returns their IDs. A suspended subscription is not billed anymore, so no failed payment comes for
+// HandlePaymentFailed to cancel it: run CancelOverdue periodically, e.g. daily. Notify is called with
+// DunningActionCancel, subscriptions already cancelled or expired are forgotten without being cancelled again.
+// A failure does not stop the other subscriptions, the first error is returned
+// Endpoint: GET /v1/billing/subscriptions/{id}, POST /v1/billing/subscriptions/{id}/cancel
+func (d *Dunning) CancelOverdue(ctx context.Context, now time.Time) ([]string, error) {
+	if d.Policy.CancelAfter <= 0 {
+		return nil, nil
+	}
+	failures, err := d.Store.Failures()
+	if err != nil {
+		return nil, err
+	}
+
+	overdue := make([]string, 0, len(failures))
+	for id, first := range failures {
+		if !first.IsZero() && now.Sub(first) >= d.Policy.CancelAfter {
+			overdue = append(overdue, id)
+		}
+	}
+	sort.Strings(overdue)
+
+	var cancelled []string
+	var firstErr error
+	for _, id := range overdue {
+		ok, err := d.cancelOverdue(ctx, id)
+		if ok {
+			cancelled = append(cancelled, id)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return cancelled, firstErr
+}
+
+// cancelOverdue cancels the subscription unless it already ended and reports whether it was cancelled
+func (d *Dunning) cancelOverdue(ctx context.Context, subscriptionID string) (bool, error) {
+	subscription, err := d.Client.ShowSubscriptionContext(ctx, subscriptionID, &ShowSubscriptionRequest{})
+	if err != nil {
+		return false, err
+	}
+	if ok, err := d.cancel(ctx, subscription); !ok || err != nil {
+		return ok, err
+	}
+
+	if d.Notify != nil {
+		return true, d.Notify(ctx, DunningActionCancel, subscription)
+	}
+	return true, nil
+}
+
+// cancel cancels the subscription and forgets its failures, a subscription that already ended is only
+// forgotten. It reports whether the subscription was cancelled
+func (d *Dunning) cancel(ctx context.Context, subscription *Subscription) (bool, error) {
+	if subscriptionEnded(subscription) {
+		return false, d.Store.Reset(subscription.ID)
+	}
+
+	if err := d.Client.CancelSubscriptionContext(ctx, subscription.ID, &UpdateSubscriptionStatusRequest{Reason: dunningReason(d.Policy.CancelReason, "Payments failed for too long")}); err != nil {
+		return false, err
+	}
+	return true, d.Store.Reset(subscription.ID)
+}
+
+// subscriptionEnded reports whether the subscription was cancelled or expired
+func subscriptionEnded(subscription *Subscription) bool {
+	return subscription.Status == SubscriptionStatusCancelled || subscription.Status == SubscriptionStatusExpired
+}
+
+// reset forgets the failures of the subscription
+func (d *Dunning) reset(ctx context.Context, subscription *Subscription) error {
+	return d.Store.Reset(subscription.ID)
+}
+
+// dunningReason returns reason or the fallback when it is empty, PayPal requires a reason
+func dunningReason(reason, fallback string) string {
+	if reason == "" {
+		return fallback
+	}
+	return reason
+}
@@ -0,0 +1,165 @@
+package paypal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// paymentFailedEvent returns a BILLING.SUBSCRIPTION.PAYMENT.FAILED event of the subscription
+func paymentFailedEvent(t *testing.T, status string, failures int, failedAt string) *Event {
+	event := &Event{}
+	data := fmt.Sprintf(`{"id":"WH-%d","event_type":"BILLING.SUBSCRIPTION.PAYMENT.FAILED","resource_type":"subscription","resource":{"id":"I-BW452GLLEP1G","status":"%s","plan_id":"P-5ML4271244454362WXNWU5NQ","billing_info":{"outstanding_balance":{"currency_code":"USD","value":"10.00"},"failed_payments_count":%d,"last_failed_payment":{"amount":{"currency_code":"USD","value":"10.00"},"time":"%s","reason_code":"PAYMENT_DENIED"}}}}`, failures, status, failures, failedAt)
+	if err := json.Unmarshal([]byte(data), event); err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestDunning(t *testing.T) {
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := &UpdateSubscriptionStatusRequest{}
+		json.NewDecoder(r.Body).Decode(body)
+		calls = append(calls, strings.TrimPrefix(r.URL.Path, "/v1/billing/subscriptions/")+" "+body.Reason)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	clock := &testClock{now: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)}
+	dunning := NewDunning(c, DunningPolicy{SuspendAfter: 3, CancelAfter: 30 * 24 * time.Hour, CancelReason: "Unpaid for 30 days"})
+	dunning.Clock = clock
+	var actions []string
+	dunning.Notify = func(ctx context.Context, action string, subscription *Subscription) error {
+		actions = append(actions, action)
+		return nil
+	}
+	router := dunning.Register(NewEventRouter())
+
+	deliver := func(event *Event) {
+		if err := router.Dispatch(context.Background(), event); err != nil {
+			t.Fatalf("Not expected error for Dispatch, got %v", err)
+		}
+	}
+
+	deliver(paymentFailedEvent(t, SubscriptionStatusActive, 1, "2020-01-01T10:00:00Z"))
+	deliver(paymentFailedEvent(t, SubscriptionStatusActive, 2, "2020-01-06T10:00:00Z"))
+	clock.now = clock.now.Add(10 * 24 * time.Hour)
+	deliver(paymentFailedEvent(t, SubscriptionStatusActive, 3, "2020-01-11T10:00:00Z"))
+	if strings.Join(actions, ",") != "RETRY,RETRY,SUSPEND" || len(calls) != 1 || calls[0] != "I-BW452GLLEP1G/suspend Too many failed payments" {
+		t.Errorf("expected the subscription to be suspended after 3 failures, got %v, %v", actions, calls)
+	}
+
+	// already suspended, PayPal is not called again until it is time to cancel
+	deliver(paymentFailedEvent(t, SubscriptionStatusSuspended, 4, "2020-01-16T10:00:00Z"))
+	clock.now = clock.now.Add(20 * 24 * time.Hour)
+	deliver(paymentFailedEvent(t, SubscriptionStatusSuspended, 5, "2020-01-31T10:00:00Z"))
+	if strings.Join(actions, ",") != "RETRY,RETRY,SUSPEND,SUSPEND,CANCEL" || len(calls) != 2 || calls[1] != "I-BW452GLLEP1G/cancel Unpaid for 30 days" {
+		t.Errorf("expected the subscription to be cancelled 30 days after the first failure, got %v, %v", actions, calls)
+	}
+	if first, _ := dunning.Store.FirstFailure("I-BW452GLLEP1G"); !first.IsZero() {
+		t.Errorf("expected the failures to be forgotten once cancelled, got %v", first)
+	}
+
+	// a payment resets the failures
+	dunning.Store.RecordFailure("I-BW452GLLEP1G", clock.now)
+	sale := &Event{}
+	json.Unmarshal([]byte(`{"id":"WH-SALE","event_type":"PAYMENT.SALE.COMPLETED","resource_type":"sale","resource":{"id":"80021663DE681814L","state":"completed","billing_agreement_id":"I-BW452GLLEP1G","amount":{"total":"10.00","currency":"USD"}}}`), sale)
+	deliver(sale)
+	if first, _ := dunning.Store.FirstFailure("I-BW452GLLEP1G"); !first.IsZero() {
+		t.Errorf("expected the failures to be forgotten once paid, got %v", first)
+	}
+
+	deliver(paymentFailedEvent(t, SubscriptionStatusCancelled, 6, "2020-02-05T10:00:00Z"))
+	if len(actions) != 5 {
+		t.Errorf("expected cancelled subscriptions to be skipped, got %v", actions)
+	}
+}
+
+func TestDunning_CancelOverdue(t *testing.T) {
+	status := map[string]string{"I-BW452GLLEP1G": SubscriptionStatusActive, "I-SUSPENDED": SubscriptionStatusActive, "I-ENDED": SubscriptionStatusCancelled}
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v1/billing/subscriptions/")
+		if r.Method == "GET" {
+			fmt.Fprintf(w, `{"id":"%s","status":"%s"}`, path, status[path])
+			return
+		}
+		calls = append(calls, path)
+		id := strings.Split(path, "/")[0]
+		switch {
+		case strings.HasSuffix(path, "/suspend"):
+			status[id] = SubscriptionStatusSuspended
+		case strings.HasSuffix(path, "/cancel"):
+			status[id] = SubscriptionStatusCancelled
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	start := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	dunning := NewDunning(c, DunningPolicy{SuspendAfter: 3, CancelAfter: 30 * 24 * time.Hour})
+	dunning.Clock = &testClock{now: start}
+	var actions []string
+	dunning.Notify = func(ctx context.Context, action string, subscription *Subscription) error {
+		actions = append(actions, action+" "+subscription.ID)
+		return nil
+	}
+
+	// suspended after 3 failures, PayPal bills it no more
+	for failures := uint64(1); failures <= 3; failures++ {
+		sub := &Subscription{ID: "I-SUSPENDED", Status: SubscriptionStatusActive, BillingInfo: &SubscriptionBillingInfo{FailedPaymentsCount: failures}}
+		if err := dunning.HandlePaymentFailed(context.Background(), sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dunning.Store.RecordFailure("I-BW452GLLEP1G", start.Add(20*24*time.Hour))
+	dunning.Store.RecordFailure("I-ENDED", start)
+
+	cancelled, err := dunning.CancelOverdue(context.Background(), start.Add(29*24*time.Hour))
+	if err != nil || len(cancelled) != 0 {
+		t.Errorf("expected nothing to cancel before 30 days, got %v, %v", cancelled, err)
+	}
+
+	cancelled, err = dunning.CancelOverdue(context.Background(), start.Add(30*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Not expected error for CancelOverdue, got %v", err)
+	}
+	if strings.Join(cancelled, ",") != "I-SUSPENDED" || strings.Join(calls, ",") != "I-SUSPENDED/suspend,I-SUSPENDED/cancel" {
+		t.Errorf("expected the suspended subscription to be cancelled, got %v, %v", cancelled, calls)
+	}
+	if strings.Join(actions, ",") != "RETRY I-SUSPENDED,RETRY I-SUSPENDED,SUSPEND I-SUSPENDED,CANCEL I-SUSPENDED" {
+		t.Errorf("unexpected notifications %v", actions)
+	}
+
+	failures, _ := dunning.Store.Failures()
+	if _, ok := failures["I-BW452GLLEP1G"]; len(failures) != 1 || !ok {
+		t.Errorf("expected only the subscription failing for 10 days to be left, got %v", failures)
+	}
+
+	// The requests to PayPal are canceled with ctx
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = nil
+	if _, err := dunning.CancelOverdue(ctx, start.Add(60*24*time.Hour)); err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("expected the canceled context error, got %v", err)
+	}
+	sub := &Subscription{ID: "I-BW452GLLEP1G", Status: SubscriptionStatusActive, BillingInfo: &SubscriptionBillingInfo{FailedPaymentsCount: 3}}
+	if err := dunning.HandlePaymentFailed(ctx, sub); err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("expected the canceled context error, got %v", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected no request with a canceled context, got %v", calls)
+	}
+}
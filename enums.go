@@ -0,0 +1,117 @@
+package paypal
+
+type (
+	// OrderIntent is the `intent` of a v2 order, see the OrderIntent* values
+	OrderIntent string
+
+	// ShippingPreference is the `shipping_preference` of an application or experience context,
+	// see the ShippingPreference* values
+	ShippingPreference string
+
+	// LandingPage is the `landing_page` of an application context, see the LandingPage* values
+	LandingPage string
+
+	// DisbursementMode is the `disbursement_mode` of a payment instruction or capture, see the DisbursementMode* values
+	DisbursementMode string
+
+	// TenureType is the `tenure_type` of a billing cycle, see the TenureType* values
+	TenureType string
+
+	// OrderStatus is the `status` of a v2 order, see the OrderStatus* values
+	OrderStatus string
+
+	// CaptureStatus is the `status` of a captured payment, see the CaptureStatus* values
+	CaptureStatus string
+
+	// RefundStatus is the `status` of a refund, see the RefundStatus* values
+	RefundStatus string
+
+	// PayoutBatchStatus is the `batch_status` of a payout batch, see the PayoutBatchStatus* values
+	PayoutBatchStatus string
+
+	// PayoutTransactionStatus is the `transaction_status` of a payout item, see the PayoutTransactionStatus* values
+	PayoutTransactionStatus string
+)
+
+// IsValid reports whether i is one of the OrderIntent* values
+func (i OrderIntent) IsValid() bool {
+	return i == OrderIntentCapture || i == OrderIntentAuthorize
+}
+
+// IsValid reports whether p is one of the ShippingPreference* values
+func (p ShippingPreference) IsValid() bool {
+	return p == ShippingPreferenceGetFromFile || p == ShippingPreferenceNoShipping || p == ShippingPreferenceSetProvidedAddress
+}
+
+// IsValid reports whether p is one of the LandingPage* values
+func (p LandingPage) IsValid() bool {
+	switch p {
+	case LandingPageLogin, LandingPageBilling, LandingPageNoPreference, LandingPageGuestCheckout:
+		return true
+	}
+	return false
+}
+
+// IsValid reports whether m is one of the DisbursementMode* values
+func (m DisbursementMode) IsValid() bool {
+	return m == DisbursementModeInstant || m == DisbursementModeDelayed
+}
+
+// IsValid reports whether t is one of the TenureType* values
+func (t TenureType) IsValid() bool {
+	return t == TenureTypeRegular || t == TenureTypeTrial
+}
+
+// IsFinal reports whether the order can no longer change, it was completed or voided
+func (s OrderStatus) IsFinal() bool {
+	return s == OrderStatusCompleted || s == OrderStatusVoided
+}
+
+// IsSuccessful reports whether the funds were captured, including captures refunded since
+func (s CaptureStatus) IsSuccessful() bool {
+	switch s {
+	case CaptureStatusCompleted, CaptureStatusPartiallyRefunded, CaptureStatusRefunded:
+		return true
+	}
+	return false
+}
+
+// IsFailed reports whether the capture was declined or failed
+func (s CaptureStatus) IsFailed() bool {
+	return s == CaptureStatusDeclined || s == CaptureStatusFailed
+}
+
+// IsFinal reports whether the refund can no longer change
+func (s RefundStatus) IsFinal() bool {
+	return s == RefundStatusCompleted || s == RefundStatusCancelled || s == RefundStatusFailed
+}
+
+// IsSuccessful reports whether the funds were refunded
+func (s RefundStatus) IsSuccessful() bool {
+	return s == RefundStatusCompleted
+}
+
+// IsFinal reports whether the payout batch was processed, canceled or denied
+func (s PayoutBatchStatus) IsFinal() bool {
+	switch s {
+	case PayoutBatchStatusSuccess, PayoutBatchStatusCanceled, PayoutBatchStatusDenied:
+		return true
+	}
+	return false
+}
+
+// IsFinal reports whether the payout item can no longer change. Unclaimed items are returned to the sender
+// after 30 days, items on hold are released or blocked after review
+func (s PayoutTransactionStatus) IsFinal() bool {
+	switch s {
+	case PayoutTransactionStatusSuccess, PayoutTransactionStatusFailed, PayoutTransactionStatusReturned,
+		PayoutTransactionStatusBlocked, PayoutTransactionStatusRefunded, PayoutTransactionStatusReversed:
+		return true
+	}
+	return false
+}
+
+// IsSuccessful reports whether the recipient received the payout
+func (s PayoutTransactionStatus) IsSuccessful() bool {
+	return s == PayoutTransactionStatusSuccess
+}
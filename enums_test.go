@@ -0,0 +1,115 @@
+package paypal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEnumIsValid(t *testing.T) {
+	valid := []interface{ IsValid() bool }{
+		OrderIntentCapture, OrderIntentAuthorize,
+		ShippingPreferenceGetFromFile, ShippingPreferenceNoShipping, ShippingPreferenceSetProvidedAddress,
+		LandingPageLogin, LandingPageBilling, LandingPageNoPreference, LandingPageGuestCheckout,
+		DisbursementModeInstant, DisbursementModeDelayed,
+		TenureTypeRegular, TenureTypeTrial,
+	}
+	for _, v := range valid {
+		if !v.IsValid() {
+			t.Errorf("expected %v to be valid", v)
+		}
+	}
+
+	invalid := []interface{ IsValid() bool }{
+		OrderIntent("SALE"), ShippingPreference("GET_FROM_FILES"), LandingPage("Login"),
+		DisbursementMode(""), TenureType("regular"),
+	}
+	for _, v := range invalid {
+		if v.IsValid() {
+			t.Errorf("expected %q to be invalid", v)
+		}
+	}
+}
+
+func TestEnumMarshalJSON(t *testing.T) {
+	appContext := &ApplicationContext{ShippingPreference: ShippingPreferenceNoShipping, LandingPage: LandingPageBilling}
+	data, err := json.Marshal(appContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"landing_page":"BILLING","shipping_preference":"NO_SHIPPING","return_url":"","cancel_url":""}`; string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+
+	decoded := &ApplicationContext{}
+	if err := json.Unmarshal(data, decoded); err != nil || decoded.ShippingPreference != ShippingPreferenceNoShipping {
+		t.Errorf("unexpected decoded application context %+v, %v", decoded, err)
+	}
+
+	// Responses with unknown values still decode and encode again so new PayPal values don't break callers,
+	// requests with unknown values are rejected by Validate
+	order := &Order{}
+	if err := json.Unmarshal([]byte(`{"id":"5O190127TN364715T","intent":"DEFERRED"}`), order); err != nil || order.Intent.IsValid() {
+		t.Errorf("unexpected order %+v, %v", order, err)
+	}
+	if data, err := json.Marshal(order); err != nil || string(data) != `{"id":"5O190127TN364715T","intent":"DEFERRED"}` {
+		t.Errorf("unexpected encoded order %s, %v", data, err)
+	}
+	capture := &Capture{}
+	if err := json.Unmarshal([]byte(`{"id":"2GG279541U471931P","disbursement_mode":"SCHEDULED"}`), capture); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := json.Marshal(capture); err != nil {
+		t.Errorf("expected a capture with an unknown disbursement mode to encode, got %v", err)
+	}
+
+	if err := (&ApplicationContext{ShippingPreference: "NONE"}).Validate(); err == nil {
+		t.Errorf("expected an error for an unknown shipping preference")
+	}
+	if err := (&BillingCycle{TenureType: "FREE", Sequence: 1}).Validate(); err == nil {
+		t.Errorf("expected an error for an unknown tenure type")
+	}
+	if err := (&AuthorizeOrderRequest{ApplicationContext: ApplicationContext{LandingPage: "Login"}}).Validate(); err == nil {
+		t.Errorf("expected an error for an unknown landing page")
+	}
+	unit := &PurchaseUnitRequest{
+		Amount:             &PurchaseUnitAmount{Currency: "USD", Value: "10.00"},
+		PaymentInstruction: &PaymentInstruction{DisbursementMode: "SCHEDULED"},
+	}
+	if err := unit.Validate(); err == nil {
+		t.Errorf("expected an error for an unknown disbursement mode")
+	}
+}
+
+func TestStatusPredicates(t *testing.T) {
+	tests := []struct {
+		status   interface{}
+		expected bool
+		actual   bool
+	}{
+		{OrderStatusCompleted, true, OrderStatusCompleted.IsFinal()},
+		{OrderStatusVoided, true, OrderStatusVoided.IsFinal()},
+		{OrderStatusApproved, false, OrderStatusApproved.IsFinal()},
+		{CaptureStatusPartiallyRefunded, true, CaptureStatusPartiallyRefunded.IsSuccessful()},
+		{CaptureStatusPending, false, CaptureStatusPending.IsSuccessful()},
+		{CaptureStatusPending, false, CaptureStatusPending.IsFailed()},
+		{CaptureStatusDeclined, true, CaptureStatusDeclined.IsFailed()},
+		{RefundStatusPending, false, RefundStatusPending.IsFinal()},
+		{RefundStatusCancelled, true, RefundStatusCancelled.IsFinal()},
+		{RefundStatusCancelled, false, RefundStatusCancelled.IsSuccessful()},
+		{PayoutBatchStatusProcessing, false, PayoutBatchStatusProcessing.IsFinal()},
+		{PayoutBatchStatusCanceled, true, PayoutBatchStatusCanceled.IsFinal()},
+		{PayoutTransactionStatusUnclaimed, false, PayoutTransactionStatusUnclaimed.IsFinal()},
+		{PayoutTransactionStatusReturned, true, PayoutTransactionStatusReturned.IsFinal()},
+		{PayoutTransactionStatusReturned, false, PayoutTransactionStatusReturned.IsSuccessful()},
+	}
+	for _, tt := range tests {
+		if tt.actual != tt.expected {
+			t.Errorf("expected %v for %s", tt.expected, tt.status)
+		}
+	}
+
+	capture := &Capture{}
+	if err := json.Unmarshal([]byte(`{"id":"2GG279541U471931P","status":"COMPLETED"}`), capture); err != nil || capture.Status != CaptureStatusCompleted {
+		t.Errorf("unexpected capture %+v, %v", capture, err)
+	}
+}
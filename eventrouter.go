@@ -0,0 +1,364 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// EventHandler handles a webhook event
+	EventHandler func(ctx context.Context, event *Event) error
+
+	// EventHandlerErrors holds the errors of all the handlers that failed for an event
+	EventHandlerErrors []error
+
+	// EventRouter dispatches webhook events to the handlers registered for their event type.
+	// Register it on a WebhookHandler with opts.On("*", router.Handle)
+	EventRouter struct {
+		mu       sync.RWMutex
+		handlers map[string][]EventHandler
+		unknown  []EventHandler
+
+		dedupe    DedupeStore
+		dedupeTTL time.Duration
+
+		queueMu sync.RWMutex
+		queue   chan *queuedEvent
+		workers sync.WaitGroup
+	}
+
+	// queuedEvent is an event dispatched to the workers, done receives the result of its handlers
+	queuedEvent struct {
+		ctx   context.Context
+		event *Event
+		done  chan error
+	}
+)
+
+// Error joins the messages of all the handler errors
+func (e EventHandlerErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// NewEventRouter returns an EventRouter without handlers, dispatching events synchronously
+func NewEventRouter() *EventRouter {
+	return &EventRouter{handlers: map[string][]EventHandler{}}
+}
+
+// On registers a handler for the event type, all handlers of an event type are called in registration order
+func (r *EventRouter) On(eventType string, handler EventHandler) *EventRouter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[eventType] = append(r.handlers[eventType], handler)
+	return r
+}
+
+// OnUnknown registers a handler for events without a handler for their event type
+func (r *EventRouter) OnUnknown(handler EventHandler) *EventRouter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unknown = append(r.unknown, handler)
+	return r
+}
+
+// SetDedupeStore makes the router skip events marked as seen in store,
+// an event is marked as seen for ttl (DefaultDedupeTTL when 0) once all its handlers succeed
+func (r *EventRouter) SetDedupeStore(store DedupeStore, ttl time.Duration) *EventRouter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dedupe = store
+	r.dedupeTTL = dedupeTTL(ttl)
+	return r
+}
+
+// OnPaymentCaptureCompleted registers a handler for PAYMENT.CAPTURE.COMPLETED events
+func (r *EventRouter) OnPaymentCaptureCompleted(handler func(ctx context.Context, capture *Capture) error) *EventRouter {
+	return r.onCapture(EventPaymentCaptureCompleted, handler)
+}
+
+// OnPaymentCaptureDenied registers a handler for PAYMENT.CAPTURE.DENIED events
+func (r *EventRouter) OnPaymentCaptureDenied(handler func(ctx context.Context, capture *Capture) error) *EventRouter {
+	return r.onCapture(EventPaymentCaptureDenied, handler)
+}
+
+// OnPaymentSaleCompleted registers a handler for PAYMENT.SALE.COMPLETED events
+func (r *EventRouter) OnPaymentSaleCompleted(handler func(ctx context.Context, sale *Sale) error) *EventRouter {
+	return r.On(EventPaymentSaleCompleted, func(ctx context.Context, event *Event) error {
+		sale, err := event.SaleResource()
+		if err != nil {
+			return err
+		}
+		return handler(ctx, sale)
+	})
+}
+
+// OnBillingSubscriptionCreated registers a handler for BILLING.SUBSCRIPTION.CREATED events
+func (r *EventRouter) OnBillingSubscriptionCreated(handler func(ctx context.Context, subscription *Subscription) error) *EventRouter {
+	return r.onSubscription(EventBillingSubscriptionCreated, handler)
+}
+
+// OnBillingSubscriptionActivated registers a handler for BILLING.SUBSCRIPTION.ACTIVATED events
+func (r *EventRouter) OnBillingSubscriptionActivated(handler func(ctx context.Context, subscription *Subscription) error) *EventRouter {
+	return r.onSubscription(EventBillingSubscriptionActivated, handler)
+}
+
+// OnBillingSubscriptionUpdated registers a handler for BILLING.SUBSCRIPTION.UPDATED events
+func (r *EventRouter) OnBillingSubscriptionUpdated(handler func(ctx context.Context, subscription *Subscription) error) *EventRouter {
+	return r.onSubscription(EventBillingSubscriptionUpdated, handler)
+}
+
+// OnBillingSubscriptionSuspended registers a handler for BILLING.SUBSCRIPTION.SUSPENDED events
+func (r *EventRouter) OnBillingSubscriptionSuspended(handler func(ctx context.Context, subscription *Subscription) error) *EventRouter {
+	return r.onSubscription(EventBillingSubscriptionSuspended, handler)
+}
+
+// OnBillingSubscriptionCancelled registers a handler for BILLING.SUBSCRIPTION.CANCELLED events
+func (r *EventRouter) OnBillingSubscriptionCancelled(handler func(ctx context.Context, subscription *Subscription) error) *EventRouter {
+	return r.onSubscription(EventBillingSubscriptionCancelled, handler)
+}
+
+// OnBillingSubscriptionPaymentFailed registers a handler for BILLING.SUBSCRIPTION.PAYMENT.FAILED events
+func (r *EventRouter) OnBillingSubscriptionPaymentFailed(handler func(ctx context.Context, subscription *Subscription) error) *EventRouter {
+	return r.onSubscription(EventBillingSubscriptionPaymentFailed, handler)
+}
+
+// OnCustomerDisputeCreated registers a handler for CUSTOMER.DISPUTE.CREATED events
+func (r *EventRouter) OnCustomerDisputeCreated(handler func(ctx context.Context, dispute *Dispute) error) *EventRouter {
+	return r.onDispute(EventCustomerDisputeCreated, handler)
+}
+
+// OnCustomerDisputeUpdated registers a handler for CUSTOMER.DISPUTE.UPDATED events
+func (r *EventRouter) OnCustomerDisputeUpdated(handler func(ctx context.Context, dispute *Dispute) error) *EventRouter {
+	return r.onDispute(EventCustomerDisputeUpdated, handler)
+}
+
+// OnCustomerDisputeResolved registers a handler for CUSTOMER.DISPUTE.RESOLVED events
+func (r *EventRouter) OnCustomerDisputeResolved(handler func(ctx context.Context, dispute *Dispute) error) *EventRouter {
+	return r.onDispute(EventCustomerDisputeResolved, handler)
+}
+
+// OnInvoicingInvoicePaid registers a handler for INVOICING.INVOICE.PAID events
+func (r *EventRouter) OnInvoicingInvoicePaid(handler func(ctx context.Context, invoice *Invoice) error) *EventRouter {
+	return r.onInvoice(EventInvoicingInvoicePaid, handler)
+}
+
+// OnInvoicingInvoiceCancelled registers a handler for INVOICING.INVOICE.CANCELLED events
+func (r *EventRouter) OnInvoicingInvoiceCancelled(handler func(ctx context.Context, invoice *Invoice) error) *EventRouter {
+	return r.onInvoice(EventInvoicingInvoiceCancelled, handler)
+}
+
+// OnInvoicingInvoiceRefunded registers a handler for INVOICING.INVOICE.REFUNDED events
+func (r *EventRouter) OnInvoicingInvoiceRefunded(handler func(ctx context.Context, invoice *Invoice) error) *EventRouter {
+	return r.onInvoice(EventInvoicingInvoiceRefunded, handler)
+}
+
+// OnVaultPaymentTokenCreated registers a handler for VAULT.PAYMENT-TOKEN.CREATED events
+func (r *EventRouter) OnVaultPaymentTokenCreated(handler func(ctx context.Context, token *PaymentToken) error) *EventRouter {
+	return r.onPaymentToken(EventVaultPaymentTokenCreated, handler)
+}
+
+// OnVaultPaymentTokenDeleted registers a handler for VAULT.PAYMENT-TOKEN.DELETED events
+func (r *EventRouter) OnVaultPaymentTokenDeleted(handler func(ctx context.Context, token *PaymentToken) error) *EventRouter {
+	return r.onPaymentToken(EventVaultPaymentTokenDeleted, handler)
+}
+
+// OnMerchantOnboardingCompleted registers a handler for MERCHANT.ONBOARDING.COMPLETED events
+func (r *EventRouter) OnMerchantOnboardingCompleted(handler func(ctx context.Context, onboarding *MerchantOnboarding) error) *EventRouter {
+	return r.onMerchantOnboarding(EventMerchantOnboardingCompleted, handler)
+}
+
+// OnMerchantPartnerConsentRevoked registers a handler for MERCHANT.PARTNER-CONSENT.REVOKED events,
+// sent when the seller revokes the permissions granted to the partner
+func (r *EventRouter) OnMerchantPartnerConsentRevoked(handler func(ctx context.Context, onboarding *MerchantOnboarding) error) *EventRouter {
+	return r.onMerchantOnboarding(EventMerchantPartnerConsentRevoked, handler)
+}
+
+func (r *EventRouter) onCapture(eventType string, handler func(ctx context.Context, capture *Capture) error) *EventRouter {
+	return r.On(eventType, func(ctx context.Context, event *Event) error {
+		capture, err := event.CaptureResource()
+		if err != nil {
+			return err
+		}
+		return handler(ctx, capture)
+	})
+}
+
+func (r *EventRouter) onSubscription(eventType string, handler func(ctx context.Context, subscription *Subscription) error) *EventRouter {
+	return r.On(eventType, func(ctx context.Context, event *Event) error {
+		subscription, err := event.SubscriptionResource()
+		if err != nil {
+			return err
+		}
+		return handler(ctx, subscription)
+	})
+}
+
+func (r *EventRouter) onDispute(eventType string, handler func(ctx context.Context, dispute *Dispute) error) *EventRouter {
+	return r.On(eventType, func(ctx context.Context, event *Event) error {
+		dispute, err := event.DisputeResource()
+		if err != nil {
+			return err
+		}
+		return handler(ctx, dispute)
+	})
+}
+
+func (r *EventRouter) onInvoice(eventType string, handler func(ctx context.Context, invoice *Invoice) error) *EventRouter {
+	return r.On(eventType, func(ctx context.Context, event *Event) error {
+		invoice, err := event.InvoiceResource()
+		if err != nil {
+			return err
+		}
+		return handler(ctx, invoice)
+	})
+}
+
+func (r *EventRouter) onPaymentToken(eventType string, handler func(ctx context.Context, token *PaymentToken) error) *EventRouter {
+	return r.On(eventType, func(ctx context.Context, event *Event) error {
+		token, err := event.PaymentTokenResource()
+		if err != nil {
+			return err
+		}
+		return handler(ctx, token)
+	})
+}
+
+func (r *EventRouter) onMerchantOnboarding(eventType string, handler func(ctx context.Context, onboarding *MerchantOnboarding) error) *EventRouter {
+	return r.On(eventType, func(ctx context.Context, event *Event) error {
+		onboarding, err := event.MerchantOnboardingResource()
+		if err != nil {
+			return err
+		}
+		return handler(ctx, onboarding)
+	})
+}
+
+// StartWorkers makes Dispatch queue the events for a pool of workers instead of calling the handlers itself,
+// bounding the number of events handled at once. Dispatch still waits until a worker has run the handlers and
+// returns their errors, so a failed delivery is not acknowledged and PayPal redelivers it.
+// The errors are also passed to onError, e.g. to log them. Call Close to stop the workers once all the queued
+// events are handled, StartWorkers returns an error when the workers are already started
+func (r *EventRouter) StartWorkers(workers, queueSize int, onError func(event *Event, err error)) error {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	queue := make(chan *queuedEvent, queueSize)
+	r.queueMu.Lock()
+	if r.queue != nil {
+		r.queueMu.Unlock()
+		return fmt.Errorf("paypal: the event router workers are already started")
+	}
+	r.queue = queue
+	r.queueMu.Unlock()
+
+	for i := 0; i < workers; i++ {
+		r.workers.Add(1)
+		go func() {
+			defer r.workers.Done()
+			for queued := range queue {
+				err := r.dispatch(queued.ctx, queued.event)
+				if err != nil && onError != nil {
+					onError(queued.event, err)
+				}
+				queued.done <- err
+			}
+		}()
+	}
+	return nil
+}
+
+// Close stops the workers started with StartWorkers after the queued events are handled,
+// Dispatch calls the handlers synchronously again afterwards
+func (r *EventRouter) Close() {
+	r.queueMu.Lock()
+	queue := r.queue
+	r.queue = nil
+	r.queueMu.Unlock()
+
+	if queue != nil {
+		close(queue)
+		r.workers.Wait()
+	}
+}
+
+// Dispatch calls the handlers of the event type, or the unknown event handlers when there are none.
+// All handlers are called, the errors of the failed ones are returned as EventHandlerErrors.
+// With workers started the event is queued instead, Dispatch blocks until there is room in the queue and
+// a worker has run the handlers, or ctx is done
+func (r *EventRouter) Dispatch(ctx context.Context, event *Event) error {
+	// The read lock is held while queueing so Close can't close the queue in between
+	r.queueMu.RLock()
+	if r.queue == nil {
+		r.queueMu.RUnlock()
+		return r.dispatch(ctx, event)
+	}
+
+	queued := &queuedEvent{ctx: ctx, event: event, done: make(chan error, 1)}
+	select {
+	case r.queue <- queued:
+		r.queueMu.RUnlock()
+	case <-ctx.Done():
+		r.queueMu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-queued.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Handle dispatches the event of a webhook delivery, it is a WebhookCallback
+func (r *EventRouter) Handle(req *http.Request, event *Event) error {
+	return r.Dispatch(req.Context(), event)
+}
+
+func (r *EventRouter) dispatch(ctx context.Context, event *Event) error {
+	r.mu.RLock()
+	handlers, ok := r.handlers[event.EventType]
+	if !ok {
+		handlers = r.unknown
+	}
+	dedupe, ttl := r.dedupe, r.dedupeTTL
+	r.mu.RUnlock()
+
+	if dedupe != nil {
+		seen, err := dedupe.Seen(event.ID)
+		if err != nil {
+			return err
+		}
+		if seen {
+			return nil
+		}
+	}
+
+	var errs EventHandlerErrors
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if dedupe != nil {
+		return dedupe.MarkSeen(event.ID, ttl)
+	}
+	return nil
+}
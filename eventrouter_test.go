@@ -0,0 +1,273 @@
+package paypal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEventRouter_Dispatch(t *testing.T) {
+	var captured, unknown []string
+
+	router := NewEventRouter().
+		OnPaymentCaptureCompleted(func(ctx context.Context, capture *Capture) error {
+			captured = append(captured, capture.ID)
+			return nil
+		}).
+		OnPaymentCaptureCompleted(func(ctx context.Context, capture *Capture) error {
+			return fmt.Errorf("ledger unavailable")
+		}).
+		OnPaymentCaptureCompleted(func(ctx context.Context, capture *Capture) error {
+			return fmt.Errorf("mailer unavailable")
+		}).
+		OnUnknown(func(ctx context.Context, event *Event) error {
+			unknown = append(unknown, event.EventType)
+			return nil
+		})
+
+	err := router.Dispatch(context.Background(), &Event{
+		EventType: EventPaymentCaptureCompleted,
+		Resource:  []byte(`{"id":"2GG279541U471931P","status":"COMPLETED"}`),
+	})
+	errs, ok := err.(EventHandlerErrors)
+	if !ok || len(errs) != 2 || err.Error() != "ledger unavailable; mailer unavailable" {
+		t.Errorf("expected aggregated handler errors, got %v", err)
+	}
+	if len(captured) != 1 || captured[0] != "2GG279541U471931P" {
+		t.Errorf("expected capture to be handled, got %v", captured)
+	}
+
+	if err := router.Dispatch(context.Background(), &Event{EventType: "CATALOG.PRODUCT.CREATED"}); err != nil {
+		t.Errorf("Not expected error for unknown event, got %v", err)
+	}
+	if len(unknown) != 1 || unknown[0] != "CATALOG.PRODUCT.CREATED" {
+		t.Errorf("expected unknown event handler to be called, got %v", unknown)
+	}
+
+	err = router.Dispatch(context.Background(), &Event{EventType: EventPaymentCaptureCompleted, Resource: []byte(`not json`)})
+	if err == nil {
+		t.Errorf("Expected error for undecodable resource")
+	}
+}
+
+func TestEventRouter_Workers(t *testing.T) {
+	var mu sync.Mutex
+	handled := map[string]bool{}
+	var failed []string
+
+	router := NewEventRouter().
+		OnBillingSubscriptionActivated(func(ctx context.Context, subscription *Subscription) error {
+			if subscription.ID == "I-FAIL" {
+				return fmt.Errorf("failed")
+			}
+			mu.Lock()
+			handled[subscription.ID] = true
+			mu.Unlock()
+			return nil
+		})
+
+	err := router.StartWorkers(4, 2, func(event *Event, err error) {
+		mu.Lock()
+		failed = append(failed, event.ID)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("Not expected error for StartWorkers, got %v", err)
+	}
+	if err := router.StartWorkers(4, 2, nil); err == nil {
+		t.Errorf("Expected error when the workers are already started")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := router.Dispatch(context.Background(), &Event{
+				ID:        fmt.Sprintf("WH-%d", i),
+				EventType: EventBillingSubscriptionActivated,
+				Resource:  []byte(fmt.Sprintf(`{"id":"I-%d"}`, i)),
+			})
+			if err != nil {
+				t.Errorf("Not expected error for Dispatch, got %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// The handler errors are returned so the delivery is not acknowledged
+	if err := router.Dispatch(context.Background(), &Event{ID: "WH-FAIL", EventType: EventBillingSubscriptionActivated, Resource: []byte(`{"id":"I-FAIL"}`)}); err == nil {
+		t.Errorf("Expected the handler error to be returned by Dispatch")
+	}
+	router.Close()
+
+	if len(handled) != 20 {
+		t.Errorf("expected 20 handled subscriptions, got %d", len(handled))
+	}
+	if len(failed) != 1 || failed[0] != "WH-FAIL" {
+		t.Errorf("expected the failed event to be reported, got %v", failed)
+	}
+
+	// After Close the handlers are called synchronously again
+	if err := router.Dispatch(context.Background(), &Event{EventType: EventBillingSubscriptionActivated, Resource: []byte(`{"id":"I-FAIL"}`)}); err == nil {
+		t.Errorf("Expected synchronous error after Close")
+	}
+}
+
+func TestEventRouter_Handle(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"verification_status":"SUCCESS"}`)
+	}))
+	defer api.Close()
+
+	c, _ := NewClient("foo", "bar", api.URL)
+
+	var sales []string
+	router := NewEventRouter().OnPaymentSaleCompleted(func(ctx context.Context, sale *Sale) error {
+		sales = append(sales, sale.ID)
+		return nil
+	})
+	handler := WebhookHandler(c, "1JE4291016473214C", (&WebhookHandlerOptions{}).On("*", router.Handle))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED","resource":{"id":"80021663DE681814L"}}`)))
+	if rec.Code != http.StatusOK || len(sales) != 1 {
+		t.Errorf("expected the sale to be routed, got %d and %v", rec.Code, sales)
+	}
+}
+
+func TestEventRouter_Disputes(t *testing.T) {
+	var created []*Dispute
+	router := NewEventRouter().OnCustomerDisputeCreated(func(ctx context.Context, dispute *Dispute) error {
+		created = append(created, dispute)
+		return nil
+	})
+
+	event := &Event{}
+	if err := json.Unmarshal(webhookPayloads(t)["customer-dispute-created.json"], event); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Dispatch(context.Background(), event); err != nil {
+		t.Fatalf("Not expected error for Dispatch, got %v", err)
+	}
+
+	if len(created) != 1 {
+		t.Fatalf("expected the dispute to be routed, got %v", created)
+	}
+	dispute := created[0]
+	if dispute.DisputeID != "PP-000-042-663-135" ||
+		dispute.Reason != DisputeReasonMerchandiseOrServiceNotReceived ||
+		dispute.Status != DisputeStatusOpen ||
+		dispute.DisputeLifeCycleStage != DisputeLifeCycleStageInquiry ||
+		dispute.DisputeChannel != DisputeChannelInternal ||
+		len(dispute.DisputedTransactions) != 1 || dispute.DisputedTransactions[0].Seller.MerchantID != "RD465XN5VS364" ||
+		len(dispute.Messages) != 1 || dispute.Messages[0].PostedBy != DisputeMessagePostedByBuyer {
+		t.Errorf("unexpected dispute %+v", dispute)
+	}
+}
+
+func TestEventRouter_Invoices(t *testing.T) {
+	var paid []*Invoice
+	router := NewEventRouter().OnInvoicingInvoicePaid(func(ctx context.Context, invoice *Invoice) error {
+		paid = append(paid, invoice)
+		return nil
+	})
+
+	event := &Event{}
+	if err := json.Unmarshal(webhookPayloads(t)["invoicing-invoice-paid.json"], event); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Dispatch(context.Background(), event); err != nil {
+		t.Fatalf("Not expected error for Dispatch, got %v", err)
+	}
+
+	if len(paid) != 1 {
+		t.Fatalf("expected the invoice to be routed, got %v", paid)
+	}
+	invoice := paid[0]
+	if invoice.ID != "INV2-Z56S-5LLA-Q52L-CPZ5" || invoice.Status != InvoiceStatusPaid ||
+		invoice.Detail.InvoiceNumber != "ERP-000042" ||
+		invoice.Payments == nil || invoice.Payments.PaidAmount.Value != "53.63" ||
+		len(invoice.Payments.Transactions) != 1 || invoice.Payments.Transactions[0].PaymentID != "6FN41634UT519735H" {
+		t.Errorf("unexpected invoice %+v", invoice)
+	}
+
+	// Deliveries carrying the invoice itself are decoded too
+	event = &Event{EventType: EventInvoicingInvoiceCancelled, Resource: json.RawMessage(`{"id":"INV2-Z56S-5LLA-Q52L-CPZ5","status":"CANCELLED"}`)}
+	invoice, err := event.InvoiceResource()
+	if err != nil || invoice.ID != "INV2-Z56S-5LLA-Q52L-CPZ5" || invoice.Status != InvoiceStatusCancelled {
+		t.Errorf("unexpected invoice %+v, %v", invoice, err)
+	}
+}
+
+func TestEventRouter_MerchantOnboarding(t *testing.T) {
+	const partnerClientID = "AXjjKqbIjfzSyCvm3M8unXSdNHYUQWjGOqbZWyxbxgMM_PRy0LjxBxh2rwhnqCIitVi9H56aTkTYG5bg"
+
+	var revoked []*MerchantOnboarding
+	router := NewEventRouter().OnMerchantPartnerConsentRevoked(func(ctx context.Context, onboarding *MerchantOnboarding) error {
+		revoked = append(revoked, onboarding)
+		return nil
+	})
+
+	event := &Event{}
+	if err := json.Unmarshal(webhookPayloads(t)["merchant-partner-consent-revoked.json"], event); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Dispatch(context.Background(), event); err != nil {
+		t.Fatalf("Not expected error for Dispatch, got %v", err)
+	}
+
+	if len(revoked) != 1 {
+		t.Fatalf("expected the revoked consent to be routed, got %v", revoked)
+	}
+	onboarding := revoked[0]
+	if onboarding.MerchantID != "C7CYMKZDG8D6E" || onboarding.TrackingID != "seller-1001" ||
+		onboarding.HasCapability("CUSTOM_CARD_PROCESSING") || !onboarding.HasCapability("WITHDRAW_MONEY") ||
+		len(onboarding.Scopes(partnerClientID)) != 0 {
+		t.Errorf("unexpected onboarding %+v", onboarding)
+	}
+
+	event = &Event{EventType: EventMerchantOnboardingCompleted, Resource: json.RawMessage(`{"merchant_id":"C7CYMKZDG8D6E","tracking_id":"seller-1001","oauth_integrations":[{"integration_type":"OAUTH_THIRD_PARTY","oauth_third_party":[{"partner_client_id":"` + partnerClientID + `","merchant_client_id":"AQh6dC0Mmy8pPuCvMFDyhVCBSJ6wr2UwaaPPLm1GpcQp","scopes":["https://uri.paypal.com/services/payments/realtimepayment","https://uri.paypal.com/services/payments/refund"]}]}]}`)}
+	onboarding, err := event.MerchantOnboardingResource()
+	if err != nil || len(onboarding.Scopes(partnerClientID)) != 2 || len(onboarding.Scopes("other")) != 0 {
+		t.Errorf("unexpected onboarding %+v, %v", onboarding, err)
+	}
+}
+
+func TestEventRouter_PaymentTokens(t *testing.T) {
+	var created, deleted []*PaymentToken
+	router := NewEventRouter().
+		OnVaultPaymentTokenCreated(func(ctx context.Context, token *PaymentToken) error {
+			created = append(created, token)
+			return nil
+		}).
+		OnVaultPaymentTokenDeleted(func(ctx context.Context, token *PaymentToken) error {
+			deleted = append(deleted, token)
+			return nil
+		})
+
+	event := &Event{}
+	if err := json.Unmarshal(webhookPayloads(t)["vault-payment-token-created.json"], event); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Dispatch(context.Background(), event); err != nil {
+		t.Fatalf("Not expected error for Dispatch, got %v", err)
+	}
+	if len(created) != 1 || created[0].ID != "7mx8kxcv" || created[0].Customer.ID != "customer_4029352050" ||
+		created[0].PaymentSource.ApplePay == nil || created[0].PaymentSource.ApplePay.Card.LastDigits != "4242" {
+		t.Errorf("unexpected created payment tokens %+v", created)
+	}
+
+	event = &Event{EventType: EventVaultPaymentTokenDeleted, Resource: json.RawMessage(`{"id":"9sk6234m","customer":{"id":"customer_4029352050"},"payment_source":{"venmo":{"email_address":"buyer@example.com","user_name":"@buyer"}}}`)}
+	if err := router.Dispatch(context.Background(), event); err != nil {
+		t.Fatalf("Not expected error for Dispatch, got %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].PaymentSource.Venmo == nil || deleted[0].PaymentSource.Venmo.UserName != "@buyer" {
+		t.Errorf("unexpected deleted payment tokens %+v", deleted)
+	}
+}
@@ -0,0 +1,231 @@
+package paypal
+
+import "sort"
+
+// Possible values for `event_type` in Event of checkout orders
+//
+// https://developer.paypal.com/docs/api-basics/notifications/webhooks/event-names/#orders
+const (
+	EventCheckoutOrderApproved  string = "CHECKOUT.ORDER.APPROVED"
+	EventCheckoutOrderCompleted string = "CHECKOUT.ORDER.COMPLETED"
+	EventCheckoutOrderSaved     string = "CHECKOUT.ORDER.SAVED"
+	EventCheckoutOrderVoided    string = "CHECKOUT.ORDER.VOIDED"
+)
+
+// Possible values for `event_type` in Event of authorized payments
+const (
+	EventPaymentAuthorizationCreated string = "PAYMENT.AUTHORIZATION.CREATED"
+	EventPaymentAuthorizationVoided  string = "PAYMENT.AUTHORIZATION.VOIDED"
+)
+
+// Possible values for `event_type` in Event of captured payments, REFUNDED and REVERSED carry a refund
+const (
+	EventPaymentCaptureCompleted string = "PAYMENT.CAPTURE.COMPLETED"
+	EventPaymentCaptureDenied    string = "PAYMENT.CAPTURE.DENIED"
+	EventPaymentCapturePending   string = "PAYMENT.CAPTURE.PENDING"
+	EventPaymentCaptureDeclined  string = "PAYMENT.CAPTURE.DECLINED"
+	EventPaymentCaptureRefunded  string = "PAYMENT.CAPTURE.REFUNDED"
+	EventPaymentCaptureReversed  string = "PAYMENT.CAPTURE.REVERSED"
+)
+
+// Possible values for `event_type` in Event of v1 sales, REFUNDED and REVERSED carry a refund
+const (
+	EventPaymentSaleCompleted string = "PAYMENT.SALE.COMPLETED"
+	EventPaymentSaleDenied    string = "PAYMENT.SALE.DENIED"
+	EventPaymentSalePending   string = "PAYMENT.SALE.PENDING"
+	EventPaymentSaleRefunded  string = "PAYMENT.SALE.REFUNDED"
+	EventPaymentSaleReversed  string = "PAYMENT.SALE.REVERSED"
+)
+
+// Possible values for `event_type` in Event of billing plans
+const (
+	EventBillingPlanCreated                string = "BILLING.PLAN.CREATED"
+	EventBillingPlanUpdated                string = "BILLING.PLAN.UPDATED"
+	EventBillingPlanActivated              string = "BILLING.PLAN.ACTIVATED"
+	EventBillingPlanDeactivated            string = "BILLING.PLAN.DEACTIVATED"
+	EventBillingPlanPricingChangeActivated string = "BILLING.PLAN.PRICING-CHANGE.ACTIVATED"
+)
+
+// Possible values for `event_type` in Event of subscriptions
+const (
+	EventBillingSubscriptionCreated       string = "BILLING.SUBSCRIPTION.CREATED"
+	EventBillingSubscriptionActivated     string = "BILLING.SUBSCRIPTION.ACTIVATED"
+	EventBillingSubscriptionUpdated       string = "BILLING.SUBSCRIPTION.UPDATED"
+	EventBillingSubscriptionExpired       string = "BILLING.SUBSCRIPTION.EXPIRED"
+	EventBillingSubscriptionSuspended     string = "BILLING.SUBSCRIPTION.SUSPENDED"
+	EventBillingSubscriptionReActivated   string = "BILLING.SUBSCRIPTION.RE-ACTIVATED"
+	EventBillingSubscriptionCancelled     string = "BILLING.SUBSCRIPTION.CANCELLED"
+	EventBillingSubscriptionPaymentFailed string = "BILLING.SUBSCRIPTION.PAYMENT.FAILED"
+)
+
+// Possible values for `event_type` in Event of catalog products
+const (
+	EventCatalogProductCreated string = "CATALOG.PRODUCT.CREATED"
+	EventCatalogProductUpdated string = "CATALOG.PRODUCT.UPDATED"
+)
+
+// Possible values for `event_type` in Event of customer disputes
+const (
+	EventCustomerDisputeCreated  string = "CUSTOMER.DISPUTE.CREATED"
+	EventCustomerDisputeUpdated  string = "CUSTOMER.DISPUTE.UPDATED"
+	EventCustomerDisputeResolved string = "CUSTOMER.DISPUTE.RESOLVED"
+)
+
+// Possible values for `event_type` in Event of payout batches
+const (
+	EventPaymentPayoutsBatchDenied     string = "PAYMENT.PAYOUTSBATCH.DENIED"
+	EventPaymentPayoutsBatchProcessing string = "PAYMENT.PAYOUTSBATCH.PROCESSING"
+	EventPaymentPayoutsBatchSuccess    string = "PAYMENT.PAYOUTSBATCH.SUCCESS"
+)
+
+// Possible values for `event_type` in Event of payout items
+const (
+	EventPaymentPayoutsItemBlocked   string = "PAYMENT.PAYOUTS-ITEM.BLOCKED"
+	EventPaymentPayoutsItemCanceled  string = "PAYMENT.PAYOUTS-ITEM.CANCELED"
+	EventPaymentPayoutsItemDenied    string = "PAYMENT.PAYOUTS-ITEM.DENIED"
+	EventPaymentPayoutsItemFailed    string = "PAYMENT.PAYOUTS-ITEM.FAILED"
+	EventPaymentPayoutsItemHeld      string = "PAYMENT.PAYOUTS-ITEM.HELD"
+	EventPaymentPayoutsItemRefunded  string = "PAYMENT.PAYOUTS-ITEM.REFUNDED"
+	EventPaymentPayoutsItemReturned  string = "PAYMENT.PAYOUTS-ITEM.RETURNED"
+	EventPaymentPayoutsItemSucceeded string = "PAYMENT.PAYOUTS-ITEM.SUCCEEDED"
+	EventPaymentPayoutsItemUnclaimed string = "PAYMENT.PAYOUTS-ITEM.UNCLAIMED"
+)
+
+// Possible values for `event_type` in Event of vaulted payment tokens
+const (
+	EventVaultPaymentTokenCreated           string = "VAULT.PAYMENT-TOKEN.CREATED"
+	EventVaultPaymentTokenDeleted           string = "VAULT.PAYMENT-TOKEN.DELETED"
+	EventVaultPaymentTokenDeletionInitiated string = "VAULT.PAYMENT-TOKEN.DELETION-INITIATED"
+)
+
+// Possible values for `event_type` in Event of invoices
+const (
+	EventInvoicingInvoiceCancelled string = "INVOICING.INVOICE.CANCELLED"
+	EventInvoicingInvoiceCreated   string = "INVOICING.INVOICE.CREATED"
+	EventInvoicingInvoicePaid      string = "INVOICING.INVOICE.PAID"
+	EventInvoicingInvoiceRefunded  string = "INVOICING.INVOICE.REFUNDED"
+	EventInvoicingInvoiceScheduled string = "INVOICING.INVOICE.SCHEDULED"
+	EventInvoicingInvoiceUpdated   string = "INVOICING.INVOICE.UPDATED"
+)
+
+// Possible values for `event_type` in Event of merchant onboarding
+const (
+	EventMerchantOnboardingCompleted   string = "MERCHANT.ONBOARDING.COMPLETED"
+	EventMerchantPartnerConsentRevoked string = "MERCHANT.PARTNER-CONSENT.REVOKED"
+)
+
+// Possible values for `resource_type` in Event
+const (
+	ResourceTypeCheckoutOrder      string = "checkout-order"
+	ResourceTypeAuthorization      string = "authorization"
+	ResourceTypeCapture            string = "capture"
+	ResourceTypeRefund             string = "refund"
+	ResourceTypeSale               string = "sale"
+	ResourceTypePlan               string = "plan"
+	ResourceTypeSubscription       string = "subscription"
+	ResourceTypeProduct            string = "product"
+	ResourceTypeDispute            string = "dispute"
+	ResourceTypePayouts            string = "payouts"
+	ResourceTypePayoutsItem        string = "payouts_item"
+	ResourceTypePaymentToken       string = "payment_token"
+	ResourceTypeInvoices           string = "invoices"
+	ResourceTypeMerchantOnboarding string = "merchant-onboarding"
+	ResourceTypePartnerConsent     string = "partner-consent"
+)
+
+// Possible values for `resource_version` in Event, 2.0 resources are those of the v2 APIs, e.g. v2 captures and orders
+const (
+	ResourceVersion1 string = "1.0"
+	ResourceVersion2 string = "2.0"
+)
+
+// eventResourceTypes maps the documented event types to the `resource_type` of their resource
+var eventResourceTypes = map[string]string{
+	EventCheckoutOrderApproved:  ResourceTypeCheckoutOrder,
+	EventCheckoutOrderCompleted: ResourceTypeCheckoutOrder,
+	EventCheckoutOrderSaved:     ResourceTypeCheckoutOrder,
+	EventCheckoutOrderVoided:    ResourceTypeCheckoutOrder,
+
+	EventPaymentAuthorizationCreated: ResourceTypeAuthorization,
+	EventPaymentAuthorizationVoided:  ResourceTypeAuthorization,
+
+	EventPaymentCaptureCompleted: ResourceTypeCapture,
+	EventPaymentCaptureDenied:    ResourceTypeCapture,
+	EventPaymentCapturePending:   ResourceTypeCapture,
+	EventPaymentCaptureDeclined:  ResourceTypeCapture,
+	EventPaymentCaptureRefunded:  ResourceTypeRefund,
+	EventPaymentCaptureReversed:  ResourceTypeRefund,
+
+	EventPaymentSaleCompleted: ResourceTypeSale,
+	EventPaymentSaleDenied:    ResourceTypeSale,
+	EventPaymentSalePending:   ResourceTypeSale,
+	EventPaymentSaleRefunded:  ResourceTypeRefund,
+	EventPaymentSaleReversed:  ResourceTypeRefund,
+
+	EventBillingPlanCreated:                ResourceTypePlan,
+	EventBillingPlanUpdated:                ResourceTypePlan,
+	EventBillingPlanActivated:              ResourceTypePlan,
+	EventBillingPlanDeactivated:            ResourceTypePlan,
+	EventBillingPlanPricingChangeActivated: ResourceTypePlan,
+
+	EventBillingSubscriptionCreated:       ResourceTypeSubscription,
+	EventBillingSubscriptionActivated:     ResourceTypeSubscription,
+	EventBillingSubscriptionUpdated:       ResourceTypeSubscription,
+	EventBillingSubscriptionExpired:       ResourceTypeSubscription,
+	EventBillingSubscriptionSuspended:     ResourceTypeSubscription,
+	EventBillingSubscriptionReActivated:   ResourceTypeSubscription,
+	EventBillingSubscriptionCancelled:     ResourceTypeSubscription,
+	EventBillingSubscriptionPaymentFailed: ResourceTypeSubscription,
+
+	EventCatalogProductCreated: ResourceTypeProduct,
+	EventCatalogProductUpdated: ResourceTypeProduct,
+
+	EventCustomerDisputeCreated:  ResourceTypeDispute,
+	EventCustomerDisputeUpdated:  ResourceTypeDispute,
+	EventCustomerDisputeResolved: ResourceTypeDispute,
+
+	EventPaymentPayoutsBatchDenied:     ResourceTypePayouts,
+	EventPaymentPayoutsBatchProcessing: ResourceTypePayouts,
+	EventPaymentPayoutsBatchSuccess:    ResourceTypePayouts,
+
+	EventPaymentPayoutsItemBlocked:   ResourceTypePayoutsItem,
+	EventPaymentPayoutsItemCanceled:  ResourceTypePayoutsItem,
+	EventPaymentPayoutsItemDenied:    ResourceTypePayoutsItem,
+	EventPaymentPayoutsItemFailed:    ResourceTypePayoutsItem,
+	EventPaymentPayoutsItemHeld:      ResourceTypePayoutsItem,
+	EventPaymentPayoutsItemRefunded:  ResourceTypePayoutsItem,
+	EventPaymentPayoutsItemReturned:  ResourceTypePayoutsItem,
+	EventPaymentPayoutsItemSucceeded: ResourceTypePayoutsItem,
+	EventPaymentPayoutsItemUnclaimed: ResourceTypePayoutsItem,
+
+	EventVaultPaymentTokenCreated:           ResourceTypePaymentToken,
+	EventVaultPaymentTokenDeleted:           ResourceTypePaymentToken,
+	EventVaultPaymentTokenDeletionInitiated: ResourceTypePaymentToken,
+
+	EventInvoicingInvoiceCancelled: ResourceTypeInvoices,
+	EventInvoicingInvoiceCreated:   ResourceTypeInvoices,
+	EventInvoicingInvoicePaid:      ResourceTypeInvoices,
+	EventInvoicingInvoiceRefunded:  ResourceTypeInvoices,
+	EventInvoicingInvoiceScheduled: ResourceTypeInvoices,
+	EventInvoicingInvoiceUpdated:   ResourceTypeInvoices,
+
+	EventMerchantOnboardingCompleted:   ResourceTypeMerchantOnboarding,
+	EventMerchantPartnerConsentRevoked: ResourceTypePartnerConsent,
+}
+
+// EventResourceType returns the `resource_type` PayPal sends with the event type,
+// ok is false for event types missing from the catalog above
+func EventResourceType(eventType string) (resourceType string, ok bool) {
+	resourceType, ok = eventResourceTypes[eventType]
+	return resourceType, ok
+}
+
+// EventTypes returns all the event types of the catalog above
+func EventTypes() []string {
+	eventTypes := make([]string, 0, len(eventResourceTypes))
+	for eventType := range eventResourceTypes {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	return eventTypes
+}
@@ -1,52 +1,101 @@
 package paypal
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
+// Possible values for `schema` in GetUserInfo
+const (
+	UserInfoSchemaOpenID    string = "openid"
+	UserInfoSchemaPayPalV11 string = "paypalv1.1"
+)
+
+// Scopes of the user attributes, see UserInfo
+const (
+	ScopeOpenID           string = "openid"
+	ScopeProfile          string = "profile"
+	ScopeEmail            string = "email"
+	ScopeAddress          string = "address"
+	ScopePhone            string = "phone"
+	ScopePayPalAttributes string = "https://uri.paypal.com/services/paypalattributes"
+)
+
+type (
+	// ClientTokenRequest represents the body of a client token request, CustomerID is the merchant's ID
+	// of a returning buyer whose vaulted payment methods are shown in the browser
+	ClientTokenRequest struct {
+		CustomerID string `json:"customer_id,omitempty"`
+	}
+
+	// ClientTokenResponse represents a client token for the JS SDK, it expires after ExpiresIn seconds
+	ClientTokenResponse struct {
+		ClientToken string         `json:"client_token"`
+		ExpiresIn   ExpirationTime `json:"expires_in"`
+	}
+)
+
+// GenerateClientToken generates a client token for the JS SDK `data-client-token` attribute, required
+// by advanced card fields and vaulting in the browser. customerID is optional
+// Endpoint: POST /v1/identity/generate-token
+func (c *Client) GenerateClientToken(customerID string) (*ClientTokenResponse, error) {
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/identity/generate-token"), &ClientTokenRequest{CustomerID: customerID})
+	resp := &ClientTokenResponse{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
 // GrantNewAccessTokenFromAuthCode - Use this call to grant a new access token, using the previously obtained authorization code.
+// The response carries the refresh token to store for later calls on behalf of the user
 // Endpoint: POST /v1/identity/openidconnect/tokenservice
 func (c *Client) GrantNewAccessTokenFromAuthCode(code, redirectURI string) (*TokenResponse, error) {
-	token := &TokenResponse{}
-
 	q := url.Values{}
 	q.Set("grant_type", "authorization_code")
 	q.Set("code", code)
 	q.Set("redirect_uri", redirectURI)
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/identity/openidconnect/tokenservice"), strings.NewReader(q.Encode()))
-	if err != nil {
-		return token, err
-	}
-
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-
-	if err = c.SendWithBasicAuth(req, token); err != nil {
-		return token, err
-	}
-
-	return token, nil
+	return c.identityTokenService(q)
 }
 
 // GrantNewAccessTokenFromRefreshToken - Use this call to grant a new access token, using a refresh token.
 // Endpoint: POST /v1/identity/openidconnect/tokenservice
 func (c *Client) GrantNewAccessTokenFromRefreshToken(refreshToken string) (*TokenResponse, error) {
-	type request struct {
-		GrantType    string `json:"grant_type"`
-		RefreshToken string `json:"refresh_token"`
+	if refreshToken == "" {
+		return &TokenResponse{}, fmt.Errorf("paypal: a refresh token is required to grant an access token")
+	}
+
+	q := url.Values{}
+	q.Set("grant_type", "refresh_token")
+	q.Set("refresh_token", refreshToken)
+
+	token, err := c.identityTokenService(q)
+	if err == nil && token.RefreshToken == "" {
+		// PayPal only returns the refresh token on the authorization code exchange
+		token.RefreshToken = refreshToken
 	}
+	return token, err
+}
 
+// identityTokenService posts the form to the token service, authenticated with the client credentials
+func (c *Client) identityTokenService(form url.Values) (*TokenResponse, error) {
 	token := &TokenResponse{}
 
-	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/identity/openidconnect/tokenservice"), request{GrantType: "refresh_token", RefreshToken: refreshToken})
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/identity/openidconnect/tokenservice"), strings.NewReader(form.Encode()))
 	if err != nil {
 		return token, err
 	}
 
-	if err = c.SendWithAuth(req, token); err != nil {
+	req.Header.Set("Content-type", "application/x-www-form-urlencoded")
+
+	if err = c.SendWithBasicAuth(req, token); err != nil {
 		return token, err
 	}
 
@@ -55,18 +104,65 @@ func (c *Client) GrantNewAccessTokenFromRefreshToken(refreshToken string) (*Toke
 
 // GetUserInfo - Use this call to retrieve user profile attributes.
 // Endpoint: GET /v1/identity/openidconnect/userinfo/?schema=<Schema>
-// Pass the schema that is used to return as per openidconnect protocol. The only supported schema value is openid.
+// Pass UserInfoSchemaOpenID for the OpenID Connect attributes or UserInfoSchemaPayPalV11 for the PayPal attributes,
+// including payer_id, verified_account and the emails array. The attributes returned depend on the scopes the user
+// consented to, see UserInfo
 func (c *Client) GetUserInfo(schema string) (*UserInfo, error) {
 	u := &UserInfo{}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/identity/openidconnect/userinfo/?schema=", schema), nil)
+	if schema != UserInfoSchemaOpenID && schema != UserInfoSchemaPayPalV11 {
+		return u, fmt.Errorf("paypal: unsupported userinfo schema %q", schema)
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/identity/openidconnect/userinfo/"), nil)
 	if err != nil {
 		return u, err
 	}
 
+	q := req.URL.Query()
+	q.Add("schema", schema)
+	req.URL.RawQuery = q.Encode()
+
 	if err = c.SendWithAuth(req, u); err != nil {
 		return u, err
 	}
 
 	return u, nil
 }
+
+// UnmarshalJSON decodes the user info, PayPal sends `verified` and `verified_account` as strings with the openid schema
+func (u *UserInfo) UnmarshalJSON(data []byte) error {
+	type userInfo UserInfo
+	aux := &struct {
+		*userInfo
+		Verified        json.RawMessage `json:"verified,omitempty"`
+		VerifiedAccount json.RawMessage `json:"verified_account,omitempty"`
+	}{userInfo: (*userInfo)(u)}
+
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	var err error
+	if u.Verified, err = parseLenientBool(aux.Verified); err != nil {
+		return fmt.Errorf("paypal: invalid verified %s", aux.Verified)
+	}
+	if u.VerifiedAccount, err = parseLenientBool(aux.VerifiedAccount); err != nil {
+		return fmt.Errorf("paypal: invalid verified_account %s", aux.VerifiedAccount)
+	}
+	return nil
+}
+
+// parseLenientBool parses a JSON boolean sent either as a boolean or as a string
+func parseLenientBool(raw json.RawMessage) (bool, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return false, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strconv.ParseBool(s)
+	}
+	var b bool
+	err := json.Unmarshal(raw, &b)
+	return b, err
+}
@@ -0,0 +1,187 @@
+package paypal
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ComputeInvoiceAmount computes the amount breakdown of an invoice the way PayPal computes it: every item line,
+// item discount and item tax is rounded to the precision of the invoice currency before being summed up.
+// Discounts and taxes given as percent are applied on the rounded line amount, taxes are computed on the
+// discounted amount when TaxCalculatedAfterDiscount is set and are already part of the prices when TaxInclusive is set.
+// The invoice level discount, shipping and custom amount are read from invoice.Amount.Breakdown when present
+func ComputeInvoiceAmount(invoice *Invoice) (*InvoiceAmount, error) {
+	if invoice == nil || invoice.Detail == nil || invoice.Detail.CurrencyCode == "" {
+		return nil, fmt.Errorf("paypal: the invoice detail currency code is required to compute the amount")
+	}
+	currency := invoice.Detail.CurrencyCode
+	config := invoice.Configuration
+	if config == nil {
+		config = &InvoiceConfiguration{}
+	}
+	var breakdown InvoiceAmountBreakdown
+	if invoice.Amount != nil && invoice.Amount.Breakdown != nil {
+		breakdown = *invoice.Amount.Breakdown
+	}
+
+	type line struct {
+		gross, net *big.Rat
+		tax        *InvoiceTax
+	}
+
+	itemTotal, itemDiscount := new(big.Rat), new(big.Rat)
+	lines := make([]line, 0, len(invoice.Items))
+	for i, item := range invoice.Items {
+		quantity, err := parseDecimal(item.Quantity, fmt.Sprintf("items[%d].quantity", i))
+		if err != nil {
+			return nil, err
+		}
+		unit, err := parseMoney(item.UnitAmount, currency, fmt.Sprintf("items[%d].unit_amount", i))
+		if err != nil {
+			return nil, err
+		}
+
+		amount := roundCurrency(new(big.Rat).Mul(quantity, unit), currency)
+		discount, err := computeDiscount(item.Discount, amount, currency, fmt.Sprintf("items[%d].discount", i))
+		if err != nil {
+			return nil, err
+		}
+
+		itemTotal.Add(itemTotal, amount)
+		itemDiscount.Add(itemDiscount, discount)
+		lines = append(lines, line{gross: amount, net: new(big.Rat).Sub(amount, discount), tax: item.Tax})
+	}
+
+	subtotal := new(big.Rat).Sub(itemTotal, itemDiscount)
+	var invoiceDiscount *InvoiceDiscount
+	if breakdown.Discount != nil {
+		invoiceDiscount = breakdown.Discount.InvoiceDiscount
+	}
+	discount, err := computeDiscount(invoiceDiscount, subtotal, currency, "amount.breakdown.discount.invoice_discount")
+	if err != nil {
+		return nil, err
+	}
+
+	// The invoice level discount lowers the taxable amount of every item proportionally
+	taxTotal := new(big.Rat)
+	for i, l := range lines {
+		base := l.gross
+		if config.TaxCalculatedAfterDiscount {
+			base = new(big.Rat).Set(l.net)
+			if discount.Sign() != 0 && subtotal.Sign() != 0 {
+				base.Sub(base, new(big.Rat).Mul(discount, new(big.Rat).Quo(l.net, subtotal)))
+			}
+		}
+
+		tax, err := computeTax(l.tax, base, currency, config.TaxInclusive, fmt.Sprintf("items[%d].tax", i))
+		if err != nil {
+			return nil, err
+		}
+		taxTotal.Add(taxTotal, tax)
+	}
+
+	total := new(big.Rat).Sub(subtotal, discount)
+
+	if breakdown.Shipping != nil {
+		shipping, err := parseMoney(breakdown.Shipping.Amount, currency, "amount.breakdown.shipping.amount")
+		if err != nil {
+			return nil, err
+		}
+		tax, err := computeTax(breakdown.Shipping.Tax, shipping, currency, config.TaxInclusive, "amount.breakdown.shipping.tax")
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, shipping)
+		taxTotal.Add(taxTotal, tax)
+	}
+
+	if breakdown.Custom != nil && breakdown.Custom.Amount != nil {
+		custom, err := parseMoney(breakdown.Custom.Amount, currency, "amount.breakdown.custom.amount")
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, custom)
+	}
+
+	if !config.TaxInclusive {
+		total.Add(total, taxTotal)
+	}
+
+	result := &InvoiceAmount{
+		CurrencyCode: currency,
+		Value:        formatCurrency(total, currency),
+		Breakdown: &InvoiceAmountBreakdown{
+			ItemTotal: &Money{Currency: currency, Value: formatCurrency(itemTotal, currency)},
+			TaxTotal:  &Money{Currency: currency, Value: formatCurrency(taxTotal, currency)},
+			Shipping:  breakdown.Shipping,
+			Custom:    breakdown.Custom,
+		},
+	}
+	if invoiceDiscount != nil || itemDiscount.Sign() != 0 {
+		result.Breakdown.Discount = &InvoiceAggregatedDiscount{
+			InvoiceDiscount: invoiceDiscount,
+			ItemDiscount:    &Money{Currency: currency, Value: formatCurrency(itemDiscount, currency)},
+		}
+	}
+
+	return result, nil
+}
+
+// ValidateInvoiceAmount checks the total set in invoice.Amount against the one PayPal will compute,
+// a mismatch is rejected by PayPal with UNPROCESSABLE_ENTITY
+func ValidateInvoiceAmount(invoice *Invoice) error {
+	computed, err := ComputeInvoiceAmount(invoice)
+	if err != nil {
+		return err
+	}
+	if invoice.Amount == nil || invoice.Amount.Value == "" {
+		return nil
+	}
+
+	expected := &Money{Currency: computed.CurrencyCode, Value: computed.Value}
+	if !moneyEqual(&Money{Currency: invoice.Amount.CurrencyCode, Value: invoice.Amount.Value}, expected) {
+		return fmt.Errorf("paypal: invoice amount %s %s does not match the computed amount %s %s",
+			invoice.Amount.Value, invoice.Amount.CurrencyCode, computed.Value, computed.CurrencyCode)
+	}
+	return nil
+}
+
+// computeDiscount returns the rounded discount of the amount, a percent discount takes precedence
+func computeDiscount(discount *InvoiceDiscount, amount *big.Rat, currency, field string) (*big.Rat, error) {
+	if discount == nil {
+		return new(big.Rat), nil
+	}
+	if discount.Percent != "" {
+		percent, err := parseDecimal(discount.Percent, field+".percent")
+		if err != nil {
+			return nil, err
+		}
+		return roundCurrency(applyPercent(amount, percent), currency), nil
+	}
+	if discount.Amount != nil {
+		return parseMoney(discount.Amount, currency, field+".amount")
+	}
+	return new(big.Rat), nil
+}
+
+// computeTax returns the rounded tax of the amount, the tax is extracted from the amount when it is inclusive
+func computeTax(tax *InvoiceTax, amount *big.Rat, currency string, inclusive bool, field string) (*big.Rat, error) {
+	if tax == nil || tax.Percent == "" {
+		return new(big.Rat), nil
+	}
+	percent, err := parseDecimal(tax.Percent, field+".percent")
+	if err != nil {
+		return nil, err
+	}
+	if inclusive {
+		// amount - amount / (1 + percent/100)
+		divisor := new(big.Rat).Add(big.NewRat(1, 1), new(big.Rat).Quo(percent, big.NewRat(100, 1)))
+		net := new(big.Rat).Quo(amount, divisor)
+		return roundCurrency(new(big.Rat).Sub(amount, net), currency), nil
+	}
+	return roundCurrency(applyPercent(amount, percent), currency), nil
+}
+
+func applyPercent(amount, percent *big.Rat) *big.Rat {
+	return new(big.Rat).Quo(new(big.Rat).Mul(amount, percent), big.NewRat(100, 1))
+}
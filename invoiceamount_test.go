@@ -0,0 +1,124 @@
+package paypal
+
+import (
+	"testing"
+)
+
+func TestComputeInvoiceAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		invoice  *Invoice
+		total    string
+		taxTotal string
+	}{
+		{
+			name: "items, shipping and custom amount",
+			invoice: &Invoice{
+				Detail: &InvoiceDetail{CurrencyCode: "USD"},
+				Items: []*InvoiceItem{
+					{Name: "Work order", Quantity: "2", UnitAmount: &Money{Currency: "USD", Value: "10.00"}, Tax: &InvoiceTax{Name: "Sales Tax", Percent: "8.25"}},
+					{Name: "Parts", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "5.55"}, Tax: &InvoiceTax{Name: "Sales Tax", Percent: "8.25"}, Discount: &InvoiceDiscount{Percent: "10"}},
+				},
+				Amount: &InvoiceAmount{
+					Breakdown: &InvoiceAmountBreakdown{
+						Shipping: &InvoiceShippingCost{Amount: &Money{Currency: "USD", Value: "5.00"}, Tax: &InvoiceTax{Name: "Shipping Tax", Percent: "10"}},
+						Custom:   &InvoiceCustomAmount{Label: "Handling", Amount: &Money{Currency: "USD", Value: "1.00"}},
+					},
+				},
+			},
+			// 20.00 + 5.55 - 0.56 + 5.00 + 1.00 + 1.65 + 0.46 + 0.50
+			total:    "33.60",
+			taxTotal: "2.61",
+		},
+		{
+			name: "tax after invoice discount",
+			invoice: &Invoice{
+				Detail:        &InvoiceDetail{CurrencyCode: "USD"},
+				Configuration: &InvoiceConfiguration{TaxCalculatedAfterDiscount: true},
+				Items: []*InvoiceItem{
+					{Name: "Work order", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "100.00"}, Tax: &InvoiceTax{Name: "VAT", Percent: "10"}},
+				},
+				Amount: &InvoiceAmount{
+					Breakdown: &InvoiceAmountBreakdown{
+						Discount: &InvoiceAggregatedDiscount{InvoiceDiscount: &InvoiceDiscount{Percent: "10"}},
+					},
+				},
+			},
+			total:    "99.00",
+			taxTotal: "9.00",
+		},
+		{
+			name: "zero decimal currency",
+			invoice: &Invoice{
+				Detail: &InvoiceDetail{CurrencyCode: "JPY"},
+				Items: []*InvoiceItem{
+					{Name: "Work order", Quantity: "3", UnitAmount: &Money{Currency: "JPY", Value: "333"}, Tax: &InvoiceTax{Name: "Consumption Tax", Percent: "10"}},
+				},
+			},
+			total:    "1099",
+			taxTotal: "100",
+		},
+		{
+			name: "tax inclusive",
+			invoice: &Invoice{
+				Detail:        &InvoiceDetail{CurrencyCode: "EUR"},
+				Configuration: &InvoiceConfiguration{TaxInclusive: true},
+				Items: []*InvoiceItem{
+					{Name: "Work order", Quantity: "1", UnitAmount: &Money{Currency: "EUR", Value: "110.00"}, Tax: &InvoiceTax{Name: "VAT", Percent: "10"}},
+				},
+			},
+			total:    "110.00",
+			taxTotal: "10.00",
+		},
+	}
+
+	for _, tt := range tests {
+		amount, err := ComputeInvoiceAmount(tt.invoice)
+		if err != nil {
+			t.Errorf("%s: not expected error, got %v", tt.name, err)
+			continue
+		}
+		if amount.Value != tt.total || amount.Breakdown.TaxTotal.Value != tt.taxTotal {
+			t.Errorf("%s: expected total %s and tax %s, got %s and %s", tt.name, tt.total, tt.taxTotal, amount.Value, amount.Breakdown.TaxTotal.Value)
+		}
+	}
+}
+
+func TestComputeInvoiceAmount_Invalid(t *testing.T) {
+	item := func(currency, quantity, value string) *Invoice {
+		return &Invoice{
+			Detail: &InvoiceDetail{CurrencyCode: "USD"},
+			Items:  []*InvoiceItem{{Name: "Work order", Quantity: quantity, UnitAmount: &Money{Currency: currency, Value: value}}},
+		}
+	}
+
+	tests := map[string]*Invoice{
+		"missing currency":   {Detail: &InvoiceDetail{}},
+		"currency mismatch":  item("EUR", "1", "10.00"),
+		"too many fractions": item("USD", "1", "10.005"),
+		"invalid quantity":   item("USD", "one", "10.00"),
+		"negative amount":    item("USD", "1", "-10.00"),
+	}
+	for name, invoice := range tests {
+		if _, err := ComputeInvoiceAmount(invoice); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestValidateInvoiceAmount(t *testing.T) {
+	invoice := &Invoice{
+		Detail: &InvoiceDetail{CurrencyCode: "USD"},
+		Items:  []*InvoiceItem{{Name: "Work order", Quantity: "1.5", UnitAmount: &Money{Currency: "USD", Value: "9.99"}}},
+		Amount: &InvoiceAmount{CurrencyCode: "USD", Value: "14.99"},
+	}
+
+	if err := ValidateInvoiceAmount(invoice); err != nil {
+		t.Errorf("Not expected error, got %v", err)
+	}
+
+	invoice.Amount.Value = "14.98"
+	if err := ValidateInvoiceAmount(invoice); err == nil {
+		t.Errorf("Expected error for a mismatching amount")
+	}
+}
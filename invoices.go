@@ -0,0 +1,391 @@
+package paypal
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// Possible values for `status` in Invoice
+const (
+	InvoiceStatusDraft             string = "DRAFT"
+	InvoiceStatusSent              string = "SENT"
+	InvoiceStatusScheduled         string = "SCHEDULED"
+	InvoiceStatusPaid              string = "PAID"
+	InvoiceStatusMarkedAsPaid      string = "MARKED_AS_PAID"
+	InvoiceStatusCancelled         string = "CANCELLED"
+	InvoiceStatusRefunded          string = "REFUNDED"
+	InvoiceStatusPartiallyPaid     string = "PARTIALLY_PAID"
+	InvoiceStatusPartiallyRefunded string = "PARTIALLY_REFUNDED"
+	InvoiceStatusMarkedAsRefunded  string = "MARKED_AS_REFUNDED"
+	InvoiceStatusUnpaid            string = "UNPAID"
+	InvoiceStatusPaymentPending    string = "PAYMENT_PENDING"
+)
+
+// Possible values for `term_type` in InvoicePaymentTerm
+const (
+	InvoiceTermTypeDueOnReceipt  string = "DUE_ON_RECEIPT"
+	InvoiceTermTypeDueOnDateSpec string = "DUE_ON_DATE_SPECIFIED"
+	InvoiceTermTypeNet10         string = "NET_10"
+	InvoiceTermTypeNet15         string = "NET_15"
+	InvoiceTermTypeNet30         string = "NET_30"
+	InvoiceTermTypeNet45         string = "NET_45"
+	InvoiceTermTypeNet60         string = "NET_60"
+	InvoiceTermTypeNet90         string = "NET_90"
+	InvoiceTermTypeNoDueDate     string = "NO_DUE_DATE"
+)
+
+type (
+	// Invoice represents a v2 invoice
+	// https://developer.paypal.com/docs/api/invoicing/v2/#definition-invoice
+	Invoice struct {
+		ID                   string                `json:"id,omitempty"`        //Read only
+		ParentID             string                `json:"parent_id,omitempty"` //Read only
+		Status               string                `json:"status,omitempty"`    //Read only
+		Detail               *InvoiceDetail        `json:"detail"`
+		Invoicer             *Invoicer             `json:"invoicer,omitempty"`
+		PrimaryRecipients    []*InvoiceRecipient   `json:"primary_recipients,omitempty"`
+		AdditionalRecipients []*InvoiceEmail       `json:"additional_recipients,omitempty"`
+		Items                []*InvoiceItem        `json:"items,omitempty"`
+		Configuration        *InvoiceConfiguration `json:"configuration,omitempty"`
+		Amount               *InvoiceAmount        `json:"amount,omitempty"`
+		DueAmount            *Money                `json:"due_amount,omitempty"` //Read only
+		Gratuity             *Money                `json:"gratuity,omitempty"`   //Read only
+		Payments             *InvoicePayments      `json:"payments,omitempty"`   //Read only
+		Refunds              *InvoiceRefunds       `json:"refunds,omitempty"`    //Read only
+		Links                []*Link               `json:"links,omitempty"`      //Read only
+	}
+
+	// InvoicePayments represents the payments recorded on an invoice
+	InvoicePayments struct {
+		PaidAmount   *Money                  `json:"paid_amount,omitempty"`
+		Transactions []*InvoicePaymentDetail `json:"transactions,omitempty"`
+	}
+
+	// InvoicePaymentDetail represents a payment of an invoice, PaymentID is empty for payments recorded outside PayPal
+	InvoicePaymentDetail struct {
+		Type        string `json:"type,omitempty"`
+		PaymentID   string `json:"payment_id,omitempty"`
+		PaymentDate string `json:"payment_date,omitempty"`
+		Method      string `json:"method,omitempty"`
+		Note        string `json:"note,omitempty"`
+		Amount      *Money `json:"amount,omitempty"`
+	}
+
+	// InvoiceRefunds represents the refunds recorded on an invoice
+	InvoiceRefunds struct {
+		RefundAmount *Money                 `json:"refund_amount,omitempty"`
+		Transactions []*InvoiceRefundDetail `json:"transactions,omitempty"`
+	}
+
+	// InvoiceRefundDetail represents a refund of an invoice payment
+	InvoiceRefundDetail struct {
+		Type       string `json:"type,omitempty"`
+		RefundID   string `json:"refund_id,omitempty"`
+		RefundDate string `json:"refund_date,omitempty"`
+		Method     string `json:"method,omitempty"`
+		Amount     *Money `json:"amount,omitempty"`
+	}
+
+	// InvoiceDetail represents the details of an invoice, CurrencyCode is required
+	InvoiceDetail struct {
+		Reference          string              `json:"reference,omitempty"`
+		CurrencyCode       string              `json:"currency_code"`
+		Note               string              `json:"note,omitempty"`
+		TermsAndConditions string              `json:"terms_and_conditions,omitempty"`
+		Memo               string              `json:"memo,omitempty"`
+		InvoiceNumber      string              `json:"invoice_number,omitempty"`
+		InvoiceDate        string              `json:"invoice_date,omitempty"` //format: 2006-01-02
+		PaymentTerm        *InvoicePaymentTerm `json:"payment_term,omitempty"`
+		Attachments        []*FileReference    `json:"attachments,omitempty"`
+		Metadata           *InvoiceMetadata    `json:"metadata,omitempty"` //Read only
+	}
+
+	// FileReference represents an uploaded file, set it in InvoiceDetail.Attachments to attach it to an invoice
+	FileReference struct {
+		ID           string `json:"id,omitempty"`
+		ReferenceURL string `json:"reference_url,omitempty"`
+		ContentType  string `json:"content_type,omitempty"`
+		CreateTime   string `json:"create_time,omitempty"`
+		Size         string `json:"size,omitempty"`
+	}
+
+	// InvoicePaymentTerm represents when an invoice is due
+	InvoicePaymentTerm struct {
+		TermType string `json:"term_type,omitempty"`
+		DueDate  string `json:"due_date,omitempty"` //format: 2006-01-02
+	}
+
+	// InvoiceMetadata represents the audit details of an invoice
+	InvoiceMetadata struct {
+		CreateTime       string `json:"create_time,omitempty"`
+		CreatedBy        string `json:"created_by,omitempty"`
+		LastUpdateTime   string `json:"last_update_time,omitempty"`
+		LastUpdatedBy    string `json:"last_updated_by,omitempty"`
+		CancelTime       string `json:"cancel_time,omitempty"`
+		CancelledBy      string `json:"cancelled_by,omitempty"`
+		FirstSentTime    string `json:"first_sent_time,omitempty"`
+		LastSentTime     string `json:"last_sent_time,omitempty"`
+		RecipientViewURL string `json:"recipient_view_url,omitempty"`
+		InvoicerViewURL  string `json:"invoicer_view_url,omitempty"`
+	}
+
+	// Invoicer represents the merchant issuing an invoice
+	Invoicer struct {
+		Name            *Name            `json:"name,omitempty"`
+		Address         *AddressPortable `json:"address,omitempty"`
+		EmailAddress    string           `json:"email_address,omitempty"`
+		Website         string           `json:"website,omitempty"`
+		TaxID           string           `json:"tax_id,omitempty"`
+		LogoURL         string           `json:"logo_url,omitempty"`
+		AdditionalNotes string           `json:"additional_notes,omitempty"`
+	}
+
+	// InvoiceRecipient represents a recipient of an invoice
+	InvoiceRecipient struct {
+		BillingInfo  *InvoiceBillingInfo  `json:"billing_info,omitempty"`
+		ShippingInfo *InvoiceShippingInfo `json:"shipping_info,omitempty"`
+	}
+
+	// InvoiceBillingInfo represents the billing details of a recipient
+	InvoiceBillingInfo struct {
+		Name           *Name            `json:"name,omitempty"`
+		Address        *AddressPortable `json:"address,omitempty"`
+		EmailAddress   string           `json:"email_address,omitempty"`
+		AdditionalInfo string           `json:"additional_info,omitempty"`
+		Language       string           `json:"language,omitempty"`
+	}
+
+	// InvoiceShippingInfo represents the shipping details of a recipient
+	InvoiceShippingInfo struct {
+		Name    *Name            `json:"name,omitempty"`
+		Address *AddressPortable `json:"address,omitempty"`
+	}
+
+	// InvoiceEmail represents an additional recipient receiving a copy of an invoice
+	InvoiceEmail struct {
+		EmailAddress string `json:"email_address"`
+	}
+
+	// InvoiceItem represents a line item of an invoice, Quantity is a decimal with up to 5 fraction digits
+	InvoiceItem struct {
+		ID            string           `json:"id,omitempty"` //Read only
+		Name          string           `json:"name"`
+		Description   string           `json:"description,omitempty"`
+		Quantity      string           `json:"quantity"`
+		UnitAmount    *Money           `json:"unit_amount"`
+		Tax           *InvoiceTax      `json:"tax,omitempty"`
+		ItemDate      string           `json:"item_date,omitempty"` //format: 2006-01-02
+		Discount      *InvoiceDiscount `json:"discount,omitempty"`
+		UnitOfMeasure string           `json:"unit_of_measure,omitempty"`
+	}
+
+	// InvoiceTax represents a tax applied to an item or to the shipping, Amount is computed by PayPal
+	InvoiceTax struct {
+		Name    string `json:"name"`
+		Percent string `json:"percent"`
+		Amount  *Money `json:"amount,omitempty"` //Read only
+	}
+
+	// InvoiceDiscount represents a discount as a percent or as an amount
+	InvoiceDiscount struct {
+		Percent string `json:"percent,omitempty"`
+		Amount  *Money `json:"amount,omitempty"`
+	}
+
+	// InvoiceConfiguration represents the payment options of an invoice
+	InvoiceConfiguration struct {
+		PartialPayment             *InvoicePartialPayment `json:"partial_payment,omitempty"`
+		AllowTip                   bool                   `json:"allow_tip,omitempty"`
+		TaxCalculatedAfterDiscount bool                   `json:"tax_calculated_after_discount,omitempty"`
+		TaxInclusive               bool                   `json:"tax_inclusive,omitempty"`
+		TemplateID                 string                 `json:"template_id,omitempty"`
+	}
+
+	// InvoicePartialPayment represents whether and how an invoice can be paid in parts
+	InvoicePartialPayment struct {
+		AllowPartialPayment bool   `json:"allow_partial_payment,omitempty"`
+		MinimumAmountDue    *Money `json:"minimum_amount_due,omitempty"`
+	}
+
+	// InvoiceAmount represents the total of an invoice and its breakdown
+	InvoiceAmount struct {
+		CurrencyCode string                  `json:"currency_code"`
+		Value        string                  `json:"value"`
+		Breakdown    *InvoiceAmountBreakdown `json:"breakdown,omitempty"`
+	}
+
+	// InvoiceAmountBreakdown represents the breakdown of the total of an invoice
+	InvoiceAmountBreakdown struct {
+		ItemTotal *Money                     `json:"item_total,omitempty"`
+		Discount  *InvoiceAggregatedDiscount `json:"discount,omitempty"`
+		TaxTotal  *Money                     `json:"tax_total,omitempty"`
+		Shipping  *InvoiceShippingCost       `json:"shipping,omitempty"`
+		Custom    *InvoiceCustomAmount       `json:"custom,omitempty"`
+	}
+
+	// InvoiceAggregatedDiscount represents the invoice level discount and the sum of the item discounts
+	InvoiceAggregatedDiscount struct {
+		InvoiceDiscount *InvoiceDiscount `json:"invoice_discount,omitempty"`
+		ItemDiscount    *Money           `json:"item_discount,omitempty"` //Read only
+	}
+
+	// InvoiceShippingCost represents the shipping fee of an invoice and its tax
+	InvoiceShippingCost struct {
+		Amount *Money      `json:"amount,omitempty"`
+		Tax    *InvoiceTax `json:"tax,omitempty"`
+	}
+
+	// InvoiceNotification represents the email sent to the recipients of an invoice on remind or cancel
+	InvoiceNotification struct {
+		Subject              string   `json:"subject,omitempty"` //max: 4000
+		Note                 string   `json:"note,omitempty"`    //max: 4000
+		SendToInvoicer       bool     `json:"send_to_invoicer,omitempty"`
+		SendToRecipient      bool     `json:"send_to_recipient,omitempty"`
+		AdditionalRecipients []string `json:"additional_recipients,omitempty"`
+	}
+
+	// InvoiceCustomAmount represents a custom amount added to an invoice, e.g. a fee
+	InvoiceCustomAmount struct {
+		Label  string `json:"label"`
+		Amount *Money `json:"amount,omitempty"`
+	}
+)
+
+// CanUpdate reports whether the status of the invoice allows a full update.
+// Paid, refunded and cancelled invoices can not be changed anymore
+func (i *Invoice) CanUpdate() bool {
+	switch i.Status {
+	case "", InvoiceStatusDraft, InvoiceStatusScheduled, InvoiceStatusSent, InvoiceStatusUnpaid:
+		return true
+	}
+	return false
+}
+
+// CanDelete reports whether the status of the invoice allows to delete it, only drafts and scheduled invoices
+// can be deleted, cancel sent invoices instead
+func (i *Invoice) CanDelete() bool {
+	return i.Status == InvoiceStatusDraft || i.Status == InvoiceStatusScheduled
+}
+
+// GetInvoice shows the details of an invoice
+// Endpoint: GET /v2/invoicing/invoices/ID
+func (c *Client) GetInvoice(invoiceID string) (*Invoice, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v2/invoicing/invoices/", invoiceID), nil)
+	invoice := &Invoice{}
+	if err != nil {
+		return invoice, err
+	}
+
+	err = c.SendWithAuth(req, invoice)
+	return invoice, err
+}
+
+// UpdateInvoice fully updates an invoice, the invoice replaces the current one so it has to be complete.
+// Use GetInvoice to change a few fields. Invoices with a status not allowing updates are rejected before calling PayPal
+// Endpoint: PUT /v2/invoicing/invoices/ID
+func (c *Client) UpdateInvoice(invoice *Invoice, sendToRecipient bool) (*Invoice, error) {
+	resp := &Invoice{}
+
+	if invoice == nil || invoice.ID == "" {
+		return resp, fmt.Errorf("paypal: an invoice with an ID is required to update it")
+	}
+	if !invoice.CanUpdate() {
+		return resp, fmt.Errorf("paypal: invoice %s with status %s can not be updated", invoice.ID, invoice.Status)
+	}
+
+	req, err := c.NewRequest("PUT", fmt.Sprintf("%s%s%s", c.APIBase, "/v2/invoicing/invoices/", invoice.ID), invoice)
+	if err != nil {
+		return resp, err
+	}
+
+	q := req.URL.Query()
+	q.Add("send_to_recipient", fmt.Sprintf("%t", sendToRecipient))
+	req.URL.RawQuery = q.Encode()
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// DeleteInvoice deletes a draft or scheduled invoice, the invoice is fetched first to reject other statuses
+// Endpoint: DELETE /v2/invoicing/invoices/ID
+func (c *Client) DeleteInvoice(invoiceID string) error {
+	invoice, err := c.GetInvoice(invoiceID)
+	if err != nil {
+		return err
+	}
+	if !invoice.CanDelete() {
+		return fmt.Errorf("paypal: invoice %s with status %s can not be deleted, only drafts and scheduled invoices can", invoiceID, invoice.Status)
+	}
+
+	req, err := c.NewRequest("DELETE", fmt.Sprintf("%s%s%s", c.APIBase, "/v2/invoicing/invoices/", invoiceID), nil)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
+
+// RemindInvoice sends a reminder to the payer about an invoice, notification can be nil to use the default email
+// Endpoint: POST /v2/invoicing/invoices/ID/remind
+func (c *Client) RemindInvoice(invoiceID string, notification *InvoiceNotification) error {
+	return c.invoiceNotificationAction(invoiceID, "remind", notification)
+}
+
+// CancelInvoice cancels a sent invoice and optionally notifies the payer, drafts should be deleted instead
+// Endpoint: POST /v2/invoicing/invoices/ID/cancel
+func (c *Client) CancelInvoice(invoiceID string, notification *InvoiceNotification) error {
+	return c.invoiceNotificationAction(invoiceID, "cancel", notification)
+}
+
+func (c *Client) invoiceNotificationAction(invoiceID string, action string, notification *InvoiceNotification) error {
+	if notification == nil {
+		notification = &InvoiceNotification{}
+	}
+	if len(notification.Subject) > 4000 || len(notification.Note) > 4000 {
+		return fmt.Errorf("paypal: invoice notification subject and note must not exceed 4000 characters")
+	}
+
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s%s/%s", c.APIBase, "/v2/invoicing/invoices/", invoiceID, action), notification)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
+
+// UploadInvoiceAttachment uploads a file to attach to invoices, up to 5 attachments of 4MB each are allowed per invoice.
+// The content is streamed to PayPal so large files are never held in memory, add the returned reference to
+// InvoiceDetail.Attachments and create or update the invoice to attach it
+// Endpoint: POST /v2/invoicing/files
+func (c *Client) UploadInvoiceAttachment(name string, content io.Reader) (*FileReference, error) {
+	resp := &FileReference{}
+
+	if name == "" || content == nil {
+		return resp, fmt.Errorf("paypal: a file name and content are required to upload an invoice attachment")
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	w := multipart.NewWriter(pw)
+	go func() {
+		part, err := w.CreateFormFile("file", name)
+		if err == nil {
+			_, err = io.Copy(part, content)
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/invoicing/files"), pr)
+	if err != nil {
+		return resp, err
+	}
+	req.Header.Set("Content-type", w.FormDataContentType())
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
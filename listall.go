@@ -0,0 +1,195 @@
+package paypal
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// DefaultListAllWorkers is the number of pages fetched at once by the ListAll*Concurrently methods when workers is not set
+const DefaultListAllWorkers = 5
+
+// ListAllProductsConcurrently lists all products like IterateProducts, but fetches the pages after the first
+// with at most workers concurrent requests using the `total_pages` of the first page. The products are in
+// the same order as with IterateProducts, the first failing page fails the listing
+// Endpoint: GET /v1/catalogs/products
+func (c *Client) ListAllProductsConcurrently(params *ListProductsRequest, workers int) ([]*Product, error) {
+	q := pageQuery(0, 0, true)
+	first := uint64(1)
+	if params != nil {
+		q = pageQuery(params.PageSize, 0, true)
+		if params.Page > 0 {
+			first = params.Page
+		}
+	}
+
+	pages, err := c.listPagesConcurrently("/v1/catalogs/products", q, first, workers)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []*Product
+	for _, page := range pages {
+		products = append(products, page.Products...)
+	}
+	return products, nil
+}
+
+// ListAllPlansConcurrently lists all plans, of params.ProductID when set, with at most workers concurrent requests
+// like ListAllProductsConcurrently
+// Endpoint: GET /v1/billing/plans
+func (c *Client) ListAllPlansConcurrently(params *ListPlansParams, workers int) ([]*Plan, error) {
+	q := pageQuery(0, 0, true)
+	first := uint64(1)
+	if params != nil {
+		q = pageQuery(params.PageSize, 0, true)
+		if params.ProductID != "" {
+			q.Set("product_id", params.ProductID)
+		}
+		if params.Page > 0 {
+			first = params.Page
+		}
+	}
+
+	pages, err := c.listPagesConcurrently("/v1/billing/plans", q, first, workers)
+	if err != nil {
+		return nil, err
+	}
+
+	var plans []*Plan
+	for _, page := range pages {
+		plans = append(plans, page.Plans...)
+	}
+	return plans, nil
+}
+
+// ListAllTransactionsConcurrently searches all the transactions of params with at most workers concurrent requests
+// per 31 days window, periods longer than a single search can cover are searched window after window.
+// The transactions are in the order of the pages
+// Endpoint: GET /v1/reporting/transactions
+func (c *Client) ListAllTransactionsConcurrently(params *TransactionSearchRequest, workers int) ([]*SearchTransactionDetails, error) {
+	if params == nil || params.StartDate.IsZero() || params.EndDate.IsZero() {
+		return nil, fmt.Errorf("paypal: start_date and end_date are required to search transactions")
+	}
+
+	var transactions []*SearchTransactionDetails
+	err := transactionWindows(params, func(window *TransactionSearchRequest) error {
+		if window.Page == 0 {
+			window.Page = 1
+		}
+
+		first, err := c.ListTransactions(window)
+		if err != nil {
+			return err
+		}
+
+		var rest []*TransactionSearchResponse
+		if first.TotalPages > int(window.Page) {
+			rest = make([]*TransactionSearchResponse, first.TotalPages-int(window.Page))
+		}
+		err = fetchPagesConcurrently(len(rest), workers, func(i int) error {
+			pageSearch := *window
+			pageSearch.Page = window.Page + uint64(i) + 1
+			page, err := c.ListTransactions(&pageSearch)
+			rest[i] = page
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		transactions = append(transactions, first.TransactionDetails...)
+		for _, page := range rest {
+			transactions = append(transactions, page.TransactionDetails...)
+		}
+		return nil
+	})
+	return transactions, err
+}
+
+// listPagesConcurrently gets the page first of the listing at path and the pages after it up to its `total_pages`
+// with at most workers concurrent requests, the pages are returned in order
+func (c *Client) listPagesConcurrently(path string, query url.Values, first uint64, workers int) ([]*linkedPage, error) {
+	getPage := func(page uint64) (*linkedPage, error) {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("page", strconv.FormatUint(page, 10))
+
+		req, err := c.NewRequest("GET", fmt.Sprintf("%s%s?%s", c.APIBase, path, q.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp := &linkedPage{}
+		if err := c.SendWithBasicAuth(req, resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	firstPage, err := getPage(first)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := []*linkedPage{firstPage}
+	if firstPage.TotalPages > first {
+		pages = append(pages, make([]*linkedPage, firstPage.TotalPages-first)...)
+	}
+	err = fetchPagesConcurrently(len(pages)-1, workers, func(i int) error {
+		page, err := getPage(first + uint64(i) + 1)
+		pages[i+1] = page
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// fetchPagesConcurrently calls fetch for the pages 0 to n-1 with at most workers concurrent calls, DefaultListAllWorkers
+// when not set. It returns the first error, the pages not fetched yet are skipped once a fetch failed
+func fetchPagesConcurrently(n, workers int, fetch func(i int) error) error {
+	if workers <= 0 {
+		workers = DefaultListAllWorkers
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	jobs := make(chan int)
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mu.Lock()
+				failed := firstErr != nil
+				mu.Unlock()
+				if failed {
+					continue
+				}
+
+				if err := fetch(i); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return firstErr
+}
@@ -0,0 +1,136 @@
+package paypal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// checkoutLocales lists the BCP-47 locales PayPal localizes checkout pages in, accepted by `locale`
+// in ApplicationContext and VaultExperienceContext. PayPal falls back to the locale of the payer for others
+//
+// https://developer.paypal.com/reference/locale-codes/
+var checkoutLocales = map[string]bool{
+	"ar-EG": true, "cs-CZ": true, "da-DK": true, "de-AT": true, "de-CH": true, "de-DE": true, "el-GR": true,
+	"en-AU": true, "en-CA": true, "en-GB": true, "en-IN": true, "en-US": true, "es-ES": true, "es-MX": true,
+	"es-XC": true, "fi-FI": true, "fr-BE": true, "fr-CA": true, "fr-CH": true, "fr-FR": true, "fr-XC": true,
+	"he-IL": true, "hu-HU": true, "id-ID": true, "it-IT": true, "ja-JP": true, "ko-KR": true, "nl-BE": true,
+	"nl-NL": true, "no-NO": true, "pl-PL": true, "pt-BR": true, "pt-PT": true, "ru-RU": true, "sk-SK": true,
+	"sv-SE": true, "th-TH": true, "zh-CN": true, "zh-HK": true, "zh-TW": true, "zh-XC": true,
+}
+
+// webProfileLocales lists the values accepted by `locale_code` in Presentation, country codes and
+// underscore separated locales
+//
+// https://developer.paypal.com/docs/api/payment-experience/v1/#definition-presentation
+var webProfileLocales = map[string]bool{
+	"AU": true, "AT": true, "BE": true, "BR": true, "CA": true, "CH": true, "CN": true, "DE": true, "ES": true,
+	"GB": true, "FR": true, "IT": true, "NL": true, "PL": true, "PT": true, "RU": true, "US": true,
+	"da_DK": true, "he_IL": true, "id_ID": true, "ja_JP": true, "no_NO": true, "pt_BR": true, "ru_RU": true,
+	"sv_SE": true, "th_TH": true, "zh_CN": true, "zh_HK": true, "zh_TW": true,
+}
+
+// IsCheckoutLocale reports whether PayPal localizes checkout pages in the BCP-47 locale, e.g. en-US
+func IsCheckoutLocale(locale string) bool {
+	return checkoutLocales[locale]
+}
+
+// SuggestCheckoutLocales returns the checkout locales close to locale: the same locale written with another
+// case or separator, e.g. en-US for en_us, or else the locales of the same language, e.g. fr-CA and fr-FR for fr
+func SuggestCheckoutLocales(locale string) []string {
+	return suggestLocales(locale, checkoutLocales)
+}
+
+// Validate checks the presentation against the constraints documented by PayPal
+func (p *Presentation) Validate() error {
+	if err := checkLength("brand_name", p.BrandName, 0, 127); err != nil {
+		return err
+	}
+	if p.LocaleCode != "" && !webProfileLocales[p.LocaleCode] {
+		return localeError("locale_code", p.LocaleCode, webProfileLocales)
+	}
+	return nil
+}
+
+// Validate checks the web profile against the constraints documented by PayPal
+func (wp *WebProfile) Validate() error {
+	if err := checkLength("name", wp.Name, 1, 50); err != nil {
+		return err
+	}
+	return nestedError("presentation", wp.Presentation.Validate())
+}
+
+// Validate checks the experience context against the constraints documented by PayPal
+func (e *VaultExperienceContext) Validate() error {
+	if err := checkLength("brand_name", e.BrandName, 0, 127); err != nil {
+		return err
+	}
+	if e.Locale != "" && !IsCheckoutLocale(e.Locale) {
+		return localeError("locale", e.Locale, checkoutLocales)
+	}
+	if e.ShippingPreference != "" && !e.ShippingPreference.IsValid() {
+		return &ValidationError{Field: "shipping_preference", Reason: fmt.Sprintf("%q is not a known shipping preference", e.ShippingPreference)}
+	}
+	return nil
+}
+
+// Validate checks the experience contexts of the payment source
+func (r *SetupTokenRequest) Validate() error {
+	if r.PaymentSource == nil {
+		return &ValidationError{Field: "payment_source", Reason: "is required"}
+	}
+
+	contexts := map[string]*VaultExperienceContext{}
+	if r.PaymentSource.Card != nil {
+		contexts["payment_source.card.experience_context"] = r.PaymentSource.Card.ExperienceContext
+	}
+	if r.PaymentSource.PayPal != nil {
+		contexts["payment_source.paypal.experience_context"] = r.PaymentSource.PayPal.ExperienceContext
+	}
+	if r.PaymentSource.Venmo != nil {
+		contexts["payment_source.venmo.experience_context"] = r.PaymentSource.Venmo.ExperienceContext
+	}
+	for field, context := range contexts {
+		if context == nil {
+			continue
+		}
+		if err := context.Validate(); err != nil {
+			return nestedError(field, err)
+		}
+	}
+	return nil
+}
+
+// localeError reports an unsupported locale along with the closest supported ones
+func localeError(field, locale string, supported map[string]bool) error {
+	reason := fmt.Sprintf("%q is not a locale PayPal supports", locale)
+	if suggestions := suggestLocales(locale, supported); len(suggestions) > 0 {
+		reason += ", did you mean " + strings.Join(suggestions, " or ") + "?"
+	}
+	return &ValidationError{Field: field, Reason: reason}
+}
+
+func suggestLocales(locale string, supported map[string]bool) []string {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Replace(s, "_", "-", -1))
+	}
+	language := func(s string) string {
+		return strings.SplitN(normalize(s), "-", 2)[0]
+	}
+
+	var exact, sameLanguage []string
+	for candidate := range supported {
+		switch {
+		case normalize(candidate) == normalize(locale):
+			exact = append(exact, candidate)
+		case len(candidate) > 2 && language(candidate) == language(locale):
+			sameLanguage = append(sameLanguage, candidate)
+		}
+	}
+
+	if len(exact) > 0 {
+		return exact
+	}
+	sort.Strings(sameLanguage)
+	return sameLanguage
+}
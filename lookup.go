@@ -0,0 +1,84 @@
+package paypal
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// TransactionLookup represents the PayPal transactions of one of our orders: the captures and refunds carrying
+	// its invoice or custom ID, and the refunds of those captures even when they don't carry it
+	TransactionLookup struct {
+		Captures []*ReconciledTransaction
+		Refunds  []*ReconciledTransaction
+	}
+)
+
+// CaptureIDs returns the IDs of the captures, the IDs to pass to ShowCapturedPayment and RefundCapturedPayment
+func (l *TransactionLookup) CaptureIDs() []string {
+	ids := make([]string, 0, len(l.Captures))
+	for _, capture := range l.Captures {
+		ids = append(ids, capture.TransactionID)
+	}
+	return ids
+}
+
+// LookupByInvoiceID searches the transactions between startDate and endDate for the ones with the invoice ID,
+// e.g. to find the capture of an order from its number in a support ticket. Periods longer than the
+// 31 days a search can cover are searched in consecutive windows, transactions of the last three hours
+// may not show up yet
+// Endpoint: GET /v1/reporting/transactions
+func (c *Client) LookupByInvoiceID(invoiceID string, startDate, endDate time.Time) (*TransactionLookup, error) {
+	if invoiceID == "" {
+		return nil, fmt.Errorf("paypal: invoice ID is required to look up transactions")
+	}
+	return c.lookupTransactions(startDate, endDate, func(info *SearchTransactionInfo) bool {
+		return info.InvoiceID == invoiceID
+	})
+}
+
+// LookupByCustomID searches the transactions between startDate and endDate for the ones with the custom ID
+// like LookupByInvoiceID
+// Endpoint: GET /v1/reporting/transactions
+func (c *Client) LookupByCustomID(customID string, startDate, endDate time.Time) (*TransactionLookup, error) {
+	if customID == "" {
+		return nil, fmt.Errorf("paypal: custom ID is required to look up transactions")
+	}
+	return c.lookupTransactions(startDate, endDate, func(info *SearchTransactionInfo) bool {
+		return info.CustomField == customID
+	})
+}
+
+// lookupTransactions collects the captures and refunds matching match, and the refunds of the matching captures
+func (c *Client) lookupTransactions(startDate, endDate time.Time, match func(info *SearchTransactionInfo) bool) (*TransactionLookup, error) {
+	lookup := &TransactionLookup{}
+	refundsByCapture := map[string][]*ReconciledTransaction{}
+
+	err := c.ForEachTransaction(&TransactionSearchRequest{StartDate: startDate, EndDate: endDate, PageSize: 500}, func(details *SearchTransactionDetails) error {
+		if details.TransactionInfo == nil {
+			return nil
+		}
+		t := searchReconciledTransaction(details.TransactionInfo)
+		if t == nil {
+			return nil
+		}
+
+		switch {
+		case t.Kind == ReconcileKindCapture && match(details.TransactionInfo):
+			lookup.Captures = append(lookup.Captures, t)
+		case t.Kind == ReconcileKindRefund && match(details.TransactionInfo):
+			lookup.Refunds = append(lookup.Refunds, t)
+		case t.Kind == ReconcileKindRefund && t.ReferenceID != "":
+			refundsByCapture[t.ReferenceID] = append(refundsByCapture[t.ReferenceID], t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, capture := range lookup.Captures {
+		lookup.Refunds = append(lookup.Refunds, refundsByCapture[capture.TransactionID]...)
+	}
+	return lookup, nil
+}
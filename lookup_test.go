@@ -0,0 +1,67 @@
+package paypal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLookupByInvoiceID(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/reporting/transactions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"transaction_details":[
+			{"transaction_info":{"transaction_id":"CAPTURE-1","transaction_event_code":"T0006","transaction_status":"S","transaction_amount":{"currency_code":"USD","value":"10.00"},"invoice_id":"INV-1","custom_field":"order-1"}},
+			{"transaction_info":{"transaction_id":"CAPTURE-2","transaction_event_code":"T0006","transaction_status":"S","transaction_amount":{"currency_code":"USD","value":"19.00"},"invoice_id":"INV-2","custom_field":"order-2"}},
+			{"transaction_info":{"transaction_id":"REFUND-1","transaction_event_code":"T1107","transaction_status":"S","transaction_amount":{"currency_code":"USD","value":"-4.00"},"paypal_reference_id":"CAPTURE-1"}},
+			{"transaction_info":{"transaction_id":"REFUND-2","transaction_event_code":"T1107","transaction_status":"S","transaction_amount":{"currency_code":"USD","value":"-19.00"},"paypal_reference_id":"CAPTURE-2"}},
+			{"transaction_info":{"transaction_id":"CAPTURE-DENIED","transaction_event_code":"T0006","transaction_status":"D","transaction_amount":{"currency_code":"USD","value":"10.00"},"invoice_id":"INV-1"}},
+			{"transaction_info":{"transaction_id":"FEE-1","transaction_event_code":"T0106","transaction_status":"S","transaction_amount":{"currency_code":"USD","value":"-0.30"},"invoice_id":"INV-1"}}
+		],"page":1,"total_items":6,"total_pages":1}`))
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+	start, end := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	lookup, err := c.LookupByInvoiceID("INV-1", start, end)
+	if err != nil {
+		t.Fatalf("Not expected error for LookupByInvoiceID, got %v", err)
+	}
+	if ids := lookup.CaptureIDs(); !reflect.DeepEqual(ids, []string{"CAPTURE-1"}) {
+		t.Errorf("expected capture CAPTURE-1, got %v", ids)
+	}
+	if len(lookup.Refunds) != 1 || lookup.Refunds[0].TransactionID != "REFUND-1" || lookup.Refunds[0].ReferenceID != "CAPTURE-1" {
+		t.Errorf("expected refund REFUND-1 of CAPTURE-1, got %+v", lookup.Refunds)
+	}
+
+	lookup, err = c.LookupByCustomID("order-2", start, end)
+	if err != nil {
+		t.Fatalf("Not expected error for LookupByCustomID, got %v", err)
+	}
+	if ids := lookup.CaptureIDs(); !reflect.DeepEqual(ids, []string{"CAPTURE-2"}) {
+		t.Errorf("expected capture CAPTURE-2, got %v", ids)
+	}
+	if len(lookup.Refunds) != 1 || lookup.Refunds[0].TransactionID != "REFUND-2" {
+		t.Errorf("expected refund REFUND-2, got %+v", lookup.Refunds)
+	}
+
+	lookup, err = c.LookupByInvoiceID("INV-404", start, end)
+	if err != nil {
+		t.Fatalf("Not expected error for LookupByInvoiceID, got %v", err)
+	}
+	if len(lookup.Captures) != 0 || len(lookup.Refunds) != 0 {
+		t.Errorf("expected no transactions, got %+v", lookup)
+	}
+
+	if _, err := c.LookupByInvoiceID("", start, end); err == nil {
+		t.Errorf("Expected error for LookupByInvoiceID without an invoice ID")
+	}
+	if _, err := c.LookupByCustomID("", start, end); err == nil {
+		t.Errorf("Expected error for LookupByCustomID without a custom ID")
+	}
+}
@@ -0,0 +1,185 @@
+package paypal
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Possible values for `disbursement_mode` in PaymentInstruction
+const (
+	DisbursementModeInstant DisbursementMode = "INSTANT"
+	DisbursementModeDelayed DisbursementMode = "DELAYED"
+)
+
+// maxMarketplaceSellers is the number of purchase units PayPal accepts in a multi-seller order
+const maxMarketplaceSellers = 10
+
+type (
+	// PlatformFeeSchedule represents the platform fee charged to sellers in basis points of their purchase unit
+	// amount, 1 basis point being 0.01%. Sellers missing from Sellers are charged Default
+	PlatformFeeSchedule struct {
+		Default uint
+		Sellers map[string]uint
+	}
+
+	// MarketplaceOrder represents a cart with the items of several sellers, each seller gets a purchase unit paid
+	// to their merchant ID with the platform fee of the schedule. FeePayee receives the fees, the API caller
+	// when omitted
+	MarketplaceOrder struct {
+		Currency         string
+		Fees             *PlatformFeeSchedule
+		FeePayee         *PayeeBase
+		DisbursementMode DisbursementMode //default: INSTANT
+		Sellers          []*MarketplaceSeller
+	}
+
+	// MarketplaceSeller represents the part of a marketplace order sold by one seller,
+	// ReferenceID defaults to the merchant ID
+	MarketplaceSeller struct {
+		MerchantID     string
+		ReferenceID    string
+		Description    string
+		CustomID       string
+		InvoiceID      string
+		SoftDescriptor string
+		Items          []Item
+		Shipping       *Money
+		ShippingDetail *ShippingDetail
+	}
+)
+
+// BasisPoints returns the fee charged to the seller in basis points
+func (s *PlatformFeeSchedule) BasisPoints(merchantID string) uint {
+	if bp, ok := s.Sellers[merchantID]; ok {
+		return bp
+	}
+	return s.Default
+}
+
+// PurchaseUnits builds the purchase units to pass to CreateOrder, one per seller with the amount broken down
+// into item, tax and shipping totals and the platform fee rounded to the precision of the currency
+func (o *MarketplaceOrder) PurchaseUnits() ([]PurchaseUnitRequest, error) {
+	if o.Currency == "" {
+		return nil, fmt.Errorf("paypal: marketplace order currency is required")
+	}
+	if len(o.Sellers) == 0 || len(o.Sellers) > maxMarketplaceSellers {
+		return nil, fmt.Errorf("paypal: marketplace order must have between 1 and %d sellers, got %d", maxMarketplaceSellers, len(o.Sellers))
+	}
+
+	units := make([]PurchaseUnitRequest, 0, len(o.Sellers))
+	references := make(map[string]bool, len(o.Sellers))
+	for i, seller := range o.Sellers {
+		if seller == nil || seller.MerchantID == "" {
+			return nil, fmt.Errorf("paypal: seller %d has no merchant ID", i)
+		}
+
+		unit, err := o.purchaseUnit(seller)
+		if err != nil {
+			return nil, err
+		}
+		if references[unit.ReferenceID] {
+			return nil, fmt.Errorf("paypal: duplicate purchase unit reference ID %s", unit.ReferenceID)
+		}
+		references[unit.ReferenceID] = true
+
+		units = append(units, unit)
+	}
+
+	return units, nil
+}
+
+func (o *MarketplaceOrder) purchaseUnit(seller *MarketplaceSeller) (PurchaseUnitRequest, error) {
+	itemTotal, taxTotal := new(big.Rat), new(big.Rat)
+	if len(seller.Items) == 0 {
+		return PurchaseUnitRequest{}, fmt.Errorf("paypal: seller %s has no items", seller.MerchantID)
+	}
+	for i, item := range seller.Items {
+		quantity, ok := parseSignedDecimal(item.Quantity)
+		if !ok || !quantity.IsInt() || quantity.Sign() <= 0 {
+			return PurchaseUnitRequest{}, fmt.Errorf("paypal: seller %s item %d has an invalid quantity %q", seller.MerchantID, i, item.Quantity)
+		}
+
+		unitAmount, err := parseMoney(item.UnitAmount, o.Currency, fmt.Sprintf("seller %s item %d unit_amount", seller.MerchantID, i))
+		if err != nil {
+			return PurchaseUnitRequest{}, err
+		}
+		itemTotal.Add(itemTotal, new(big.Rat).Mul(unitAmount, quantity))
+
+		if item.Tax != nil {
+			tax, err := parseMoney(item.Tax, o.Currency, fmt.Sprintf("seller %s item %d tax", seller.MerchantID, i))
+			if err != nil {
+				return PurchaseUnitRequest{}, err
+			}
+			taxTotal.Add(taxTotal, new(big.Rat).Mul(tax, quantity))
+		}
+	}
+
+	total := new(big.Rat).Add(itemTotal, taxTotal)
+	breakdown := &PurchaseUnitAmountBreakdown{
+		ItemTotal: &Money{Currency: o.Currency, Value: formatCurrency(itemTotal, o.Currency)},
+	}
+	if taxTotal.Sign() > 0 {
+		breakdown.TaxTotal = &Money{Currency: o.Currency, Value: formatCurrency(taxTotal, o.Currency)}
+	}
+	if seller.Shipping != nil {
+		shipping, err := parseMoney(seller.Shipping, o.Currency, fmt.Sprintf("seller %s shipping", seller.MerchantID))
+		if err != nil {
+			return PurchaseUnitRequest{}, err
+		}
+		total.Add(total, shipping)
+		breakdown.Shipping = &Money{Currency: o.Currency, Value: formatCurrency(shipping, o.Currency)}
+	}
+
+	referenceID := seller.ReferenceID
+	if referenceID == "" {
+		referenceID = seller.MerchantID
+	}
+
+	unit := PurchaseUnitRequest{
+		ReferenceID: referenceID,
+		Amount: &PurchaseUnitAmount{
+			Currency:  o.Currency,
+			Value:     formatCurrency(total, o.Currency),
+			Breakdown: breakdown,
+		},
+		Payee:          &PayeeForOrders{MerchantID: seller.MerchantID},
+		Description:    seller.Description,
+		CustomID:       seller.CustomID,
+		InvoiceID:      seller.InvoiceID,
+		SoftDescriptor: seller.SoftDescriptor,
+		Items:          seller.Items,
+		Shipping:       seller.ShippingDetail,
+	}
+
+	instruction := &PaymentInstruction{DisbursementMode: o.DisbursementMode}
+	if o.Fees != nil {
+		bp := o.Fees.BasisPoints(seller.MerchantID)
+		if bp > 10000 {
+			return PurchaseUnitRequest{}, fmt.Errorf("paypal: platform fee of seller %s is over 10000 basis points", seller.MerchantID)
+		}
+
+		fee := roundCurrency(new(big.Rat).Mul(total, big.NewRat(int64(bp), 10000)), o.Currency)
+		if fee.Sign() > 0 {
+			instruction.PlatformFees = []PlatformFee{{
+				Amount: &Money{Currency: o.Currency, Value: formatCurrency(fee, o.Currency)},
+				Payee:  o.FeePayee,
+			}}
+		}
+	}
+	if len(instruction.PlatformFees) > 0 || instruction.DisbursementMode != "" {
+		unit.PaymentInstruction = instruction
+	}
+
+	return unit, nil
+}
+
+// CreateMarketplaceOrder creates an order for the marketplace cart, see MarketplaceOrder.PurchaseUnits
+// Endpoint: POST /v2/checkout/orders
+func (c *Client) CreateMarketplaceOrder(intent OrderIntent, order *MarketplaceOrder, payer *CreateOrderPayer, appContext *ApplicationContext) (*Order, error) {
+	units, err := order.PurchaseUnits()
+	if err != nil {
+		return &Order{}, err
+	}
+
+	return c.CreateOrder(intent, units, payer, appContext)
+}
@@ -0,0 +1,177 @@
+package paypal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMarketplaceOrder_PurchaseUnits(t *testing.T) {
+	order := &MarketplaceOrder{
+		Currency: "USD",
+		Fees:     &PlatformFeeSchedule{Default: 1000, Sellers: map[string]uint{"DKT6MWRNSQQ9G": 250}},
+		FeePayee: &PayeeBase{MerchantID: "Y5FWFLXCAMKNL"},
+		Sellers: []*MarketplaceSeller{
+			{
+				MerchantID: "C7CYMKZDG8D6E",
+				Items: []Item{
+					{Name: "Yoga Mat", Quantity: "2", UnitAmount: &Money{Currency: "USD", Value: "19.99"}, Tax: &Money{Currency: "USD", Value: "1.45"}},
+				},
+				Shipping: &Money{Currency: "USD", Value: "4.99"},
+			},
+			{
+				MerchantID:  "DKT6MWRNSQQ9G",
+				ReferenceID: "seller-2",
+				Items: []Item{
+					{Name: "Water Bottle", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "12.50"}},
+				},
+			},
+		},
+	}
+
+	units, err := order.PurchaseUnits()
+	if err != nil {
+		t.Fatalf("Not expected error for PurchaseUnits, got %v", err)
+	}
+	if len(units) != 2 {
+		t.Fatalf("expected 2 purchase units, got %d", len(units))
+	}
+
+	first := units[0]
+	if first.ReferenceID != "C7CYMKZDG8D6E" || first.Payee.MerchantID != "C7CYMKZDG8D6E" ||
+		first.Amount.Value != "47.87" || first.Amount.Breakdown.ItemTotal.Value != "39.98" ||
+		first.Amount.Breakdown.TaxTotal.Value != "2.90" || first.Amount.Breakdown.Shipping.Value != "4.99" {
+		t.Errorf("unexpected first purchase unit %+v", first)
+	}
+	// 10% of 47.87 rounds to 4.79
+	if fees := first.PaymentInstruction.PlatformFees; len(fees) != 1 || fees[0].Amount.Value != "4.79" || fees[0].Payee.MerchantID != "Y5FWFLXCAMKNL" {
+		t.Errorf("unexpected first platform fees %+v", first.PaymentInstruction)
+	}
+
+	second := units[1]
+	if second.ReferenceID != "seller-2" || second.Amount.Value != "12.50" || second.Amount.Breakdown.TaxTotal != nil {
+		t.Errorf("unexpected second purchase unit %+v", second)
+	}
+	// 2.5% of 12.50 rounds to 0.31
+	if fees := second.PaymentInstruction.PlatformFees; len(fees) != 1 || fees[0].Amount.Value != "0.31" {
+		t.Errorf("unexpected second platform fees %+v", second.PaymentInstruction)
+	}
+}
+
+func TestMarketplaceOrder_Invalid(t *testing.T) {
+	item := Item{Name: "Yoga Mat", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "10.00"}}
+
+	tests := []struct {
+		name  string
+		order *MarketplaceOrder
+	}{
+		{"no currency", &MarketplaceOrder{Sellers: []*MarketplaceSeller{{MerchantID: "C7CYMKZDG8D6E", Items: []Item{item}}}}},
+		{"no sellers", &MarketplaceOrder{Currency: "USD"}},
+		{"no merchant ID", &MarketplaceOrder{Currency: "USD", Sellers: []*MarketplaceSeller{{Items: []Item{item}}}}},
+		{"no items", &MarketplaceOrder{Currency: "USD", Sellers: []*MarketplaceSeller{{MerchantID: "C7CYMKZDG8D6E"}}}},
+		{"currency mismatch", &MarketplaceOrder{Currency: "EUR", Sellers: []*MarketplaceSeller{{MerchantID: "C7CYMKZDG8D6E", Items: []Item{item}}}}},
+		{"shipping currency mismatch", &MarketplaceOrder{Currency: "USD", Sellers: []*MarketplaceSeller{{MerchantID: "C7CYMKZDG8D6E", Items: []Item{item}, Shipping: &Money{Currency: "EUR", Value: "1.00"}}}}},
+		{"fractional quantity", &MarketplaceOrder{Currency: "USD", Sellers: []*MarketplaceSeller{{MerchantID: "C7CYMKZDG8D6E", Items: []Item{{Name: "Yoga Mat", Quantity: "1.5", UnitAmount: item.UnitAmount}}}}}},
+		{"duplicate reference", &MarketplaceOrder{Currency: "USD", Sellers: []*MarketplaceSeller{{MerchantID: "C7CYMKZDG8D6E", Items: []Item{item}}, {MerchantID: "C7CYMKZDG8D6E", Items: []Item{item}}}}},
+		{"fee over 100%", &MarketplaceOrder{Currency: "USD", Fees: &PlatformFeeSchedule{Default: 10001}, Sellers: []*MarketplaceSeller{{MerchantID: "C7CYMKZDG8D6E", Items: []Item{item}}}}},
+	}
+	for _, tt := range tests {
+		if _, err := tt.order.PurchaseUnits(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestCreateMarketplaceOrder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			PurchaseUnits []PurchaseUnitRequest `json:"purchase_units"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if len(body.PurchaseUnits) != 1 || body.PurchaseUnits[0].PaymentInstruction.DisbursementMode != DisbursementModeDelayed ||
+			body.PurchaseUnits[0].PaymentInstruction.PlatformFees[0].Amount.Value != "150" {
+			t.Errorf("unexpected purchase units %+v", body.PurchaseUnits)
+		}
+		fmt.Fprint(w, `{"id":"5O190127TN364715T","status":"CREATED"}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	order, err := c.CreateMarketplaceOrder(OrderIntentCapture, &MarketplaceOrder{
+		Currency:         "JPY",
+		Fees:             &PlatformFeeSchedule{Default: 500},
+		DisbursementMode: DisbursementModeDelayed,
+		Sellers: []*MarketplaceSeller{
+			{MerchantID: "C7CYMKZDG8D6E", Items: []Item{{Name: "Yoga Mat", Quantity: "1", UnitAmount: &Money{Currency: "JPY", Value: "3000"}}}},
+		},
+	}, nil, nil)
+	if err != nil || order.ID != "5O190127TN364715T" {
+		t.Errorf("unexpected order %+v, %v", order, err)
+	}
+}
+
+func TestCapture_PlatformFeeRefund(t *testing.T) {
+	capture := &Capture{
+		ID:     "2GG279541U471931P",
+		Amount: &Money{Currency: "USD", Value: "47.87"},
+		SellerReceivableBreakdown: &SellerReceivableBreakdown{
+			PlatformFees: []*PlatformFee{{Amount: &Money{Currency: "USD", Value: "4.79"}, Payee: &PayeeBase{MerchantID: "Y5FWFLXCAMKNL"}}},
+		},
+	}
+
+	fees, err := capture.PlatformFeeRefund(nil)
+	if err != nil || len(fees) != 1 || fees[0].Amount.Value != "4.79" || fees[0].Payee.MerchantID != "Y5FWFLXCAMKNL" {
+		t.Errorf("unexpected full refund fees %+v, %v", fees, err)
+	}
+
+	// 4.79 * 20 / 47.87 rounds to 2.00
+	fees, err = capture.PlatformFeeRefund(&Money{Currency: "USD", Value: "20.00"})
+	if err != nil || len(fees) != 1 || fees[0].Amount.Value != "2.00" {
+		t.Errorf("unexpected partial refund fees %+v, %v", fees, err)
+	}
+
+	if _, err := capture.PlatformFeeRefund(&Money{Currency: "USD", Value: "50.00"}); err == nil {
+		t.Errorf("expected an error refunding more than captured")
+	}
+	if _, err := capture.PlatformFeeRefund(&Money{Currency: "EUR", Value: "10.00"}); err == nil {
+		t.Errorf("expected an error refunding another currency")
+	}
+	if fees, err := (&Capture{}).PlatformFeeRefund(nil); fees != nil || err != nil {
+		t.Errorf("expected no fees for a capture without platform fees, got %+v, %v", fees, err)
+	}
+}
+
+func TestRefundCapturedPayment_PlatformFees(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body RefundRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/v2/payments/captures/2GG279541U471931P/refund" || body.PaymentInstruction == nil ||
+			len(body.PaymentInstruction.PlatformFees) != 1 || body.PaymentInstruction.PlatformFees[0].Amount.Value != "2.00" {
+			t.Errorf("unexpected refund request %s %+v", r.URL.Path, body)
+		}
+		fmt.Fprint(w, `{"id":"1JU08902781691411","status":"COMPLETED","amount":{"currency_code":"USD","value":"20.00"},"seller_payable_breakdown":{"gross_amount":{"currency_code":"USD","value":"20.00"},"paypal_fee":{"currency_code":"USD","value":"0.59"},"platform_fees":[{"amount":{"currency_code":"USD","value":"2.00"},"payee":{"merchant_id":"Y5FWFLXCAMKNL"}}],"net_amount":{"currency_code":"USD","value":"17.41"},"total_refunded_amount":{"currency_code":"USD","value":"20.00"}}}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	refund, err := c.RefundCapturedPayment("2GG279541U471931P", &RefundRequest{
+		Amount:             &Money{Currency: "USD", Value: "20.00"},
+		PaymentInstruction: &PaymentInstruction{PlatformFees: []PlatformFee{{Amount: &Money{Currency: "USD", Value: "2.00"}}}},
+	})
+	if err != nil {
+		t.Fatalf("Not expected error for RefundCapturedPayment, got %v", err)
+	}
+
+	reversal, err := refund.SellerPayableBreakdown.PlatformFeeReversal()
+	if err != nil || reversal == nil || reversal.Currency != "USD" || reversal.Value != "2.00" {
+		t.Errorf("unexpected platform fee reversal %+v, %v", reversal, err)
+	}
+	if reversal, err := (&SellerPayableBreakdown{}).PlatformFeeReversal(); reversal != nil || err != nil {
+		t.Errorf("expected no reversal, got %+v, %v", reversal, err)
+	}
+}
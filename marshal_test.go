@@ -0,0 +1,211 @@
+package paypal
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTrip decodes the recorded payload into v, encodes it back and checks the encoded JSON decodes
+// to the same value and contains the wire fields
+func roundTrip(t *testing.T, file string, v interface{}, fields ...string) []byte {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "payloads", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s: decode failed: %v", file, err)
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("%s: encode failed: %v", file, err)
+	}
+
+	again := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	if err := json.Unmarshal(encoded, again); err != nil {
+		t.Fatalf("%s: decode of %s failed: %v", file, encoded, err)
+	}
+	if !reflect.DeepEqual(v, again) {
+		t.Errorf("%s: round trip changed the value\nbefore: %+v\nafter:  %+v", file, v, again)
+	}
+
+	for _, field := range fields {
+		if !strings.Contains(string(encoded), field) {
+			t.Errorf("%s: expected %s in %s", file, field, encoded)
+		}
+	}
+	return encoded
+}
+
+func TestRoundTripBillingAgreement(t *testing.T) {
+	agreement := &ExecuteAgreementResponse{}
+	roundTrip(t, "billing-agreement.json", agreement,
+		`"cycles_remaining":"11"`, `"cycles_completed":"1"`, `"failed_payment_count":"0"`)
+
+	details := agreement.AgreementDetails
+	if details.CyclesRemaining != 11 || details.CyclesCompleted != 1 || details.FailedPaymentCount != 0 {
+		t.Errorf("unexpected agreement details %+v", details)
+	}
+}
+
+func TestRoundTripProduct(t *testing.T) {
+	product := &Product{}
+	roundTrip(t, "product.json", product, `"create_time":"2019-01-10T21:20:49Z"`, `"update_time":"2019-01-10T21:20:49Z"`)
+
+	if product.CreateTime == "" || product.UpdateTime == "" {
+		t.Errorf("expected product timestamps, got %+v", product)
+	}
+
+	encoded, err := json.Marshal(&Product{Name: "Video Streaming Service", Type: ProductTypeService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(encoded), "_time") {
+		t.Errorf("expected empty timestamps to be omitted, got %s", encoded)
+	}
+}
+
+func TestRoundTripSubscription(t *testing.T) {
+	subscription := &Subscription{}
+	roundTrip(t, "subscription.json", subscription, `"create_time":"2019-04-09T10:26:04Z"`, `"last_digits":"7704"`)
+
+	if subscription.CreateTime != "2019-04-09T10:26:04Z" {
+		t.Errorf("expected subscription create time, got %q", subscription.CreateTime)
+	}
+	source := subscription.Subscriber.PaymentSource
+	if source == nil || source.Card == nil || source.Card.LastDigit != "7704" {
+		t.Errorf("expected subscriber card last digits, got %+v", source)
+	}
+
+	encoded, err := json.Marshal(&SubscriberRequest{EmailAddress: "customer@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(encoded), "name") {
+		t.Errorf("expected empty subscriber name to be omitted, got %s", encoded)
+	}
+}
+
+func TestRoundTripUserInfo(t *testing.T) {
+	userInfo := &UserInfo{}
+	roundTrip(t, "userinfo.json", userInfo, `"verified":"true"`, `"verified_account":"true"`)
+
+	if !userInfo.Verified || !userInfo.VerifiedAccount {
+		t.Errorf("expected verified user info, got %+v", userInfo)
+	}
+
+	encoded, err := json.Marshal(&UserInfo{ID: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(encoded), "verified") {
+		t.Errorf("expected unverified flags to be omitted, got %s", encoded)
+	}
+}
+
+func TestMarshalReferralRequestOmitsPartnerConfigOverride(t *testing.T) {
+	encoded, err := json.Marshal(&ReferralRequest{TrackingID: "seller-1001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(encoded), "partner_config_override") {
+		t.Errorf("expected empty partner config override to be omitted, got %s", encoded)
+	}
+}
+
+func TestJSONTimeUnmarshalJSON(t *testing.T) {
+	want := time.Date(2019, 6, 17, 7, 0, 0, 0, time.UTC)
+	for _, stamp := range []string{
+		`"2019-06-17T07:00:00Z"`,
+		`"2019-06-17T07:00:00.000Z"`,
+		`"2019-06-17T00:00:00-07:00"`,
+		`"2019-06-17T00:00:00-0700"`,
+		`"2019-06-17T07:00:00"`,
+	} {
+		var got JSONTime
+		if err := json.Unmarshal([]byte(stamp), &got); err != nil || !got.Time().Equal(want) {
+			t.Errorf("%s: expected %v, got %v, %v", stamp, want, got.Time(), err)
+		}
+	}
+
+	var date JSONTime
+	if err := json.Unmarshal([]byte(`"2019-06-17"`), &date); err != nil || !date.Time().Equal(time.Date(2019, 6, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date %v, %v", date.Time(), err)
+	}
+
+	payment := &Payment{}
+	if err := json.Unmarshal([]byte(`{"id":"PAY-1","create_time":"","update_time":null}`), payment); err != nil || !payment.CreateTime.Time().IsZero() || payment.UpdateTime != nil {
+		t.Errorf("expected empty timestamps to decode as zero, got %+v, %v", payment, err)
+	}
+
+	err := decodeJSON([]byte(`{"id":"PAY-1","transactions":[{"related_resources":[{"sale":{"id":"4RR959492F879224U"}},{"refund":{"create_time":"17/06/2019"}}]}]}`), payment)
+	if tsErr, ok := err.(*TimestampError); !ok || tsErr.Field != "transactions[0].related_resources[1].refund.create_time" || tsErr.Value != "17/06/2019" {
+		t.Errorf("expected an error naming the refund create_time, got %v", err)
+	}
+
+	// The same value in a field decoded as a string is not taken for the invalid timestamp
+	err = decodeJSON([]byte(`{"id":"PAY-1","note_to_payer":"17/06/2019","transactions":[{"related_resources":[{"refund":{"description":"17/06/2019","create_time":"17/06/2019"}}]}]}`), payment)
+	if tsErr, ok := err.(*TimestampError); !ok || tsErr.Field != "transactions[0].related_resources[0].refund.create_time" {
+		t.Errorf("expected an error naming the refund create_time, got %v", err)
+	}
+}
+
+func TestRoundTripCapture(t *testing.T) {
+	capture := &Capture{}
+	roundTrip(t, "capture.json", capture, `"payment_advice_code":"01"`, `"network_transaction_reference":{"id":"123456789012345"`)
+
+	processor := capture.ProcessorResponse
+	if processor == nil || processor.AvsCode != "Y" || processor.CvvCode != "M" || processor.ResponseCode != "0000" {
+		t.Errorf("expected processor response, got %+v", processor)
+	}
+	reference := capture.NetworkTransactionReference
+	if reference == nil || reference.ID != "123456789012345" || reference.Network != "VISA" || reference.Date != "0405" {
+		t.Errorf("expected network transaction reference, got %+v", reference)
+	}
+}
+
+func TestMarshalOmitsEmptyNestedStructs(t *testing.T) {
+	tests := []struct {
+		v        interface{}
+		expected string
+	}{
+		{AuthorizeOrderRequest{}, `{}`},
+		{AuthorizeOrderRequest{ApplicationContext: ApplicationContext{BrandName: "YeowZa!"}}, `{"application_context":{"brand_name":"YeowZa!","return_url":"","cancel_url":""}}`},
+		{BillingAgreement{Name: "Magazine"}, `{"name":"Magazine"}`},
+		{BillingAgreement{Name: "Magazine", StartDate: JSONTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)), Plan: BillingPlan{ID: "P-1"}}, `{"name":"Magazine","start_date":"2020-01-01T00:00:00Z","plan":{"id":"P-1"}}`},
+		{BillingPlan{PaymentDefinitions: []PaymentDefinition{{Name: "Regular", ChargeModels: []ChargeModel{{Type: "TAX"}}}}}, `{"payment_definitions":[{"name":"Regular","charge_models":[{"type":"TAX"}]}]}`},
+		{CreateBillingResp{ID: "P-1"}, `{"id":"P-1"}`},
+		{CreateAgreementResp{Name: "Magazine"}, `{"name":"Magazine"}`},
+		{PayoutItemResponse{PayoutItemID: "8AELMXH8UB2P8"}, `{"payout_item_id":"8AELMXH8UB2P8","transaction_id":"","transaction_status":"","payout_item":null,"links":null}`},
+		{SubscriptionBillingInfo{FailedPaymentsCount: 1}, `{"outstanding_balance":null,"next_billing_time":"","final_payment_time":"","failed_payments_count":1,"last_failed_payment":{"amount":null,"time":""}}`},
+		{WebProfile{Name: "YeowZa! T-Shirt Shop"}, `{"name":"YeowZa! T-Shirt Shop"}`},
+		{WebProfile{Name: "YeowZa! T-Shirt Shop", FlowConfig: FlowConfig{UserAction: "commit"}}, `{"name":"YeowZa! T-Shirt Shop","flow_config":{"user_action":"commit"}}`},
+	}
+	for _, tt := range tests {
+		encoded, err := json.Marshal(tt.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, encoded)
+		}
+	}
+
+	profile := WebProfile{Name: "YeowZa! T-Shirt Shop", Presentation: Presentation{LocaleCode: "US"}, InputFields: InputFields{NoShipping: NoShippingHide}}
+	encoded, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded WebProfile
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != profile {
+		t.Errorf("Expected %+v, got %+v", profile, decoded)
+	}
+}
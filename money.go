@@ -0,0 +1,154 @@
+package paypal
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+)
+
+// decimalPattern matches amounts as PayPal accepts them, big.Rat also parses "4/2", "1e2", "0x10", "1." and "+3"
+var decimalPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// NewMoney returns the amount rounded half away from zero and formatted with the precision of the currency
+func NewMoney(currency string, amount *big.Rat) *Money {
+	return &Money{Currency: currency, Value: formatCurrency(amount, currency)}
+}
+
+// Decimal parses the value of the money, values with more fraction digits than the currency allows are rejected
+// as PayPal rejects them
+func (m *Money) Decimal() (*big.Rat, error) {
+	if m == nil {
+		return nil, fmt.Errorf("paypal: money is required")
+	}
+	if _, ok := LookupCurrency(m.Currency); !ok {
+		return nil, fmt.Errorf("paypal: %q is not an ISO-4217 currency code", m.Currency)
+	}
+	return parseMoney(m, m.Currency, "money value")
+}
+
+// Normalize returns the money formatted with the precision of the currency, e.g. "10" becomes "10.00" for USD
+func (m *Money) Normalize() (*Money, error) {
+	value, err := m.Decimal()
+	if err != nil {
+		return nil, err
+	}
+	return NewMoney(m.Currency, value), nil
+}
+
+// Add returns the sum of m and other, both must be in the same currency
+func (m *Money) Add(other *Money) (*Money, error) {
+	x, y, err := m.operands(other)
+	if err != nil {
+		return nil, err
+	}
+	return NewMoney(m.Currency, x.Add(x, y)), nil
+}
+
+// Sub returns m minus other, both must be in the same currency and the result must not be negative
+func (m *Money) Sub(other *Money) (*Money, error) {
+	x, y, err := m.operands(other)
+	if err != nil {
+		return nil, err
+	}
+	if x.Cmp(y) < 0 {
+		return nil, fmt.Errorf("paypal: cannot subtract %s %s from %s %s", other.Value, other.Currency, m.Value, m.Currency)
+	}
+	return NewMoney(m.Currency, x.Sub(x, y)), nil
+}
+
+// Percent returns the given percent of m rounded to the precision of the currency, e.g. a 2.9 percent fee
+func (m *Money) Percent(percent string) (*Money, error) {
+	value, err := m.Decimal()
+	if err != nil {
+		return nil, err
+	}
+	p, err := parseDecimal(percent, "percent")
+	if err != nil {
+		return nil, err
+	}
+	return NewMoney(m.Currency, applyPercent(value, p)), nil
+}
+
+// Money returns the amount without its breakdown
+func (a *PurchaseUnitAmount) Money() *Money {
+	if a == nil {
+		return nil
+	}
+	return &Money{Currency: a.Currency, Value: a.Value}
+}
+
+// Decimal parses the value of the amount, see Money.Decimal
+func (a *PurchaseUnitAmount) Decimal() (*big.Rat, error) {
+	return a.Money().Decimal()
+}
+
+func (m *Money) operands(other *Money) (*big.Rat, *big.Rat, error) {
+	if m == nil || other == nil {
+		return nil, nil, fmt.Errorf("paypal: money is required")
+	}
+	x, err := m.Decimal()
+	if err != nil {
+		return nil, nil, err
+	}
+	y, err := parseMoney(other, m.Currency, "money value")
+	if err != nil {
+		return nil, nil, err
+	}
+	return x, y, nil
+}
+
+// parseSignedDecimal parses an amount or a quantity in the decimal notation PayPal accepts, negative values included
+func parseSignedDecimal(value string) (*big.Rat, bool) {
+	if !decimalPattern.MatchString(value) {
+		return nil, false
+	}
+	return new(big.Rat).SetString(value)
+}
+
+func parseDecimal(value, field string) (*big.Rat, error) {
+	r, ok := parseSignedDecimal(value)
+	if !ok || r.Sign() < 0 {
+		return nil, fmt.Errorf("paypal: invalid %s %q", field, value)
+	}
+	return r, nil
+}
+
+func parseMoney(m *Money, currency, field string) (*big.Rat, error) {
+	if m == nil {
+		return nil, fmt.Errorf("paypal: %s is required", field)
+	}
+	if m.Currency != currency {
+		return nil, fmt.Errorf("paypal: %s currency %s does not match %s", field, m.Currency, currency)
+	}
+	r, err := parseDecimal(m.Value, field)
+	if err != nil {
+		return nil, err
+	}
+	if roundCurrency(r, currency).Cmp(r) != 0 {
+		return nil, fmt.Errorf("paypal: %s %s has more fraction digits than %s allows", field, m.Value, currency)
+	}
+	return r, nil
+}
+
+// roundCurrency rounds half away from zero to the precision of the currency
+func roundCurrency(r *big.Rat, currency string) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(CurrencyDecimals(currency))), nil)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+
+	num, den := scaled.Num(), scaled.Denom()
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return new(big.Rat).SetFrac(q, scale)
+}
+
+// formatCurrency formats the amount with the precision of the currency, e.g. "10.00" or "1000"
+func formatCurrency(r *big.Rat, currency string) string {
+	return roundCurrency(r, currency).FloatString(CurrencyDecimals(currency))
+}
@@ -0,0 +1,129 @@
+package paypal
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCurrencyDecimals(t *testing.T) {
+	for currency, want := range map[string]int{"USD": 2, "EUR": 2, "JPY": 0, "HUF": 0, "KRW": 0, "TND": 3, "KWD": 3} {
+		if got := CurrencyDecimals(currency); got != want {
+			t.Errorf("expected %d decimals for %s, got %d", want, currency, got)
+		}
+	}
+}
+
+func TestNewMoney(t *testing.T) {
+	tests := []struct {
+		currency string
+		amount   *big.Rat
+		want     string
+	}{
+		{"USD", big.NewRat(1005, 100), "10.05"},
+		{"USD", big.NewRat(10, 1), "10.00"},
+		{"USD", big.NewRat(1, 200), "0.01"},
+		{"JPY", big.NewRat(2001, 2), "1001"},
+		{"TND", big.NewRat(12345, 1000), "12.345"},
+		{"TND", big.NewRat(1, 2000), "0.001"},
+	}
+	for _, tt := range tests {
+		if got := NewMoney(tt.currency, tt.amount); got.Value != tt.want || got.Currency != tt.currency {
+			t.Errorf("expected %s %s, got %+v", tt.want, tt.currency, got)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	// 0.1 + 0.2 is exactly 0.30, unlike with float64
+	sum, err := (&Money{Currency: "USD", Value: "0.10"}).Add(&Money{Currency: "USD", Value: "0.20"})
+	if err != nil || sum.Value != "0.30" {
+		t.Errorf("unexpected sum %+v, %v", sum, err)
+	}
+
+	diff, err := (&Money{Currency: "TND", Value: "10.500"}).Sub(&Money{Currency: "TND", Value: "0.125"})
+	if err != nil || diff.Value != "10.375" {
+		t.Errorf("unexpected difference %+v, %v", diff, err)
+	}
+
+	if _, err := (&Money{Currency: "USD", Value: "1.00"}).Sub(&Money{Currency: "USD", Value: "2.00"}); err == nil {
+		t.Errorf("expected an error for a negative result")
+	}
+	if _, err := (&Money{Currency: "USD", Value: "1.00"}).Add(&Money{Currency: "EUR", Value: "1.00"}); err == nil {
+		t.Errorf("expected an error for mixed currencies")
+	}
+	if _, err := (&Money{Currency: "JPY", Value: "100.5"}).Add(&Money{Currency: "JPY", Value: "1"}); err == nil {
+		t.Errorf("expected an error for a fractional JPY amount")
+	}
+
+	fee, err := (&Money{Currency: "USD", Value: "19.99"}).Percent("2.9")
+	if err != nil || fee.Value != "0.58" {
+		t.Errorf("unexpected fee %+v, %v", fee, err)
+	}
+	fee, err = (&Money{Currency: "JPY", Value: "1999"}).Percent("3.6")
+	if err != nil || fee.Value != "72" {
+		t.Errorf("unexpected fee %+v, %v", fee, err)
+	}
+
+	normalized, err := (&Money{Currency: "EUR", Value: "7"}).Normalize()
+	if err != nil || normalized.Value != "7.00" {
+		t.Errorf("unexpected normalized money %+v, %v", normalized, err)
+	}
+
+	value, err := (&PurchaseUnitAmount{Currency: "USD", Value: "7.25"}).Decimal()
+	if err != nil || value.Cmp(big.NewRat(29, 4)) != 0 {
+		t.Errorf("unexpected amount %v, %v", value, err)
+	}
+}
+
+func TestMoneyDecimal_Notation(t *testing.T) {
+	// big.Rat parses these, PayPal rejects them with INVALID_PARAMETER_SYNTAX
+	for _, value := range []string{"4/2", "1e2", "0x10", "1.", ".5", "+3", " 1.00", "1,00", ""} {
+		if _, err := (&Money{Currency: "USD", Value: value}).Decimal(); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+		if err := checkMoney("amount", &Money{Currency: "USD", Value: value}); err == nil {
+			t.Errorf("expected a validation error for %q", value)
+		}
+	}
+	if moneyEqual(&Money{Currency: "USD", Value: "1e1"}, &Money{Currency: "USD", Value: "10"}) {
+		t.Errorf("expected 1e1 not to be a valid amount")
+	}
+
+	if value, ok := parseSignedDecimal("-4.00"); !ok || value.Cmp(big.NewRat(-4, 1)) != 0 {
+		t.Errorf("unexpected negative amount %v", value)
+	}
+}
+
+func TestCurrencyCatalog(t *testing.T) {
+	brl, ok := LookupCurrency("BRL")
+	if !ok || !brl.PayPal || !brl.InCountryOnly || brl.Decimals != 2 {
+		t.Errorf("unexpected BRL %+v, %v", brl, ok)
+	}
+	if _, ok := LookupCurrency("usd"); ok {
+		t.Errorf("expected lower case codes to be unknown")
+	}
+
+	supported := map[string]bool{}
+	for _, c := range PayPalCurrencies() {
+		supported[c.Code] = true
+	}
+	if !supported["USD"] || !supported["HUF"] || supported["TND"] || supported["INR"] {
+		t.Errorf("unexpected PayPal currencies %v", supported)
+	}
+
+	if _, err := (&Money{Currency: "XYZ", Value: "1.00"}).Add(&Money{Currency: "XYZ", Value: "1.00"}); err == nil {
+		t.Errorf("expected an error for an unknown currency")
+	}
+
+	if err, ok := checkMoney("amount", &Money{Currency: "TND", Value: "1.000"}).(*ValidationError); !ok || err.Field != "amount.currency_code" {
+		t.Errorf("expected a validation error for a currency PayPal does not support, got %v", err)
+	}
+
+	payout := &Payout{
+		SenderBatchHeader: &SenderBatchHeader{EmailSubject: "You have a payout!"},
+		Items:             []PayoutItem{{Receiver: "receiver@example.com", Amount: &AmountPayout{Currency: "US", Value: "9.87"}}},
+	}
+	if err, ok := payout.Validate().(*ValidationError); !ok || err.Field != "items[0].amount.currency" {
+		t.Errorf("expected a validation error for the payout currency, got %v", err)
+	}
+}
@@ -1,6 +1,22 @@
 package paypal
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// maxPurchaseUnits is the number of purchase units PayPal accepts in an order
+const maxPurchaseUnits = 10
+
+// createOrderRequest is the body of create order
+type createOrderRequest struct {
+	Intent             OrderIntent           `json:"intent"`
+	Payer              *CreateOrderPayer     `json:"payer,omitempty"`
+	PurchaseUnits      []PurchaseUnitRequest `json:"purchase_units"`
+	ApplicationContext *ApplicationContext   `json:"application_context,omitempty"`
+}
 
 // GetOrder retrieves order by ID
 // Endpoint: GET /v2/checkout/orders/ID
@@ -21,17 +37,15 @@ func (c *Client) GetOrder(orderID string) (*Order, error) {
 
 // CreateOrder - Use this call to create an order
 // Endpoint: POST /v2/checkout/orders
-func (c *Client) CreateOrder(intent string, purchaseUnits []PurchaseUnitRequest, payer *CreateOrderPayer, appContext *ApplicationContext) (*Order, error) {
-	type createOrderRequest struct {
-		Intent             string                `json:"intent"`
-		Payer              *CreateOrderPayer     `json:"payer,omitempty"`
-		PurchaseUnits      []PurchaseUnitRequest `json:"purchase_units"`
-		ApplicationContext *ApplicationContext   `json:"application_context,omitempty"`
-	}
-
+func (c *Client) CreateOrder(intent OrderIntent, purchaseUnits []PurchaseUnitRequest, payer *CreateOrderPayer, appContext *ApplicationContext) (*Order, error) {
 	order := &Order{}
 
-	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders"), createOrderRequest{Intent: intent, PurchaseUnits: purchaseUnits, Payer: payer, ApplicationContext: appContext})
+	body := &createOrderRequest{Intent: intent, PurchaseUnits: purchaseUnits, Payer: payer, ApplicationContext: appContext}
+	if err := c.validate(body); err != nil {
+		return order, err
+	}
+
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders"), body)
 	if err != nil {
 		return order, err
 	}
@@ -65,6 +79,10 @@ func (c *Client) UpdateOrder(orderID string, purchaseUnits []PurchaseUnitRequest
 func (c *Client) AuthorizeOrder(orderID string, authorizeOrderRequest AuthorizeOrderRequest) (*Authorization, error) {
 	auth := &Authorization{}
 
+	if err := c.validate(&authorizeOrderRequest); err != nil {
+		return auth, err
+	}
+
 	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders/"+orderID+"/authorize"), authorizeOrderRequest)
 	if err != nil {
 		return auth, err
@@ -94,3 +112,332 @@ func (c *Client) CaptureOrder(orderID string, captureOrderRequest CaptureOrderRe
 
 	return capture, nil
 }
+
+// ChargeBillingAgreementOptions configures ChargeBillingAgreement, nil uses a random request ID
+type ChargeBillingAgreementOptions struct {
+	SoftDescriptor string
+	// RequestID prefixes the PayPal-Request-Id of both requests, calling ChargeBillingAgreement again with the
+	// same RequestID after a failure does not charge the payer twice. A random ID is used when empty
+	RequestID string
+	// ClientMetadataID is sent as the PayPal-Client-Metadata-Id header, the tracking ID of the transaction
+	// context set with SetTransactionContext or the device data collected for the payer
+	ClientMetadataID string
+}
+
+// ChargeBillingAgreement charges a billing agreement without the payer being present (merchant initiated transaction).
+// It creates a CAPTURE order for amount and captures it with the billing agreement as payment source.
+// Both requests carry a PayPal-Request-Id header derived from opts.RequestID, which PayPal requires when a payment
+// source is supplied, and the PayPal-Client-Metadata-Id header when opts.ClientMetadataID is set.
+// They are canceled with ctx
+// Endpoint: POST /v2/checkout/orders, POST /v2/checkout/orders/ID/capture
+func (c *Client) ChargeBillingAgreement(ctx context.Context, billingAgreementID string, amount *PurchaseUnitAmount, opts *ChargeBillingAgreementOptions) (*CaptureOrderResponse, error) {
+	capture := &CaptureOrderResponse{}
+
+	if billingAgreementID == "" || amount == nil {
+		return capture, fmt.Errorf("paypal: billing agreement ID and amount are required to charge a billing agreement")
+	}
+	if opts == nil {
+		opts = &ChargeBillingAgreementOptions{}
+	}
+
+	requestID := opts.RequestID
+	if requestID == "" {
+		var err error
+		if requestID, err = newRequestID(); err != nil {
+			return capture, err
+		}
+	}
+
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders"), createOrderRequest{
+		Intent:        OrderIntentCapture,
+		PurchaseUnits: []PurchaseUnitRequest{{Amount: amount, SoftDescriptor: opts.SoftDescriptor}},
+	})
+	if err != nil {
+		return capture, err
+	}
+	req.Header.Set("PayPal-Request-Id", requestID+"-create")
+	if opts.ClientMetadataID != "" {
+		req.Header.Set("PayPal-Client-Metadata-Id", opts.ClientMetadataID)
+	}
+
+	order := &Order{}
+	if err = c.SendWithAuth(req.WithContext(ctx), order); err != nil {
+		return capture, err
+	}
+
+	req, err = c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/checkout/orders/"+order.ID+"/capture"), CaptureOrderRequest{
+		PaymentSource: &PaymentSource{
+			Token: &PaymentSourceToken{ID: billingAgreementID, Type: PaymentSourceTokenTypeBillingAgreement},
+		},
+	})
+	if err != nil {
+		return capture, err
+	}
+	req.Header.Set("PayPal-Request-Id", requestID+"-capture")
+	req.Header.Set("Prefer", "return=representation")
+	if opts.ClientMetadataID != "" {
+		req.Header.Set("PayPal-Client-Metadata-Id", opts.ClientMetadataID)
+	}
+
+	if err = c.SendWithAuth(req.WithContext(ctx), capture); err != nil {
+		return capture, err
+	}
+
+	return capture, nil
+}
+
+// Validate checks the order against the constraints documented by PayPal
+func (r *createOrderRequest) Validate() error {
+	if !r.Intent.IsValid() {
+		return &ValidationError{Field: "intent", Reason: fmt.Sprintf("%q is not one of %s and %s", r.Intent, OrderIntentCapture, OrderIntentAuthorize)}
+	}
+	if len(r.PurchaseUnits) == 0 || len(r.PurchaseUnits) > maxPurchaseUnits {
+		return &ValidationError{Field: "purchase_units", Reason: fmt.Sprintf("must have between 1 and %d purchase units", maxPurchaseUnits)}
+	}
+
+	referenceIDs := map[string]bool{}
+	for i := range r.PurchaseUnits {
+		unit := &r.PurchaseUnits[i]
+		field := fmt.Sprintf("purchase_units[%d]", i)
+		if err := unit.Validate(); err != nil {
+			return nestedError(field, err)
+		}
+		if len(r.PurchaseUnits) == 1 {
+			continue
+		}
+		if unit.ReferenceID == "" {
+			return &ValidationError{Field: field + ".reference_id", Reason: "is required when an order has several purchase units"}
+		}
+		if referenceIDs[unit.ReferenceID] {
+			return &ValidationError{Field: field + ".reference_id", Reason: fmt.Sprintf("%q is used by another purchase unit", unit.ReferenceID)}
+		}
+		referenceIDs[unit.ReferenceID] = true
+	}
+
+	if r.ApplicationContext != nil {
+		return nestedError("application_context", r.ApplicationContext.Validate())
+	}
+	return nil
+}
+
+// Validate checks the purchase unit against the constraints documented by PayPal
+func (u *PurchaseUnitRequest) Validate() error {
+	if u.Amount == nil {
+		return &ValidationError{Field: "amount", Reason: "is required"}
+	}
+	if err := checkMoney("amount", u.Amount.Money()); err != nil {
+		return err
+	}
+
+	err := firstError(
+		checkLength("reference_id", u.ReferenceID, 0, 256),
+		checkLength("description", u.Description, 0, 127),
+		checkLength("custom_id", u.CustomID, 0, 127),
+		checkLength("invoice_id", u.InvoiceID, 0, 127),
+		checkLength("soft_descriptor", u.SoftDescriptor, 0, 22),
+	)
+	if err != nil {
+		return err
+	}
+
+	if u.Shipping != nil && u.Shipping.Address != nil {
+		if err := u.Shipping.Address.Validate(); err != nil {
+			return nestedError("shipping.address", err)
+		}
+	}
+	if u.PaymentInstruction != nil && u.PaymentInstruction.DisbursementMode != "" && !u.PaymentInstruction.DisbursementMode.IsValid() {
+		return &ValidationError{Field: "payment_instruction.disbursement_mode", Reason: fmt.Sprintf("%q is not one of %s and %s", u.PaymentInstruction.DisbursementMode, DisbursementModeInstant, DisbursementModeDelayed)}
+	}
+
+	for i := range u.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if err := u.Items[i].Validate(); err != nil {
+			return nestedError(field, err)
+		}
+		if u.Items[i].UnitAmount.Currency != u.Amount.Currency {
+			return &ValidationError{Field: field + ".unit_amount.currency_code", Reason: fmt.Sprintf("%s does not match the amount currency %s", u.Items[i].UnitAmount.Currency, u.Amount.Currency)}
+		}
+	}
+	return u.ValidateBreakdown()
+}
+
+// ValidateBreakdown checks the amount adds up as PayPal checks it: item_total is the sum of the items, tax_total
+// the sum of the item taxes and the value is item_total + tax_total + shipping + handling + insurance
+// - shipping_discount - discount, all in the precision of the currency. The error tells which component is off and by how much
+func (u *PurchaseUnitRequest) ValidateBreakdown() error {
+	if u.Amount == nil {
+		return &ValidationError{Field: "amount", Reason: "is required"}
+	}
+	currency := u.Amount.Currency
+	if err := checkMoney("amount", u.Amount.Money()); err != nil {
+		return err
+	}
+
+	breakdown := u.Amount.Breakdown
+	if breakdown == nil {
+		if len(u.Items) > 0 {
+			return &ValidationError{Field: "amount.breakdown.item_total", Reason: "is required when the purchase unit has items"}
+		}
+		return nil
+	}
+
+	if len(u.Items) > 0 {
+		itemTotal, taxTotal := new(big.Rat), new(big.Rat)
+		taxed := false
+		for i, item := range u.Items {
+			field := fmt.Sprintf("items[%d]", i)
+			quantity, ok := parseSignedDecimal(item.Quantity)
+			if !ok || !quantity.IsInt() || quantity.Sign() <= 0 {
+				return &ValidationError{Field: field + ".quantity", Reason: fmt.Sprintf("%q is not a positive whole number", item.Quantity)}
+			}
+			unitAmount, err := breakdownAmount(field+".unit_amount", item.UnitAmount, currency)
+			if err != nil {
+				return err
+			}
+			itemTotal.Add(itemTotal, new(big.Rat).Mul(unitAmount, quantity))
+
+			if item.Tax != nil {
+				tax, err := breakdownAmount(field+".tax", item.Tax, currency)
+				if err != nil {
+					return err
+				}
+				taxTotal.Add(taxTotal, new(big.Rat).Mul(tax, quantity))
+				taxed = true
+			}
+		}
+
+		if err := checkSum("amount.breakdown.item_total", breakdown.ItemTotal, itemTotal, "the items", currency); err != nil {
+			return err
+		}
+		if taxed {
+			if err := checkSum("amount.breakdown.tax_total", breakdown.TaxTotal, taxTotal, "the item taxes", currency); err != nil {
+				return err
+			}
+		}
+	}
+
+	components := []struct {
+		field string
+		money *Money
+		sign  int
+	}{
+		{"item_total", breakdown.ItemTotal, 1},
+		{"tax_total", breakdown.TaxTotal, 1},
+		{"shipping", breakdown.Shipping, 1},
+		{"handling", breakdown.Handling, 1},
+		{"insurance", breakdown.Insurance, 1},
+		{"shipping_discount", breakdown.ShippingDiscount, -1},
+		{"discount", breakdown.Discount, -1},
+	}
+
+	total := new(big.Rat)
+	var terms []string
+	for _, component := range components {
+		if component.money == nil {
+			continue
+		}
+		amount, err := breakdownAmount("amount.breakdown."+component.field, component.money, currency)
+		if err != nil {
+			return err
+		}
+
+		operator := "+"
+		if component.sign < 0 {
+			operator = "-"
+			amount.Neg(amount)
+		}
+		if len(terms) > 0 || component.sign < 0 {
+			terms = append(terms, operator)
+		}
+		terms = append(terms, component.field+" "+component.money.Value)
+		total.Add(total, amount)
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+
+	return checkSum("amount.value", u.Amount.Money(), total, "the breakdown "+strings.Join(terms, " "), currency)
+}
+
+// breakdownAmount parses a component of an amount breakdown, which must be in the currency of the amount
+func breakdownAmount(field string, m *Money, currency string) (*big.Rat, error) {
+	if err := checkMoney(field, m); err != nil {
+		return nil, err
+	}
+	if m.Currency != currency {
+		return nil, &ValidationError{Field: field + ".currency_code", Reason: fmt.Sprintf("%s does not match the amount currency %s", m.Currency, currency)}
+	}
+	return m.Decimal()
+}
+
+// checkSum checks the money equals the sum of what it adds up, e.g. the items for an item_total
+func checkSum(field string, m *Money, sum *big.Rat, what, currency string) error {
+	if m == nil {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("is required to match %s adding up to %s", what, formatCurrency(sum, currency))}
+	}
+	value, err := breakdownAmount(field, m, currency)
+	if err != nil {
+		return err
+	}
+
+	diff := new(big.Rat).Sub(value, sum)
+	switch diff.Sign() {
+	case 1:
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("is %s but %s add up to %s, %s too high", m.Value, what, formatCurrency(sum, currency), formatCurrency(diff, currency))}
+	case -1:
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("is %s but %s add up to %s, %s too low", m.Value, what, formatCurrency(sum, currency), formatCurrency(diff.Neg(diff), currency))}
+	}
+	return nil
+}
+
+// Validate checks the item against the constraints documented by PayPal
+func (i *Item) Validate() error {
+	err := firstError(
+		checkLength("name", i.Name, 1, 127),
+		checkLength("quantity", i.Quantity, 1, 10),
+		checkLength("description", i.Description, 0, 127),
+		checkLength("sku", i.SKU, 0, 127),
+		checkMoney("unit_amount", i.UnitAmount),
+	)
+	if err != nil {
+		return err
+	}
+
+	if quantity, ok := new(big.Int).SetString(i.Quantity, 10); !ok || quantity.Sign() <= 0 {
+		return &ValidationError{Field: "quantity", Reason: fmt.Sprintf("%q is not a positive whole number", i.Quantity)}
+	}
+	if i.Tax != nil {
+		if err := checkMoney("tax", i.Tax); err != nil {
+			return err
+		}
+	}
+	switch i.Category {
+	case "", ItemCategoryDigitalGood, ItemCategoryPhysicalGood:
+	default:
+		return &ValidationError{Field: "category", Reason: fmt.Sprintf("%q is not one of %s and %s", i.Category, ItemCategoryDigitalGood, ItemCategoryPhysicalGood)}
+	}
+	return nil
+}
+
+// Validate checks the application context of the authorization against the constraints documented by PayPal
+func (r *AuthorizeOrderRequest) Validate() error {
+	return nestedError("application_context", r.ApplicationContext.Validate())
+}
+
+// Validate checks the application context against the constraints documented by PayPal
+func (a *ApplicationContext) Validate() error {
+	if err := checkLength("brand_name", a.BrandName, 0, 127); err != nil {
+		return err
+	}
+	if a.Locale != "" && !IsCheckoutLocale(a.Locale) {
+		return localeError("locale", a.Locale, checkoutLocales)
+	}
+
+	if a.LandingPage != "" && !a.LandingPage.IsValid() {
+		return &ValidationError{Field: "landing_page", Reason: fmt.Sprintf("%q is not a known landing page", a.LandingPage)}
+	}
+	if a.ShippingPreference != "" && !a.ShippingPreference.IsValid() {
+		return &ValidationError{Field: "shipping_preference", Reason: fmt.Sprintf("%q is not a known shipping preference", a.ShippingPreference)}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+type (
+	// RefundOrderOptions configures RefundOrder, nil refunds every completed capture in full
+	RefundOrderOptions struct {
+		// Amount is refunded across the completed captures in proportion to their amounts, they are refunded
+		// in full when nil
+		Amount      *Money
+		InvoiceID   string
+		NoteToPayer string
+		// RequestID prefixes the PayPal-Request-Id and the custom_id of the refund of every capture, calling
+		// RefundOrder again with the same RequestID after a failure does not refund the captures twice.
+		// A random ID is used when empty
+		RequestID string
+	}
+
+	// OrderRefund is the result of RefundOrder, Total is the amount refunded by the successful refunds
+	OrderRefund struct {
+		OrderID string
+		Refunds []*CaptureRefund
+		Total   *Money
+	}
+
+	// CaptureRefund is the refund of a single capture of an order, Err is set when it failed.
+	// Amount is the amount requested, nil for a full refund
+	CaptureRefund struct {
+		CaptureID string
+		Amount    *Money
+		Refund    *Refund
+		Err       error
+	}
+)
+
+// RefundOrder refunds an order captured in several parts: it gets the order and refunds each of its COMPLETED
+// and PARTIALLY_REFUNDED captures, in full or its share of opts.Amount. A failing refund does not stop the others,
+// the error then tells how many failed and the result has the error of each capture.
+// Every refund carries a PayPal-Request-Id and a custom_id derived from opts.RequestID and the capture ID, so
+// RefundOrder can be retried with the same options: the captures refunded by an earlier call keep their share
+// and are not refunded again, the others are refunded with the same share and PayPal-Request-Id
+// Endpoint: GET /v2/checkout/orders/ID, POST /v2/payments/captures/ID/refund
+func (c *Client) RefundOrder(ctx context.Context, orderID string, opts *RefundOrderOptions) (*OrderRefund, error) {
+	result := &OrderRefund{OrderID: orderID}
+	if orderID == "" {
+		return result, fmt.Errorf("paypal: order ID is required to refund an order")
+	}
+	if opts == nil {
+		opts = &RefundOrderOptions{}
+	}
+
+	requestID := opts.RequestID
+	if requestID == "" {
+		var err error
+		if requestID, err = newRequestID(); err != nil {
+			return result, err
+		}
+	}
+
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v2/checkout/orders/", orderID), nil)
+	if err != nil {
+		return result, err
+	}
+	order := &Order{}
+	if err := c.SendWithAuth(req.WithContext(ctx), order); err != nil {
+		return result, err
+	}
+
+	// Refunds of earlier calls with the same RequestID, the captures they fully refunded are still split over
+	previous := map[string]*Refund{}
+	for i := range order.PurchaseUnits {
+		if order.PurchaseUnits[i].Payments == nil {
+			continue
+		}
+		for j := range order.PurchaseUnits[i].Payments.Refunds {
+			refund := &order.PurchaseUnits[i].Payments.Refunds[j]
+			captureID := refund.CaptureID()
+			if captureID != "" && refund.CustomID == orderRefundID(requestID, captureID) &&
+				refund.Status != RefundStatusCancelled && refund.Status != RefundStatusFailed {
+				previous[captureID] = refund
+			}
+		}
+	}
+
+	var captures []*Capture
+	for i := range order.PurchaseUnits {
+		if order.PurchaseUnits[i].Payments == nil {
+			continue
+		}
+		for j := range order.PurchaseUnits[i].Payments.Captures {
+			capture := &order.PurchaseUnits[i].Payments.Captures[j]
+			switch {
+			case capture.Status == CaptureStatusCompleted, capture.Status == CaptureStatusPartiallyRefunded,
+				previous[capture.ID] != nil:
+				captures = append(captures, capture)
+			}
+		}
+	}
+	if len(captures) == 0 {
+		return result, fmt.Errorf("paypal: order %s has no completed captures to refund", orderID)
+	}
+
+	amounts := make([]*Money, len(captures))
+	if opts.Amount != nil {
+		if amounts, err = splitRefund(opts.Amount, captures); err != nil {
+			return result, err
+		}
+	}
+
+	var (
+		currency string
+		total    = new(big.Rat)
+		failed   int
+		firstErr error
+	)
+	for i, capture := range captures {
+		if opts.Amount != nil && amounts[i] == nil {
+			continue
+		}
+
+		refund := &CaptureRefund{CaptureID: capture.ID, Amount: amounts[i], Refund: previous[capture.ID]}
+		result.Refunds = append(result.Refunds, refund)
+
+		if refund.Refund == nil {
+			refund.Refund, refund.Err = c.refundCapture(ctx, capture.ID, orderRefundID(requestID, capture.ID), &RefundRequest{
+				Amount:      amounts[i],
+				InvoiceID:   opts.InvoiceID,
+				CustomID:    orderRefundID(requestID, capture.ID),
+				NoteToPayer: opts.NoteToPayer,
+			})
+		}
+		if refund.Err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = refund.Err
+			}
+			continue
+		}
+
+		refunded := refund.Refund.Amount
+		if refunded == nil {
+			if refunded = amounts[i]; refunded == nil {
+				refunded = capture.Amount
+			}
+		}
+		if refunded != nil {
+			if currency == "" {
+				currency = refunded.Currency
+			}
+			if value, err := parseMoney(refunded, currency, "refund amount"); err == nil {
+				total.Add(total, value)
+			}
+		}
+	}
+	if currency != "" {
+		result.Total = &Money{Currency: currency, Value: formatCurrency(total, currency)}
+	}
+
+	if failed > 0 {
+		return result, fmt.Errorf("paypal: %d of %d refunds of order %s failed: %v", failed, len(result.Refunds), orderID, firstErr)
+	}
+	return result, nil
+}
+
+// orderRefundID is the PayPal-Request-Id and custom_id of the refund of a capture by RefundOrder
+func orderRefundID(requestID, captureID string) string {
+	return requestID + "-" + captureID
+}
+
+// refundCapture refunds the capture with the PayPal-Request-Id requestID, the request is canceled with ctx
+func (c *Client) refundCapture(ctx context.Context, captureID, requestID string, body *RefundRequest) (*Refund, error) {
+	resp := &Refund{}
+
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/payments/captures/"+captureID+"/refund"), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PayPal-Request-Id", requestID)
+
+	if err = c.SendWithAuth(req.WithContext(ctx), resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// splitRefund splits amount between the captures in proportion to their amounts. The shares are rounded
+// cumulatively so they add up to amount exactly and never exceed their capture, zero shares are nil
+func splitRefund(amount *Money, captures []*Capture) ([]*Money, error) {
+	currency := amount.Currency
+	target, err := parseMoney(amount, currency, "refund amount")
+	if err != nil {
+		return nil, err
+	}
+	if target.Sign() == 0 {
+		return nil, fmt.Errorf("paypal: refund amount must be greater than 0")
+	}
+
+	captured := make([]*big.Rat, len(captures))
+	sum := new(big.Rat)
+	for i, capture := range captures {
+		if captured[i], err = parseMoney(capture.Amount, currency, fmt.Sprintf("capture %s amount", capture.ID)); err != nil {
+			return nil, err
+		}
+		sum.Add(sum, captured[i])
+	}
+	if target.Cmp(sum) > 0 {
+		return nil, fmt.Errorf("paypal: refund amount %s is more than the captured %s", amount.Value, formatCurrency(sum, currency))
+	}
+
+	shares := make([]*Money, len(captures))
+	cumulative, previous := new(big.Rat), new(big.Rat)
+	for i := range captures {
+		cumulative.Add(cumulative, captured[i])
+		rounded := roundCurrency(new(big.Rat).Quo(new(big.Rat).Mul(target, cumulative), sum), currency)
+		if share := new(big.Rat).Sub(rounded, previous); share.Sign() > 0 {
+			shares[i] = &Money{Currency: currency, Value: formatCurrency(share, currency)}
+		}
+		previous = rounded
+	}
+
+	return shares, nil
+}
@@ -0,0 +1,217 @@
+package paypal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const multiCaptureOrder = `{"id":"5O190127TN364715T","status":"COMPLETED","purchase_units":[
+	{"reference_id":"default","amount":{"currency_code":"USD","value":"100.00"},"payments":{"captures":[
+		{"id":"3C679366HH908993F","status":"COMPLETED","amount":{"currency_code":"USD","value":"60.00"}},
+		{"id":"8MC585209K746392H","status":"DECLINED","amount":{"currency_code":"USD","value":"10.00"}}
+	]}},
+	{"reference_id":"second","amount":{"currency_code":"USD","value":"30.00"},"payments":{"captures":[
+		{"id":"2GG279541U471931P","status":"COMPLETED","amount":{"currency_code":"USD","value":"30.00"}},
+		{"id":"1JU08902781691411","status":"REFUNDED","amount":{"currency_code":"USD","value":"10.00"}}
+	]}}
+]}`
+
+// newOrderRefundServer serves multiCaptureOrder and refunds its captures, recording the requests
+func newOrderRefundServer(t *testing.T, fail string) (*httptest.Server, map[string]*RefundRequest, map[string]string) {
+	var mu sync.Mutex
+	bodies := map[string]*RefundRequest{}
+	requestIDs := map[string]string{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/checkout/orders/5O190127TN364715T" {
+			fmt.Fprint(w, multiCaptureOrder)
+			return
+		}
+
+		captureID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v2/payments/captures/"), "/refund")
+		body := &RefundRequest{}
+		json.NewDecoder(r.Body).Decode(body)
+		mu.Lock()
+		bodies[captureID] = body
+		requestIDs[captureID] = r.Header.Get("PayPal-Request-Id")
+		mu.Unlock()
+
+		if captureID == fail {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"name":"UNPROCESSABLE_ENTITY","message":"The requested action could not be performed."}`)
+			return
+		}
+		amount := `{"currency_code":"USD","value":"60.00"}`
+		if body.Amount != nil {
+			raw, _ := json.Marshal(body.Amount)
+			amount = string(raw)
+		} else if captureID == "2GG279541U471931P" {
+			amount = `{"currency_code":"USD","value":"30.00"}`
+		}
+		fmt.Fprintf(w, `{"id":"REFUND-%s","status":"COMPLETED","amount":%s}`, captureID, amount)
+	}))
+
+	return ts, bodies, requestIDs
+}
+
+func TestRefundOrder_Full(t *testing.T) {
+	ts, bodies, requestIDs := newOrderRefundServer(t, "")
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	result, err := c.RefundOrder(context.Background(), "5O190127TN364715T", &RefundOrderOptions{RequestID: "refund-42", NoteToPayer: "Sorry"})
+	if err != nil {
+		t.Fatalf("Not expected error for RefundOrder, got %v", err)
+	}
+	if len(result.Refunds) != 2 || result.Total.Value != "90.00" || result.Refunds[1].Refund.ID != "REFUND-2GG279541U471931P" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(bodies) != 2 || bodies["3C679366HH908993F"].Amount != nil || bodies["3C679366HH908993F"].NoteToPayer != "Sorry" {
+		t.Errorf("expected full refunds of the completed captures, got %+v", bodies)
+	}
+	if requestIDs["3C679366HH908993F"] != "refund-42-3C679366HH908993F" || requestIDs["2GG279541U471931P"] != "refund-42-2GG279541U471931P" {
+		t.Errorf("unexpected request IDs %v", requestIDs)
+	}
+}
+
+func TestRefundOrder_Proportional(t *testing.T) {
+	ts, bodies, _ := newOrderRefundServer(t, "")
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	// 10.00 split 60/30 is 6.666... and 3.333..., the shares still add up to 10.00
+	result, err := c.RefundOrder(context.Background(), "5O190127TN364715T", &RefundOrderOptions{Amount: &Money{Currency: "USD", Value: "10.00"}})
+	if err != nil {
+		t.Fatalf("Not expected error for RefundOrder, got %v", err)
+	}
+	if bodies["3C679366HH908993F"].Amount.Value != "6.67" || bodies["2GG279541U471931P"].Amount.Value != "3.33" || result.Total.Value != "10.00" {
+		t.Errorf("unexpected proportional refunds %+v %+v, total %+v", bodies["3C679366HH908993F"].Amount, bodies["2GG279541U471931P"].Amount, result.Total)
+	}
+
+	for _, amount := range []*Money{{Currency: "USD", Value: "90.01"}, {Currency: "EUR", Value: "10.00"}, {Currency: "USD", Value: "0"}} {
+		if _, err := c.RefundOrder(context.Background(), "5O190127TN364715T", &RefundOrderOptions{Amount: amount}); err == nil {
+			t.Errorf("expected an error for refund amount %+v", amount)
+		}
+	}
+}
+
+func TestRefundOrder_PartialFailure(t *testing.T) {
+	ts, _, _ := newOrderRefundServer(t, "3C679366HH908993F")
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	result, err := c.RefundOrder(context.Background(), "5O190127TN364715T", nil)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 refunds") {
+		t.Errorf("expected the failed refund to be reported, got %v", err)
+	}
+	if len(result.Refunds) != 2 || result.Refunds[0].Err == nil || result.Refunds[1].Refund == nil || result.Total.Value != "30.00" {
+		t.Errorf("expected the other capture to be refunded, got %+v", result)
+	}
+}
+
+func TestRefundOrder_Retry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+		refunds  []string
+		bodies   = map[string]*RefundRequest{}
+		ids      = map[string]string{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path == "/v2/checkout/orders/5O190127TN364715T" {
+			status := "COMPLETED"
+			if len(refunds) > 0 {
+				status = "PARTIALLY_REFUNDED"
+			}
+			fmt.Fprintf(w, `{"id":"5O190127TN364715T","status":"COMPLETED","purchase_units":[{"reference_id":"default","payments":{"captures":[
+				{"id":"3C679366HH908993F","status":%q,"amount":{"currency_code":"USD","value":"50.00"}},
+				{"id":"2GG279541U471931P","status":"COMPLETED","amount":{"currency_code":"USD","value":"100.00"}}
+			],"refunds":[%s]}}]}`, status, strings.Join(refunds, ","))
+			return
+		}
+
+		captureID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v2/payments/captures/"), "/refund")
+		body := &RefundRequest{}
+		json.NewDecoder(r.Body).Decode(body)
+		attempts[captureID]++
+		bodies[captureID] = body
+		ids[captureID] = r.Header.Get("PayPal-Request-Id")
+
+		if captureID == "2GG279541U471931P" && attempts[captureID] == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"name":"INTERNAL_SERVER_ERROR","message":"An internal server error occurred."}`)
+			return
+		}
+		raw, _ := json.Marshal(body.Amount)
+		refund := fmt.Sprintf(`{"id":"REFUND-%s","status":"COMPLETED","amount":%s,"custom_id":%q,"links":[{"href":"https://api.paypal.com/v2/payments/captures/%s","rel":"up","method":"GET"}]}`,
+			captureID, raw, body.CustomID, captureID)
+		refunds = append(refunds, refund)
+		fmt.Fprint(w, refund)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	opts := &RefundOrderOptions{Amount: &Money{Currency: "USD", Value: "60.00"}, RequestID: "refund-42"}
+	if _, err := c.RefundOrder(context.Background(), "5O190127TN364715T", opts); err == nil {
+		t.Fatalf("expected the failed refund to be reported")
+	}
+	if bodies["3C679366HH908993F"].Amount.Value != "20.00" || bodies["2GG279541U471931P"].Amount.Value != "40.00" {
+		t.Fatalf("unexpected shares %+v %+v", bodies["3C679366HH908993F"].Amount, bodies["2GG279541U471931P"].Amount)
+	}
+
+	result, err := c.RefundOrder(context.Background(), "5O190127TN364715T", opts)
+	if err != nil {
+		t.Fatalf("Not expected error for the retried RefundOrder, got %v", err)
+	}
+	if attempts["3C679366HH908993F"] != 1 || attempts["2GG279541U471931P"] != 2 {
+		t.Errorf("expected only the failed refund to be sent again, got %v", attempts)
+	}
+	if bodies["2GG279541U471931P"].Amount.Value != "40.00" || ids["2GG279541U471931P"] != "refund-42-2GG279541U471931P" ||
+		bodies["2GG279541U471931P"].CustomID != "refund-42-2GG279541U471931P" {
+		t.Errorf("expected the same share and request ID on retry, got %+v, %s", bodies["2GG279541U471931P"], ids["2GG279541U471931P"])
+	}
+	if len(result.Refunds) != 2 || result.Refunds[0].Refund.ID != "REFUND-3C679366HH908993F" || result.Total.Value != "60.00" {
+		t.Errorf("expected the earlier refund to count towards the total, got %+v", result)
+	}
+}
+
+func TestSplitRefund(t *testing.T) {
+	captures := []*Capture{
+		{ID: "A", Amount: &Money{Currency: "USD", Value: "0.01"}},
+		{ID: "B", Amount: &Money{Currency: "USD", Value: "0.01"}},
+		{ID: "C", Amount: &Money{Currency: "USD", Value: "0.01"}},
+	}
+	shares, err := splitRefund(&Money{Currency: "USD", Value: "0.02"}, captures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, share := range shares {
+		if share == nil {
+			got = append(got, "-")
+			continue
+		}
+		got = append(got, share.Value)
+	}
+	// no share exceeds its capture and they add up to the amount
+	if strings.Join(got, ",") != "0.01,-,0.01" {
+		t.Errorf("unexpected shares %v", got)
+	}
+}
@@ -0,0 +1,229 @@
+package paypal
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Possible values for `displayMode` in CreateReferralResponse.ActionURL
+const (
+	OnboardingDisplayModeFullPage    string = "fullpage"
+	OnboardingDisplayModeMiniBrowser string = "minibrowser"
+)
+
+// Possible values for `status` in MerchantCapability
+const (
+	MerchantCapabilityStatusActive    string = "ACTIVE"
+	MerchantCapabilityStatusSuspended string = "SUSPENDED"
+)
+
+type (
+	// CreateReferralResponse represents the response of create partner referral, the seller is sent to the
+	// action_url link to sign up and grant the permissions
+	CreateReferralResponse struct {
+		Links []*Link `json:"links"`
+	}
+
+	// OnboardingReturn represents the query parameters PayPal appends to the partner return URL once the seller
+	// finished onboarding. MerchantID is the tracking ID of the referral, MerchantIDInPayPal the seller's payer ID
+	OnboardingReturn struct {
+		MerchantID         string
+		MerchantIDInPayPal string
+		PermissionsGranted bool
+		ConsentStatus      bool
+		ProductIntentID    string
+		IsEmailConfirmed   bool
+		AccountStatus      string
+		RiskStatus         string
+	}
+
+	// MerchantIntegrationLookup represents the seller onboarded with a tracking ID, MerchantID is the seller's payer ID
+	MerchantIntegrationLookup struct {
+		MerchantID string  `json:"merchant_id"`
+		Links      []*Link `json:"links,omitempty"`
+	}
+
+	// MerchantOnboarding represents the resource of MERCHANT.ONBOARDING.COMPLETED and MERCHANT.PARTNER-CONSENT.REVOKED
+	// events. TrackingID is the tracking ID of the referral, MerchantID the seller's payer ID
+	MerchantOnboarding struct {
+		PartnerClientID   string                      `json:"partner_client_id,omitempty"`
+		MerchantID        string                      `json:"merchant_id"`
+		TrackingID        string                      `json:"tracking_id,omitempty"`
+		Capabilities      []*MerchantCapability       `json:"capabilities,omitempty"`
+		OAuthIntegrations []*MerchantOAuthIntegration `json:"oauth_integrations,omitempty"`
+		Links             []*Link                     `json:"links,omitempty"`
+	}
+
+	// MerchantCapability represents a capability granted to the seller, e.g. CUSTOM_CARD_PROCESSING
+	MerchantCapability struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+
+	// MerchantOAuthIntegration represents the permissions the seller granted to third parties
+	MerchantOAuthIntegration struct {
+		IntegrationType   string                     `json:"integration_type,omitempty"`
+		IntegrationMethod string                     `json:"integration_method,omitempty"`
+		OAuthThirdParty   []*MerchantOAuthThirdParty `json:"oauth_third_party,omitempty"`
+	}
+
+	// MerchantOAuthThirdParty represents the scopes granted to a partner
+	MerchantOAuthThirdParty struct {
+		PartnerClientID  string   `json:"partner_client_id"`
+		MerchantClientID string   `json:"merchant_client_id,omitempty"`
+		Scopes           []string `json:"scopes"`
+	}
+
+	// ReferralData represents a partner referral as it was submitted
+	ReferralData struct {
+		PartnerReferralID string           `json:"partner_referral_id"`
+		SubmitterPayerID  string           `json:"submitter_payer_id,omitempty"`
+		ReferralData      *ReferralRequest `json:"referral_data"`
+		Links             []*Link          `json:"links,omitempty"`
+	}
+)
+
+// ActionURL returns the URL to send the seller to for onboarding, or an empty string when the response has no
+// action_url link. With OnboardingDisplayModeMiniBrowser the URL opens in the PayPal mini browser
+// started by partner.js, the default OnboardingDisplayModeFullPage redirects the whole page
+func (r *CreateReferralResponse) ActionURL(displayMode string) string {
+	l := findLink(r.Links, LinkRelActionURL)
+	if l == nil {
+		return ""
+	}
+	if displayMode != OnboardingDisplayModeMiniBrowser {
+		return l.Href
+	}
+
+	u, err := url.Parse(l.Href)
+	if err != nil {
+		return l.Href
+	}
+	q := u.Query()
+	q.Set("displayMode", displayMode)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// Completed reports whether the seller granted the permissions and the consent to share data with the partner,
+// a seller who did not still has to finish onboarding before the partner can call on their behalf
+func (o *OnboardingReturn) Completed() bool {
+	return o.PermissionsGranted && o.ConsentStatus
+}
+
+// HasCapability reports whether the capability is granted to the seller and active
+func (m *MerchantOnboarding) HasCapability(name string) bool {
+	for _, c := range m.Capabilities {
+		if c != nil && c.Name == name && c.Status == MerchantCapabilityStatusActive {
+			return true
+		}
+	}
+	return false
+}
+
+// Scopes returns the OAuth scopes the seller granted to partnerClientID, none once the consent was revoked
+func (m *MerchantOnboarding) Scopes(partnerClientID string) []string {
+	var scopes []string
+	for _, integration := range m.OAuthIntegrations {
+		if integration == nil {
+			continue
+		}
+		for _, party := range integration.OAuthThirdParty {
+			if party != nil && party.PartnerClientID == partnerClientID {
+				scopes = append(scopes, party.Scopes...)
+			}
+		}
+	}
+	return scopes
+}
+
+// CreateReferral creates a partner referral, send the seller to ActionURL of the response to onboard
+// Endpoint: POST /v2/customer/partner-referrals
+func (c *Client) CreateReferral(referral *ReferralRequest) (*CreateReferralResponse, error) {
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v2/customer/partner-referrals"), referral)
+	resp := &CreateReferralResponse{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// ParseOnboardingReturn reads the parameters of the request PayPal redirects the seller to after onboarding,
+// the return_url of the referral. The flags are reported as they were sent, use Completed to check them
+func ParseOnboardingReturn(r *http.Request) (*OnboardingReturn, error) {
+	q := r.URL.Query()
+
+	ret := &OnboardingReturn{
+		MerchantID:         q.Get("merchantId"),
+		MerchantIDInPayPal: q.Get("merchantIdInPayPal"),
+		ProductIntentID:    q.Get("productIntentId"),
+		AccountStatus:      q.Get("accountStatus"),
+		RiskStatus:         q.Get("riskStatus"),
+	}
+	if ret.MerchantIDInPayPal == "" {
+		return ret, fmt.Errorf("paypal: onboarding return has no merchantIdInPayPal")
+	}
+
+	flags := []struct {
+		name string
+		v    *bool
+	}{
+		{"permissionsGranted", &ret.PermissionsGranted},
+		{"consentStatus", &ret.ConsentStatus},
+		{"isEmailConfirmed", &ret.IsEmailConfirmed},
+	}
+	for _, f := range flags {
+		raw := q.Get(f.name)
+		if raw == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return ret, fmt.Errorf("paypal: invalid onboarding return %s %q", f.name, raw)
+		}
+		*f.v = b
+	}
+
+	return ret, nil
+}
+
+// GetMerchantIDByTrackingID returns the merchant ID PayPal assigned to the seller onboarded with trackingID,
+// the tracking ID passed in the partner referral. partnerID is the payer ID of the partner account
+// Endpoint: GET /v1/customer/partners/ID/merchant-integrations?tracking_id=TRACKING_ID
+func (c *Client) GetMerchantIDByTrackingID(partnerID, trackingID string) (*MerchantIntegrationLookup, error) {
+	resp := &MerchantIntegrationLookup{}
+
+	if partnerID == "" || trackingID == "" {
+		return resp, fmt.Errorf("paypal: partner ID and tracking ID are required to look up a seller")
+	}
+
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s%s", c.APIBase, "/v1/customer/partners/", url.PathEscape(partnerID), "/merchant-integrations"), nil)
+	if err != nil {
+		return resp, err
+	}
+
+	q := req.URL.Query()
+	q.Add("tracking_id", trackingID)
+	req.URL.RawQuery = q.Encode()
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// GetReferralData shows the data submitted with a partner referral, partnerReferralID is the last path segment
+// of the `self` link returned when the referral was created
+// Endpoint: GET /v2/customer/partner-referrals/ID
+func (c *Client) GetReferralData(partnerReferralID string) (*ReferralData, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v2/customer/partner-referrals/", url.PathEscape(partnerReferralID)), nil)
+	resp := &ReferralData{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
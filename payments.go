@@ -0,0 +1,137 @@
+package paypal
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Possible values for `sort_by` in ListPaymentsRequest
+const (
+	PaymentSortByCreateTime string = "create_time"
+	PaymentSortByUpdateTime string = "update_time"
+)
+
+type (
+	// Payment represents a payment of the v1 Payments API, e.g. the parent payment of a sale
+	Payment struct {
+		ID            string                `json:"id"`
+		Intent        string                `json:"intent,omitempty"`
+		State         string                `json:"state,omitempty"` //Read only
+		Payer         *Payer                `json:"payer,omitempty"`
+		Transactions  []*PaymentTransaction `json:"transactions,omitempty"`
+		Cart          string                `json:"cart,omitempty"`           //Read only
+		FailureReason string                `json:"failure_reason,omitempty"` //Read only
+		CreateTime    *JSONTime             `json:"create_time,omitempty"`    //Read only
+		UpdateTime    *JSONTime             `json:"update_time,omitempty"`    //Read only
+		Links         []*Link               `json:"links,omitempty"`          //Read only
+	}
+
+	// PaymentTransaction represents a transaction of a v1 payment, RelatedResources holds the sales,
+	// authorizations, captures and refunds of the transaction
+	PaymentTransaction struct {
+		Amount           *Amount    `json:"amount,omitempty"`
+		Payee            *Payee     `json:"payee,omitempty"`
+		Description      string     `json:"description,omitempty"`
+		InvoiceNumber    string     `json:"invoice_number,omitempty"`
+		Custom           string     `json:"custom,omitempty"`
+		SoftDescriptor   string     `json:"soft_descriptor,omitempty"`
+		ItemList         *ItemList  `json:"item_list,omitempty"`
+		RelatedResources []*Related `json:"related_resources,omitempty"` //Read only
+	}
+
+	// RefundSaleRequest represents body parameters for refund sale, a nil Amount refunds the whole sale
+	RefundSaleRequest struct {
+		Amount        *Amount `json:"amount,omitempty"`
+		InvoiceNumber string  `json:"invoice_number,omitempty"`
+		Description   string  `json:"description,omitempty"`
+		Reason        string  `json:"reason,omitempty"`
+	}
+
+	// SaleRefund represents a refund of a v1 sale
+	SaleRefund struct {
+		ID            string    `json:"id"`
+		State         string    `json:"state,omitempty"`
+		Amount        *Amount   `json:"amount,omitempty"`
+		SaleID        string    `json:"sale_id,omitempty"`
+		ParentPayment string    `json:"parent_payment,omitempty"`
+		InvoiceNumber string    `json:"invoice_number,omitempty"`
+		Description   string    `json:"description,omitempty"`
+		Reason        string    `json:"reason,omitempty"`
+		CreateTime    *JSONTime `json:"create_time,omitempty"`
+		UpdateTime    *JSONTime `json:"update_time,omitempty"`
+		Links         []*Link   `json:"links,omitempty"`
+	}
+
+	// ListPaymentsRequest represents query params for list payments call,
+	// use StartID with the NextID of the previous page to page through the payments
+	ListPaymentsRequest struct {
+		Count      int       `json:"count"` //default: 10 max: 20
+		StartID    string    `json:"start_id"`
+		StartIndex int       `json:"start_index"`
+		StartTime  time.Time `json:"start_time"`
+		EndTime    time.Time `json:"end_time"`
+		SortBy     string    `json:"sort_by"`    //default: create_time
+		SortOrder  string    `json:"sort_order"` //default: desc
+	}
+
+	// ListPaymentsResponse represents the response of list payments
+	ListPaymentsResponse struct {
+		Payments []*Payment `json:"payments"`
+		Count    int        `json:"count"`
+		NextID   string     `json:"next_id,omitempty"`
+	}
+)
+
+// ListPayments lists the payments created by the REST API, payments completed elsewhere are not listed
+// Endpoint: GET /v1/payments/payment
+func (c *Client) ListPayments(params *ListPaymentsRequest) (*ListPaymentsResponse, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payment"), nil)
+	resp := &ListPaymentsResponse{}
+	if err != nil {
+		return resp, err
+	}
+
+	if params != nil {
+		q := req.URL.Query()
+		if params.Count > 0 {
+			q.Add("count", strconv.Itoa(params.Count))
+		}
+		if params.StartID != "" {
+			q.Add("start_id", params.StartID)
+		}
+		if params.StartIndex > 0 {
+			q.Add("start_index", strconv.Itoa(params.StartIndex))
+		}
+		if !params.StartTime.IsZero() {
+			q.Add("start_time", params.StartTime.UTC().Format(time.RFC3339))
+		}
+		if !params.EndTime.IsZero() {
+			q.Add("end_time", params.EndTime.UTC().Format(time.RFC3339))
+		}
+		if params.SortBy != "" {
+			q.Add("sort_by", params.SortBy)
+		}
+		if params.SortOrder != "" {
+			q.Add("sort_order", params.SortOrder)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// GetPayment shows details for a v1 payment, e.g. the parent payment of a sale
+// Endpoint: GET /v1/payments/payment/ID
+func (c *Client) GetPayment(paymentID string) (*Payment, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payments/payment/", url.PathEscape(paymentID)), nil)
+	resp := &Payment{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
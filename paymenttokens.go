@@ -0,0 +1,315 @@
+package paypal
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Possible values for `status` in SetupToken
+const (
+	SetupTokenStatusCreated             string = "CREATED"
+	SetupTokenStatusPayerActionRequired string = "PAYER_ACTION_REQUIRED"
+	SetupTokenStatusApproved            string = "APPROVED"
+	SetupTokenStatusVaulted             string = "VAULTED"
+	SetupTokenStatusTokenized           string = "TOKENIZED"
+)
+
+// Possible values for `usage_type` in VaultWalletRequest
+const (
+	VaultUsageTypeMerchant string = "MERCHANT"
+	VaultUsageTypePlatform string = "PLATFORM"
+)
+
+// Possible values for `usage_pattern` in VaultWalletRequest
+const (
+	VaultUsagePatternImmediate         string = "IMMEDIATE"
+	VaultUsagePatternDeferred          string = "DEFERRED"
+	VaultUsagePatternRecurringPrepaid  string = "RECURRING_PREPAID"
+	VaultUsagePatternRecurringPostpaid string = "RECURRING_POSTPAID"
+	VaultUsagePatternThresholdPrepaid  string = "THRESHOLD_PREPAID"
+	VaultUsagePatternThresholdPostpaid string = "THRESHOLD_POSTPAID"
+)
+
+// Possible values for `verification_method` in VaultCardRequest
+const (
+	VaultVerificationMethodSCAAlways       string = "SCA_ALWAYS"
+	VaultVerificationMethodSCAWhenRequired string = "SCA_WHEN_REQUIRED"
+)
+
+type (
+	// SetupTokenRequest represents body parameters needed to create a setup token, set one payment source
+	SetupTokenRequest struct {
+		Customer      *VaultCustomer           `json:"customer,omitempty"`
+		PaymentSource *SetupTokenPaymentSource `json:"payment_source"`
+	}
+
+	// VaultCustomer represents the customer payment methods are vaulted for. ID is generated by PayPal when
+	// omitted, MerchantCustomerID is the customer ID in the merchant's system
+	VaultCustomer struct {
+		ID                 string `json:"id,omitempty"`
+		MerchantCustomerID string `json:"merchant_customer_id,omitempty"`
+	}
+
+	// SetupTokenPaymentSource represents the payment method to vault
+	SetupTokenPaymentSource struct {
+		Card   *VaultCardRequest   `json:"card,omitempty"`
+		PayPal *VaultWalletRequest `json:"paypal,omitempty"`
+		Venmo  *VaultWalletRequest `json:"venmo,omitempty"`
+	}
+
+	// VaultCardRequest represents a card to vault, Expiry is formatted as YYYY-MM
+	VaultCardRequest struct {
+		Name               string                  `json:"name,omitempty"`
+		Number             string                  `json:"number,omitempty"`
+		Expiry             string                  `json:"expiry,omitempty"`
+		SecurityCode       string                  `json:"security_code,omitempty"`
+		BillingAddress     *AddressPortable        `json:"billing_address,omitempty"`
+		VerificationMethod string                  `json:"verification_method,omitempty"`
+		ExperienceContext  *VaultExperienceContext `json:"experience_context,omitempty"`
+	}
+
+	// VaultWalletRequest represents a PayPal or Venmo wallet to vault, the payer approves it at ApproveURL
+	VaultWalletRequest struct {
+		Description                 string                  `json:"description,omitempty"`
+		Shipping                    *ShippingDetail         `json:"shipping,omitempty"`
+		PermitMultiplePaymentTokens bool                    `json:"permit_multiple_payment_tokens,omitempty"`
+		UsageType                   string                  `json:"usage_type,omitempty"` //default: MERCHANT
+		UsagePattern                string                  `json:"usage_pattern,omitempty"`
+		CustomerType                string                  `json:"customer_type,omitempty"`
+		ExperienceContext           *VaultExperienceContext `json:"experience_context,omitempty"`
+	}
+
+	// VaultExperienceContext represents the experience of the payer approving the payment method
+	VaultExperienceContext struct {
+		BrandName          string             `json:"brand_name,omitempty"`
+		Locale             string             `json:"locale,omitempty"`
+		ReturnURL          string             `json:"return_url,omitempty"`
+		CancelURL          string             `json:"cancel_url,omitempty"`
+		ShippingPreference ShippingPreference `json:"shipping_preference,omitempty"`
+		VaultInstruction   string             `json:"vault_instruction,omitempty"`
+	}
+
+	// SetupToken represents a setup token, a payment method saved temporarily until the payer approves it
+	SetupToken struct {
+		ID            string              `json:"id"`
+		Customer      *VaultCustomer      `json:"customer,omitempty"`
+		Status        string              `json:"status,omitempty"`
+		PaymentSource *VaultPaymentSource `json:"payment_source,omitempty"`
+		Links         []*Link             `json:"links,omitempty"`
+	}
+
+	// PaymentToken represents a payment method vaulted for a customer
+	PaymentToken struct {
+		ID            string              `json:"id"`
+		Customer      *VaultCustomer      `json:"customer,omitempty"`
+		PaymentSource *VaultPaymentSource `json:"payment_source,omitempty"`
+		Links         []*Link             `json:"links,omitempty"`
+	}
+
+	// ListPaymentTokensRequest represents query params for list payment tokens call
+	ListPaymentTokensRequest struct {
+		CustomerID    string `json:"customer_id"`
+		PageSize      uint64 `json:"page_size"`      //default: 5 min:1 max:5
+		Page          uint64 `json:"page"`           //default: 1 min:1 max:10
+		TotalRequired bool   `json:"total_required"` //default: false
+	}
+
+	// ListPaymentTokensResponse represents the response of list payment tokens
+	ListPaymentTokensResponse struct {
+		Customer      *VaultCustomer  `json:"customer,omitempty"`
+		PaymentTokens []*PaymentToken `json:"payment_tokens"`
+		TotalItems    uint64          `json:"total_items,omitempty"`
+		TotalPages    uint64          `json:"total_pages,omitempty"`
+		Links         []*Link         `json:"links"` //Read only
+	}
+
+	// VaultPaymentSource represents a vaulted payment method as returned by PayPal,
+	// Apple Pay cards are vaulted from orders paid with Apple Pay
+	VaultPaymentSource struct {
+		Card     *VaultCard     `json:"card,omitempty"`
+		PayPal   *VaultWallet   `json:"paypal,omitempty"`
+		Venmo    *VaultWallet   `json:"venmo,omitempty"`
+		ApplePay *VaultApplePay `json:"apple_pay,omitempty"`
+	}
+
+	// VaultCard represents a vaulted card, Expiry is formatted as YYYY-MM
+	VaultCard struct {
+		Name           string           `json:"name,omitempty"`
+		Brand          string           `json:"brand,omitempty"`
+		LastDigits     string           `json:"last_digits,omitempty"`
+		Expiry         string           `json:"expiry,omitempty"`
+		BillingAddress *AddressPortable `json:"billing_address,omitempty"`
+	}
+
+	// VaultApplePay represents a vaulted Apple Pay card
+	VaultApplePay struct {
+		Card *VaultApplePayCard `json:"card,omitempty"`
+	}
+
+	// VaultApplePayCard represents the card behind an Apple Pay payment token, Type is CREDIT, DEBIT or PREPAID
+	VaultApplePayCard struct {
+		Name           string           `json:"name,omitempty"`
+		Type           string           `json:"type,omitempty"`
+		Brand          string           `json:"brand,omitempty"`
+		LastDigits     string           `json:"last_digits,omitempty"`
+		Expiry         string           `json:"expiry,omitempty"`
+		BillingAddress *AddressPortable `json:"billing_address,omitempty"`
+	}
+
+	// VaultWallet represents a vaulted PayPal or Venmo wallet, UserName is the Venmo user name
+	VaultWallet struct {
+		Description  string                `json:"description,omitempty"`
+		UsagePattern string                `json:"usage_pattern,omitempty"`
+		UsageType    string                `json:"usage_type,omitempty"`
+		CustomerType string                `json:"customer_type,omitempty"`
+		EmailAddress string                `json:"email_address,omitempty"`
+		PayerID      string                `json:"payer_id,omitempty"`
+		Name         *CreateOrderPayerName `json:"name,omitempty"`
+		UserName     string                `json:"user_name,omitempty"`
+		Shipping     *ShippingDetail       `json:"shipping,omitempty"`
+	}
+)
+
+// ApproveURL returns the URL the payer must be redirected to in order to approve the payment method,
+// or an empty string when no approval is needed
+func (t *SetupToken) ApproveURL() string {
+	if l := findLink(t.Links, LinkRelApprove); l != nil {
+		return l.Href
+	}
+	return ""
+}
+
+// Approved reports whether the payer approved the payment method, the setup token can then be converted into
+// a payment token
+func (t *SetupToken) Approved() bool {
+	return t.Status == SetupTokenStatusApproved
+}
+
+// CreateSetupToken saves a payment method temporarily, the first step of vaulting a payment method without
+// a purchase. Redirect the payer to ApproveURL() for wallets, then create a payment token from the setup token
+// Endpoint: POST /v3/vault/setup-tokens
+func (c *Client) CreateSetupToken(setupToken *SetupTokenRequest) (*SetupToken, error) {
+	resp := &SetupToken{}
+
+	if setupToken == nil || setupToken.PaymentSource == nil {
+		return resp, fmt.Errorf("paypal: a payment source is required to create a setup token")
+	}
+	if err := c.validate(setupToken); err != nil {
+		return resp, err
+	}
+
+	requestID, err := newRequestID()
+	if err != nil {
+		return resp, err
+	}
+
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v3/vault/setup-tokens"), setupToken)
+	if err != nil {
+		return resp, err
+	}
+	req.Header.Set("PayPal-Request-Id", requestID)
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// GetSetupToken retrieves a setup token, e.g. to check it was approved before creating a payment token from it
+// Endpoint: GET /v3/vault/setup-tokens/ID
+func (c *Client) GetSetupToken(setupTokenID string) (*SetupToken, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v3/vault/setup-tokens/", url.PathEscape(setupTokenID)), nil)
+	resp := &SetupToken{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// ListPaymentTokens lists the payment tokens vaulted for a customer
+// Endpoint: GET /v3/vault/payment-tokens?customer_id=ID
+func (c *Client) ListPaymentTokens(params *ListPaymentTokensRequest) (*ListPaymentTokensResponse, error) {
+	resp := &ListPaymentTokensResponse{}
+
+	if params == nil || params.CustomerID == "" {
+		return resp, fmt.Errorf("paypal: a customer ID is required to list payment tokens")
+	}
+
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v3/vault/payment-tokens"), nil)
+	if err != nil {
+		return resp, err
+	}
+
+	q := req.URL.Query()
+	q.Add("customer_id", params.CustomerID)
+	if params.PageSize > 0 {
+		q.Add("page_size", strconv.FormatUint(params.PageSize, 10))
+	}
+	if params.Page > 0 {
+		q.Add("page", strconv.FormatUint(params.Page, 10))
+	}
+	if params.TotalRequired {
+		q.Add("total_required", strconv.FormatBool(params.TotalRequired))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// ListAllPaymentTokens lists all the payment tokens vaulted for a customer, following the `next` links
+// until there are no more pages
+// Endpoint: GET /v3/vault/payment-tokens?customer_id=ID
+func (c *Client) ListAllPaymentTokens(customerID string) ([]*PaymentToken, error) {
+	page, err := c.ListPaymentTokens(&ListPaymentTokensRequest{CustomerID: customerID})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := page.PaymentTokens
+	visited := map[string]bool{}
+	for {
+		next := findLink(page.Links, LinkRelNext)
+		if next == nil || visited[next.Href] {
+			return tokens, nil
+		}
+		visited[next.Href] = true
+
+		req, err := c.NewRequest("GET", next.Href, nil)
+		if err != nil {
+			return tokens, err
+		}
+
+		page = &ListPaymentTokensResponse{}
+		if err = c.SendWithAuth(req, page); err != nil {
+			return tokens, err
+		}
+
+		tokens = append(tokens, page.PaymentTokens...)
+	}
+}
+
+// GetPaymentToken retrieves a payment token by ID
+// Endpoint: GET /v3/vault/payment-tokens/ID
+func (c *Client) GetPaymentToken(paymentTokenID string) (*PaymentToken, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v3/vault/payment-tokens/", url.PathEscape(paymentTokenID)), nil)
+	resp := &PaymentToken{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// DeletePaymentToken deletes a payment token, the payment method can no longer be charged
+// Endpoint: DELETE /v3/vault/payment-tokens/ID
+func (c *Client) DeletePaymentToken(paymentTokenID string) error {
+	req, err := c.NewRequest("DELETE", fmt.Sprintf("%s%s%s", c.APIBase, "/v3/vault/payment-tokens/", url.PathEscape(paymentTokenID)), nil)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
@@ -4,13 +4,19 @@ import (
 	"fmt"
 )
 
+// maxPayoutItems is the number of items PayPal accepts in a payout
+const maxPayoutItems = 15000
+
 // CreateSinglePayout submits a payout with an asynchronous API call, which immediately returns the results of a PayPal payment.
 // For email payout set RecipientType: "EMAIL" and receiver email into Receiver
 // Endpoint: POST /v1/payments/payouts
 func (c *Client) CreateSinglePayout(p Payout) (*PayoutResponse, error) {
-	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payouts"), p)
 	response := &PayoutResponse{}
+	if err := c.validate(&p); err != nil {
+		return response, err
+	}
 
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/payouts"), p)
 	if err != nil {
 		return response, err
 	}
@@ -75,3 +81,49 @@ func (c *Client) CancelPayoutItem(payoutItemID string) (*PayoutItemResponse, err
 
 	return response, nil
 }
+
+// Validate checks the payout against the constraints documented by PayPal
+func (p *Payout) Validate() error {
+	if p.SenderBatchHeader == nil {
+		return &ValidationError{Field: "sender_batch_header", Reason: "is required"}
+	}
+	err := firstError(
+		checkLength("sender_batch_header.sender_batch_id", p.SenderBatchHeader.SenderBatchID, 0, 256),
+		checkLength("sender_batch_header.email_subject", p.SenderBatchHeader.EmailSubject, 0, 255),
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(p.Items) == 0 || len(p.Items) > maxPayoutItems {
+		return &ValidationError{Field: "items", Reason: fmt.Sprintf("must have between 1 and %d items", maxPayoutItems)}
+	}
+	for i := range p.Items {
+		if err := p.Items[i].Validate(); err != nil {
+			return nestedError(fmt.Sprintf("items[%d]", i), err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the payout item against the constraints documented by PayPal
+func (i *PayoutItem) Validate() error {
+	switch i.RecipientType {
+	case "", PayoutRecipientTypeEmail, PayoutRecipientTypePhone, PayoutRecipientTypePayPalID:
+	default:
+		return &ValidationError{Field: "recipient_type", Reason: fmt.Sprintf("%q is not one of %s, %s and %s", i.RecipientType, PayoutRecipientTypeEmail, PayoutRecipientTypePhone, PayoutRecipientTypePayPalID)}
+	}
+
+	if i.Amount == nil {
+		return &ValidationError{Field: "amount", Reason: "is required"}
+	}
+	if err := checkCurrency("amount.currency", i.Amount.Currency); err != nil {
+		return err
+	}
+	return firstError(
+		checkLength("receiver", i.Receiver, 1, 127),
+		checkMoney("amount", &Money{Currency: i.Amount.Currency, Value: i.Amount.Value}),
+		checkLength("note", i.Note, 0, 4000),
+		checkLength("sender_item_id", i.SenderItemID, 0, 63),
+	)
+}
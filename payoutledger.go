@@ -0,0 +1,142 @@
+package paypal
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+type (
+	// PayoutLedgerStore records the sender_item_id of every payout item submitted to PayPal. Share it between the
+	// instances running payouts, e.g. with a unique index in a database
+	PayoutLedgerStore interface {
+		// Reserve records the sender item IDs not recorded yet as submitted in the batch and returns them,
+		// atomically so concurrent jobs never reserve the same ID
+		Reserve(senderBatchID string, senderItemIDs []string) (reserved []string, err error)
+		// Release forgets sender item IDs whose payout PayPal rejected, so they can be paid again
+		Release(senderItemIDs []string) error
+	}
+
+	// MemoryPayoutLedgerStore is a PayoutLedgerStore keeping the sender item IDs in memory, it does not survive
+	// the crash of the process and is only suitable for tests and single long running instances
+	MemoryPayoutLedgerStore struct {
+		mu      sync.Mutex
+		batches map[string]string
+	}
+
+	// PayoutLedger submits payouts through a PayoutLedgerStore so a batch job rerun after a crash or a timeout does
+	// not pay the same item twice: the items whose sender_item_id was submitted before are left out of the payout
+	PayoutLedger struct {
+		Client *Client
+		Store  PayoutLedgerStore
+	}
+)
+
+// NewMemoryPayoutLedgerStore returns an empty MemoryPayoutLedgerStore
+func NewMemoryPayoutLedgerStore() *MemoryPayoutLedgerStore {
+	return &MemoryPayoutLedgerStore{batches: map[string]string{}}
+}
+
+// Reserve records the sender item IDs not recorded yet as submitted in the batch and returns them
+func (s *MemoryPayoutLedgerStore) Reserve(senderBatchID string, senderItemIDs []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.batches == nil {
+		s.batches = map[string]string{}
+	}
+	var reserved []string
+	for _, id := range senderItemIDs {
+		if _, ok := s.batches[id]; ok {
+			continue
+		}
+		s.batches[id] = senderBatchID
+		reserved = append(reserved, id)
+	}
+	return reserved, nil
+}
+
+// Release forgets the sender item IDs
+func (s *MemoryPayoutLedgerStore) Release(senderItemIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range senderItemIDs {
+		delete(s.batches, id)
+	}
+	return nil
+}
+
+// NewPayoutLedger returns a PayoutLedger submitting payouts with c, store defaults to a MemoryPayoutLedgerStore
+func NewPayoutLedger(c *Client, store PayoutLedgerStore) *PayoutLedger {
+	if store == nil {
+		store = NewMemoryPayoutLedgerStore()
+	}
+	return &PayoutLedger{Client: c, Store: store}
+}
+
+// CreatePayout reserves the sender_item_id of the items of the payout and submits the items that were not
+// submitted before, returning the ones left out. Every item needs a sender_item_id. The response is nil when all
+// the items were left out. When PayPal rejects the payout the items are released, when the outcome is unknown,
+// e.g. after a timeout, they stay reserved: check the batch with GetPayout before releasing them
+// Endpoint: POST /v1/payments/payouts
+func (l *PayoutLedger) CreatePayout(p Payout) (*PayoutResponse, []PayoutItem, error) {
+	if p.SenderBatchHeader == nil || p.SenderBatchHeader.SenderBatchID == "" {
+		return nil, nil, fmt.Errorf("paypal: sender_batch_id is required to submit a payout through the ledger")
+	}
+
+	ids := make([]string, len(p.Items))
+	for i, item := range p.Items {
+		if item.SenderItemID == "" {
+			return nil, nil, fmt.Errorf("paypal: payout item %d has no sender_item_id", i)
+		}
+		ids[i] = item.SenderItemID
+	}
+
+	reserved, err := l.Store.Reserve(p.SenderBatchHeader.SenderBatchID, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	pending := make(map[string]bool, len(reserved))
+	for _, id := range reserved {
+		pending[id] = true
+	}
+
+	items, skipped := make([]PayoutItem, 0, len(reserved)), []PayoutItem(nil)
+	for _, item := range p.Items {
+		if pending[item.SenderItemID] {
+			items = append(items, item)
+			// a sender_item_id repeated in the batch is paid once
+			delete(pending, item.SenderItemID)
+			continue
+		}
+		skipped = append(skipped, item)
+	}
+	if len(items) == 0 {
+		return nil, skipped, nil
+	}
+
+	p.Items = items
+	resp, err := l.Client.CreateSinglePayout(p)
+	if err != nil {
+		if payoutRejected(err) {
+			if releaseErr := l.Store.Release(reserved); releaseErr != nil {
+				return resp, skipped, fmt.Errorf("paypal: %v, and releasing its items failed: %v", err, releaseErr)
+			}
+		}
+		return resp, skipped, err
+	}
+
+	return resp, skipped, nil
+}
+
+// payoutRejected reports whether the payout was certainly not made: it failed validation or PayPal refused it
+func payoutRejected(err error) bool {
+	switch err := err.(type) {
+	case *ValidationError:
+		return true
+	case *ErrorResponse:
+		return err.Response != nil && err.Response.StatusCode >= http.StatusBadRequest && err.Response.StatusCode < http.StatusInternalServerError
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package paypal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func payoutItem(senderItemID string) PayoutItem {
+	return PayoutItem{
+		RecipientType: PayoutRecipientTypeEmail,
+		Receiver:      senderItemID + "@example.com",
+		Amount:        &AmountPayout{Currency: "USD", Value: "9.87"},
+		SenderItemID:  senderItemID,
+	}
+}
+
+func TestPayoutLedger(t *testing.T) {
+	var submitted [][]string
+	status := http.StatusCreated
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := &Payout{}
+		json.NewDecoder(r.Body).Decode(p)
+		var ids []string
+		for _, item := range p.Items {
+			ids = append(ids, item.SenderItemID)
+		}
+		submitted = append(submitted, ids)
+
+		w.WriteHeader(status)
+		if status >= http.StatusBadRequest {
+			fmt.Fprint(w, `{"name":"INSUFFICIENT_FUNDS","message":"Sender does not have sufficient funds."}`)
+			return
+		}
+		fmt.Fprintf(w, `{"batch_header":{"payout_batch_id":"5UXD2E8A7EBQJ","batch_status":"PENDING","sender_batch_header":{"sender_batch_id":"%s"}}}`, p.SenderBatchHeader.SenderBatchID)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+	ledger := NewPayoutLedger(c, nil)
+
+	resp, skipped, err := ledger.CreatePayout(Payout{
+		SenderBatchHeader: &SenderBatchHeader{SenderBatchID: "run-1"},
+		Items:             []PayoutItem{payoutItem("A"), payoutItem("B"), payoutItem("A")},
+	})
+	if err != nil || resp == nil || len(submitted) != 1 || len(submitted[0]) != 2 || len(skipped) != 1 {
+		t.Fatalf("expected A and B to be paid once, got %v, %v skipped, %v", submitted, skipped, err)
+	}
+
+	// the job is rerun after a crash with one more item
+	_, skipped, err = ledger.CreatePayout(Payout{
+		SenderBatchHeader: &SenderBatchHeader{SenderBatchID: "run-2"},
+		Items:             []PayoutItem{payoutItem("A"), payoutItem("B"), payoutItem("C")},
+	})
+	if err != nil || len(submitted) != 2 || len(submitted[1]) != 1 || submitted[1][0] != "C" || len(skipped) != 2 {
+		t.Errorf("expected only C to be paid, got %v, %v skipped, %v", submitted, skipped, err)
+	}
+
+	resp, skipped, err = ledger.CreatePayout(Payout{
+		SenderBatchHeader: &SenderBatchHeader{SenderBatchID: "run-3"},
+		Items:             []PayoutItem{payoutItem("C")},
+	})
+	if err != nil || resp != nil || len(skipped) != 1 || len(submitted) != 2 {
+		t.Errorf("expected nothing to be submitted, got %+v, %v", resp, err)
+	}
+
+	// items of a rejected payout can be paid again
+	status = http.StatusUnprocessableEntity
+	if _, _, err := ledger.CreatePayout(Payout{SenderBatchHeader: &SenderBatchHeader{SenderBatchID: "run-4"}, Items: []PayoutItem{payoutItem("D")}}); err == nil {
+		t.Fatalf("expected the error of PayPal")
+	}
+	status = http.StatusCreated
+	if _, _, err := ledger.CreatePayout(Payout{SenderBatchHeader: &SenderBatchHeader{SenderBatchID: "run-5"}, Items: []PayoutItem{payoutItem("D")}}); err != nil || len(submitted) != 4 {
+		t.Errorf("expected D to be submitted again, got %v, %v", submitted, err)
+	}
+
+	// items of a payout whose outcome is unknown stay reserved
+	status = http.StatusInternalServerError
+	ledger.CreatePayout(Payout{SenderBatchHeader: &SenderBatchHeader{SenderBatchID: "run-6"}, Items: []PayoutItem{payoutItem("E")}})
+	status = http.StatusCreated
+	if _, skipped, _ := ledger.CreatePayout(Payout{SenderBatchHeader: &SenderBatchHeader{SenderBatchID: "run-7"}, Items: []PayoutItem{payoutItem("E")}}); len(skipped) != 1 {
+		t.Errorf("expected E to stay reserved after a server error, got %v", submitted)
+	}
+
+	if _, _, err := ledger.CreatePayout(Payout{SenderBatchHeader: &SenderBatchHeader{SenderBatchID: "run-8"}, Items: []PayoutItem{{Receiver: "f@example.com"}}}); err == nil {
+		t.Errorf("expected an error for an item without sender_item_id")
+	}
+}
@@ -0,0 +1,42 @@
+package paypaltest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/inplayer-org/paypal"
+)
+
+func TestClock(t *testing.T) {
+	server := NewServer("client-id", "secret")
+	defer server.Close()
+
+	clock := NewClock(time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC))
+	c, _ := server.Client()
+	c.SetClock(clock)
+
+	var tokens []string
+	c.SetTokenRefreshCallback(func(token *paypal.TokenResponse) {
+		tokens = append(tokens, token.Token)
+	})
+	if _, err := c.GetAccessToken(); err != nil {
+		t.Fatal(err)
+	}
+	if expiresIn := c.TokenExpiresIn(); expiresIn != 9*time.Hour {
+		t.Errorf("expected the token to expire in 9h on the stopped clock, got %v", expiresIn)
+	}
+
+	clock.Advance(8 * time.Hour)
+	if _, err := c.GetOrder("PPTEST00000000001"); err == nil {
+		t.Fatal("expected the unknown order to be missing")
+	}
+	if len(tokens) != 1 {
+		t.Errorf("expected the token to be used while it is valid, got %v", tokens)
+	}
+
+	clock.Advance(time.Hour)
+	c.GetOrder("PPTEST00000000001")
+	if len(tokens) != 2 || c.TokenExpiresIn() != 9*time.Hour {
+		t.Errorf("expected the expired token to be refreshed, got %v", tokens)
+	}
+}
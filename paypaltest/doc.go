@@ -0,0 +1,4 @@
+// Package paypaltest provides utilities for testing code built on the paypal package
+// without calling the PayPal sandbox: Server fakes the PayPal API in memory and WebhookSigner
+// signs webhook deliveries.
+package paypaltest
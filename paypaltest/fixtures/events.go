@@ -0,0 +1,293 @@
+package fixtures
+
+import "github.com/inplayer-org/paypal"
+
+// CheckoutOrderApproved returns a CHECKOUT.ORDER.APPROVED event
+func CheckoutOrderApproved(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventCheckoutOrderApproved, opts)
+}
+
+// CheckoutOrderCompleted returns a CHECKOUT.ORDER.COMPLETED event
+func CheckoutOrderCompleted(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventCheckoutOrderCompleted, opts)
+}
+
+// CheckoutOrderSaved returns a CHECKOUT.ORDER.SAVED event
+func CheckoutOrderSaved(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventCheckoutOrderSaved, opts)
+}
+
+// CheckoutOrderVoided returns a CHECKOUT.ORDER.VOIDED event
+func CheckoutOrderVoided(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventCheckoutOrderVoided, opts)
+}
+
+// PaymentAuthorizationCreated returns a PAYMENT.AUTHORIZATION.CREATED event
+func PaymentAuthorizationCreated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentAuthorizationCreated, opts)
+}
+
+// PaymentAuthorizationVoided returns a PAYMENT.AUTHORIZATION.VOIDED event
+func PaymentAuthorizationVoided(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentAuthorizationVoided, opts)
+}
+
+// PaymentCaptureCompleted returns a PAYMENT.CAPTURE.COMPLETED event
+func PaymentCaptureCompleted(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentCaptureCompleted, opts)
+}
+
+// PaymentCaptureDenied returns a PAYMENT.CAPTURE.DENIED event
+func PaymentCaptureDenied(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentCaptureDenied, opts)
+}
+
+// PaymentCapturePending returns a PAYMENT.CAPTURE.PENDING event
+func PaymentCapturePending(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentCapturePending, opts)
+}
+
+// PaymentCaptureDeclined returns a PAYMENT.CAPTURE.DECLINED event
+func PaymentCaptureDeclined(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentCaptureDeclined, opts)
+}
+
+// PaymentCaptureRefunded returns a PAYMENT.CAPTURE.REFUNDED event
+func PaymentCaptureRefunded(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentCaptureRefunded, opts)
+}
+
+// PaymentCaptureReversed returns a PAYMENT.CAPTURE.REVERSED event
+func PaymentCaptureReversed(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentCaptureReversed, opts)
+}
+
+// PaymentSaleCompleted returns a PAYMENT.SALE.COMPLETED event
+func PaymentSaleCompleted(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentSaleCompleted, opts)
+}
+
+// PaymentSaleDenied returns a PAYMENT.SALE.DENIED event
+func PaymentSaleDenied(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentSaleDenied, opts)
+}
+
+// PaymentSalePending returns a PAYMENT.SALE.PENDING event
+func PaymentSalePending(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentSalePending, opts)
+}
+
+// PaymentSaleRefunded returns a PAYMENT.SALE.REFUNDED event
+func PaymentSaleRefunded(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentSaleRefunded, opts)
+}
+
+// PaymentSaleReversed returns a PAYMENT.SALE.REVERSED event
+func PaymentSaleReversed(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentSaleReversed, opts)
+}
+
+// BillingPlanCreated returns a BILLING.PLAN.CREATED event
+func BillingPlanCreated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingPlanCreated, opts)
+}
+
+// BillingPlanUpdated returns a BILLING.PLAN.UPDATED event
+func BillingPlanUpdated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingPlanUpdated, opts)
+}
+
+// BillingPlanActivated returns a BILLING.PLAN.ACTIVATED event
+func BillingPlanActivated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingPlanActivated, opts)
+}
+
+// BillingPlanDeactivated returns a BILLING.PLAN.DEACTIVATED event
+func BillingPlanDeactivated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingPlanDeactivated, opts)
+}
+
+// BillingPlanPricingChangeActivated returns a BILLING.PLAN.PRICING-CHANGE.ACTIVATED event
+func BillingPlanPricingChangeActivated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingPlanPricingChangeActivated, opts)
+}
+
+// BillingSubscriptionCreated returns a BILLING.SUBSCRIPTION.CREATED event
+func BillingSubscriptionCreated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingSubscriptionCreated, opts)
+}
+
+// BillingSubscriptionActivated returns a BILLING.SUBSCRIPTION.ACTIVATED event
+func BillingSubscriptionActivated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingSubscriptionActivated, opts)
+}
+
+// BillingSubscriptionUpdated returns a BILLING.SUBSCRIPTION.UPDATED event
+func BillingSubscriptionUpdated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingSubscriptionUpdated, opts)
+}
+
+// BillingSubscriptionExpired returns a BILLING.SUBSCRIPTION.EXPIRED event
+func BillingSubscriptionExpired(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingSubscriptionExpired, opts)
+}
+
+// BillingSubscriptionSuspended returns a BILLING.SUBSCRIPTION.SUSPENDED event
+func BillingSubscriptionSuspended(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingSubscriptionSuspended, opts)
+}
+
+// BillingSubscriptionReActivated returns a BILLING.SUBSCRIPTION.RE-ACTIVATED event
+func BillingSubscriptionReActivated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingSubscriptionReActivated, opts)
+}
+
+// BillingSubscriptionCancelled returns a BILLING.SUBSCRIPTION.CANCELLED event
+func BillingSubscriptionCancelled(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingSubscriptionCancelled, opts)
+}
+
+// BillingSubscriptionPaymentFailed returns a BILLING.SUBSCRIPTION.PAYMENT.FAILED event
+func BillingSubscriptionPaymentFailed(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventBillingSubscriptionPaymentFailed, opts)
+}
+
+// CatalogProductCreated returns a CATALOG.PRODUCT.CREATED event
+func CatalogProductCreated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventCatalogProductCreated, opts)
+}
+
+// CatalogProductUpdated returns a CATALOG.PRODUCT.UPDATED event
+func CatalogProductUpdated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventCatalogProductUpdated, opts)
+}
+
+// CustomerDisputeCreated returns a CUSTOMER.DISPUTE.CREATED event
+func CustomerDisputeCreated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventCustomerDisputeCreated, opts)
+}
+
+// CustomerDisputeUpdated returns a CUSTOMER.DISPUTE.UPDATED event
+func CustomerDisputeUpdated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventCustomerDisputeUpdated, opts)
+}
+
+// CustomerDisputeResolved returns a CUSTOMER.DISPUTE.RESOLVED event
+func CustomerDisputeResolved(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventCustomerDisputeResolved, opts)
+}
+
+// PaymentPayoutsBatchDenied returns a PAYMENT.PAYOUTSBATCH.DENIED event
+func PaymentPayoutsBatchDenied(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsBatchDenied, opts)
+}
+
+// PaymentPayoutsBatchProcessing returns a PAYMENT.PAYOUTSBATCH.PROCESSING event
+func PaymentPayoutsBatchProcessing(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsBatchProcessing, opts)
+}
+
+// PaymentPayoutsBatchSuccess returns a PAYMENT.PAYOUTSBATCH.SUCCESS event
+func PaymentPayoutsBatchSuccess(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsBatchSuccess, opts)
+}
+
+// PaymentPayoutsItemBlocked returns a PAYMENT.PAYOUTS-ITEM.BLOCKED event
+func PaymentPayoutsItemBlocked(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsItemBlocked, opts)
+}
+
+// PaymentPayoutsItemCanceled returns a PAYMENT.PAYOUTS-ITEM.CANCELED event
+func PaymentPayoutsItemCanceled(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsItemCanceled, opts)
+}
+
+// PaymentPayoutsItemDenied returns a PAYMENT.PAYOUTS-ITEM.DENIED event
+func PaymentPayoutsItemDenied(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsItemDenied, opts)
+}
+
+// PaymentPayoutsItemFailed returns a PAYMENT.PAYOUTS-ITEM.FAILED event
+func PaymentPayoutsItemFailed(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsItemFailed, opts)
+}
+
+// PaymentPayoutsItemHeld returns a PAYMENT.PAYOUTS-ITEM.HELD event
+func PaymentPayoutsItemHeld(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsItemHeld, opts)
+}
+
+// PaymentPayoutsItemRefunded returns a PAYMENT.PAYOUTS-ITEM.REFUNDED event
+func PaymentPayoutsItemRefunded(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsItemRefunded, opts)
+}
+
+// PaymentPayoutsItemReturned returns a PAYMENT.PAYOUTS-ITEM.RETURNED event
+func PaymentPayoutsItemReturned(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsItemReturned, opts)
+}
+
+// PaymentPayoutsItemSucceeded returns a PAYMENT.PAYOUTS-ITEM.SUCCEEDED event
+func PaymentPayoutsItemSucceeded(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsItemSucceeded, opts)
+}
+
+// PaymentPayoutsItemUnclaimed returns a PAYMENT.PAYOUTS-ITEM.UNCLAIMED event
+func PaymentPayoutsItemUnclaimed(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventPaymentPayoutsItemUnclaimed, opts)
+}
+
+// VaultPaymentTokenCreated returns a VAULT.PAYMENT-TOKEN.CREATED event
+func VaultPaymentTokenCreated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventVaultPaymentTokenCreated, opts)
+}
+
+// VaultPaymentTokenDeleted returns a VAULT.PAYMENT-TOKEN.DELETED event
+func VaultPaymentTokenDeleted(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventVaultPaymentTokenDeleted, opts)
+}
+
+// VaultPaymentTokenDeletionInitiated returns a VAULT.PAYMENT-TOKEN.DELETION-INITIATED event
+func VaultPaymentTokenDeletionInitiated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventVaultPaymentTokenDeletionInitiated, opts)
+}
+
+// InvoicingInvoiceCancelled returns a INVOICING.INVOICE.CANCELLED event
+func InvoicingInvoiceCancelled(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventInvoicingInvoiceCancelled, opts)
+}
+
+// InvoicingInvoiceCreated returns a INVOICING.INVOICE.CREATED event
+func InvoicingInvoiceCreated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventInvoicingInvoiceCreated, opts)
+}
+
+// InvoicingInvoicePaid returns a INVOICING.INVOICE.PAID event
+func InvoicingInvoicePaid(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventInvoicingInvoicePaid, opts)
+}
+
+// InvoicingInvoiceRefunded returns a INVOICING.INVOICE.REFUNDED event
+func InvoicingInvoiceRefunded(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventInvoicingInvoiceRefunded, opts)
+}
+
+// InvoicingInvoiceScheduled returns a INVOICING.INVOICE.SCHEDULED event
+func InvoicingInvoiceScheduled(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventInvoicingInvoiceScheduled, opts)
+}
+
+// InvoicingInvoiceUpdated returns a INVOICING.INVOICE.UPDATED event
+func InvoicingInvoiceUpdated(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventInvoicingInvoiceUpdated, opts)
+}
+
+// MerchantOnboardingCompleted returns a MERCHANT.ONBOARDING.COMPLETED event
+func MerchantOnboardingCompleted(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventMerchantOnboardingCompleted, opts)
+}
+
+// MerchantPartnerConsentRevoked returns a MERCHANT.PARTNER-CONSENT.REVOKED event
+func MerchantPartnerConsentRevoked(opts ...Option) *paypal.Event {
+	return mustEvent(paypal.EventMerchantPartnerConsentRevoked, opts)
+}
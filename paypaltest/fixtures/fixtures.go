@@ -0,0 +1,226 @@
+// Package fixtures provides webhook events shaped like PayPal deliveries for every event type of the
+// paypal package, for tests of webhook handlers:
+//
+//	event := fixtures.PaymentCaptureCompleted(fixtures.WithInvoiceID("INV-1001"), fixtures.WithAmount("EUR", "25.00"))
+//
+// The resources decode into the structs of the paypal package without unknown fields, they change
+// with the structs and Version is bumped whenever they do
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/inplayer-org/paypal"
+)
+
+// Version is the version of the fixture set, bumped whenever a resource changes shape
+const Version = "1"
+
+// CreateTime is the `create_time` of the fixture events
+const CreateTime = "2020-01-15T10:00:00Z"
+
+type (
+	// Option changes a fixture event before it is returned
+	Option func(f *fixture)
+
+	// fixture is the event being built with its resource decoded into generic JSON values
+	fixture struct {
+		event    *paypal.Event
+		resource map[string]interface{}
+		replaced json.RawMessage
+	}
+)
+
+// WithID sets the ID of the event
+func WithID(id string) Option {
+	return func(f *fixture) {
+		f.event.ID = id
+	}
+}
+
+// WithCreateTime sets the `create_time` of the event
+func WithCreateTime(createTime string) Option {
+	return func(f *fixture) {
+		f.event.CreateTime = createTime
+	}
+}
+
+// WithResourceID sets the ID of the resource, e.g. the capture ID of a PAYMENT.CAPTURE.* event
+// or the `payout_item_id` of a PAYMENT.PAYOUTS-ITEM.* event
+func WithResourceID(id string) Option {
+	return func(f *fixture) {
+		key := "id"
+		switch f.event.ResourceType {
+		case paypal.ResourceTypeDispute:
+			key = "dispute_id"
+		case paypal.ResourceTypePayouts:
+			key = "batch_header.payout_batch_id"
+		case paypal.ResourceTypePayoutsItem:
+			key = "payout_item_id"
+		case paypal.ResourceTypeMerchantOnboarding, paypal.ResourceTypePartnerConsent:
+			key = "merchant_id"
+		}
+		setField(f.resource, key, id)
+	}
+}
+
+// WithAmount sets the amount of the resource in the shape of its resource type
+func WithAmount(currency, value string) Option {
+	return func(f *fixture) {
+		v2 := map[string]interface{}{"currency_code": currency, "value": value}
+		v1 := map[string]interface{}{"currency": currency, "value": value}
+		switch f.event.ResourceType {
+		case paypal.ResourceTypeCapture, paypal.ResourceTypeAuthorization, paypal.ResourceTypeInvoices:
+			setField(f.resource, "amount", v2)
+		case paypal.ResourceTypeRefund:
+			if f.event.ResourceVersion == paypal.ResourceVersion1 {
+				setField(f.resource, "amount", map[string]interface{}{"currency": currency, "total": value})
+			} else {
+				setField(f.resource, "amount", v2)
+			}
+		case paypal.ResourceTypeSale:
+			setField(f.resource, "amount", map[string]interface{}{"currency": currency, "total": value})
+		case paypal.ResourceTypeCheckoutOrder:
+			if units, ok := f.resource["purchase_units"].([]interface{}); ok && len(units) > 0 {
+				setField(units[0].(map[string]interface{}), "amount", v2)
+			}
+		case paypal.ResourceTypeDispute:
+			setField(f.resource, "dispute_amount", v2)
+		case paypal.ResourceTypePayouts:
+			setField(f.resource, "batch_header.amount", v1)
+		case paypal.ResourceTypePayoutsItem:
+			setField(f.resource, "payout_item.amount", v1)
+		case paypal.ResourceTypePlan:
+			if cycles, ok := f.resource["billing_cycles"].([]interface{}); ok && len(cycles) > 0 {
+				setField(cycles[0].(map[string]interface{}), "pricing_scheme.fixed_price", v2)
+			}
+		case paypal.ResourceTypeSubscription:
+			setField(f.resource, "billing_info.last_payment.amount", v2)
+		}
+	}
+}
+
+// WithInvoiceID sets the `invoice_id` of captures, refunds and authorizations and of the purchase unit of orders
+func WithInvoiceID(invoiceID string) Option {
+	return withPurchaseUnitField("invoice_id", invoiceID)
+}
+
+// WithCustomID sets the `custom_id` of captures, refunds and authorizations and of the purchase unit of orders,
+// and of subscriptions
+func WithCustomID(customID string) Option {
+	return withPurchaseUnitField("custom_id", customID)
+}
+
+func withPurchaseUnitField(key, value string) Option {
+	return func(f *fixture) {
+		if units, ok := f.resource["purchase_units"].([]interface{}); ok && len(units) > 0 {
+			setField(units[0].(map[string]interface{}), key, value)
+			return
+		}
+		setField(f.resource, key, value)
+	}
+}
+
+// WithField sets a field of the resource by its dotted path, e.g. "status" or "billing_info.failed_payments_count"
+func WithField(path string, value interface{}) Option {
+	return func(f *fixture) {
+		setField(f.resource, path, value)
+	}
+}
+
+// WithResource replaces the resource with v encoded to JSON, the other resource options are ignored
+func WithResource(v interface{}) Option {
+	return func(f *fixture) {
+		f.replaced, _ = json.Marshal(v)
+	}
+}
+
+// Event returns the fixture event of the event type changed by the options, it fails for event types
+// that are not in paypal.EventTypes
+func Event(eventType string, opts ...Option) (*paypal.Event, error) {
+	resourceType, ok := paypal.EventResourceType(eventType)
+	if !ok {
+		return nil, fmt.Errorf("fixtures: no fixture for event type %s", eventType)
+	}
+
+	raw := resources[resourceType]
+	if override, ok := eventResources[eventType]; ok {
+		raw = override
+	}
+	resource := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(raw), &resource); err != nil {
+		return nil, fmt.Errorf("fixtures: resource of %s: %v", eventType, err)
+	}
+	for path, value := range eventFields[eventType] {
+		setField(resource, path, value)
+	}
+
+	id := fmt.Sprintf("WH-%08X-FIXTURE", crc32.ChecksumIEEE([]byte(eventType)))
+	f := &fixture{
+		event: &paypal.Event{
+			ID:              id,
+			EventVersion:    "1.0",
+			CreateTime:      CreateTime,
+			ResourceType:    resourceType,
+			ResourceVersion: resourceVersion(eventType, resourceType),
+			EventType:       eventType,
+			Summary:         fmt.Sprintf("Webhook event %s", eventType),
+			Links: []*paypal.Link{
+				{Href: "https://api.paypal.com/v1/notifications/webhooks-events/" + id, Rel: paypal.LinkRelSelf, Method: "GET"},
+			},
+		},
+		resource: resource,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if f.replaced != nil {
+		f.event.Resource = f.replaced
+	} else {
+		data, err := json.Marshal(f.resource)
+		if err != nil {
+			return nil, err
+		}
+		f.event.Resource = data
+	}
+	return f.event, nil
+}
+
+// mustEvent returns the fixture of an event type known to have one
+func mustEvent(eventType string, opts []Option) *paypal.Event {
+	event, err := Event(eventType, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return event
+}
+
+// resourceVersion returns the `resource_version` PayPal sends for the event type
+func resourceVersion(eventType, resourceType string) string {
+	switch {
+	case eventType == paypal.EventPaymentSaleRefunded || eventType == paypal.EventPaymentSaleReversed:
+		return paypal.ResourceVersion1
+	case resourceType == paypal.ResourceTypeSale, resourceType == paypal.ResourceTypePlan,
+		resourceType == paypal.ResourceTypeSubscription, resourceType == paypal.ResourceTypeProduct:
+		return paypal.ResourceVersion1
+	}
+	return paypal.ResourceVersion2
+}
+
+// setField sets the value at the dotted path, creating the objects on the way
+func setField(m map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[key] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = value
+}
@@ -0,0 +1,105 @@
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/inplayer-org/paypal"
+)
+
+// structs are the structs the resources of each resource type decode into
+var structs = map[string]func() interface{}{
+	paypal.ResourceTypeCheckoutOrder:      func() interface{} { return &paypal.Order{} },
+	paypal.ResourceTypeAuthorization:      func() interface{} { return &paypal.Authorization{} },
+	paypal.ResourceTypeCapture:            func() interface{} { return &paypal.Capture{} },
+	paypal.ResourceTypeRefund:             func() interface{} { return &paypal.Refund{} },
+	paypal.ResourceTypeSale:               func() interface{} { return &paypal.Sale{} },
+	paypal.ResourceTypePlan:               func() interface{} { return &paypal.Plan{} },
+	paypal.ResourceTypeSubscription:       func() interface{} { return &paypal.Subscription{} },
+	paypal.ResourceTypeProduct:            func() interface{} { return &paypal.Product{} },
+	paypal.ResourceTypeDispute:            func() interface{} { return &paypal.Dispute{} },
+	paypal.ResourceTypePayouts:            func() interface{} { return &paypal.PayoutResponse{} },
+	paypal.ResourceTypePayoutsItem:        func() interface{} { return &paypal.PayoutItemResponse{} },
+	paypal.ResourceTypePaymentToken:       func() interface{} { return &paypal.PaymentToken{} },
+	paypal.ResourceTypeInvoices:           func() interface{} { return &paypal.Invoice{} },
+	paypal.ResourceTypeMerchantOnboarding: func() interface{} { return &paypal.MerchantOnboarding{} },
+	paypal.ResourceTypePartnerConsent:     func() interface{} { return &paypal.MerchantOnboarding{} },
+}
+
+func TestEveryEventTypeDecodesStrictly(t *testing.T) {
+	for _, eventType := range paypal.EventTypes() {
+		event, err := Event(eventType)
+		if err != nil {
+			t.Errorf("%s: %v", eventType, err)
+			continue
+		}
+		if event.EventType != eventType || event.ID == "" || event.CreateTime != CreateTime {
+			t.Errorf("%s: unexpected event %+v", eventType, event)
+		}
+
+		// v1 sale refunds only partially fit Refund
+		if event.ResourceType == paypal.ResourceTypeRefund && event.ResourceVersion == paypal.ResourceVersion1 {
+			continue
+		}
+		newStruct, ok := structs[event.ResourceType]
+		if !ok {
+			t.Errorf("%s: no struct for resource type %s", eventType, event.ResourceType)
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(event.Resource))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(newStruct()); err != nil {
+			t.Errorf("%s: resource doesn't match the struct: %v", eventType, err)
+		}
+	}
+
+	if _, err := Event("UNKNOWN.EVENT"); err == nil {
+		t.Error("expected an error for an unknown event type")
+	}
+}
+
+func TestEventTypeStatus(t *testing.T) {
+	capture, err := PaymentCaptureDenied().CaptureResource()
+	if err != nil || capture.Status != paypal.CaptureStatusDeclined {
+		t.Errorf("expected a declined capture, got %+v, %v", capture, err)
+	}
+
+	sub, err := BillingSubscriptionPaymentFailed().SubscriptionResource()
+	if err != nil || sub.BillingInfo == nil || sub.BillingInfo.FailedPaymentsCount != 1 {
+		t.Errorf("expected a failed payment, got %+v, %v", sub, err)
+	}
+
+	if event := PaymentSaleRefunded(); event.ResourceVersion != paypal.ResourceVersion1 {
+		t.Errorf("expected a v1 sale refund, got %s", event.ResourceVersion)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	capture, err := PaymentCaptureCompleted(WithResourceID("CAP-1"), WithAmount("EUR", "25.00"), WithInvoiceID("INV-7")).CaptureResource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capture.ID != "CAP-1" || capture.Amount.Currency != "EUR" || capture.Amount.Value != "25.00" || capture.InvoiceID != "INV-7" {
+		t.Errorf("unexpected capture %+v", capture)
+	}
+
+	event := CheckoutOrderApproved(WithAmount("EUR", "25.00"), WithCustomID("order-7"))
+	order, err := event.OrderResource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unit := order.PurchaseUnits[0]; unit.Amount.Value != "25.00" || !bytes.Contains(event.Resource, []byte(`"custom_id":"order-7"`)) {
+		t.Errorf("unexpected purchase unit %+v in %s", unit, event.Resource)
+	}
+
+	item, err := PaymentPayoutsItemSucceeded(WithResourceID("ITEM-1")).PayoutItemResource()
+	if err != nil || item.PayoutItemID != "ITEM-1" {
+		t.Errorf("unexpected payout item %+v, %v", item, err)
+	}
+
+	event = BillingSubscriptionActivated(WithID("WH-1"), WithResource(map[string]string{"id": "I-1"}))
+	if event.ID != "WH-1" || string(event.Resource) != `{"id":"I-1"}` {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
@@ -0,0 +1,91 @@
+package fixtures
+
+import "github.com/inplayer-org/paypal"
+
+// resources are the resources by `resource_type`
+var resources = map[string]string{
+	paypal.ResourceTypeCapture:            `{"id":"42311647XV020574X","status":"COMPLETED","amount":{"currency_code":"USD","value":"10.00"},"final_capture":true,"seller_protection":{"status":"ELIGIBLE","dispute_categories":["ITEM_NOT_RECEIVED","UNAUTHORIZED_TRANSACTION"]},"seller_receivable_breakdown":{"gross_amount":{"currency_code":"USD","value":"10.00"},"paypal_fee":{"currency_code":"USD","value":"0.59"},"net_amount":{"currency_code":"USD","value":"9.41"}},"invoice_id":"INV-1001","custom_id":"order-1001","create_time":"2020-01-15T10:00:00Z","update_time":"2020-01-15T10:00:00Z","links":[{"href":"https://api.paypal.com/v2/payments/captures/42311647XV020574X","rel":"self","method":"GET"}]}`,
+	paypal.ResourceTypeRefund:             `{"id":"1Y107995YT783435V","status":"COMPLETED","amount":{"currency_code":"USD","value":"10.00"},"note_to_payer":"Refund for order-1001","seller_payable_breakdown":{"gross_amount":{"currency_code":"USD","value":"10.00"},"paypal_fee":{"currency_code":"USD","value":"0.29"},"net_amount":{"currency_code":"USD","value":"9.71"},"total_refunded_amount":{"currency_code":"USD","value":"10.00"}},"invoice_id":"INV-1001","create_time":"2020-01-16T10:00:00Z","update_time":"2020-01-16T10:00:00Z","links":[{"href":"https://api.paypal.com/v2/payments/refunds/1Y107995YT783435V","rel":"self","method":"GET"}]}`,
+	paypal.ResourceTypeSale:               `{"id":"80021663DE681814L","state":"completed","amount":{"total":"10.00","currency":"USD","details":{"subtotal":"10.00"}},"payment_mode":"INSTANT_TRANSFER","protection_eligibility":"ELIGIBLE","transaction_fee":{"value":"0.59","currency":"USD"},"billing_agreement_id":"I-BW452GLLEP1G","create_time":"2020-01-15T10:00:00Z","update_time":"2020-01-15T10:00:00Z","links":[{"href":"https://api.paypal.com/v1/payments/sale/80021663DE681814L","rel":"self","method":"GET"}]}`,
+	paypal.ResourceTypeSubscription:       `{"id":"I-BW452GLLEP1G","plan_id":"P-5ML4271244454362WXNWU5NQ","status":"ACTIVE","start_time":"2020-01-15T10:00:00Z","quantity":"1","subscriber":{"name":{"given_name":"John","surname":"Doe"},"email_address":"customer@example.com","payer_id":"2J6QB8YJQSJRJ"},"billing_info":{"outstanding_balance":{"currency_code":"USD","value":"0.00"},"cycle_executions":[{"tenure_type":"REGULAR","sequence":1,"cycles_completed":1,"cycles_remaining":0,"total_cycles":0}],"next_billing_time":"2020-02-15T10:00:00Z","failed_payments_count":0},"create_time":"2020-01-15T09:59:00Z","update_time":"2020-01-15T10:00:00Z","links":[{"href":"https://api.paypal.com/v1/billing/subscriptions/I-BW452GLLEP1G","rel":"self","method":"GET"}]}`,
+	paypal.ResourceTypeCheckoutOrder:      `{"id":"5O190127TN364715T","status":"APPROVED","intent":"CAPTURE","purchase_units":[{"reference_id":"default","amount":{"currency_code":"USD","value":"10.00"}}],"create_time":"2020-01-15T09:58:00Z","links":[{"href":"https://api.paypal.com/v2/checkout/orders/5O190127TN364715T","rel":"self","method":"GET"}]}`,
+	paypal.ResourceTypeAuthorization:      `{"id":"0VF52814937998046","status":"CREATED","amount":{"currency_code":"USD","value":"10.00"},"seller_protection":{"status":"ELIGIBLE"},"expiration_time":"2020-02-13T10:00:00Z","create_time":"2020-01-15T10:00:00Z","update_time":"2020-01-15T10:00:00Z","links":[{"href":"https://api.paypal.com/v2/payments/authorizations/0VF52814937998046","rel":"self","method":"GET"}]}`,
+	paypal.ResourceTypeDispute:            `{"dispute_id":"PP-D-4012","create_time":"2020-01-20T10:00:00Z","update_time":"2020-01-20T10:00:00Z","disputed_transactions":[{"seller_transaction_id":"42311647XV020574X"}],"reason":"MERCHANDISE_OR_SERVICE_NOT_RECEIVED","status":"OPEN","dispute_amount":{"currency_code":"USD","value":"10.00"},"dispute_life_cycle_stage":"INQUIRY","dispute_channel":"INTERNAL","seller_response_due_date":"2020-02-10T10:00:00Z","links":[{"href":"https://api.paypal.com/v1/customer/disputes/PP-D-4012","rel":"self","method":"GET"}]}`,
+	paypal.ResourceTypePlan:               `{"id":"P-5ML4271244454362WXNWU5NQ","product_id":"PROD-XXCD1234QWER65782","name":"Monthly plan","status":"ACTIVE","billing_cycles":[{"frequency":{"interval_unit":"MONTH","interval_count":1},"tenure_type":"REGULAR","sequence":1,"total_cycles":0,"pricing_scheme":{"fixed_price":{"currency_code":"USD","value":"10.00"}}}],"payment_preferences":{"auto_bill_outstanding":true,"payment_failure_threshold":3},"create_time":"2020-01-01T10:00:00Z","update_time":"2020-01-01T10:00:00Z"}`,
+	paypal.ResourceTypeProduct:            `{"id":"PROD-XXCD1234QWER65782","name":"Video Streaming Service","description":"Video streaming service","type":"SERVICE","category":"SOFTWARE","create_time":"2020-01-01T10:00:00Z","update_time":"2020-01-01T10:00:00Z"}`,
+	paypal.ResourceTypePayouts:            `{"batch_header":{"payout_batch_id":"5UXD2E8A7EBQJ","batch_status":"SUCCESS","time_created":"2020-01-15T10:00:00Z","time_completed":"2020-01-15T10:01:00Z","sender_batch_header":{"sender_batch_id":"batch-1001"},"amount":{"currency":"USD","value":"10.00"},"fees":{"currency":"USD","value":"0.25"}}}`,
+	paypal.ResourceTypePayoutsItem:        `{"payout_item_id":"8AELMXH8UB2P8","transaction_id":"0C413693MN970190K","transaction_status":"SUCCESS","payout_batch_id":"5UXD2E8A7EBQJ","payout_item_fee":{"currency":"USD","value":"0.25"},"payout_item":{"recipient_type":"EMAIL","amount":{"currency":"USD","value":"10.00"},"receiver":"receiver@example.com","sender_item_id":"item-1001"},"time_processed":"2020-01-15T10:01:00Z"}`,
+	paypal.ResourceTypePaymentToken:       `{"id":"8kk8451t","customer":{"id":"customer_4029352050"},"payment_source":{"card":{"brand":"VISA","last_digits":"1111","expiry":"2025-12"}},"links":[{"href":"https://api.paypal.com/v3/vault/payment-tokens/8kk8451t","rel":"self","method":"GET"}]}`,
+	paypal.ResourceTypeInvoices:           `{"id":"INV2-Z56S-5LLA-Q52L-CPZ5","status":"PAID","detail":{"invoice_number":"1001","currency_code":"USD","invoice_date":"2020-01-15"},"amount":{"currency_code":"USD","value":"10.00"},"due_amount":{"currency_code":"USD","value":"0.00"}}`,
+	paypal.ResourceTypeMerchantOnboarding: `{"partner_client_id":"AXjjKqbIjfzSyCvm3M8unXSdNHYUQWjGOqbZWyxbxgMM_PRy0LjxBxh2rwhnqCIitVi9H56aTkTYG5bg","merchant_id":"C7CYMKZDG8D6E","links":[{"href":"https://api.paypal.com/v1/customer/partners/C7CYMKZDG8D6E/merchant-integrations/C7CYMKZDG8D6E","rel":"self","method":"GET"}]}`,
+	paypal.ResourceTypePartnerConsent:     `{"merchant_id":"C7CYMKZDG8D6E","tracking_id":"seller-1001","links":[{"href":"https://api.paypal.com/v1/customer/partners/C7CYMKZDG8D6E/merchant-integrations/C7CYMKZDG8D6E","rel":"self","method":"GET"}]}`,
+}
+
+// eventResources replace the resource of the resource type for event types carrying a different shape
+var eventResources = map[string]string{
+	paypal.EventPaymentSaleRefunded: saleRefund,
+	paypal.EventPaymentSaleReversed: saleRefund,
+}
+
+// saleRefund is the v1 refund of PAYMENT.SALE.REFUNDED and PAYMENT.SALE.REVERSED
+const saleRefund = `{"id":"4RR959492F879224U","state":"completed","amount":{"total":"10.00","currency":"USD"},"sale_id":"80021663DE681814L","parent_payment":"PAYID-LYGFXBA5TM86398J3713143N","invoice_number":"INV-1001","create_time":"2020-01-16T10:00:00Z","update_time":"2020-01-16T10:00:00Z","links":[{"href":"https://api.paypal.com/v1/payments/refund/4RR959492F879224U","rel":"self","method":"GET"},{"href":"https://api.paypal.com/v1/payments/sale/80021663DE681814L","rel":"sale","method":"GET"}]}`
+
+// eventFields are the fields of the resource set for the event type, mostly the status the event reports
+var eventFields = map[string]map[string]interface{}{
+	paypal.EventCheckoutOrderApproved:  {"status": "APPROVED"},
+	paypal.EventCheckoutOrderCompleted: {"status": "COMPLETED"},
+	paypal.EventCheckoutOrderSaved:     {"status": "SAVED"},
+	paypal.EventCheckoutOrderVoided:    {"status": "VOIDED"},
+
+	paypal.EventPaymentAuthorizationCreated: {"status": "CREATED"},
+	paypal.EventPaymentAuthorizationVoided:  {"status": "VOIDED"},
+
+	paypal.EventPaymentCaptureCompleted: {"status": "COMPLETED"},
+	paypal.EventPaymentCaptureDenied:    {"status": "DECLINED"},
+	paypal.EventPaymentCapturePending:   {"status": "PENDING", "status_details": map[string]interface{}{"reason": "PENDING_REVIEW"}},
+	paypal.EventPaymentCaptureDeclined:  {"status": "DECLINED"},
+	paypal.EventPaymentCaptureRefunded:  {"status": "COMPLETED"},
+	paypal.EventPaymentCaptureReversed:  {"status": "COMPLETED", "note_to_payer": "Reversal of a disputed payment"},
+
+	paypal.EventPaymentSaleCompleted: {"state": "completed"},
+	paypal.EventPaymentSaleDenied:    {"state": "denied"},
+	paypal.EventPaymentSalePending:   {"state": "pending", "reason_code": "PAYMENT_REVIEW"},
+
+	paypal.EventBillingPlanCreated:     {"status": paypal.PlanStatusActive},
+	paypal.EventBillingPlanActivated:   {"status": paypal.PlanStatusActive},
+	paypal.EventBillingPlanDeactivated: {"status": paypal.PlanStatusInactive},
+
+	paypal.EventBillingSubscriptionCreated:       {"status": paypal.SubscriptionStatusApprovalPending},
+	paypal.EventBillingSubscriptionActivated:     {"status": paypal.SubscriptionStatusActive},
+	paypal.EventBillingSubscriptionUpdated:       {"status": paypal.SubscriptionStatusActive},
+	paypal.EventBillingSubscriptionExpired:       {"status": paypal.SubscriptionStatusExpired},
+	paypal.EventBillingSubscriptionSuspended:     {"status": paypal.SubscriptionStatusSuspended},
+	paypal.EventBillingSubscriptionReActivated:   {"status": paypal.SubscriptionStatusActive},
+	paypal.EventBillingSubscriptionCancelled:     {"status": paypal.SubscriptionStatusCancelled},
+	paypal.EventBillingSubscriptionPaymentFailed: {"status": paypal.SubscriptionStatusActive, "billing_info.failed_payments_count": 1},
+
+	paypal.EventCustomerDisputeCreated:  {"status": "OPEN"},
+	paypal.EventCustomerDisputeUpdated:  {"status": "WAITING_FOR_SELLER_RESPONSE"},
+	paypal.EventCustomerDisputeResolved: {"status": "RESOLVED", "dispute_outcome": map[string]interface{}{"outcome_code": "RESOLVED_BUYER_FAVOUR"}},
+
+	paypal.EventPaymentPayoutsBatchDenied:     {"batch_header.batch_status": "DENIED"},
+	paypal.EventPaymentPayoutsBatchProcessing: {"batch_header.batch_status": "PROCESSING"},
+	paypal.EventPaymentPayoutsBatchSuccess:    {"batch_header.batch_status": "SUCCESS"},
+
+	paypal.EventPaymentPayoutsItemBlocked:   {"transaction_status": "BLOCKED"},
+	paypal.EventPaymentPayoutsItemCanceled:  {"transaction_status": "RETURNED"},
+	paypal.EventPaymentPayoutsItemDenied:    {"transaction_status": "FAILED"},
+	paypal.EventPaymentPayoutsItemFailed:    {"transaction_status": "FAILED"},
+	paypal.EventPaymentPayoutsItemHeld:      {"transaction_status": "ONHOLD"},
+	paypal.EventPaymentPayoutsItemRefunded:  {"transaction_status": "REFUNDED"},
+	paypal.EventPaymentPayoutsItemReturned:  {"transaction_status": "RETURNED"},
+	paypal.EventPaymentPayoutsItemSucceeded: {"transaction_status": "SUCCESS"},
+	paypal.EventPaymentPayoutsItemUnclaimed: {"transaction_status": "UNCLAIMED"},
+
+	paypal.EventInvoicingInvoiceCancelled: {"status": "CANCELLED"},
+	paypal.EventInvoicingInvoiceCreated:   {"status": "DRAFT"},
+	paypal.EventInvoicingInvoicePaid:      {"status": "PAID"},
+	paypal.EventInvoicingInvoiceRefunded:  {"status": "REFUNDED"},
+	paypal.EventInvoicingInvoiceScheduled: {"status": "SCHEDULED"},
+	paypal.EventInvoicingInvoiceUpdated:   {"status": "SENT"},
+}
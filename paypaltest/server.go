@@ -0,0 +1,939 @@
+package paypaltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inplayer-org/paypal"
+)
+
+type (
+	// Server is an in-memory fake of the PayPal REST API serving the OAuth token endpoint, checkout orders,
+	// authorizations, captures, refunds, payouts, plans and subscriptions. Resources move through the states
+	// PayPal moves them through, the steps the payer takes on the PayPal site are simulated with ApproveOrder
+	// and ApproveSubscription. Requests authenticate with the client credentials or an issued access token
+	Server struct {
+		URL      string
+		ClientID string
+		Secret   string
+
+		// OnEvent is called with every webhook event the server emits, after the state changed and before
+		// the response is sent, e.g. to deliver the event with WebhookSigner.Request
+		OnEvent func(event *paypal.Event)
+
+		server *httptest.Server
+
+		mu             sync.Mutex
+		lastID         int
+		tokens         map[string]bool
+		orders         map[string]*order
+		authorizations map[string]*authorization
+		captures       map[string]*capture
+		refunds        map[string]*paypal.Refund
+		payouts        map[string]*paypal.PayoutResponse
+		payoutItems    map[string]*paypal.PayoutItemResponse
+		plans          map[string]*paypal.Plan
+		subscriptions  map[string]*paypal.Subscription
+		events         []*paypal.Event
+		pending        []*paypal.Event
+	}
+
+	order struct {
+		paypal.Order
+		payer *paypal.PayerWithNameAndPhone
+		units []paypal.PurchaseUnitRequest
+	}
+
+	authorization struct {
+		paypal.Authorization
+		captured *big.Rat
+	}
+
+	capture struct {
+		paypal.Capture
+		refunded *big.Rat
+	}
+
+	route struct {
+		method  string
+		pattern string // * matches the ID of a resource
+		handle  func(s *Server, r *http.Request, id string) (int, interface{})
+	}
+)
+
+var routes = []route{
+	{"POST", "/v2/checkout/orders", (*Server).createOrder},
+	{"GET", "/v2/checkout/orders/*", (*Server).getOrder},
+	{"POST", "/v2/checkout/orders/*/authorize", (*Server).authorizeOrder},
+	{"POST", "/v2/checkout/orders/*/capture", (*Server).captureOrder},
+	{"GET", "/v2/payments/authorizations/*", (*Server).getAuthorization},
+	{"POST", "/v2/payments/authorizations/*/capture", (*Server).captureAuthorization},
+	{"POST", "/v2/payments/authorizations/*/void", (*Server).voidAuthorization},
+	{"GET", "/v2/payments/captures/*", (*Server).getCapture},
+	{"POST", "/v2/payments/captures/*/refund", (*Server).refundCapture},
+	{"GET", "/v2/payments/refunds/*", (*Server).getRefund},
+	{"POST", "/v1/payments/payouts", (*Server).createPayout},
+	{"GET", "/v1/payments/payouts/*", (*Server).getPayout},
+	{"GET", "/v1/payments/payouts-item/*", (*Server).getPayoutItem},
+	{"POST", "/v1/billing/plans", (*Server).createPlan},
+	{"GET", "/v1/billing/plans/*", (*Server).getPlan},
+	{"POST", "/v1/billing/subscriptions", (*Server).createSubscription},
+	{"GET", "/v1/billing/subscriptions/*", (*Server).getSubscription},
+	{"POST", "/v1/billing/subscriptions/*/activate", (*Server).activateSubscription},
+	{"POST", "/v1/billing/subscriptions/*/suspend", (*Server).suspendSubscription},
+	{"POST", "/v1/billing/subscriptions/*/cancel", (*Server).cancelSubscription},
+}
+
+// NewServer starts a fake PayPal API accepting the client credentials, call Close to stop it
+func NewServer(clientID, secret string) *Server {
+	s := &Server{
+		ClientID:       clientID,
+		Secret:         secret,
+		tokens:         map[string]bool{},
+		orders:         map[string]*order{},
+		authorizations: map[string]*authorization{},
+		captures:       map[string]*capture{},
+		refunds:        map[string]*paypal.Refund{},
+		payouts:        map[string]*paypal.PayoutResponse{},
+		payoutItems:    map[string]*paypal.PayoutItemResponse{},
+		plans:          map[string]*paypal.Plan{},
+		subscriptions:  map[string]*paypal.Subscription{},
+	}
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+	return s
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a paypal.Client calling the server with its client credentials
+func (s *Server) Client() (*paypal.Client, error) {
+	return paypal.NewClient(s.ClientID, s.Secret, s.URL)
+}
+
+// Events returns the webhook events emitted so far, oldest first
+func (s *Server) Events() []*paypal.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*paypal.Event(nil), s.events...)
+}
+
+// ApproveOrder simulates the payer approving the order on the PayPal site, the order moves from
+// CREATED to APPROVED and can be authorized or captured. A nil payer approves as a sample buyer
+func (s *Server) ApproveOrder(orderID string, payer *paypal.PayerWithNameAndPhone) error {
+	s.mu.Lock()
+	err := s.approveOrder(orderID, payer)
+	s.mu.Unlock()
+
+	s.flush()
+	return err
+}
+
+func (s *Server) approveOrder(orderID string, payer *paypal.PayerWithNameAndPhone) error {
+	o, ok := s.orders[orderID]
+	if !ok {
+		return fmt.Errorf("paypaltest: order %s not found", orderID)
+	}
+	if o.Status != paypal.OrderStatusCreated {
+		return fmt.Errorf("paypaltest: order %s is %s, only CREATED orders can be approved", orderID, o.Status)
+	}
+
+	if payer != nil {
+		o.payer = payer
+	}
+	if o.payer == nil {
+		o.payer = &paypal.PayerWithNameAndPhone{
+			Name:         &paypal.CreateOrderPayerName{GivenName: "John", Surname: "Doe"},
+			EmailAddress: "buyer@example.com",
+		}
+	}
+	if o.payer.PayerID == "" {
+		o.payer.PayerID = s.newID("")
+	}
+	o.Status = paypal.OrderStatusApproved
+	o.UpdateTime = now()
+
+	s.emit(paypal.EventCheckoutOrderApproved, o.Order)
+	return nil
+}
+
+// ApproveSubscription simulates the subscriber approving the subscription on the PayPal site,
+// the subscription moves from APPROVAL_PENDING to ACTIVE
+func (s *Server) ApproveSubscription(subscriptionID string) error {
+	s.mu.Lock()
+	err := s.approveSubscription(subscriptionID)
+	s.mu.Unlock()
+
+	s.flush()
+	return err
+}
+
+func (s *Server) approveSubscription(subscriptionID string) error {
+	sub, ok := s.subscriptions[subscriptionID]
+	if !ok {
+		return fmt.Errorf("paypaltest: subscription %s not found", subscriptionID)
+	}
+	if sub.Status != paypal.SubscriptionStatusApprovalPending {
+		return fmt.Errorf("paypaltest: subscription %s is %s, only APPROVAL_PENDING subscriptions can be approved", subscriptionID, sub.Status)
+	}
+
+	if sub.Subscriber == nil {
+		sub.Subscriber = &paypal.Subscriber{EmailAddress: "buyer@example.com"}
+	}
+	if sub.Subscriber.PayerID == "" {
+		sub.Subscriber.PayerID = s.newID("")
+	}
+	s.setSubscriptionStatus(sub, paypal.SubscriptionStatusActive, "")
+
+	s.emit(paypal.EventBillingSubscriptionActivated, sub)
+	return nil
+}
+
+// ServeHTTP routes the request to the fake of the endpoint, the webhook events emitted while handling
+// it are passed to OnEvent before the response is written
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		status int
+		body   interface{}
+	)
+
+	s.mu.Lock()
+	switch {
+	case r.URL.Path == "/v1/oauth2/token" && r.Method == "POST":
+		status, body = s.token(r)
+	case !s.authorized(r):
+		status, body = http.StatusUnauthorized, map[string]string{"error": "invalid_token", "error_description": "Token signature verification failed"}
+	default:
+		status, body = apiError(http.StatusNotFound, "RESOURCE_NOT_FOUND", "INVALID_RESOURCE_ID", "", fmt.Sprintf("%s %s is not supported by paypaltest", r.Method, r.URL.Path))
+		for _, rt := range routes {
+			if id, ok := match(rt.pattern, r.URL.Path); ok && rt.method == r.Method {
+				status, body = rt.handle(s, r, id)
+				break
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	s.flush()
+
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// match matches the path against the pattern, returning the ID matched by *
+func match(pattern, path string) (string, bool) {
+	want := strings.Split(pattern, "/")
+	got := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	if len(want) != len(got) {
+		return "", false
+	}
+
+	var id string
+	for i := range want {
+		switch {
+		case want[i] == "*" && got[i] != "":
+			id = got[i]
+		case want[i] != got[i]:
+			return "", false
+		}
+	}
+	return id, true
+}
+
+func (s *Server) token(r *http.Request) (int, interface{}) {
+	if clientID, secret, ok := r.BasicAuth(); !ok || clientID != s.ClientID || secret != s.Secret {
+		return http.StatusUnauthorized, map[string]string{"error": "invalid_client", "error_description": "Client Authentication failed"}
+	}
+
+	token := "A21AA" + s.newID("")
+	s.tokens[token] = true
+	return http.StatusOK, &paypal.TokenResponse{
+		Token:     token,
+		Type:      "Bearer",
+		ExpiresIn: paypal.ExpirationTime(32400),
+		AppID:     "APP-80W284485P519543T",
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if clientID, secret, ok := r.BasicAuth(); ok {
+		return clientID == s.ClientID && secret == s.Secret
+	}
+	auth := r.Header.Get("Authorization")
+	return strings.HasPrefix(auth, "Bearer ") && s.tokens[strings.TrimPrefix(auth, "Bearer ")]
+}
+
+func (s *Server) createOrder(r *http.Request, _ string) (int, interface{}) {
+	req := &struct {
+		Intent        paypal.OrderIntent           `json:"intent"`
+		Payer         *paypal.CreateOrderPayer     `json:"payer"`
+		PurchaseUnits []paypal.PurchaseUnitRequest `json:"purchase_units"`
+	}{}
+	if status, body := decode(r, req); status != 0 {
+		return status, body
+	}
+	if !req.Intent.IsValid() {
+		return invalidRequest("/intent", "INVALID_PARAMETER_VALUE", "intent must be CAPTURE or AUTHORIZE")
+	}
+	if len(req.PurchaseUnits) == 0 {
+		return invalidRequest("/purchase_units", "MISSING_REQUIRED_PARAMETER", "at least one purchase unit is required")
+	}
+	for i := range req.PurchaseUnits {
+		if err := req.PurchaseUnits[i].Validate(); err != nil {
+			return invalidRequest(fmt.Sprintf("/purchase_units/%d", i), "INVALID_PARAMETER_VALUE", err.Error())
+		}
+		if req.PurchaseUnits[i].ReferenceID == "" {
+			req.PurchaseUnits[i].ReferenceID = "default"
+		}
+	}
+
+	id := s.newID("")
+	o := &order{
+		Order: paypal.Order{
+			ID:         id,
+			Status:     paypal.OrderStatusCreated,
+			Intent:     req.Intent,
+			CreateTime: now(),
+			Links: []paypal.Link{
+				{Href: s.URL + "/v2/checkout/orders/" + id, Rel: paypal.LinkRelSelf, Method: "GET"},
+				{Href: s.URL + "/checkoutnow?token=" + id, Rel: paypal.LinkRelApprove, Method: "GET"},
+				{Href: s.URL + "/v2/checkout/orders/" + id + "/" + strings.ToLower(string(req.Intent)), Rel: strings.ToLower(string(req.Intent)), Method: "POST"},
+			},
+		},
+		units: req.PurchaseUnits,
+	}
+	for _, unit := range req.PurchaseUnits {
+		o.PurchaseUnits = append(o.PurchaseUnits, paypal.PurchaseUnit{ReferenceID: unit.ReferenceID, Amount: unit.Amount})
+	}
+	if req.Payer != nil {
+		o.payer = &paypal.PayerWithNameAndPhone{Name: req.Payer.Name, EmailAddress: req.Payer.EmailAddress, Phone: req.Payer.Phone, PayerID: req.Payer.PayerID}
+	}
+	s.orders[id] = o
+
+	return http.StatusCreated, o.Order
+}
+
+func (s *Server) getOrder(_ *http.Request, id string) (int, interface{}) {
+	o, ok := s.orders[id]
+	if !ok {
+		return notFound("order", id)
+	}
+	return http.StatusOK, o.Order
+}
+
+// approvedOrder returns the order when it is approved for the intent
+func (s *Server) approvedOrder(id string, intent paypal.OrderIntent) (*order, int, interface{}) {
+	o, ok := s.orders[id]
+	if !ok {
+		status, body := notFound("order", id)
+		return nil, status, body
+	}
+	if o.Intent != intent {
+		status, body := unprocessable("ACTION_DOES_NOT_MATCH_INTENT", fmt.Sprintf("the order intent is %s", o.Intent))
+		return nil, status, body
+	}
+	switch o.Status {
+	case paypal.OrderStatusApproved:
+		return o, 0, nil
+	case paypal.OrderStatusCompleted:
+		status, body := unprocessable("ORDER_ALREADY_CAPTURED", "the order has already been captured or authorized")
+		return nil, status, body
+	default:
+		status, body := unprocessable("ORDER_NOT_APPROVED", "the payer has not yet approved the order")
+		return nil, status, body
+	}
+}
+
+func (s *Server) authorizeOrder(_ *http.Request, id string) (int, interface{}) {
+	o, status, body := s.approvedOrder(id, paypal.OrderIntentAuthorize)
+	if o == nil {
+		return status, body
+	}
+
+	var first *authorization
+	for _, unit := range o.units {
+		authID := s.newID("")
+		auth := &authorization{
+			Authorization: paypal.Authorization{
+				ID:               authID,
+				CustomID:         unit.CustomID,
+				InvoiceID:        unit.InvoiceID,
+				Status:           "CREATED",
+				Amount:           &paypal.PurchaseUnitAmount{Currency: unit.Amount.Currency, Value: unit.Amount.Value},
+				SellerProtection: &paypal.SellerProtection{Status: "ELIGIBLE"},
+				CreateTime:       now(),
+				UpdateTime:       now(),
+				ExpirationTime:   jsonTime(time.Now().Add(29 * 24 * time.Hour)),
+				Links: []paypal.Link{
+					{Href: s.URL + "/v2/payments/authorizations/" + authID, Rel: paypal.LinkRelSelf, Method: "GET"},
+					{Href: s.URL + "/v2/payments/authorizations/" + authID + "/capture", Rel: "capture", Method: "POST"},
+					{Href: s.URL + "/v2/payments/authorizations/" + authID + "/void", Rel: "void", Method: "POST"},
+				},
+			},
+			captured: new(big.Rat),
+		}
+		s.authorizations[authID] = auth
+		s.emit(paypal.EventPaymentAuthorizationCreated, auth.Authorization)
+		if first == nil {
+			first = auth
+		}
+	}
+	o.Status = paypal.OrderStatusCompleted
+	o.UpdateTime = now()
+
+	return http.StatusCreated, first.Authorization
+}
+
+func (s *Server) captureOrder(_ *http.Request, id string) (int, interface{}) {
+	o, status, body := s.approvedOrder(id, paypal.OrderIntentCapture)
+	if o == nil {
+		return status, body
+	}
+
+	resp := &paypal.CaptureOrderResponse{ID: o.ID, Status: paypal.OrderStatusCompleted, Payer: o.payer}
+	for _, unit := range o.units {
+		c := s.newCapture(&paypal.Money{Currency: unit.Amount.Currency, Value: unit.Amount.Value}, unit.InvoiceID, unit.CustomID, true)
+		resp.PurchaseUnits = append(resp.PurchaseUnits, paypal.CapturedPurchaseUnit{
+			Payments: &paypal.CapturedPayments{
+				Captures: []paypal.CaptureAmount{{ID: c.ID, CustomID: c.CustomID, Amount: &paypal.PurchaseUnitAmount{Currency: c.Amount.Currency, Value: c.Amount.Value}}},
+			},
+		})
+	}
+	o.Status = paypal.OrderStatusCompleted
+	o.UpdateTime = now()
+
+	return http.StatusCreated, resp
+}
+
+// newCapture stores a completed capture of the amount and emits its webhook
+func (s *Server) newCapture(amount *paypal.Money, invoiceID, customID string, final bool) *capture {
+	id := s.newID("")
+	c := &capture{
+		Capture: paypal.Capture{
+			ID:                        id,
+			Status:                    paypal.CaptureStatusCompleted,
+			Amount:                    amount,
+			InvoiceID:                 invoiceID,
+			CustomID:                  customID,
+			FinalCapture:              final,
+			SellerProtection:          &paypal.SellerProtection{Status: "ELIGIBLE"},
+			SellerReceivableBreakdown: &paypal.SellerReceivableBreakdown{GrossAmount: amount, NetAmount: amount},
+			CreateTime:                time.Now().UTC().Format(time.RFC3339),
+			UpdateTime:                time.Now().UTC().Format(time.RFC3339),
+			Links: []*paypal.Link{
+				{Href: s.URL + "/v2/payments/captures/" + id, Rel: paypal.LinkRelSelf, Method: "GET"},
+				{Href: s.URL + "/v2/payments/captures/" + id + "/refund", Rel: "refund", Method: "POST"},
+			},
+		},
+		refunded: new(big.Rat),
+	}
+	s.captures[id] = c
+
+	s.emit(paypal.EventPaymentCaptureCompleted, c.Capture)
+	return c
+}
+
+func (s *Server) getAuthorization(_ *http.Request, id string) (int, interface{}) {
+	auth, ok := s.authorizations[id]
+	if !ok {
+		return notFound("authorization", id)
+	}
+	return http.StatusOK, auth.Authorization
+}
+
+func (s *Server) captureAuthorization(r *http.Request, id string) (int, interface{}) {
+	auth, ok := s.authorizations[id]
+	if !ok {
+		return notFound("authorization", id)
+	}
+	req := &paypal.PaymentCaptureRequest{}
+	if status, body := decode(r, req); status != 0 {
+		return status, body
+	}
+	switch auth.Status {
+	case "CREATED", "PARTIALLY_CAPTURED":
+	case "VOIDED":
+		return unprocessable("AUTHORIZATION_VOIDED", "the authorization has been voided")
+	default:
+		return unprocessable("AUTHORIZATION_ALREADY_CAPTURED", "the authorization has already been captured")
+	}
+
+	authorized, _ := new(big.Rat).SetString(auth.Amount.Value)
+	remaining := new(big.Rat).Sub(authorized, auth.captured)
+	amount := &paypal.Money{Currency: auth.Amount.Currency, Value: formatValue(remaining, auth.Amount.Value)}
+	if req.Amount != nil {
+		value, ok := new(big.Rat).SetString(req.Amount.Value)
+		switch {
+		case !ok || value.Sign() <= 0:
+			return invalidRequest("/amount/value", "INVALID_PARAMETER_VALUE", "the amount must be a positive number")
+		case req.Amount.Currency != auth.Amount.Currency:
+			return unprocessable("CURRENCY_MISMATCH", "the currency must match the authorization currency")
+		case value.Cmp(remaining) > 0:
+			return unprocessable("MAX_CAPTURE_AMOUNT_EXCEEDED", "the amount is more than the amount left to capture")
+		}
+		amount = req.Amount
+	}
+
+	value, _ := new(big.Rat).SetString(amount.Value)
+	auth.captured.Add(auth.captured, value)
+	final := req.FinalCapture || auth.captured.Cmp(authorized) == 0
+	if final {
+		auth.Status = "CAPTURED"
+	} else {
+		auth.Status = "PARTIALLY_CAPTURED"
+	}
+	auth.UpdateTime = now()
+
+	invoiceID := req.InvoiceID
+	if invoiceID == "" {
+		invoiceID = auth.InvoiceID
+	}
+	c := s.newCapture(amount, invoiceID, auth.CustomID, final)
+
+	return http.StatusCreated, &paypal.PaymentCaptureResponse{
+		ID:           c.ID,
+		Status:       c.Status,
+		Amount:       c.Amount,
+		InvoiceID:    c.InvoiceID,
+		FinalCapture: c.FinalCapture,
+		Links:        []paypal.Link{*c.Links[0], *c.Links[1]},
+	}
+}
+
+func (s *Server) voidAuthorization(_ *http.Request, id string) (int, interface{}) {
+	auth, ok := s.authorizations[id]
+	if !ok {
+		return notFound("authorization", id)
+	}
+	if auth.Status != "CREATED" {
+		return unprocessable("CANNOT_BE_VOIDED", fmt.Sprintf("the authorization is %s", auth.Status))
+	}
+
+	auth.Status = "VOIDED"
+	auth.UpdateTime = now()
+
+	s.emit(paypal.EventPaymentAuthorizationVoided, auth.Authorization)
+	return http.StatusOK, auth.Authorization
+}
+
+func (s *Server) getCapture(_ *http.Request, id string) (int, interface{}) {
+	c, ok := s.captures[id]
+	if !ok {
+		return notFound("capture", id)
+	}
+	return http.StatusOK, c.Capture
+}
+
+func (s *Server) refundCapture(r *http.Request, id string) (int, interface{}) {
+	c, ok := s.captures[id]
+	if !ok {
+		return notFound("capture", id)
+	}
+	req := &paypal.RefundRequest{}
+	if status, body := decode(r, req); status != 0 {
+		return status, body
+	}
+	switch c.Status {
+	case paypal.CaptureStatusCompleted, paypal.CaptureStatusPartiallyRefunded:
+	case paypal.CaptureStatusRefunded:
+		return unprocessable("CAPTURE_FULLY_REFUNDED", "the capture has already been fully refunded")
+	default:
+		return unprocessable("INVALID_RESOURCE_STATE", fmt.Sprintf("the capture is %s", c.Status))
+	}
+
+	captured, _ := new(big.Rat).SetString(c.Amount.Value)
+	remaining := new(big.Rat).Sub(captured, c.refunded)
+	amount := &paypal.Money{Currency: c.Amount.Currency, Value: formatValue(remaining, c.Amount.Value)}
+	if req.Amount != nil {
+		value, ok := new(big.Rat).SetString(req.Amount.Value)
+		switch {
+		case !ok || value.Sign() <= 0:
+			return invalidRequest("/amount/value", "INVALID_PARAMETER_VALUE", "the amount must be a positive number")
+		case req.Amount.Currency != c.Amount.Currency:
+			return unprocessable("REFUND_CURRENCY_MISMATCH", "the currency must match the capture currency")
+		case value.Cmp(remaining) > 0:
+			return unprocessable("REFUND_AMOUNT_EXCEEDED", "the amount is more than the amount left to refund")
+		}
+		amount = req.Amount
+	}
+
+	value, _ := new(big.Rat).SetString(amount.Value)
+	c.refunded.Add(c.refunded, value)
+	if c.refunded.Cmp(captured) == 0 {
+		c.Status = paypal.CaptureStatusRefunded
+	} else {
+		c.Status = paypal.CaptureStatusPartiallyRefunded
+	}
+	c.UpdateTime = time.Now().UTC().Format(time.RFC3339)
+
+	invoiceID := req.InvoiceID
+	if invoiceID == "" {
+		invoiceID = c.InvoiceID
+	}
+	refundID := s.newID("")
+	refund := &paypal.Refund{
+		ID:          refundID,
+		Status:      paypal.RefundStatusCompleted,
+		Amount:      amount,
+		InvoiceID:   invoiceID,
+		NoteToPayer: req.NoteToPayer,
+		SellerPayableBreakdown: &paypal.SellerPayableBreakdown{
+			GrossAmount:         amount,
+			NetAmount:           amount,
+			TotalRefundedAmount: &paypal.Money{Currency: c.Amount.Currency, Value: formatValue(c.refunded, c.Amount.Value)},
+		},
+		Links: []*paypal.Link{
+			{Href: s.URL + "/v2/payments/refunds/" + refundID, Rel: paypal.LinkRelSelf, Method: "GET"},
+			{Href: s.URL + "/v2/payments/captures/" + c.ID, Rel: "up", Method: "GET"},
+		},
+		CreateTime: now(),
+		UpdateTime: now(),
+	}
+	s.refunds[refundID] = refund
+
+	s.emit(paypal.EventPaymentCaptureRefunded, refund)
+	return http.StatusCreated, refund
+}
+
+func (s *Server) getRefund(_ *http.Request, id string) (int, interface{}) {
+	refund, ok := s.refunds[id]
+	if !ok {
+		return notFound("refund", id)
+	}
+	return http.StatusOK, refund
+}
+
+// createPayout completes the batch and its items right away, the response still has the batch PENDING
+// as PayPal processes batches asynchronously
+func (s *Server) createPayout(r *http.Request, _ string) (int, interface{}) {
+	req := &paypal.Payout{}
+	if status, body := decode(r, req); status != 0 {
+		return status, body
+	}
+	if status, body := validate(req); status != 0 {
+		return status, body
+	}
+	for _, batch := range s.payouts {
+		if req.SenderBatchHeader.SenderBatchID != "" && batch.BatchHeader.SenderBatchHeader.SenderBatchID == req.SenderBatchHeader.SenderBatchID {
+			return apiError(http.StatusBadRequest, "USER_BUSINESS_ERROR", "DUPLICATE_REQUEST_ID", "/sender_batch_header/sender_batch_id", "Batch with given sender_batch_id already exists")
+		}
+	}
+
+	batchID := s.newID("")
+	currency := req.Items[0].Amount.Currency
+	total := new(big.Rat)
+	batch := &paypal.PayoutResponse{
+		BatchHeader: &paypal.BatchHeader{
+			PayoutBatchID:     batchID,
+			BatchStatus:       paypal.PayoutBatchStatusSuccess,
+			TimeCreated:       now(),
+			TimeCompleted:     now(),
+			SenderBatchHeader: req.SenderBatchHeader,
+			Fees:              &paypal.AmountPayout{Currency: currency, Value: "0.00"},
+		},
+		Links: []paypal.Link{{Href: s.URL + "/v1/payments/payouts/" + batchID, Rel: paypal.LinkRelSelf, Method: "GET"}},
+	}
+	for i := range req.Items {
+		item := req.Items[i]
+		itemID := s.newID("")
+		value, _ := new(big.Rat).SetString(item.Amount.Value)
+		total.Add(total, value)
+
+		resp := &paypal.PayoutItemResponse{
+			PayoutItemID:      itemID,
+			TransactionID:     s.newID(""),
+			TransactionStatus: paypal.PayoutTransactionStatusSuccess,
+			PayoutBatchID:     batchID,
+			PayoutItemFee:     &paypal.AmountPayout{Currency: item.Amount.Currency, Value: "0.00"},
+			PayoutItem:        &item,
+			TimeProcessed:     now(),
+			Links:             []paypal.Link{{Href: s.URL + "/v1/payments/payouts-item/" + itemID, Rel: paypal.LinkRelSelf, Method: "GET"}},
+		}
+		s.payoutItems[itemID] = resp
+		batch.Items = append(batch.Items, *resp)
+	}
+	batch.BatchHeader.Amount = &paypal.AmountPayout{Currency: currency, Value: formatValue(total, req.Items[0].Amount.Value)}
+	s.payouts[batchID] = batch
+
+	s.emit(paypal.EventPaymentPayoutsBatchSuccess, &paypal.PayoutResponse{BatchHeader: batch.BatchHeader})
+	for _, item := range batch.Items {
+		s.emit(paypal.EventPaymentPayoutsItemSucceeded, item)
+	}
+
+	header := *batch.BatchHeader
+	header.BatchStatus = paypal.PayoutBatchStatusPending
+	header.TimeCompleted = nil
+	return http.StatusCreated, &paypal.PayoutResponse{BatchHeader: &header, Links: batch.Links}
+}
+
+func (s *Server) getPayout(_ *http.Request, id string) (int, interface{}) {
+	batch, ok := s.payouts[id]
+	if !ok {
+		return notFound("payout batch", id)
+	}
+	return http.StatusOK, batch
+}
+
+func (s *Server) getPayoutItem(_ *http.Request, id string) (int, interface{}) {
+	item, ok := s.payoutItems[id]
+	if !ok {
+		return notFound("payout item", id)
+	}
+	return http.StatusOK, item
+}
+
+func (s *Server) createPlan(r *http.Request, _ string) (int, interface{}) {
+	req := &paypal.CreatePlan{}
+	if status, body := decode(r, req); status != 0 {
+		return status, body
+	}
+	if status, body := validate(req); status != 0 {
+		return status, body
+	}
+
+	id := s.newID("P-")
+	plan := &paypal.Plan{
+		ID:                 id,
+		ProductID:          req.ProductID,
+		Name:               req.Name,
+		Status:             req.Status,
+		Description:        req.Description,
+		BillingCycles:      req.BillingCycles,
+		PaymentPreferences: req.PaymentPreferences,
+		Taxes:              req.Taxes,
+		QuantitySupported:  req.QuantitySupported,
+		CreateTime:         time.Now().UTC().Format(time.RFC3339),
+		UpdateTime:         time.Now().UTC().Format(time.RFC3339),
+		UsageType:          "LICENSED",
+		Links:              []*paypal.Link{{Href: s.URL + "/v1/billing/plans/" + id, Rel: paypal.LinkRelSelf, Method: "GET"}},
+	}
+	if plan.Status == "" {
+		plan.Status = paypal.PlanStatusActive
+	}
+	s.plans[id] = plan
+
+	s.emit(paypal.EventBillingPlanCreated, plan)
+	return http.StatusCreated, plan
+}
+
+func (s *Server) getPlan(_ *http.Request, id string) (int, interface{}) {
+	plan, ok := s.plans[id]
+	if !ok {
+		return notFound("plan", id)
+	}
+	return http.StatusOK, plan
+}
+
+func (s *Server) createSubscription(r *http.Request, _ string) (int, interface{}) {
+	req := &paypal.CreateSubscriptionRequest{}
+	if status, body := decode(r, req); status != 0 {
+		return status, body
+	}
+	if status, body := validate(req); status != 0 {
+		return status, body
+	}
+	plan, ok := s.plans[req.PlanID]
+	if !ok {
+		return notFound("plan", req.PlanID)
+	}
+	if plan.Status != paypal.PlanStatusActive {
+		return unprocessable("PLAN_STATUS_INVALID", fmt.Sprintf("the plan is %s", plan.Status))
+	}
+
+	id := s.newID("I-")
+	sub := &paypal.Subscription{
+		ID:             id,
+		Status:         paypal.SubscriptionStatusApprovalPending,
+		PlanID:         req.PlanID,
+		StartTime:      req.StartTime,
+		Quantity:       req.Quantity,
+		ShippingAmount: req.ShippingAmount,
+		CreateTime:     time.Now().UTC().Format(time.RFC3339),
+		UpdateTime:     time.Now().UTC().Format(time.RFC3339),
+		Links: []*paypal.Link{
+			{Href: s.URL + "/webapps/billing/subscriptions?ba_token=BA-" + id, Rel: paypal.LinkRelApprove, Method: "GET"},
+			{Href: s.URL + "/v1/billing/subscriptions/" + id, Rel: paypal.LinkRelSelf, Method: "GET"},
+		},
+	}
+	if sub.StartTime == "" {
+		sub.StartTime = sub.CreateTime
+	}
+	if sub.Quantity == "" {
+		sub.Quantity = "1"
+	}
+	if req.Subscriber != nil {
+		sub.Subscriber = &paypal.Subscriber{EmailAddress: req.Subscriber.EmailAddress, ShippingAddress: req.Subscriber.ShippingAddress}
+		if req.Subscriber.Name != nil {
+			sub.Subscriber.Name = &paypal.Name{GivenName: req.Subscriber.Name.GivenName, Surname: req.Subscriber.Name.Surname}
+		}
+	}
+	s.subscriptions[id] = sub
+
+	s.emit(paypal.EventBillingSubscriptionCreated, sub)
+	return http.StatusCreated, sub
+}
+
+func (s *Server) getSubscription(_ *http.Request, id string) (int, interface{}) {
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return notFound("subscription", id)
+	}
+	return http.StatusOK, sub
+}
+
+func (s *Server) activateSubscription(r *http.Request, id string) (int, interface{}) {
+	return s.changeSubscriptionStatus(r, id, paypal.SubscriptionStatusActive, paypal.EventBillingSubscriptionActivated, paypal.SubscriptionStatusSuspended)
+}
+
+func (s *Server) suspendSubscription(r *http.Request, id string) (int, interface{}) {
+	return s.changeSubscriptionStatus(r, id, paypal.SubscriptionStatusSuspended, paypal.EventBillingSubscriptionSuspended, paypal.SubscriptionStatusActive)
+}
+
+func (s *Server) cancelSubscription(r *http.Request, id string) (int, interface{}) {
+	return s.changeSubscriptionStatus(r, id, paypal.SubscriptionStatusCancelled, paypal.EventBillingSubscriptionCancelled, paypal.SubscriptionStatusActive, paypal.SubscriptionStatusSuspended)
+}
+
+// changeSubscriptionStatus moves a subscription in one of the from statuses to status
+func (s *Server) changeSubscriptionStatus(r *http.Request, id, status, eventType string, from ...string) (int, interface{}) {
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return notFound("subscription", id)
+	}
+	req := &paypal.UpdateSubscriptionStatusRequest{}
+	if code, body := decode(r, req); code != 0 {
+		return code, body
+	}
+
+	allowed := false
+	for _, f := range from {
+		allowed = allowed || sub.Status == f
+	}
+	if !allowed {
+		return unprocessable("SUBSCRIPTION_STATUS_INVALID", fmt.Sprintf("the subscription is %s", sub.Status))
+	}
+	s.setSubscriptionStatus(sub, status, req.Reason)
+
+	s.emit(eventType, sub)
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) setSubscriptionStatus(sub *paypal.Subscription, status, note string) {
+	sub.Status = status
+	sub.StatusChangeNote = note
+	sub.StatusUpdateTime = time.Now().UTC().Format(time.RFC3339)
+	sub.UpdateTime = sub.StatusUpdateTime
+}
+
+// emit queues the webhook event for the resource, the resource is encoded right away so later changes
+// to it don't show in the event
+func (s *Server) emit(eventType string, resource interface{}) {
+	event, err := newEvent(eventType, resource)
+	if err != nil {
+		panic(fmt.Sprintf("paypaltest: encoding the %s resource: %v", eventType, err))
+	}
+	s.events = append(s.events, event)
+	s.pending = append(s.pending, event)
+}
+
+// flush passes the queued events to OnEvent, it is called without holding the lock so OnEvent can call the server
+func (s *Server) flush() {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if s.OnEvent == nil {
+		return
+	}
+	for _, event := range pending {
+		s.OnEvent(event)
+	}
+}
+
+// newID returns a new resource ID in the format of PayPal IDs
+func (s *Server) newID(prefix string) string {
+	s.lastID++
+	return fmt.Sprintf("%sPPTEST%011d", prefix, s.lastID)
+}
+
+// decode decodes the JSON body of the request into v, an empty body leaves v as is
+func decode(r *http.Request, v interface{}) (int, interface{}) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return invalidRequest("", "MALFORMED_REQUEST", err.Error())
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return invalidRequest("", "MALFORMED_REQUEST_JSON", err.Error())
+	}
+	return 0, nil
+}
+
+// validate rejects the request like PayPal when its Validate method fails
+func validate(v interface{ Validate() error }) (int, interface{}) {
+	err := v.Validate()
+	if err == nil {
+		return 0, nil
+	}
+	if verr, ok := err.(*paypal.ValidationError); ok {
+		return invalidRequest(verr.Field, "INVALID_PARAMETER_VALUE", verr.Reason)
+	}
+	return invalidRequest("", "INVALID_PARAMETER_VALUE", err.Error())
+}
+
+func apiError(status int, name, issue, field, description string) (int, interface{}) {
+	return status, &paypal.ErrorResponse{
+		Name:    name,
+		Message: description,
+		DebugID: "paypaltest",
+		Details: []paypal.ErrorResponseDetail{{Field: field, Issue: issue, Description: description}},
+	}
+}
+
+func invalidRequest(field, issue, description string) (int, interface{}) {
+	return apiError(http.StatusBadRequest, "INVALID_REQUEST", issue, field, description)
+}
+
+func unprocessable(issue, description string) (int, interface{}) {
+	return apiError(http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", issue, "", description)
+}
+
+func notFound(resource, id string) (int, interface{}) {
+	return apiError(http.StatusNotFound, "RESOURCE_NOT_FOUND", "INVALID_RESOURCE_ID", "", fmt.Sprintf("%s %s does not exist", resource, id))
+}
+
+// formatValue formats the amount with the decimal places of like, a value in the same currency
+func formatValue(amount *big.Rat, like string) string {
+	decimals := 0
+	if i := strings.IndexByte(like, '.'); i >= 0 {
+		decimals = len(like) - i - 1
+	}
+	return amount.FloatString(decimals)
+}
+
+func now() *paypal.JSONTime {
+	return jsonTime(time.Now())
+}
+
+func jsonTime(t time.Time) *paypal.JSONTime {
+	j := paypal.JSONTime(t.UTC().Truncate(time.Second))
+	return &j
+}
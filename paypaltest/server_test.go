@@ -0,0 +1,210 @@
+package paypaltest
+
+import (
+	"testing"
+
+	"github.com/inplayer-org/paypal"
+)
+
+func newTestServer(t *testing.T) (*Server, *paypal.Client) {
+	server := NewServer("client-id", "secret")
+	c, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetAccessToken(); err != nil {
+		t.Fatal(err)
+	}
+	return server, c
+}
+
+func TestServer_CaptureOrder(t *testing.T) {
+	server, c := newTestServer(t)
+	defer server.Close()
+
+	var events []string
+	server.OnEvent = func(event *paypal.Event) {
+		events = append(events, event.EventType)
+	}
+
+	order, err := c.CreateOrder(paypal.OrderIntentCapture, []paypal.PurchaseUnitRequest{
+		{InvoiceID: "INV-1001", Amount: &paypal.PurchaseUnitAmount{Currency: "USD", Value: "10.00"}},
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != paypal.OrderStatusCreated || order.PurchaseUnits[0].ReferenceID != "default" {
+		t.Fatalf("unexpected order %+v", order)
+	}
+
+	// Capturing before the payer approved is rejected like PayPal does
+	_, err = c.CaptureOrder(order.ID, paypal.CaptureOrderRequest{})
+	if errResp, ok := err.(*paypal.ErrorResponse); !ok || len(errResp.Details) != 1 || errResp.Details[0].Issue != "ORDER_NOT_APPROVED" {
+		t.Fatalf("expected ORDER_NOT_APPROVED, got %v", err)
+	}
+
+	if err := server.ApproveOrder(order.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	captured, err := c.CaptureOrder(order.ID, paypal.CaptureOrderRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if captured.Status != paypal.OrderStatusCompleted || captured.Payer == nil || captured.Payer.PayerID == "" {
+		t.Fatalf("unexpected capture response %+v", captured)
+	}
+	captureID := captured.PurchaseUnits[0].Payments.Captures[0].ID
+
+	refund, err := c.RefundCapturedPayment(captureID, &paypal.RefundRequest{Amount: &paypal.Money{Currency: "USD", Value: "4.00"}})
+	if err != nil || refund.Status != paypal.RefundStatusCompleted || refund.InvoiceID != "INV-1001" {
+		t.Fatalf("unexpected refund %+v, %v", refund, err)
+	}
+	if _, err := c.RefundCapturedPayment(captureID, &paypal.RefundRequest{Amount: &paypal.Money{Currency: "USD", Value: "6.01"}}); err == nil {
+		t.Errorf("expected refunding more than the captured amount to fail")
+	}
+	if refund, err = c.RefundCapturedPayment(captureID, nil); err != nil || refund.Amount.Value != "6.00" {
+		t.Fatalf("expected the remaining 6.00 to be refunded, got %+v, %v", refund, err)
+	}
+
+	capture, err := c.ShowCapturedPayment(captureID)
+	if err != nil || capture.Status != paypal.CaptureStatusRefunded {
+		t.Errorf("expected a refunded capture, got %+v, %v", capture, err)
+	}
+
+	expected := []string{paypal.EventCheckoutOrderApproved, paypal.EventPaymentCaptureCompleted, paypal.EventPaymentCaptureRefunded, paypal.EventPaymentCaptureRefunded}
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("expected event %d to be %s, got %s", i, expected[i], events[i])
+		}
+	}
+
+	event := server.Events()[1]
+	if resource, err := event.CaptureResource(); err != nil || resource.ID != captureID || resource.Status != paypal.CaptureStatusCompleted {
+		t.Errorf("expected the capture as it was when captured, got %+v, %v", resource, err)
+	}
+}
+
+func TestServer_AuthorizeOrder(t *testing.T) {
+	server, c := newTestServer(t)
+	defer server.Close()
+
+	order, err := c.CreateOrder(paypal.OrderIntentAuthorize, []paypal.PurchaseUnitRequest{
+		{Amount: &paypal.PurchaseUnitAmount{Currency: "EUR", Value: "25.00"}},
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.ApproveOrder(order.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CaptureOrder(order.ID, paypal.CaptureOrderRequest{}); err == nil {
+		t.Errorf("expected capturing an order with the AUTHORIZE intent to fail")
+	}
+
+	auth, err := c.AuthorizeOrder(order.ID, paypal.AuthorizeOrderRequest{})
+	if err != nil || auth.Status != "CREATED" {
+		t.Fatalf("unexpected authorization %+v, %v", auth, err)
+	}
+
+	capture, err := c.CaptureAuthorization(auth.ID, &paypal.PaymentCaptureRequest{Amount: &paypal.Money{Currency: "EUR", Value: "10.00"}})
+	if err != nil || capture.Status != paypal.CaptureStatusCompleted || capture.FinalCapture {
+		t.Fatalf("unexpected capture %+v, %v", capture, err)
+	}
+	if auth, err = c.GetAuthorization(auth.ID); err != nil || auth.Status != "PARTIALLY_CAPTURED" {
+		t.Errorf("expected a partially captured authorization, got %+v, %v", auth, err)
+	}
+	if _, err := c.VoidAuthorization(auth.ID); err == nil {
+		t.Errorf("expected voiding a partially captured authorization to fail")
+	}
+}
+
+func TestServer_Payout(t *testing.T) {
+	server, c := newTestServer(t)
+	defer server.Close()
+
+	payout := paypal.Payout{
+		SenderBatchHeader: &paypal.SenderBatchHeader{SenderBatchID: "batch-1001"},
+		Items: []paypal.PayoutItem{
+			{RecipientType: "EMAIL", Receiver: "one@example.com", Amount: &paypal.AmountPayout{Currency: "USD", Value: "1.50"}},
+			{RecipientType: "EMAIL", Receiver: "two@example.com", Amount: &paypal.AmountPayout{Currency: "USD", Value: "2.50"}},
+		},
+	}
+	created, err := c.CreateSinglePayout(payout)
+	if err != nil || created.BatchHeader.BatchStatus != paypal.PayoutBatchStatusPending {
+		t.Fatalf("unexpected payout %+v, %v", created, err)
+	}
+	if _, err := c.CreateSinglePayout(payout); err == nil {
+		t.Errorf("expected a second batch with the same sender_batch_id to fail")
+	}
+
+	batch, err := c.GetPayout(created.BatchHeader.PayoutBatchID)
+	if err != nil || batch.BatchHeader.BatchStatus != paypal.PayoutBatchStatusSuccess || batch.BatchHeader.Amount.Value != "4.00" || len(batch.Items) != 2 {
+		t.Fatalf("unexpected batch %+v, %v", batch, err)
+	}
+	item, err := c.GetPayoutItem(batch.Items[1].PayoutItemID)
+	if err != nil || item.PayoutItem.Receiver != "two@example.com" || item.TransactionStatus != paypal.PayoutTransactionStatusSuccess {
+		t.Errorf("unexpected payout item %+v, %v", item, err)
+	}
+	if events := server.Events(); len(events) != 3 || events[0].EventType != paypal.EventPaymentPayoutsBatchSuccess {
+		t.Errorf("expected a batch and two item events, got %d", len(events))
+	}
+}
+
+func TestServer_Subscription(t *testing.T) {
+	server, c := newTestServer(t)
+	defer server.Close()
+
+	plan, err := c.CreatePlan(&paypal.CreatePlan{
+		ProductID: "PROD-XXCD1234QWER65782",
+		Name:      "Monthly plan",
+		BillingCycles: []*paypal.BillingCycle{{
+			PricingScheme: &paypal.PricingScheme{FixedPrice: &paypal.Money{Currency: "USD", Value: "10.00"}},
+			Frequency:     &paypal.Frequency{IntervalUnit: paypal.IntervalUnitMonth, IntervalCount: 1},
+			TenureType:    paypal.TenureTypeRegular,
+			Sequence:      1,
+		}},
+		PaymentPreferences: &paypal.PaymentPreferences{AutoBillOutstanding: true},
+	})
+	if err != nil || plan.Status != paypal.PlanStatusActive {
+		t.Fatalf("unexpected plan %+v, %v", plan, err)
+	}
+
+	sub, err := c.CreateSubscription(&paypal.CreateSubscriptionRequest{PlanID: plan.ID})
+	if err != nil || sub.Status != paypal.SubscriptionStatusApprovalPending {
+		t.Fatalf("unexpected subscription %+v, %v", sub, err)
+	}
+	if err := c.SuspendSubscription(sub.ID, &paypal.UpdateSubscriptionStatusRequest{Reason: "paused"}); err == nil {
+		t.Errorf("expected suspending a subscription pending approval to fail")
+	}
+
+	if err := server.ApproveSubscription(sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SuspendSubscription(sub.ID, &paypal.UpdateSubscriptionStatusRequest{Reason: "paused"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CancelSubscription(sub.ID, &paypal.UpdateSubscriptionStatusRequest{Reason: "moved away"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err = c.ShowSubscription(sub.ID, &paypal.ShowSubscriptionRequest{})
+	if err != nil || sub.Status != paypal.SubscriptionStatusCancelled || sub.StatusChangeNote != "moved away" || sub.Subscriber.PayerID == "" {
+		t.Errorf("unexpected subscription %+v, %v", sub, err)
+	}
+}
+
+func TestServer_Authentication(t *testing.T) {
+	server := NewServer("client-id", "secret")
+	defer server.Close()
+
+	c, _ := paypal.NewClient("client-id", "wrong", server.URL)
+	if _, err := c.GetAccessToken(); err == nil {
+		t.Errorf("expected wrong credentials to be rejected")
+	}
+	if _, err := c.GetOrder("PPTEST00000000001"); err == nil {
+		t.Errorf("expected a call with wrong credentials to be rejected")
+	}
+}
@@ -0,0 +1,249 @@
+package paypaltest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/inplayer-org/paypal"
+	"github.com/inplayer-org/paypal/paypaltest/fixtures"
+)
+
+// WebhookSigner produces webhook events signed like PayPal signs them, with a locally generated certificate.
+// The certificate is served over TLS by a test server, Verifier returns a paypal.WebhookVerifier trusting it
+type WebhookSigner struct {
+	WebhookID string
+
+	server  *httptest.Server
+	ca      *x509.Certificate
+	roots   *x509.CertPool
+	key     *rsa.PrivateKey
+	certURL string
+}
+
+// NewWebhookSigner generates a certificate authority and a signing certificate for the webhook,
+// call Close to stop the certificate server
+func NewWebhookSigner(webhookID string) (*WebhookSigner, error) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "paypaltest root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: paypal.DefaultWebhookCertNames[0]},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain bytes.Buffer
+	pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: leafDER})
+	pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(chain.Bytes())
+	}))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	return &WebhookSigner{
+		WebhookID: webhookID,
+		server:    server,
+		ca:        ca,
+		roots:     roots,
+		key:       key,
+		certURL:   server.URL + "/v1/notifications/certs/CERT-360caa42-fca2a594-paypaltest",
+	}, nil
+}
+
+// Close stops the certificate server
+func (s *WebhookSigner) Close() {
+	s.server.Close()
+}
+
+// Verifier returns a paypal.WebhookVerifier for the webhook trusting only the certificates of the signer
+func (s *WebhookSigner) Verifier() *paypal.WebhookVerifier {
+	return newVerifier(s.WebhookID, s.roots, s.server.Certificate(), s.server.URL)
+}
+
+// TrustBundle returns the PEM encoded root of the signing certificate and the certificate of the certificate
+// server, for processes verifying the deliveries of the signer with VerifierFromTrustBundle
+func (s *WebhookSigner) TrustBundle() []byte {
+	var bundle bytes.Buffer
+	pem.Encode(&bundle, &pem.Block{Type: "CERTIFICATE", Headers: map[string]string{"Purpose": "webhook-root"}, Bytes: s.ca.Raw})
+	pem.Encode(&bundle, &pem.Block{Type: "CERTIFICATE", Headers: map[string]string{"Purpose": "cert-server", "URL": s.server.URL}, Bytes: s.server.Certificate().Raw})
+	return bundle.Bytes()
+}
+
+// VerifierFromTrustBundle returns a paypal.WebhookVerifier for the webhook trusting only the signer
+// the bundle was returned by, the signer must be running to serve its certificates
+func VerifierFromTrustBundle(webhookID string, bundle []byte) (*paypal.WebhookVerifier, error) {
+	var (
+		roots     = x509.NewCertPool()
+		serverURL string
+		server    *x509.Certificate
+	)
+	for {
+		var block *pem.Block
+		if block, bundle = pem.Decode(bundle); block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch block.Headers["Purpose"] {
+		case "webhook-root":
+			roots.AddCert(cert)
+		case "cert-server":
+			server, serverURL = cert, block.Headers["URL"]
+		}
+	}
+	if server == nil || serverURL == "" {
+		return nil, fmt.Errorf("paypaltest: the trust bundle has no certificate server")
+	}
+
+	return newVerifier(webhookID, roots, server, serverURL), nil
+}
+
+// newVerifier returns a verifier trusting the roots for signatures, downloading the signing certificates
+// from the certificate server at serverURL
+func newVerifier(webhookID string, roots *x509.CertPool, server *x509.Certificate, serverURL string) *paypal.WebhookVerifier {
+	u, _ := url.Parse(serverURL)
+
+	serverRoots := x509.NewCertPool()
+	serverRoots.AddCert(server)
+
+	v := paypal.NewWebhookVerifier(webhookID)
+	v.HTTPClient = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: serverRoots}}}
+	v.Roots = roots
+	v.CertHosts = []string{u.Hostname()}
+	return v
+}
+
+// Event returns an event of the event type carrying resource, or the resource of its fixture in package fixtures
+// when it is nil.
+// The resource type is looked up with paypal.EventResourceType
+func (s *WebhookSigner) Event(eventType string, resource interface{}) (*paypal.Event, error) {
+	return newEvent(eventType, resource)
+}
+
+// newEvent builds the event for Event and for the webhooks emitted by Server from the fixture of the event type
+func newEvent(eventType string, resource interface{}) (*paypal.Event, error) {
+	var opts []fixtures.Option
+	if resource != nil {
+		opts = append(opts, fixtures.WithResource(resource))
+	}
+	event, err := fixtures.Event(eventType, opts...)
+	if err != nil {
+		resourceType, _ := paypal.EventResourceType(eventType)
+		raw := []byte(`{}`)
+		if resource != nil {
+			if raw, err = json.Marshal(resource); err != nil {
+				return nil, err
+			}
+		}
+		event = &paypal.Event{
+			EventVersion:    "1.0",
+			ResourceType:    resourceType,
+			ResourceVersion: paypal.ResourceVersion2,
+			EventType:       eventType,
+			Summary:         fmt.Sprintf("Webhook event %s", eventType),
+			Resource:        raw,
+		}
+	}
+
+	id := make([]byte, 8)
+	rand.Read(id)
+	event.ID = "WH-" + strings.ToUpper(hex.EncodeToString(id))
+	event.CreateTime = time.Now().UTC().Format(time.RFC3339)
+	event.Links = []*paypal.Link{
+		{Href: "https://api.paypal.com/v1/notifications/webhooks-events/" + event.ID, Rel: paypal.LinkRelSelf, Method: "GET"},
+	}
+	return event, nil
+}
+
+// Sign returns the PAYPAL-* headers PayPal sends with body for the webhook
+func (s *WebhookSigner) Sign(body []byte) (http.Header, error) {
+	id := make([]byte, 16)
+	rand.Read(id)
+	transmissionID := hex.EncodeToString(id)
+	transmissionTime := time.Now().UTC().Format(time.RFC3339)
+
+	message := fmt.Sprintf("%s|%s|%s|%d", transmissionID, transmissionTime, s.WebhookID, crc32.ChecksumIEEE(body))
+	hashed := sha256.Sum256([]byte(message))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(paypal.HeaderAuthAlgo, paypal.AuthAlgoSHA256WithRSA)
+	header.Set(paypal.HeaderCertURL, s.certURL)
+	header.Set(paypal.HeaderTransmissionID, transmissionID)
+	header.Set(paypal.HeaderTransmissionSig, base64.StdEncoding.EncodeToString(signature))
+	header.Set(paypal.HeaderTransmissionTime, transmissionTime)
+	return header, nil
+}
+
+// Request returns a signed webhook delivery of the event, ready to be served by a paypal.WebhookHandler
+func (s *WebhookSigner) Request(target string, event *paypal.Event) (*http.Request, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := s.Sign(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req := httptest.NewRequest("POST", target, bytes.NewReader(body))
+	req.Header = header
+	return req, nil
+}
@@ -0,0 +1,86 @@
+package paypaltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/inplayer-org/paypal"
+)
+
+func TestWebhookSigner(t *testing.T) {
+	signer, err := NewWebhookSigner("1JE4291016473214C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+
+	var captured *paypal.Capture
+	opts := &paypal.WebhookHandlerOptions{Verifier: signer.Verifier()}
+	opts.On(paypal.EventPaymentCaptureCompleted, func(r *http.Request, event *paypal.Event) error {
+		captured, err = event.CaptureResource()
+		return err
+	})
+	handler := paypal.WebhookHandler(nil, signer.WebhookID, opts)
+
+	event, err := signer.Event(paypal.EventPaymentCaptureCompleted, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := signer.Request("/webhook", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for signed event, got %d", rec.Code)
+	}
+	if captured == nil || captured.ID != "42311647XV020574X" || captured.SellerReceivableBreakdown == nil {
+		t.Errorf("expected the sample capture to be decoded, got %+v", captured)
+	}
+
+	// A delivery whose body was changed after signing is rejected
+	body, _ := json.Marshal(event)
+	header, err := signer.Sign(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest("POST", "/webhook", bytes.NewReader(bytes.Replace(body, []byte("10.00"), []byte("99.00"), -1)))
+	req.Header = header
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for tampered event, got %d", rec.Code)
+	}
+}
+
+func TestWebhookSigner_Event(t *testing.T) {
+	signer, err := NewWebhookSigner("1JE4291016473214C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+
+	for _, eventType := range paypal.EventTypes() {
+		event, err := signer.Event(eventType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resourceType, _ := paypal.EventResourceType(eventType); event.ResourceType != resourceType {
+			t.Errorf("expected resource type %s for %s, got %s", resourceType, eventType, event.ResourceType)
+		}
+		if string(event.Resource) == "{}" {
+			t.Errorf("expected a sample resource for %s", eventType)
+		}
+	}
+
+	event, _ := signer.Event(paypal.EventBillingSubscriptionCancelled, &paypal.Subscription{ID: "I-CUSTOM"})
+	subscription, err := event.SubscriptionResource()
+	if err != nil || subscription.ID != "I-CUSTOM" {
+		t.Errorf("expected the given resource, got %+v, %v", subscription, err)
+	}
+}
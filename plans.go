@@ -10,6 +10,13 @@ import (
 func (c *Client) CreatePlan(plan *CreatePlan) (*Plan, error) {
 	resp := &Plan{}
 
+	if plan == nil {
+		return nil, fmt.Errorf("paypal: a plan is required to create a plan")
+	}
+	if err := c.validate(plan); err != nil {
+		return nil, err
+	}
+
 	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/plans"), plan)
 	if err != nil {
 		return nil, err
@@ -55,7 +62,7 @@ func (c *Client) ShowPlan(planID string) (*Plan, error) {
 		return nil, err
 	}
 
-	if err = c.SendWithBasicAuth(req, resp); err != nil {
+	if err = c.sendCached(req, resp, c.SendWithBasicAuth); err != nil {
 		return nil, err
 	}
 
@@ -70,6 +77,7 @@ func (c *Client) ActivatePlan(planID string) error {
 		return err
 	}
 
+	defer c.invalidatePlanCache(planID)
 	return c.SendWithBasicAuth(req, nil)
 }
 
@@ -81,6 +89,7 @@ func (c *Client) DeactivatePlan(planID string) error {
 		return err
 	}
 
+	defer c.invalidatePlanCache(planID)
 	return c.SendWithBasicAuth(req, nil)
 }
 
@@ -104,16 +113,157 @@ func (c *Client) UpdatePlan(planID string, patchObject []*PatchObject) error {
 		return err
 	}
 
+	defer c.invalidatePlanCache(planID)
 	return c.SendWithBasicAuth(req, nil)
 }
 
 // UpdatePricing updates pricing for a plan
-// Endpoint: PATCH /v1/billing/plan/{plan_id}/update-pricing-schemas
+// Endpoint: POST /v1/billing/plans/{plan_id}/update-pricing-schemes
 func (c *Client) UpdatePricing(planID string,updatePricing UpdatePricingSchemasListRequest) error {
-	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/plan/"+planID+"/update-pricing-schemas"), updatePricing)
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/plans/"+planID+"/update-pricing-schemes"), updatePricing)
 	if err != nil {
 		return err
 	}
 
+	defer c.invalidatePlanCache(planID)
 	return c.SendWithBasicAuth(req, nil)
-}
\ No newline at end of file
+}
+
+// invalidatePlanCache removes the cached response of ShowPlan for the plan
+func (c *Client) invalidatePlanCache(planID string) {
+	c.invalidateCache(fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/plans/"+planID))
+}
+
+// maxIntervalCounts is the longest interval PayPal accepts for each interval unit
+var maxIntervalCounts = map[string]uint64{
+	IntervalUnitDay:   365,
+	IntervalUnitWeek:  52,
+	IntervalUnitMonth: 12,
+	IntervalUnitYear:  1,
+}
+
+// Validate checks the plan against the constraints documented by PayPal: at most two trial cycles running
+// before a single regular cycle, unique sequences and intervals within the limits of their unit
+func (p *CreatePlan) Validate() error {
+	err := firstError(
+		checkLength("product_id", p.ProductID, 6, 50),
+		checkLength("name", p.Name, 1, 127),
+		checkLength("description", p.Description, 0, 127),
+	)
+	if err != nil {
+		return err
+	}
+
+	switch p.Status {
+	case "", PlanStatusCreated, PlanStatusActive:
+	default:
+		return &ValidationError{Field: "status", Reason: fmt.Sprintf("%q is not one of %s and %s", p.Status, PlanStatusCreated, PlanStatusActive)}
+	}
+
+	if len(p.BillingCycles) == 0 || len(p.BillingCycles) > 12 {
+		return &ValidationError{Field: "billing_cycles", Reason: "must have between 1 and 12 billing cycles"}
+	}
+
+	sequences := map[uint64]bool{}
+	var trials, regulars int
+	var lastTrial, regular uint64
+	for i, cycle := range p.BillingCycles {
+		field := fmt.Sprintf("billing_cycles[%d]", i)
+		if cycle == nil {
+			return &ValidationError{Field: field, Reason: "is required"}
+		}
+		if err := cycle.Validate(); err != nil {
+			return nestedError(field, err)
+		}
+		if sequences[cycle.Sequence] {
+			return &ValidationError{Field: field + ".sequence", Reason: fmt.Sprintf("%d is used by another billing cycle", cycle.Sequence)}
+		}
+		sequences[cycle.Sequence] = true
+
+		if cycle.TenureType == TenureTypeTrial {
+			trials++
+			if cycle.Sequence > lastTrial {
+				lastTrial = cycle.Sequence
+			}
+			continue
+		}
+		regulars++
+		regular = cycle.Sequence
+	}
+
+	switch {
+	case trials > 2:
+		return &ValidationError{Field: "billing_cycles", Reason: "must have at most two trial billing cycles"}
+	case regulars != 1:
+		return &ValidationError{Field: "billing_cycles", Reason: "must have exactly one regular billing cycle"}
+	case lastTrial > regular:
+		return &ValidationError{Field: "billing_cycles", Reason: "trial billing cycles must run before the regular billing cycle"}
+	}
+
+	if p.PaymentPreferences != nil {
+		if err := nestedError("payment_preferences", p.PaymentPreferences.Validate()); err != nil {
+			return err
+		}
+	}
+	if p.Taxes != nil {
+		if _, err := parseDecimal(p.Taxes.Percentage, "percentage"); err != nil {
+			return &ValidationError{Field: "taxes.percentage", Reason: fmt.Sprintf("%q is not a valid percentage", p.Taxes.Percentage)}
+		}
+	}
+	return nil
+}
+
+// Validate checks the billing cycle against the constraints documented by PayPal,
+// a trial cycle must end while a regular cycle with no total cycles runs until the subscription is cancelled
+func (b *BillingCycle) Validate() error {
+	if !b.TenureType.IsValid() {
+		return &ValidationError{Field: "tenure_type", Reason: fmt.Sprintf("%q is not one of %s and %s", b.TenureType, TenureTypeRegular, TenureTypeTrial)}
+	}
+	if b.Sequence < 1 || b.Sequence > 99 {
+		return &ValidationError{Field: "sequence", Reason: "must be between 1 and 99"}
+	}
+	if b.TotalCycles > 999 {
+		return &ValidationError{Field: "total_cycles", Reason: "must be at most 999"}
+	}
+	if b.TenureType == TenureTypeTrial && b.TotalCycles == 0 {
+		return &ValidationError{Field: "total_cycles", Reason: "must be set for a trial billing cycle"}
+	}
+
+	if b.Frequency == nil {
+		return &ValidationError{Field: "frequency", Reason: "is required"}
+	}
+	max, ok := maxIntervalCounts[b.Frequency.IntervalUnit]
+	if !ok {
+		return &ValidationError{Field: "frequency.interval_unit", Reason: fmt.Sprintf("%q is not one of %s, %s, %s and %s", b.Frequency.IntervalUnit, IntervalUnitDay, IntervalUnitWeek, IntervalUnitMonth, IntervalUnitYear)}
+	}
+	if b.Frequency.IntervalCount > max {
+		return &ValidationError{Field: "frequency.interval_count", Reason: fmt.Sprintf("must be at most %d for %s", max, b.Frequency.IntervalUnit)}
+	}
+
+	if b.PricingScheme != nil && b.PricingScheme.FixedPrice != nil {
+		return checkMoney("pricing_scheme.fixed_price", b.PricingScheme.FixedPrice)
+	}
+	if b.TenureType == TenureTypeRegular {
+		return &ValidationError{Field: "pricing_scheme.fixed_price", Reason: "is required for a regular billing cycle"}
+	}
+	return nil
+}
+
+// Validate checks the payment preferences against the constraints documented by PayPal
+func (p *PaymentPreferences) Validate() error {
+	if !isValidSetupFeeFailureAction(p.SetupFeeFailureAction) {
+		return &ValidationError{Field: "setup_fee_failure_action", Reason: fmt.Sprintf("%q is not one of %s and %s", p.SetupFeeFailureAction, FailureActionContinue, FailureActionCancel)}
+	}
+	if p.PaymentFailureThreshold > 999 {
+		return &ValidationError{Field: "payment_failure_threshold", Reason: "must be at most 999"}
+	}
+	if p.SetupFee != nil {
+		return checkMoney("setup_fee", p.SetupFee)
+	}
+	return nil
+}
+
+// isValidSetupFeeFailureAction reports whether action is empty (PayPal defaults to CANCEL) or one of the FailureAction* values
+func isValidSetupFeeFailureAction(action string) bool {
+	return action == "" || action == FailureActionContinue || action == FailureActionCancel
+}
@@ -0,0 +1,40 @@
+package paypal
+
+// String returns a pointer to the string, for optional request fields where "" must be sent
+func String(v string) *string {
+	return &v
+}
+
+// StringValue returns the string the pointer points to, or "" for nil
+func StringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+// Bool returns a pointer to the bool, e.g. for PartnerConfigOverride.ShowAddCreditCard where false must be sent
+func Bool(v bool) *bool {
+	return &v
+}
+
+// BoolValue returns the bool the pointer points to, or false for nil
+func BoolValue(p *bool) bool {
+	if p == nil {
+		return false
+	}
+	return *p
+}
+
+// Int returns a pointer to the int, for optional request fields where 0 must be sent
+func Int(v int) *int {
+	return &v
+}
+
+// IntValue returns the int the pointer points to, or 0 for nil
+func IntValue(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
@@ -0,0 +1,26 @@
+package paypal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPointerHelpers(t *testing.T) {
+	if StringValue(String("")) != "" || StringValue(String("a")) != "a" || StringValue(nil) != "" {
+		t.Errorf("String and StringValue do not round trip")
+	}
+	if !BoolValue(Bool(true)) || BoolValue(Bool(false)) || BoolValue(nil) {
+		t.Errorf("Bool and BoolValue do not round trip")
+	}
+	if IntValue(Int(0)) != 0 || IntValue(Int(7)) != 7 || IntValue(nil) != 0 {
+		t.Errorf("Int and IntValue do not round trip")
+	}
+
+	encoded, err := json.Marshal(&PartnerConfigOverride{ShowAddCreditCard: Bool(false)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"show_add_credit_card":false}` {
+		t.Errorf("Expected false to be sent, got %s", encoded)
+	}
+}
@@ -0,0 +1,384 @@
+package paypal
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Possible values for `Kind` in ExpectedTransaction and ReconciledTransaction
+const (
+	ReconcileKindCapture string = "CAPTURE"
+	ReconcileKindRefund  string = "REFUND"
+	ReconcileKindPayout  string = "PAYOUT"
+)
+
+// Possible values for `Type` in ReconciliationDiscrepancy
+const (
+	DiscrepancyMissing        string = "MISSING"         // No PayPal transaction was found for an expected record
+	DiscrepancyAmountMismatch string = "AMOUNT_MISMATCH" // The PayPal transactions of a record add up to another amount
+	DiscrepancyUnexpected     string = "UNEXPECTED"      // A PayPal transaction matches none of the expected records
+)
+
+type (
+	// ExpectedTransaction represents a capture, refund or payout recorded on our side. Captures and refunds are
+	// matched on InvoiceID, or CustomID when InvoiceID is empty, payouts are matched on SenderItemID.
+	// Amount is compared without its sign, partial refunds of a record are added up
+	ExpectedTransaction struct {
+		Kind         string `json:"kind"`
+		InvoiceID    string `json:"invoice_id,omitempty"`
+		CustomID     string `json:"custom_id,omitempty"`
+		SenderItemID string `json:"sender_item_id,omitempty"`
+		Amount       *Money `json:"amount"`
+	}
+
+	// ReconciledTransaction represents a money movement found in the transaction search or the webhook history
+	ReconciledTransaction struct {
+		Kind          string `json:"kind"`
+		TransactionID string `json:"transaction_id"`
+		InvoiceID     string `json:"invoice_id,omitempty"`
+		CustomID      string `json:"custom_id,omitempty"`
+		SenderItemID  string `json:"sender_item_id,omitempty"`
+		Amount        *Money `json:"amount"`
+		EventCode     string `json:"event_code,omitempty"`
+		EventID       string `json:"event_id,omitempty"`
+		// ReferenceID is the transaction the transaction relates to, e.g. the capture of a refund
+		ReferenceID string `json:"reference_id,omitempty"`
+	}
+
+	// ReconciliationParams represents the period and the records to reconcile. Events is the webhook history for
+	// the period, PAYMENT.CAPTURE.COMPLETED, PAYMENT.CAPTURE.REFUNDED, PAYMENT.SALE.COMPLETED and
+	// PAYMENT.PAYOUTS-ITEM.SUCCEEDED events are used, they cover the last three hours the transaction search lags behind
+	// and carry the sender_item_id of payouts
+	ReconciliationParams struct {
+		StartDate time.Time
+		EndDate   time.Time
+		Expected  []*ExpectedTransaction
+		Events    []*Event
+	}
+
+	// ReconciliationDiscrepancy represents a single difference between our records and PayPal
+	ReconciliationDiscrepancy struct {
+		Type         string                   `json:"type"`
+		Expected     *ExpectedTransaction     `json:"expected,omitempty"`
+		Actual       *Money                   `json:"actual,omitempty"`
+		Transactions []*ReconciledTransaction `json:"transactions,omitempty"`
+	}
+
+	// ReconciliationReport represents the result of reconciling our records with PayPal
+	ReconciliationReport struct {
+		StartDate     time.Time                   `json:"start_date"`
+		EndDate       time.Time                   `json:"end_date"`
+		Expected      int                         `json:"expected"`
+		Matched       int                         `json:"matched"`
+		Discrepancies []ReconciliationDiscrepancy `json:"discrepancies,omitempty"`
+	}
+)
+
+// OK reports whether the reconciliation found no discrepancies
+func (r *ReconciliationReport) OK() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// Missing returns the expected records no PayPal transaction was found for
+func (r *ReconciliationReport) Missing() []ReconciliationDiscrepancy {
+	return r.discrepancies(DiscrepancyMissing)
+}
+
+// AmountMismatches returns the expected records whose PayPal transactions add up to another amount
+func (r *ReconciliationReport) AmountMismatches() []ReconciliationDiscrepancy {
+	return r.discrepancies(DiscrepancyAmountMismatch)
+}
+
+// Unexpected returns the PayPal transactions matching none of the expected records
+func (r *ReconciliationReport) Unexpected() []ReconciliationDiscrepancy {
+	return r.discrepancies(DiscrepancyUnexpected)
+}
+
+func (r *ReconciliationReport) discrepancies(discrepancyType string) []ReconciliationDiscrepancy {
+	var result []ReconciliationDiscrepancy
+	for _, d := range r.Discrepancies {
+		if d.Type == discrepancyType {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// ReconcileTransactions searches the transactions of the period and reconciles them, along with the webhook
+// history, with the expected records
+func (c *Client) ReconcileTransactions(params *ReconciliationParams) (*ReconciliationReport, error) {
+	if params == nil || params.StartDate.IsZero() || params.EndDate.IsZero() || params.EndDate.Before(params.StartDate) {
+		return nil, fmt.Errorf("paypal: StartDate and EndDate are required for reconciliation")
+	}
+
+	var transactions []*SearchTransactionDetails
+	err := c.searchTransactionPages(&TransactionSearchRequest{
+		StartDate: params.StartDate,
+		EndDate:   params.EndDate,
+		PageSize:  500,
+	}, func(page *TransactionSearchResponse) error {
+		transactions = append(transactions, page.TransactionDetails...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ReconcileTransactionRecords(transactions, params)
+}
+
+// ReconcileTransactionRecords matches the expected records against transaction search results and the webhook history
+// of params. A money movement found in both is counted once. Transaction search results are classified by event code:
+// T00xx credits are captures, T11xx are refunds and reversals, T0001 debits are payouts; denied, reversed and
+// cancelled transactions as well as other event codes like fees, holds and transfers are ignored
+func ReconcileTransactionRecords(transactions []*SearchTransactionDetails, params *ReconciliationParams) (*ReconciliationReport, error) {
+	if params == nil {
+		return nil, fmt.Errorf("paypal: params are required for reconciliation")
+	}
+
+	actual, err := reconciledTransactions(transactions, params.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconciliationReport{
+		StartDate: params.StartDate,
+		EndDate:   params.EndDate,
+		Expected:  len(params.Expected),
+	}
+
+	claimed := map[*ReconciledTransaction]bool{}
+	for _, expected := range params.Expected {
+		if expected == nil {
+			continue
+		}
+		key := expected.key()
+		if key == "" {
+			return nil, fmt.Errorf("paypal: expected %s record without identifier", expected.Kind)
+		}
+
+		var matches []*ReconciledTransaction
+		for _, t := range actual {
+			if t.Kind == expected.Kind && t.key() == key {
+				matches = append(matches, t)
+				claimed[t] = true
+			}
+		}
+
+		if len(matches) == 0 {
+			report.Discrepancies = append(report.Discrepancies, ReconciliationDiscrepancy{Type: DiscrepancyMissing, Expected: expected})
+			continue
+		}
+
+		total, ok := sumAbsMoney(matches)
+		if !ok || !moneyEqual(total, absMoney(expected.Amount)) {
+			report.Discrepancies = append(report.Discrepancies, ReconciliationDiscrepancy{
+				Type:         DiscrepancyAmountMismatch,
+				Expected:     expected,
+				Actual:       total,
+				Transactions: matches,
+			})
+			continue
+		}
+		report.Matched++
+	}
+
+	for _, t := range actual {
+		if !claimed[t] {
+			report.Discrepancies = append(report.Discrepancies, ReconciliationDiscrepancy{
+				Type:         DiscrepancyUnexpected,
+				Actual:       t.Amount,
+				Transactions: []*ReconciledTransaction{t},
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// key returns the identifier an expected record is matched on
+func (e *ExpectedTransaction) key() string {
+	if e.Kind == ReconcileKindPayout {
+		return e.SenderItemID
+	}
+	if e.InvoiceID != "" {
+		return "invoice:" + e.InvoiceID
+	}
+	if e.CustomID != "" {
+		return "custom:" + e.CustomID
+	}
+	return ""
+}
+
+// key returns the identifier a PayPal transaction is matched on, see ExpectedTransaction
+func (t *ReconciledTransaction) key() string {
+	return (&ExpectedTransaction{Kind: t.Kind, InvoiceID: t.InvoiceID, CustomID: t.CustomID, SenderItemID: t.SenderItemID}).key()
+}
+
+// reconciledTransactions merges the transaction search results and the webhook history by transaction ID,
+// the result is sorted by transaction ID
+func reconciledTransactions(transactions []*SearchTransactionDetails, events []*Event) ([]*ReconciledTransaction, error) {
+	byID := map[string]*ReconciledTransaction{}
+	merge := func(t *ReconciledTransaction) {
+		existing, ok := byID[t.TransactionID]
+		if !ok {
+			byID[t.TransactionID] = t
+			return
+		}
+		// Webhooks carry the identifiers the transaction search lacks, e.g. the sender_item_id of payouts
+		if existing.InvoiceID == "" {
+			existing.InvoiceID = t.InvoiceID
+		}
+		if existing.CustomID == "" {
+			existing.CustomID = t.CustomID
+		}
+		if existing.SenderItemID == "" {
+			existing.SenderItemID = t.SenderItemID
+		}
+		if existing.EventID == "" {
+			existing.EventID = t.EventID
+		}
+	}
+
+	for _, details := range transactions {
+		if details == nil || details.TransactionInfo == nil {
+			continue
+		}
+		if t := searchReconciledTransaction(details.TransactionInfo); t != nil {
+			merge(t)
+		}
+	}
+
+	for _, event := range events {
+		t, err := eventReconciledTransaction(event)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			merge(t)
+		}
+	}
+
+	result := make([]*ReconciledTransaction, 0, len(byID))
+	for _, t := range byID {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TransactionID < result[j].TransactionID })
+	return result, nil
+}
+
+// searchReconciledTransaction classifies a transaction search result, it returns nil for ignored transactions
+func searchReconciledTransaction(info *SearchTransactionInfo) *ReconciledTransaction {
+	if info.TransactionStatus != TransactionStatusSuccess && info.TransactionStatus != TransactionStatusPending {
+		return nil
+	}
+	if info.TransactionAmount == nil {
+		return nil
+	}
+
+	debit := strings.HasPrefix(info.TransactionAmount.Value, "-")
+	var kind string
+	switch {
+	case strings.HasPrefix(info.TransactionEventCode, "T11"):
+		kind = ReconcileKindRefund
+	case info.TransactionEventCode == "T0001" && debit:
+		kind = ReconcileKindPayout
+	case strings.HasPrefix(info.TransactionEventCode, "T00") && !debit:
+		kind = ReconcileKindCapture
+	default:
+		return nil
+	}
+
+	return &ReconciledTransaction{
+		Kind:          kind,
+		TransactionID: info.TransactionID,
+		InvoiceID:     info.InvoiceID,
+		CustomID:      info.CustomField,
+		Amount:        info.TransactionAmount,
+		EventCode:     info.TransactionEventCode,
+		ReferenceID:   info.PayPalReferenceID,
+	}
+}
+
+// eventReconciledTransaction extracts the money movement of a webhook event, it returns nil for other events
+func eventReconciledTransaction(event *Event) (*ReconciledTransaction, error) {
+	if event == nil {
+		return nil, nil
+	}
+
+	switch event.EventType {
+	case EventPaymentCaptureCompleted:
+		capture, err := event.CaptureResource()
+		if err != nil {
+			return nil, err
+		}
+		return &ReconciledTransaction{Kind: ReconcileKindCapture, TransactionID: capture.ID, InvoiceID: capture.InvoiceID,
+			CustomID: capture.CustomID, Amount: capture.Amount, EventID: event.ID}, nil
+	case EventPaymentCaptureRefunded:
+		refund, err := event.RefundResource()
+		if err != nil {
+			return nil, err
+		}
+		return &ReconciledTransaction{Kind: ReconcileKindRefund, TransactionID: refund.ID, InvoiceID: refund.InvoiceID,
+			Amount: refund.Amount, EventID: event.ID}, nil
+	case EventPaymentSaleCompleted:
+		sale, err := event.SaleResource()
+		if err != nil {
+			return nil, err
+		}
+		var amount *Money
+		if sale.Amount != nil {
+			amount = &Money{Currency: sale.Amount.Currency, Value: sale.Amount.Total}
+		}
+		return &ReconciledTransaction{Kind: ReconcileKindCapture, TransactionID: sale.ID, InvoiceID: sale.InvoiceNumber,
+			CustomID: sale.Custom, Amount: amount, EventID: event.ID}, nil
+	case EventPaymentPayoutsItemSucceeded:
+		item, err := event.PayoutItemResource()
+		if err != nil {
+			return nil, err
+		}
+		t := &ReconciledTransaction{Kind: ReconcileKindPayout, TransactionID: item.TransactionID, EventID: event.ID}
+		if item.PayoutItem != nil {
+			t.SenderItemID = item.PayoutItem.SenderItemID
+			if item.PayoutItem.Amount != nil {
+				t.Amount = &Money{Currency: item.PayoutItem.Amount.Currency, Value: item.PayoutItem.Amount.Value}
+			}
+		}
+		return t, nil
+	}
+
+	return nil, nil
+}
+
+// absMoney returns the amount without its sign
+func absMoney(m *Money) *Money {
+	if m == nil {
+		return nil
+	}
+	return &Money{Currency: m.Currency, Value: strings.TrimPrefix(m.Value, "-")}
+}
+
+// sumAbsMoney adds up the amounts of the transactions without their sign, it fails on mixed currencies
+func sumAbsMoney(transactions []*ReconciledTransaction) (*Money, bool) {
+	sum := new(big.Rat)
+	currency := ""
+	for _, t := range transactions {
+		if t.Amount == nil {
+			return nil, false
+		}
+		if currency != "" && t.Amount.Currency != currency {
+			return nil, false
+		}
+		currency = t.Amount.Currency
+
+		value, ok := parseSignedDecimal(absMoney(t.Amount).Value)
+		if !ok {
+			return nil, false
+		}
+		sum.Add(sum, value)
+	}
+	return &Money{Currency: currency, Value: formatCurrency(sum, currency)}, true
+}
@@ -0,0 +1,95 @@
+package paypal
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func testSearchTransaction(id, eventCode, status, value, invoiceID string) *SearchTransactionDetails {
+	return &SearchTransactionDetails{TransactionInfo: &SearchTransactionInfo{
+		TransactionID:        id,
+		TransactionEventCode: eventCode,
+		TransactionStatus:    status,
+		TransactionAmount:    &Money{Currency: "USD", Value: value},
+		InvoiceID:            invoiceID,
+	}}
+}
+
+func testReconcileEvent(id, eventType, resource string) *Event {
+	return &Event{ID: id, EventType: eventType, Resource: json.RawMessage(resource)}
+}
+
+func TestReconcileTransactionRecords(t *testing.T) {
+	transactions := []*SearchTransactionDetails{
+		testSearchTransaction("CAPTURE-1", "T0006", TransactionStatusSuccess, "10.00", "INV-1"),
+		testSearchTransaction("CAPTURE-2", "T0006", TransactionStatusSuccess, "19.00", "INV-2"),
+		testSearchTransaction("REFUND-1", "T1107", TransactionStatusSuccess, "-4.00", "INV-1"),
+		testSearchTransaction("PAYOUT-1", "T0001", TransactionStatusSuccess, "-5.00", ""),
+		testSearchTransaction("CAPTURE-404", "T0006", TransactionStatusSuccess, "7.00", "INV-404"),
+		testSearchTransaction("CAPTURE-DENIED", "T0006", TransactionStatusDenied, "8.00", "INV-3"),
+		testSearchTransaction("FEE-1", "T0106", TransactionStatusSuccess, "-0.30", ""),
+	}
+	events := []*Event{
+		// Refund too recent for the transaction search
+		testReconcileEvent("WH-1", EventPaymentCaptureRefunded, `{"id":"REFUND-2","status":"COMPLETED","amount":{"currency_code":"USD","value":"6.00"},"invoice_id":"INV-1"}`),
+		// Already in the transaction search, counted once
+		testReconcileEvent("WH-2", EventPaymentCaptureCompleted, `{"id":"CAPTURE-1","status":"COMPLETED","amount":{"currency_code":"USD","value":"10.00"},"invoice_id":"INV-1"}`),
+		// Carries the sender_item_id the transaction search lacks
+		testReconcileEvent("WH-3", EventPaymentPayoutsItemSucceeded, `{"payout_item_id":"8AELMXH8UB2P8","transaction_id":"PAYOUT-1","transaction_status":"SUCCESS","payout_item":{"recipient_type":"EMAIL","amount":{"currency":"USD","value":"5.00"},"receiver":"payee@example.com","sender_item_id":"payout-1"}}`),
+		testReconcileEvent("WH-4", EventBillingSubscriptionActivated, `{"id":"I-BW452GLLEP1G"}`),
+	}
+
+	params := &ReconciliationParams{
+		StartDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+		Expected: []*ExpectedTransaction{
+			{Kind: ReconcileKindCapture, InvoiceID: "INV-1", Amount: &Money{Currency: "USD", Value: "10"}},
+			{Kind: ReconcileKindCapture, InvoiceID: "INV-2", Amount: &Money{Currency: "USD", Value: "20.00"}},
+			{Kind: ReconcileKindRefund, InvoiceID: "INV-1", Amount: &Money{Currency: "USD", Value: "10.00"}},
+			{Kind: ReconcileKindPayout, SenderItemID: "payout-1", Amount: &Money{Currency: "USD", Value: "5.00"}},
+			{Kind: ReconcileKindCapture, CustomID: "order-9", Amount: &Money{Currency: "USD", Value: "3.00"}},
+		},
+		Events: events,
+	}
+
+	report, err := ReconcileTransactionRecords(transactions, params)
+	if err != nil {
+		t.Fatalf("Not expected error, got %v", err)
+	}
+
+	if report.OK() || report.Expected != 5 || report.Matched != 3 {
+		t.Errorf("expected 3 of 5 records to match, got %+v", report)
+	}
+
+	missing := report.Missing()
+	if len(missing) != 1 || missing[0].Expected.CustomID != "order-9" {
+		t.Errorf("unexpected missing records %+v", missing)
+	}
+
+	mismatches := report.AmountMismatches()
+	if len(mismatches) != 1 || mismatches[0].Expected.InvoiceID != "INV-2" || mismatches[0].Actual.Value != "19.00" {
+		t.Errorf("unexpected amount mismatches %+v", mismatches)
+	}
+
+	unexpected := report.Unexpected()
+	if len(unexpected) != 1 || unexpected[0].Transactions[0].TransactionID != "CAPTURE-404" {
+		t.Errorf("unexpected transactions %+v", unexpected)
+	}
+}
+
+func TestReconcileTransactionRecords_Invalid(t *testing.T) {
+	if _, err := ReconcileTransactionRecords(nil, nil); err == nil {
+		t.Errorf("Expected error without params")
+	}
+
+	params := &ReconciliationParams{Expected: []*ExpectedTransaction{{Kind: ReconcileKindPayout, InvoiceID: "INV-1"}}}
+	if _, err := ReconcileTransactionRecords(nil, params); err == nil {
+		t.Errorf("Expected error for a payout without sender_item_id")
+	}
+
+	params = &ReconciliationParams{Events: []*Event{testReconcileEvent("WH-1", EventPaymentCaptureCompleted, `{"id":`)}}
+	if _, err := ReconcileTransactionRecords(nil, params); err == nil {
+		t.Errorf("Expected error for an invalid event resource")
+	}
+}
@@ -0,0 +1,158 @@
+package paypal
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+type (
+	// RefundLedger tracks the refunds of a capture to check partial refunds against what is left to refund.
+	// Record the refunds returned by RefundCapturedPayment with the amount requested and the
+	// PAYMENT.CAPTURE.REFUNDED and REVERSED webhooks, a refund seen both ways or several times is counted once. Pending refunds count as refunded,
+	// failed and cancelled ones don't. It is safe for concurrent use
+	RefundLedger struct {
+		CaptureID string
+		Captured  *Money
+
+		mu      sync.Mutex
+		refunds map[string]*big.Rat
+	}
+)
+
+// NewRefundLedger returns an empty RefundLedger of the capture
+func NewRefundLedger(capture *Capture) *RefundLedger {
+	return &RefundLedger{CaptureID: capture.ID, Captured: capture.Amount, refunds: map[string]*big.Rat{}}
+}
+
+// Record adds the refund to the ledger, or updates it when already recorded. PayPal returns refunds without
+// their amount unless the client asked for the full representation, requested is the amount sent with the refund
+// and is recorded when the refund has none, pass Remaining for a full refund. It fails for the refunds of other
+// captures, amounts in another currency and refunds without an amount when requested is nil
+func (l *RefundLedger) Record(refund *Refund, requested *Money) error {
+	if refund == nil || refund.ID == "" {
+		return fmt.Errorf("paypal: refund ID is required to record a refund")
+	}
+	if captureID := refund.CaptureID(); captureID != "" && captureID != l.CaptureID {
+		return fmt.Errorf("paypal: refund %s is of capture %s, not %s", refund.ID, captureID, l.CaptureID)
+	}
+	if l.Captured == nil {
+		return fmt.Errorf("paypal: capture %s has no amount", l.CaptureID)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.refunds == nil {
+		l.refunds = map[string]*big.Rat{}
+	}
+	if refund.Status == RefundStatusFailed || refund.Status == RefundStatusCancelled {
+		delete(l.refunds, refund.ID)
+		return nil
+	}
+
+	refunded := refund.Amount
+	if refunded == nil {
+		refunded = requested
+	}
+	if refunded == nil {
+		return fmt.Errorf("paypal: refund %s has no amount, pass the amount requested", refund.ID)
+	}
+	amount, err := parseMoney(refunded, l.Captured.Currency, fmt.Sprintf("refund %s amount", refund.ID))
+	if err != nil {
+		return err
+	}
+	l.refunds[refund.ID] = amount
+	return nil
+}
+
+// RecordEvent records the refund of a PAYMENT.CAPTURE.REFUNDED or PAYMENT.CAPTURE.REVERSED event,
+// other events are ignored
+func (l *RefundLedger) RecordEvent(event *Event) error {
+	if event == nil || (event.EventType != EventPaymentCaptureRefunded && event.EventType != EventPaymentCaptureReversed) {
+		return nil
+	}
+
+	refund, err := event.RefundResource()
+	if err != nil {
+		return err
+	}
+	return l.Record(refund, nil)
+}
+
+// Refunded returns the total of the refunds recorded
+func (l *RefundLedger) Refunded() (*Money, error) {
+	if l.Captured == nil {
+		return nil, fmt.Errorf("paypal: capture %s has no amount", l.CaptureID)
+	}
+
+	l.mu.Lock()
+	total := l.refunded()
+	l.mu.Unlock()
+
+	return &Money{Currency: l.Captured.Currency, Value: formatCurrency(total, l.Captured.Currency)}, nil
+}
+
+// Remaining returns the amount of the capture left to refund
+func (l *RefundLedger) Remaining() (*Money, error) {
+	remaining, err := l.remaining()
+	if err != nil {
+		return nil, err
+	}
+	return &Money{Currency: l.Captured.Currency, Value: formatCurrency(remaining, l.Captured.Currency)}, nil
+}
+
+// Check validates a partial refund of amount before it is sent and returns the amount left to refund after it.
+// It returns a ValidationError when amount is not positive, is in another currency or exceeds what is left
+func (l *RefundLedger) Check(amount *Money) (*Money, error) {
+	remaining, err := l.remaining()
+	if err != nil {
+		return nil, err
+	}
+
+	currency := l.Captured.Currency
+	if amount == nil {
+		return nil, &ValidationError{Field: "amount", Reason: "is required"}
+	}
+	if amount.Currency != currency {
+		return nil, &ValidationError{Field: "amount.currency_code", Reason: fmt.Sprintf("%s does not match the capture currency %s", amount.Currency, currency)}
+	}
+	value, err := parseMoney(amount, currency, "refund amount")
+	if err != nil {
+		return nil, &ValidationError{Field: "amount.value", Reason: fmt.Sprintf("%q is not a valid %s amount", amount.Value, currency)}
+	}
+	if value.Sign() == 0 {
+		return nil, &ValidationError{Field: "amount.value", Reason: "must be greater than 0"}
+	}
+	if value.Cmp(remaining) > 0 {
+		return nil, &ValidationError{Field: "amount.value", Reason: fmt.Sprintf("%s exceeds the %s %s left to refund on capture %s", amount.Value, formatCurrency(remaining, currency), currency, l.CaptureID)}
+	}
+
+	return &Money{Currency: currency, Value: formatCurrency(remaining.Sub(remaining, value), currency)}, nil
+}
+
+// remaining returns the captured amount minus the refunds
+func (l *RefundLedger) remaining() (*big.Rat, error) {
+	if l.Captured == nil {
+		return nil, fmt.Errorf("paypal: capture %s has no amount", l.CaptureID)
+	}
+	captured, err := parseMoney(l.Captured, l.Captured.Currency, "capture amount")
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	refunded := l.refunded()
+	l.mu.Unlock()
+
+	return captured.Sub(captured, refunded), nil
+}
+
+// refunded returns the total of the refunds, l.mu must be held
+func (l *RefundLedger) refunded() *big.Rat {
+	total := new(big.Rat)
+	for _, amount := range l.refunds {
+		total.Add(total, amount)
+	}
+	return total
+}
@@ -0,0 +1,98 @@
+package paypal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRefundLedger(t *testing.T) {
+	ledger := NewRefundLedger(&Capture{ID: "2GG279541U471931P", Amount: &Money{Currency: "USD", Value: "100.00"}})
+
+	refund := &Refund{
+		ID:     "1JU08902781691411",
+		Status: RefundStatusPending,
+		Amount: &Money{Currency: "USD", Value: "30.00"},
+		Links:  []*Link{{Href: "https://api.paypal.com/v2/payments/captures/2GG279541U471931P", Rel: LinkRelUp, Method: "GET"}},
+	}
+	if err := ledger.Record(refund, nil); err != nil {
+		t.Fatalf("Not expected error for Record, got %v", err)
+	}
+
+	// the webhook of the same refund is not counted twice
+	event := &Event{}
+	if err := json.Unmarshal([]byte(`{"id":"WH-1","event_type":"PAYMENT.CAPTURE.REFUNDED","resource_type":"refund","resource":{"id":"1JU08902781691411","status":"COMPLETED","amount":{"currency_code":"USD","value":"30.00"},"links":[{"href":"https://api.paypal.com/v2/payments/captures/2GG279541U471931P","rel":"up","method":"GET"}]}}`), event); err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.RecordEvent(event); err != nil {
+		t.Fatalf("Not expected error for RecordEvent, got %v", err)
+	}
+	if refunded, _ := ledger.Refunded(); refunded.Value != "30.00" {
+		t.Errorf("expected 30.00 refunded, got %+v", refunded)
+	}
+
+	if remaining, err := ledger.Check(&Money{Currency: "USD", Value: "50"}); err != nil || remaining.Value != "20.00" {
+		t.Errorf("expected 20.00 left after refunding 50, got %+v, %v", remaining, err)
+	}
+	if remaining, err := ledger.Check(&Money{Currency: "USD", Value: "70.00"}); err != nil || remaining.Value != "0.00" {
+		t.Errorf("expected nothing left after refunding the rest, got %+v, %v", remaining, err)
+	}
+	for _, amount := range []*Money{nil, {Currency: "USD", Value: "70.01"}, {Currency: "EUR", Value: "10.00"}, {Currency: "USD", Value: "0"}, {Currency: "USD", Value: "1.005"}} {
+		if _, err := ledger.Check(amount); err == nil {
+			t.Errorf("expected an error for refund amount %+v", amount)
+		} else if _, ok := err.(*ValidationError); !ok {
+			t.Errorf("expected a ValidationError for refund amount %+v, got %T", amount, err)
+		}
+	}
+
+	// a failed refund gives its amount back
+	refund.Status = RefundStatusFailed
+	if err := ledger.Record(refund, nil); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, _ := ledger.Remaining(); remaining.Value != "100.00" {
+		t.Errorf("expected the failed refund not to count, got %+v", remaining)
+	}
+
+	other := &Refund{ID: "0P4523523V1233300", Amount: &Money{Currency: "USD", Value: "5.00"},
+		Links: []*Link{{Href: "https://api.paypal.com/v2/payments/captures/3C679366HH908993F", Rel: LinkRelUp}}}
+	if err := ledger.Record(other, nil); err == nil {
+		t.Errorf("expected an error for the refund of another capture")
+	}
+	if err := ledger.RecordEvent(&Event{EventType: EventPaymentCaptureCompleted}); err != nil {
+		t.Errorf("expected other events to be ignored, got %v", err)
+	}
+}
+
+func TestRefundLedger_MinimalRefund(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Prefer: return=minimal, the default
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"1JU08902781691411","status":"COMPLETED","links":[{"href":"https://api.paypal.com/v2/payments/refunds/1JU08902781691411","rel":"self","method":"GET"},{"href":"https://api.paypal.com/v2/payments/captures/2GG279541U471931P","rel":"up","method":"GET"}]}`))
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	ledger := NewRefundLedger(&Capture{ID: "2GG279541U471931P", Amount: &Money{Currency: "USD", Value: "100.00"}})
+	requested := &Money{Currency: "USD", Value: "30.00"}
+	refund, err := c.RefundCapturedPayment("2GG279541U471931P", &RefundRequest{Amount: requested})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Amount != nil {
+		t.Fatalf("expected a refund without amount, got %+v", refund.Amount)
+	}
+
+	if err := ledger.Record(refund, nil); err == nil {
+		t.Errorf("expected an error for a refund without amount")
+	}
+	if err := ledger.Record(refund, requested); err != nil {
+		t.Fatalf("Not expected error for Record, got %v", err)
+	}
+	if remaining, _ := ledger.Remaining(); remaining.Value != "70.00" {
+		t.Errorf("expected 70.00 left, got %+v", remaining)
+	}
+}
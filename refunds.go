@@ -1,6 +1,10 @@
 package paypal
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
 
 // ShowRefund shows details for a refund by ID
 // Endpoint: GET /v2/payments/refunds/{refund_id}
@@ -19,3 +23,43 @@ func (c *Client) ShowRefund(refundID string) (*Refund, error) {
 
 	return resp, nil
 }
+
+// CaptureID returns the ID of the refunded capture from the `up` link of the refund, empty when it has none
+func (r *Refund) CaptureID() string {
+	link := findLink(r.Links, LinkRelUp)
+	if link == nil {
+		return ""
+	}
+	i := strings.LastIndex(link.Href, "/captures/")
+	if i < 0 {
+		return ""
+	}
+	return strings.Trim(link.Href[i+len("/captures/"):], "/")
+}
+
+// PlatformFeeReversal returns the total of the platform fees returned to the seller with the refund,
+// nil when the refund did not return any platform fee
+func (b *SellerPayableBreakdown) PlatformFeeReversal() (*Money, error) {
+	var (
+		currency string
+		total    = new(big.Rat)
+	)
+	for i, fee := range b.PlatformFees {
+		if fee == nil || fee.Amount == nil {
+			continue
+		}
+		if currency == "" {
+			currency = fee.Amount.Currency
+		}
+		value, err := parseMoney(fee.Amount, currency, fmt.Sprintf("platform fee %d", i))
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, value)
+	}
+	if currency == "" {
+		return nil, nil
+	}
+
+	return &Money{Currency: currency, Value: formatCurrency(total, currency)}, nil
+}
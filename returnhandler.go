@@ -0,0 +1,150 @@
+package paypal
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type (
+	// CheckoutReturn holds the query parameters PayPal appends to the return and cancel URLs
+	// when it redirects the payer back after an approval
+	CheckoutReturn struct {
+		// OrderID is the `token` of orders, for v1 payments and billing agreements it is the EC token
+		OrderID string
+		// PayerID is the `PayerID` of the payer who approved the order, empty on cancel URLs
+		PayerID string
+		// PaymentID is the `paymentId` of v1 payments
+		PaymentID string
+		// SubscriptionID is the `subscription_id` of subscriptions
+		SubscriptionID string
+		// BAToken is the `ba_token` of subscriptions and billing agreements
+		BAToken string
+		// Cancelled is true when the payer was redirected to the cancel URL
+		Cancelled bool
+	}
+
+	// ReturnCallback handles a validated return, e.g. captures the order and redirects to a receipt page.
+	// It writes the response, returning an error makes the handler respond with 500
+	ReturnCallback func(w http.ResponseWriter, r *http.Request, ret *CheckoutReturn) error
+
+	// ReturnHandlerOptions configures the handlers returned by ReturnHandler and CancelHandler
+	ReturnHandlerOptions struct {
+		// Validate checks the return belongs to the user, e.g. the order ID matches the one stored in their session.
+		// PayPal doesn't pass a state through the approval, without Validate anyone can call the return URL with any token
+		Validate func(r *http.Request, ret *CheckoutReturn) error
+		// OnError is called with every rejected return and failed callback, e.g. for logging
+		OnError func(r *http.Request, err error)
+	}
+)
+
+// IsSubscription returns true when the return is for a subscription rather than an order
+func (ret *CheckoutReturn) IsSubscription() bool {
+	return ret.SubscriptionID != ""
+}
+
+// ParseCheckoutReturn reads the query parameters of a return or cancel URL. It fails when the request has neither
+// `token`, `subscription_id` nor `ba_token`, and for approved orders without `PayerID`
+func ParseCheckoutReturn(r *http.Request, cancelled bool) (*CheckoutReturn, error) {
+	q := r.URL.Query()
+	ret := &CheckoutReturn{
+		OrderID:        q.Get("token"),
+		PayerID:        q.Get("PayerID"),
+		PaymentID:      q.Get("paymentId"),
+		SubscriptionID: q.Get("subscription_id"),
+		BAToken:        q.Get("ba_token"),
+		Cancelled:      cancelled,
+	}
+
+	if ret.OrderID == "" && ret.SubscriptionID == "" && ret.BAToken == "" {
+		return nil, fmt.Errorf("paypal: return URL has no token, subscription_id or ba_token")
+	}
+	if !cancelled && ret.SubscriptionID == "" && ret.BAToken == "" && ret.PayerID == "" {
+		return nil, fmt.Errorf("paypal: return URL of order %s has no PayerID", ret.OrderID)
+	}
+	return ret, nil
+}
+
+// ReturnHandler returns an http.Handler for the return URL of orders and subscriptions, it passes
+// the approved return to the callback once opts.Validate accepts it. It responds with
+//   - 400 when the query parameters are missing or the return is not valid
+//   - 405 for other methods than GET
+//   - 500 when the callback failed
+//
+// Capture the order in the callback:
+//
+//	paypal.ReturnHandler(func(w http.ResponseWriter, r *http.Request, ret *paypal.CheckoutReturn) error {
+//		_, err := c.CaptureOrder(ret.OrderID, paypal.CaptureOrderRequest{})
+//		...
+//	}, opts)
+func ReturnHandler(callback ReturnCallback, opts *ReturnHandlerOptions) http.Handler {
+	return returnHandler(callback, opts, false)
+}
+
+// CancelHandler returns an http.Handler for the cancel URL of orders and subscriptions, it passes the
+// return with Cancelled set to the callback and responds like ReturnHandler
+func CancelHandler(callback ReturnCallback, opts *ReturnHandlerOptions) http.Handler {
+	return returnHandler(callback, opts, true)
+}
+
+func returnHandler(callback ReturnCallback, opts *ReturnHandlerOptions, cancelled bool) http.Handler {
+	if opts == nil {
+		opts = &ReturnHandlerOptions{}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := serveReturn(w, r, callback, opts, cancelled)
+		if err == nil {
+			return
+		}
+		if opts.OnError != nil {
+			opts.OnError(r, err)
+		}
+		if status != 0 {
+			http.Error(w, http.StatusText(status), status)
+		}
+	})
+}
+
+// serveReturn handles a single return, the status is 0 when the callback wrote the response
+func serveReturn(w http.ResponseWriter, r *http.Request, callback ReturnCallback, opts *ReturnHandlerOptions, cancelled bool) (int, error) {
+	if r.Method != http.MethodGet {
+		return http.StatusMethodNotAllowed, fmt.Errorf("paypal: return URL called with %s", r.Method)
+	}
+
+	ret, err := ParseCheckoutReturn(r, cancelled)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	if opts.Validate != nil {
+		if err := opts.Validate(r, ret); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("paypal: invalid return: %v", err)
+		}
+	}
+
+	rec := &statusRecorder{ResponseWriter: w}
+	if err := callback(rec, r, ret); err != nil {
+		if rec.status != 0 {
+			return 0, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	return 0, nil
+}
+
+// statusRecorder remembers whether the callback already wrote the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,97 @@
+package paypal
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseCheckoutReturn(t *testing.T) {
+	tests := []struct {
+		query     string
+		cancelled bool
+		want      *CheckoutReturn
+	}{
+		{"token=5O190127TN364715T&PayerID=2J6QB8YJQSJRJ", false, &CheckoutReturn{OrderID: "5O190127TN364715T", PayerID: "2J6QB8YJQSJRJ"}},
+		{"token=5O190127TN364715T", true, &CheckoutReturn{OrderID: "5O190127TN364715T", Cancelled: true}},
+		{"subscription_id=I-BW452GLLEP1G&ba_token=BA-2M539689T3856352J&token=3C679366HH908993F", false,
+			&CheckoutReturn{OrderID: "3C679366HH908993F", SubscriptionID: "I-BW452GLLEP1G", BAToken: "BA-2M539689T3856352J"}},
+		{"token=5O190127TN364715T", false, nil},
+		{"PayerID=2J6QB8YJQSJRJ", false, nil},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/paypal/return?"+tt.query, nil)
+		got, err := ParseCheckoutReturn(r, tt.cancelled)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %+v", tt.query, got)
+			}
+			continue
+		}
+		if err != nil || *got != *tt.want {
+			t.Errorf("%s: expected %+v, got %+v, %v", tt.query, tt.want, got, err)
+		}
+	}
+}
+
+func TestReturnHandler(t *testing.T) {
+	var errs []error
+	opts := &ReturnHandlerOptions{
+		Validate: func(r *http.Request, ret *CheckoutReturn) error {
+			if cookie, err := r.Cookie("order"); err != nil || cookie.Value != ret.OrderID {
+				return errors.New("order does not belong to the session")
+			}
+			return nil
+		},
+		OnError: func(r *http.Request, err error) { errs = append(errs, err) },
+	}
+
+	var captured []string
+	handler := ReturnHandler(func(w http.ResponseWriter, r *http.Request, ret *CheckoutReturn) error {
+		if ret.OrderID == "FAILING" {
+			return errors.New("capture failed")
+		}
+		captured = append(captured, ret.OrderID)
+		http.Redirect(w, r, "/receipt", http.StatusSeeOther)
+		return nil
+	}, opts)
+
+	serve := func(method, query, order string) int {
+		r := httptest.NewRequest(method, "/paypal/return?"+query, nil)
+		r.AddCookie(&http.Cookie{Name: "order", Value: order})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if status := serve("GET", "token=5O190127TN364715T&PayerID=2J6QB8YJQSJRJ", "5O190127TN364715T"); status != http.StatusSeeOther {
+		t.Errorf("expected the callback to redirect, got %d", status)
+	}
+	if status := serve("GET", "token=5O190127TN364715T&PayerID=2J6QB8YJQSJRJ", "8MC585209K746392H"); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for an order of another session, got %d", status)
+	}
+	if status := serve("GET", "token=5O190127TN364715T", "5O190127TN364715T"); status != http.StatusBadRequest {
+		t.Errorf("expected 400 without PayerID, got %d", status)
+	}
+	if status := serve("POST", "token=5O190127TN364715T&PayerID=2J6QB8YJQSJRJ", "5O190127TN364715T"); status != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", status)
+	}
+	if status := serve("GET", "token=FAILING&PayerID=2J6QB8YJQSJRJ", "FAILING"); status != http.StatusInternalServerError {
+		t.Errorf("expected 500 when the callback fails, got %d", status)
+	}
+	if len(captured) != 1 || len(errs) != 4 {
+		t.Errorf("expected one capture and four errors, got %v, %v", captured, errs)
+	}
+
+	var cancelled *CheckoutReturn
+	cancel := CancelHandler(func(w http.ResponseWriter, r *http.Request, ret *CheckoutReturn) error {
+		cancelled = ret
+		return nil
+	}, nil)
+	cancel.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/paypal/cancel?token=5O190127TN364715T", nil))
+	if cancelled == nil || !cancelled.Cancelled || cancelled.OrderID != "5O190127TN364715T" {
+		t.Errorf("unexpected cancelled return %+v", cancelled)
+	}
+}
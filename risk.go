@@ -0,0 +1,95 @@
+package paypal
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Possible values for `key` in TransactionContextData, the keys PayPal documents for most risk programs.
+// Dates are formatted as RFC 3339, the program agreement lists the keys required for the merchant
+const (
+	TransactionContextKeySenderAccountID            string = "sender_account_id"
+	TransactionContextKeySenderFirstName            string = "sender_first_name"
+	TransactionContextKeySenderLastName             string = "sender_last_name"
+	TransactionContextKeySenderEmail                string = "sender_email"
+	TransactionContextKeySenderPhone                string = "sender_phone"
+	TransactionContextKeySenderCountryCode          string = "sender_country_code"
+	TransactionContextKeySenderCreateDate           string = "sender_create_date"
+	TransactionContextKeySenderSignupIP             string = "sender_signup_ip"
+	TransactionContextKeySenderPopularityScore      string = "sender_popularity_score"
+	TransactionContextKeyReceiverAccountID          string = "receiver_account_id"
+	TransactionContextKeyReceiverCreateDate         string = "receiver_create_date"
+	TransactionContextKeyReceiverEmail              string = "receiver_email"
+	TransactionContextKeyReceiverAddressCountryCode string = "receiver_address_country_code"
+	TransactionContextKeyBusinessName               string = "business_name"
+	TransactionContextKeyRecipientPopularityScore   string = "recipient_popularity_score"
+	TransactionContextKeyFirstInteractionDate       string = "first_interaction_date"
+	TransactionContextKeyTxnCountTotal              string = "txn_count_total"
+	TransactionContextKeyVertical                   string = "vertical"
+	TransactionContextKeyTransactionIsTangible      string = "transaction_is_tangible"
+)
+
+type (
+	// TransactionContext represents the risk data set for a transaction before the order is created, PayPal
+	// matches it with the order through the tracking ID passed in the PayPal-Client-Metadata-Id header
+	TransactionContext struct {
+		TrackingID     string                    `json:"tracking_id,omitempty"` //Read only
+		AdditionalData []*TransactionContextData `json:"additional_data"`
+	}
+
+	// TransactionContextData represents a key of the risk data, see the TransactionContextKey* constants
+	TransactionContextData struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+)
+
+// Value returns the value set for the key, or an empty string
+func (t *TransactionContext) Value(key string) string {
+	for _, d := range t.AdditionalData {
+		if d != nil && d.Key == key {
+			return d.Value
+		}
+	}
+	return ""
+}
+
+// SetTransactionContext sets the risk data of a transaction. merchantID is the payer ID of the merchant receiving
+// the payment and trackingID a unique ID of the transaction, at most 32 characters
+// Endpoint: PUT /v1/risk/transaction-contexts/MERCHANT_ID/TRACKING_ID
+func (c *Client) SetTransactionContext(merchantID, trackingID string, data []*TransactionContextData) (*TransactionContext, error) {
+	resp := &TransactionContext{}
+
+	if merchantID == "" || trackingID == "" {
+		return resp, fmt.Errorf("paypal: merchant ID and tracking ID are required to set a transaction context")
+	}
+	if len(trackingID) > 32 {
+		return resp, fmt.Errorf("paypal: transaction context tracking ID %s is longer than 32 characters", trackingID)
+	}
+	for _, d := range data {
+		if d == nil || d.Key == "" {
+			return resp, fmt.Errorf("paypal: transaction context data must have a key")
+		}
+	}
+
+	req, err := c.NewRequest("PUT", fmt.Sprintf("%s%s%s/%s", c.APIBase, "/v1/risk/transaction-contexts/", url.PathEscape(merchantID), url.PathEscape(trackingID)), &TransactionContext{AdditionalData: data})
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// GetTransactionContext shows the risk data set for a transaction
+// Endpoint: GET /v1/risk/transaction-contexts/MERCHANT_ID/TRACKING_ID
+func (c *Client) GetTransactionContext(merchantID, trackingID string) (*TransactionContext, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s/%s", c.APIBase, "/v1/risk/transaction-contexts/", url.PathEscape(merchantID), url.PathEscape(trackingID)), nil)
+	resp := &TransactionContext{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
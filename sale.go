@@ -43,6 +43,28 @@ func (c *Client) RefundSale(saleID string, a *Amount) (*Refund, error) {
 	return refund, nil
 }
 
+// RefundSaleWithDetails refunds a completed sale like RefundSale, with the invoice number, description and
+// reason of the refund, and returns the v1 refund with its state and parent payment
+// Endpoint: POST /v1/payments/sale/ID/refund
+func (c *Client) RefundSaleWithDetails(saleID string, body *RefundSaleRequest) (*SaleRefund, error) {
+	refund := &SaleRefund{}
+
+	if body == nil {
+		body = &RefundSaleRequest{}
+	}
+
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/payments/sale/"+saleID+"/refund"), body)
+	if err != nil {
+		return refund, err
+	}
+
+	if err = c.SendWithBasicAuth(req, refund); err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
 // GetRefund by ID
 // Use it to look up details of a specific refund on direct and captured payments.
 // Endpoint: GET /v1/payments/refund/ID
@@ -0,0 +1,283 @@
+// +build sandbox
+
+package paypal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The sandbox suite calls every endpoint it can drive without a payer against the sandbox account of
+// PAYPAL_SANDBOX_CLIENT_ID and PAYPAL_SANDBOX_SECRET and checks the responses still decode into the structs:
+//
+//	PAYPAL_SANDBOX_CLIENT_ID=... PAYPAL_SANDBOX_SECRET=... go test -tags=sandbox -run Sandbox
+//
+// Fields PayPal sends that the structs don't have are logged, set PAYPAL_SANDBOX_STRICT=1 to fail on them
+
+// sandboxRecorder keeps the body of the last response, so the suite can decode it strictly
+type sandboxRecorder struct {
+	mu   sync.Mutex
+	last []byte
+}
+
+func (r *sandboxRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.last = body
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func newSandboxClient(t *testing.T) (*Client, *sandboxRecorder) {
+	clientID, secret := os.Getenv("PAYPAL_SANDBOX_CLIENT_ID"), os.Getenv("PAYPAL_SANDBOX_SECRET")
+	if clientID == "" || secret == "" {
+		t.Skip("PAYPAL_SANDBOX_CLIENT_ID and PAYPAL_SANDBOX_SECRET are required for the sandbox suite")
+	}
+
+	c, err := NewClient(clientID, secret, APIBaseSandBox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &sandboxRecorder{}
+	c.SetHTTPClient(&http.Client{Transport: rec, Timeout: 30 * time.Second})
+	if _, err := c.GetAccessToken(); err != nil {
+		t.Fatalf("GetAccessToken: %v", err)
+	}
+	assertSandboxDecodes(t, rec, &TokenResponse{})
+	return c, rec
+}
+
+// assertSandboxDecodes decodes the last response into a new value of the type of v, reporting the fields
+// of the response the type doesn't have
+func assertSandboxDecodes(t *testing.T, rec *sandboxRecorder, v interface{}) {
+	t.Helper()
+
+	rec.mu.Lock()
+	body := rec.last
+	rec.mu.Unlock()
+	if len(body) == 0 {
+		return
+	}
+
+	strict := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(strict); err != nil {
+		if os.Getenv("PAYPAL_SANDBOX_STRICT") != "" {
+			t.Errorf("%T doesn't match the sandbox response: %v\n%s", v, err, body)
+		} else {
+			t.Logf("%T doesn't match the sandbox response: %v", v, err)
+		}
+	}
+}
+
+func sandboxID(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
+
+func TestSandboxOrders(t *testing.T) {
+	c, rec := newSandboxClient(t)
+
+	order, err := c.CreateOrder(OrderIntentCapture, []PurchaseUnitRequest{{
+		ReferenceID: "sandbox",
+		InvoiceID:   sandboxID("INV"),
+		Amount: &PurchaseUnitAmount{
+			Currency:  "USD",
+			Value:     "7.00",
+			Breakdown: &PurchaseUnitAmountBreakdown{ItemTotal: &Money{Currency: "USD", Value: "7.00"}},
+		},
+		Items: []Item{{Name: "Sandbox item", Quantity: "1", UnitAmount: &Money{Currency: "USD", Value: "7.00"}}},
+	}}, nil, &ApplicationContext{ReturnURL: "https://example.com/return", CancelURL: "https://example.com/cancel"})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	assertSandboxDecodes(t, rec, order)
+	if order.ID == "" || order.Status != OrderStatusCreated || len(order.Links) == 0 {
+		t.Errorf("unexpected order %+v", order)
+	}
+
+	got, err := c.GetOrder(order.ID)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	assertSandboxDecodes(t, rec, got)
+	if got.ID != order.ID || len(got.PurchaseUnits) != 1 || got.PurchaseUnits[0].Amount == nil || got.PurchaseUnits[0].Amount.Value != "7.00" {
+		t.Errorf("unexpected order %+v", got)
+	}
+
+	// The payer has not approved the order, PayPal rejects the capture with an error the client decodes
+	_, err = c.CaptureOrder(order.ID, CaptureOrderRequest{})
+	if errResp, ok := err.(*ErrorResponse); !ok || errResp.Name == "" || len(errResp.Details) == 0 {
+		t.Errorf("expected an ErrorResponse with details, got %v", err)
+	}
+}
+
+func TestSandboxSubscriptions(t *testing.T) {
+	c, rec := newSandboxClient(t)
+
+	product, err := c.CreateProduct(&CreateProductRequest{Name: "Sandbox suite", Description: "Created by the sandbox suite", Type: "SERVICE"})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	assertSandboxDecodes(t, rec, product)
+
+	plan, err := c.CreatePlan(&CreatePlan{
+		ProductID: product.ID,
+		Name:      "Sandbox suite monthly",
+		Status:    PlanStatusActive,
+		BillingCycles: []*BillingCycle{{
+			PricingScheme: &PricingScheme{FixedPrice: &Money{Currency: "USD", Value: "5.00"}},
+			Frequency:     &Frequency{IntervalUnit: IntervalUnitMonth, IntervalCount: 1},
+			TenureType:    TenureTypeRegular,
+			Sequence:      1,
+		}},
+		PaymentPreferences: &PaymentPreferences{AutoBillOutstanding: true, PaymentFailureThreshold: 1},
+	})
+	if err != nil {
+		t.Fatalf("CreatePlan: %v", err)
+	}
+	assertSandboxDecodes(t, rec, plan)
+	defer c.DeactivatePlan(plan.ID)
+
+	shown, err := c.ShowPlan(plan.ID)
+	if err != nil {
+		t.Fatalf("ShowPlan: %v", err)
+	}
+	assertSandboxDecodes(t, rec, shown)
+	if len(shown.BillingCycles) != 1 || shown.BillingCycles[0].TenureType != TenureTypeRegular {
+		t.Errorf("unexpected plan %+v", shown)
+	}
+
+	plans, err := c.ListAllPlans(&ListPlansParams{ProductID: product.ID, PageSize: 10, Page: 1, TotalRequired: true})
+	if err != nil {
+		t.Fatalf("ListAllPlans: %v", err)
+	}
+	assertSandboxDecodes(t, rec, plans)
+
+	sub, err := c.CreateSubscription(&CreateSubscriptionRequest{PlanID: plan.ID})
+	if err != nil {
+		t.Fatalf("CreateSubscription: %v", err)
+	}
+	assertSandboxDecodes(t, rec, sub)
+	if sub.Status != SubscriptionStatusApprovalPending {
+		t.Errorf("unexpected subscription %+v", sub)
+	}
+
+	shownSub, err := c.ShowSubscription(sub.ID, &ShowSubscriptionRequest{})
+	if err != nil {
+		t.Fatalf("ShowSubscription: %v", err)
+	}
+	assertSandboxDecodes(t, rec, shownSub)
+}
+
+func TestSandboxPayouts(t *testing.T) {
+	c, rec := newSandboxClient(t)
+
+	created, err := c.CreateSinglePayout(Payout{
+		SenderBatchHeader: &SenderBatchHeader{SenderBatchID: sandboxID("batch"), EmailSubject: "Sandbox suite payout"},
+		Items: []PayoutItem{{
+			RecipientType: "EMAIL",
+			Receiver:      "sandbox-suite-payout@example.com",
+			Amount:        &AmountPayout{Currency: "USD", Value: "1.00"},
+			SenderItemID:  sandboxID("item"),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSinglePayout: %v", err)
+	}
+	assertSandboxDecodes(t, rec, created)
+
+	batch, err := c.GetPayout(created.BatchHeader.PayoutBatchID)
+	if err != nil {
+		t.Fatalf("GetPayout: %v", err)
+	}
+	assertSandboxDecodes(t, rec, batch)
+
+	if len(batch.Items) > 0 {
+		item, err := c.GetPayoutItem(batch.Items[0].PayoutItemID)
+		if err != nil {
+			t.Fatalf("GetPayoutItem: %v", err)
+		}
+		assertSandboxDecodes(t, rec, item)
+	}
+}
+
+func TestSandboxWebhooks(t *testing.T) {
+	c, rec := newSandboxClient(t)
+
+	types, err := c.ListWebhookEventTypes()
+	if err != nil {
+		t.Fatalf("ListWebhookEventTypes: %v", err)
+	}
+	assertSandboxDecodes(t, rec, types)
+	for _, eventType := range types.EventTypes {
+		if _, ok := EventResourceType(eventType.Name); !ok {
+			t.Logf("event type %s is not known to the package", eventType.Name)
+		}
+	}
+
+	webhook, err := c.CreateWebhook(&CreateWebhookRequest{
+		URL:        "https://example.com/paypal/" + sandboxID("webhook"),
+		EventTypes: []*EventType{{Name: EventPaymentCaptureCompleted}},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	assertSandboxDecodes(t, rec, webhook)
+	defer c.DeleteWebhook(webhook.ID)
+
+	webhooks, err := c.ListWebhooks()
+	if err != nil {
+		t.Fatalf("ListWebhooks: %v", err)
+	}
+	assertSandboxDecodes(t, rec, webhooks)
+
+	event, err := c.SimulateWebhookEvent(&SimulateWebhookEventRequest{
+		WebhookID:       webhook.ID,
+		EventType:       EventPaymentCaptureCompleted,
+		ResourceVersion: ResourceVersion2,
+	})
+	if err != nil {
+		t.Fatalf("SimulateWebhookEvent: %v", err)
+	}
+	assertSandboxDecodes(t, rec, event)
+	if capture, err := event.CaptureResource(); err != nil || capture.ID == "" {
+		t.Errorf("expected the simulated capture to decode, got %+v, %v", capture, err)
+	}
+}
+
+func TestSandboxDisputes(t *testing.T) {
+	c, rec := newSandboxClient(t)
+
+	disputes, err := c.ListDisputes(&ListDisputesRequest{PageSize: 10})
+	if err != nil {
+		t.Fatalf("ListDisputes: %v", err)
+	}
+	assertSandboxDecodes(t, rec, disputes)
+
+	if len(disputes.Items) > 0 {
+		dispute, err := c.GetDispute(disputes.Items[0].DisputeID)
+		if err != nil {
+			t.Fatalf("GetDispute: %v", err)
+		}
+		assertSandboxDecodes(t, rec, dispute)
+	}
+}
@@ -0,0 +1,163 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// StreamBuffer is the number of items a stream fetches ahead of its consumer
+const StreamBuffer = 100
+
+// linkedPage is a page of products or plans, the listings following the `next` links
+type linkedPage struct {
+	Products     []*Product     `json:"products"`
+	Plans        []*Plan        `json:"plans"`
+	Transactions []*Transaction `json:"transactions"`
+	TotalPages   uint64         `json:"total_pages,omitempty"`
+	Links        []*Link        `json:"links"`
+}
+
+// StreamProducts lists the products page by page in the background, sending them on the returned channel as the
+// pages arrive. The error channel receives the error that stopped the listing, nil once every page was sent, after
+// the products channel is closed. Cancel ctx to stop the listing, the error is then ctx.Err():
+//
+//	products, errc := c.StreamProducts(ctx, &paypal.ListProductsRequest{PageSize: 20})
+//	for product := range products { ... }
+//	if err := <-errc; err != nil { ... }
+//
+// Endpoint: GET /v1/catalogs/products
+func (c *Client) StreamProducts(ctx context.Context, params *ListProductsRequest) (<-chan *Product, <-chan error) {
+	products := make(chan *Product, StreamBuffer)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(products)
+
+		q := pageQuery(0, 0, false)
+		if params != nil {
+			q = pageQuery(params.PageSize, params.Page, params.TotalRequired)
+		}
+		errc <- c.streamLinkedPages(ctx, "/v1/catalogs/products", q, func(page *linkedPage) error {
+			for _, product := range page.Products {
+				select {
+				case products <- product:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+	}()
+
+	return products, errc
+}
+
+// StreamPlans lists the plans page by page in the background like StreamProducts, params may be nil
+// Endpoint: GET /v1/billing/plans
+func (c *Client) StreamPlans(ctx context.Context, params *ListPlansParams) (<-chan *Plan, <-chan error) {
+	plans := make(chan *Plan, StreamBuffer)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(plans)
+
+		q := pageQuery(0, 0, false)
+		if params != nil {
+			q = pageQuery(params.PageSize, params.Page, params.TotalRequired)
+			if params.ProductID != "" {
+				q.Set("product_id", params.ProductID)
+			}
+		}
+		errc <- c.streamLinkedPages(ctx, "/v1/billing/plans", q, func(page *linkedPage) error {
+			for _, plan := range page.Plans {
+				select {
+				case plans <- plan:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+	}()
+
+	return plans, errc
+}
+
+// StreamTransactions searches the transactions in the background like StreamProducts, sending them as the
+// responses are read with ForEachTransaction.
+// Once ctx is canceled the listing stops at the next transaction
+// Endpoint: GET /v1/reporting/transactions
+func (c *Client) StreamTransactions(ctx context.Context, params *TransactionSearchRequest) (<-chan *SearchTransactionDetails, <-chan error) {
+	transactions := make(chan *SearchTransactionDetails, StreamBuffer)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(transactions)
+
+		errc <- c.ForEachTransaction(params, func(transaction *SearchTransactionDetails) error {
+			select {
+			case transactions <- transaction:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return transactions, errc
+}
+
+// streamLinkedPages gets the first page of the listing at path and follows the `next` links,
+// calling fn with every page. The requests are canceled with ctx
+func (c *Client) streamLinkedPages(ctx context.Context, path string, query url.Values, fn func(page *linkedPage) error) error {
+	u := fmt.Sprintf("%s%s", c.APIBase, path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	visited := map[string]bool{}
+	for {
+		req, err := c.NewRequest("GET", u, nil)
+		if err != nil {
+			return err
+		}
+
+		page := &linkedPage{}
+		if err := c.SendWithBasicAuth(req.WithContext(ctx), page); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+
+		next := findLink(page.Links, LinkRelNext)
+		if next == nil || visited[next.Href] {
+			return nil
+		}
+		visited[next.Href] = true
+		u = next.Href
+	}
+}
+
+// pageQuery returns the pagination parameters of the products and plans listings, zero values are left out
+func pageQuery(pageSize, page uint64, totalRequired bool) url.Values {
+	q := url.Values{}
+	if pageSize > 0 {
+		q.Set("page_size", strconv.FormatUint(pageSize, 10))
+	}
+	if page > 0 {
+		q.Set("page", strconv.FormatUint(page, 10))
+	}
+	if totalRequired {
+		q.Set("total_required", "true")
+	}
+	return q
+}
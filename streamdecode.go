@@ -0,0 +1,173 @@
+package paypal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+type (
+	// streamDecoder is implemented by the values Send decodes from the response body as it is read,
+	// instead of reading the whole body first
+	streamDecoder interface {
+		decodeFrom(r io.Reader) error
+	}
+
+	// transactionStream decodes a page of a transaction search, passing the transactions to fn one at a time.
+	// The page receives the other fields, its TransactionDetails stays empty. The unknown fields of the page,
+	// the ones of its transactions included, are passed to report when it is set
+	transactionStream struct {
+		page   *TransactionSearchResponse
+		fn     func(details *SearchTransactionDetails) error
+		report func(fields []string)
+	}
+)
+
+// decodeFrom decodes the page read from r, the transactions are never held together in memory
+func (s *transactionStream) decodeFrom(r io.Reader) error {
+	return decodeArrayStream(r, s.page, "transaction_details", func(item json.RawMessage) error {
+		details := &SearchTransactionDetails{}
+		if err := decodeJSON(item, details); err != nil {
+			return err
+		}
+		return s.fn(details)
+	}, s.report)
+}
+
+// ForEachTransaction searches the transactions matching params and calls fn with each of them as the response
+// is read, so a month of transactions is never held in memory, not even a whole page. Periods longer than the
+// 31 days a search can cover are searched in consecutive windows. An error returned by fn stops the search
+// Endpoint: GET /v1/reporting/transactions
+func (c *Client) ForEachTransaction(params *TransactionSearchRequest, fn func(details *SearchTransactionDetails) error) error {
+	if params == nil || params.StartDate.IsZero() || params.EndDate.IsZero() {
+		return fmt.Errorf("paypal: start_date and end_date are required to search transactions")
+	}
+
+	return transactionWindows(params, func(search *TransactionSearchRequest) error {
+		for {
+			req, err := c.transactionsRequest(search)
+			if err != nil {
+				return err
+			}
+
+			page := &TransactionSearchResponse{}
+			stream := &transactionStream{page: page, fn: fn, report: c.unknownFieldsReporter(req, page)}
+			if err := c.SendWithAuth(req, stream); err != nil {
+				return err
+			}
+			if page.Page >= page.TotalPages {
+				return nil
+			}
+			search.Page = uint64(page.Page) + 1
+		}
+	})
+}
+
+// decodeArrayStream decodes the JSON object read from r into v, except for the elements of the array field
+// which are passed to item one at a time as they are read instead of being collected in v.
+// When report is set, it is called once the object is read with the fields neither v nor the elements map,
+// with the paths the unknown fields callback receives for responses decoded at once
+func decodeArrayStream(r io.Reader, v interface{}, field string, item func(raw json.RawMessage) error, report func(fields []string)) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	var found map[string]bool
+	var elemType reflect.Type
+	if report != nil {
+		found = map[string]bool{}
+		elemType = arrayElemType(reflect.TypeOf(v), field)
+	}
+
+	rest := map[string]json.RawMessage{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+
+		if key != field {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+			rest[key] = raw
+			continue
+		}
+
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok == nil {
+			continue
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("paypal: expected an array in %s, got %v", field, tok)
+		}
+		for dec.More() {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+			if elemType != nil {
+				collectUnknownJSONFields(raw, elemType, field+"[]", found)
+			}
+			if err := item(raw); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(rest)
+	if err != nil {
+		return err
+	}
+	if err := decodeJSON(data, v); err != nil {
+		return err
+	}
+
+	if report != nil {
+		collectUnknownJSONFields(data, reflect.TypeOf(v), "", found)
+		if len(found) > 0 {
+			report(sortedFields(found))
+		}
+	}
+	return nil
+}
+
+// arrayElemType returns the element type of the array field of the struct type t, nil when it has none
+func arrayElemType(t reflect.Type, field string) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	ft, ok := jsonFields(t)[field]
+	if !ok || (ft.Kind() != reflect.Slice && ft.Kind() != reflect.Array) {
+		return nil
+	}
+	return ft.Elem()
+}
+
+// expectDelim reads the next token, failing when it is not the delimiter
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("paypal: expected %v in the response, got %v", delim, tok)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package paypal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// transactionPage returns a transaction search page of n transactions as PayPal sends it
+func transactionPage(page, totalPages, n int) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"transaction_details":[`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(buf, `{"transaction_info":{"paypal_account_id":"6STWC2LSUYYYE","transaction_id":"5TY05013RG%07d","transaction_event_code":"T0006","transaction_initiation_date":"2020-01-15T10:00:00+0000","transaction_updated_date":"2020-01-15T10:00:00+0000","transaction_amount":{"currency_code":"USD","value":"10.00"},"fee_amount":{"currency_code":"USD","value":"-0.59"},"transaction_status":"S","custom_field":"order-%d"},"payer_info":{"account_id":"6STWC2LSUYYYE","email_address":"buyer@example.com","payer_name":{"given_name":"John","surname":"Doe"}}}`, page*n+i, i)
+	}
+	fmt.Fprintf(buf, `],"account_number":"XZXSPECPDZHZU","start_date":"2020-01-01T00:00:00+0000","end_date":"2020-01-31T00:00:00+0000","last_refreshed_datetime":"2020-02-01T00:00:00+0000","page":%d,"total_items":%d,"total_pages":%d,"links":[]}`, page, totalPages*n, totalPages)
+	return buf.Bytes()
+}
+
+func TestDecodeArrayStream(t *testing.T) {
+	page := &TransactionSearchResponse{}
+	var ids []string
+	err := decodeArrayStream(bytes.NewReader(transactionPage(2, 3, 4)), page, "transaction_details", func(raw json.RawMessage) error {
+		details := &SearchTransactionDetails{}
+		if err := json.Unmarshal(raw, details); err != nil {
+			return err
+		}
+		ids = append(ids, details.TransactionInfo.TransactionID)
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 4 || ids[0] != "5TY05013RG0000008" || page.Page != 2 || page.TotalPages != 3 || page.AccountNumber != "XZXSPECPDZHZU" || page.TransactionDetails != nil {
+		t.Errorf("unexpected page %+v with transactions %v", page, ids)
+	}
+
+	stop := errors.New("stop")
+	err = decodeArrayStream(bytes.NewReader(transactionPage(1, 1, 4)), page, "transaction_details", func(raw json.RawMessage) error {
+		return stop
+	}, nil)
+	if err != stop {
+		t.Errorf("expected the error of the callback, got %v", err)
+	}
+
+	for _, data := range []string{`[]`, `{"transaction_details":{}}`, `{"transaction_details":[{"transaction_info":`} {
+		if err := decodeArrayStream(bytes.NewReader([]byte(data)), page, "transaction_details", func(json.RawMessage) error { return nil }, nil); err == nil {
+			t.Errorf("expected an error for %s", data)
+		}
+	}
+	if err := decodeArrayStream(bytes.NewReader([]byte(`{"transaction_details":null,"page":1}`)), page, "transaction_details", nil, nil); err != nil || page.Page != 1 {
+		t.Errorf("expected null to be skipped, got %+v, %v", page, err)
+	}
+}
+
+func TestForEachTransaction(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		w.Write(transactionPage(page, 3, 2))
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	var ids []string
+	err := c.ForEachTransaction(&TransactionSearchRequest{
+		StartDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC),
+		PageSize:  2,
+	}, func(details *SearchTransactionDetails) error {
+		ids = append(ids, details.TransactionInfo.TransactionID)
+		return nil
+	})
+	if err != nil || len(ids) != 6 || ids[0] != "5TY05013RG0000002" || ids[5] != "5TY05013RG0000007" {
+		t.Errorf("expected the transactions of the 3 pages, got %v, %v", ids, err)
+	}
+}
+
+func TestForEachTransaction_UnknownFields(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"transaction_details":[{"transaction_info":{"transaction_id":"5TY05013RG002845M","transaction_score":"LOW"},"risk_info":{}}],"page":1,"total_pages":1,"report_version":"2"}`))
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	var reported []string
+	c.SetUnknownFieldsCallback(func(req *http.Request, v interface{}, fields []string) {
+		if _, ok := v.(*TransactionSearchResponse); !ok || req.URL.Path != "/v1/reporting/transactions" {
+			t.Errorf("unexpected report for %T %s", v, req.URL.Path)
+		}
+		reported = append(reported, fields...)
+	})
+
+	err := c.ForEachTransaction(&TransactionSearchRequest{
+		StartDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC),
+	}, func(*SearchTransactionDetails) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"report_version", "transaction_details[].risk_info", "transaction_details[].transaction_info.transaction_score"}
+	if !reflect.DeepEqual(reported, expected) {
+		t.Errorf("expected the unknown fields %v, got %v", expected, reported)
+	}
+}
+
+// The benchmarks decode a page of 500 transactions, the most a search returns, as a month-long report does page
+// after page. Compare the bytes allocated per page:
+//
+//	go test -bench DecodeTransactions -benchmem
+func BenchmarkDecodeTransactionsBuffered(b *testing.B) {
+	data := transactionPage(1, 1, 500)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		page := &TransactionSearchResponse{}
+		if err := json.NewDecoder(bytes.NewReader(data)).Decode(page); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeTransactionsStreamed(b *testing.B) {
+	data := transactionPage(1, 1, 500)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		page := &TransactionSearchResponse{}
+		stream := &transactionStream{page: page, fn: func(*SearchTransactionDetails) error { return nil }}
+		if err := stream.decodeFrom(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
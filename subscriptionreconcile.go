@@ -0,0 +1,332 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Possible values for `Type` in SubscriptionChargeIssue
+const (
+	ChargeIssueMissed         string = "MISSED"          // No charge was found for a billing cycle that is due
+	ChargeIssueDuplicate      string = "DUPLICATE"       // More charges than expected were found for a billing cycle
+	ChargeIssueAmountMismatch string = "AMOUNT_MISMATCH" // The charged amount differs from the plan pricing
+	ChargeIssueUnexpected     string = "UNEXPECTED"      // A charge was found for a free cycle or after the last cycle
+	ChargeIssueUnrecorded     string = "UNRECORDED"      // A PAYMENT.SALE.COMPLETED webhook has no matching transaction
+)
+
+type (
+	// SubscriptionReconciliationParams represents the window and webhook history used to reconcile a subscription
+	// Grace is how long after a billing time a missing charge is tolerated, PayPal retries failed payments for a few days.
+	// Events is the webhook history recorded for the subscription, only PAYMENT.SALE.COMPLETED events are used.
+	SubscriptionReconciliationParams struct {
+		StartTime time.Time
+		EndTime   time.Time
+		Grace     time.Duration
+		Events    []*Event
+	}
+
+	// SubscriptionChargeIssue represents a single discrepancy found while reconciling a subscription,
+	// BillingTime is nil for the issues not tied to a billing cycle
+	SubscriptionChargeIssue struct {
+		Type          string         `json:"type"`
+		CycleSequence uint64         `json:"cycle_sequence,omitempty"`
+		TenureType    TenureType     `json:"tenure_type,omitempty"`
+		BillingTime   *time.Time     `json:"billing_time,omitempty"`
+		Expected      *Money         `json:"expected,omitempty"`
+		Transactions  []*Transaction `json:"transactions,omitempty"`
+		SaleID        string         `json:"sale_id,omitempty"`
+	}
+
+	// SubscriptionReconciliationReport represents the result of reconciling subscription charges with plan pricing.
+	// ExpectedCharges counts the charges due in the window, the setup fee is charged on top of the first cycle
+	SubscriptionReconciliationReport struct {
+		SubscriptionID   string                    `json:"subscription_id"`
+		PlanID           string                    `json:"plan_id"`
+		StartTime        time.Time                 `json:"start_time"`
+		EndTime          time.Time                 `json:"end_time"`
+		ExpectedCharges  int                       `json:"expected_charges"`
+		CompletedCharges int                       `json:"completed_charges"`
+		Issues           []SubscriptionChargeIssue `json:"issues,omitempty"`
+	}
+
+	// scheduledCharge is a single billing time derived from the plan billing cycles
+	scheduledCharge struct {
+		time       time.Time
+		next       time.Time
+		sequence   uint64
+		tenureType TenureType
+		price      *Money
+	}
+)
+
+// OK reports whether the reconciliation found no issues
+func (r *SubscriptionReconciliationReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// ReconcileSubscription fetches the subscription, its plan and all the pages of its transactions for the given
+// window and cross-references them with the plan pricing and the supplied webhook history.
+// Endpoint: GET /v1/billing/subscriptions/{subscription_id}/transactions
+func (c *Client) ReconcileSubscription(subscriptionID string, params *SubscriptionReconciliationParams) (*SubscriptionReconciliationReport, error) {
+	if params == nil || params.StartTime.IsZero() || params.EndTime.IsZero() {
+		return nil, fmt.Errorf("paypal: StartTime and EndTime are required to reconcile subscription %s", subscriptionID)
+	}
+
+	subscription, err := c.ShowSubscription(subscriptionID, &ShowSubscriptionRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := c.ShowPlan(subscription.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transactions of long windows span several pages
+	query := url.Values{}
+	query.Set("start_time", params.StartTime.UTC().Format(time.RFC3339))
+	query.Set("end_time", params.EndTime.UTC().Format(time.RFC3339))
+	var transactions []*Transaction
+	err = c.streamLinkedPages(context.Background(), "/v1/billing/subscriptions/"+subscriptionID+"/transactions", query, func(page *linkedPage) error {
+		transactions = append(transactions, page.Transactions...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ReconcileSubscriptionCharges(subscription, plan, transactions, params)
+}
+
+// ReconcileSubscriptionCharges matches subscription transactions against the billing schedule of the plan.
+// Every billing time between params.StartTime and params.EndTime is expected to have exactly one completed charge
+// at the price of its billing cycle (the first cycle may also carry the setup fee). Charges are assigned to the
+// billing period they fall into, so a late retry is still counted for the cycle it belongs to.
+func ReconcileSubscriptionCharges(subscription *Subscription, plan *Plan, transactions []*Transaction, params *SubscriptionReconciliationParams) (*SubscriptionReconciliationReport, error) {
+	if subscription == nil || plan == nil || params == nil {
+		return nil, fmt.Errorf("paypal: subscription, plan and params are required for reconciliation")
+	}
+
+	start, err := time.Parse(time.RFC3339, subscription.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("paypal: invalid start_time %q on subscription %s: %v", subscription.StartTime, subscription.ID, err)
+	}
+
+	report := &SubscriptionReconciliationReport{
+		SubscriptionID: subscription.ID,
+		PlanID:         plan.ID,
+		StartTime:      params.StartTime,
+		EndTime:        params.EndTime,
+	}
+
+	schedule, err := billingSchedule(plan, start, params.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var setupFee *Money
+	if plan.PaymentPreferences != nil {
+		setupFee = plan.PaymentPreferences.SetupFee
+	}
+
+	// Assign every charged transaction to the billing period it falls into
+	charged := make([][]*Transaction, len(schedule))
+	var unexpected []*Transaction
+	transactionIDs := map[string]bool{}
+	for _, t := range transactions {
+		if t == nil {
+			continue
+		}
+		transactionIDs[t.ID] = true
+		if !isChargedTransaction(t) {
+			continue
+		}
+		report.CompletedCharges++
+
+		tt, err := time.Parse(time.RFC3339, t.Time)
+		if err != nil {
+			return nil, fmt.Errorf("paypal: invalid time %q on transaction %s: %v", t.Time, t.ID, err)
+		}
+
+		i := sort.Search(len(schedule), func(i int) bool { return schedule[i].next.After(tt) })
+		if i == len(schedule) {
+			unexpected = append(unexpected, t)
+			continue
+		}
+		charged[i] = append(charged[i], t)
+	}
+
+	for i, s := range schedule {
+		if s.time.Before(params.StartTime) || s.time.After(params.EndTime) {
+			continue
+		}
+
+		expectedCount := 1
+		if s.price == nil {
+			expectedCount = 0
+		}
+		if i == 0 && setupFee != nil {
+			expectedCount++
+		}
+		report.ExpectedCharges += expectedCount
+
+		billingTime := s.time
+		issue := SubscriptionChargeIssue{
+			CycleSequence: s.sequence,
+			TenureType:    s.tenureType,
+			BillingTime:   &billingTime,
+			Expected:      s.price,
+			Transactions:  charged[i],
+		}
+
+		switch {
+		case len(charged[i]) == 0 && expectedCount > 0:
+			if s.time.Add(params.Grace).Before(params.EndTime) {
+				issue.Type = ChargeIssueMissed
+				report.Issues = append(report.Issues, issue)
+			}
+			continue
+		case expectedCount == 0 && len(charged[i]) > 0:
+			issue.Type = ChargeIssueUnexpected
+			report.Issues = append(report.Issues, issue)
+			continue
+		case len(charged[i]) > expectedCount:
+			issue.Type = ChargeIssueDuplicate
+			report.Issues = append(report.Issues, issue)
+		}
+
+		for _, t := range charged[i] {
+			if t.AmountWithBreakdown == nil || !(moneyEqual(t.AmountWithBreakdown.GrossAmount, s.price) ||
+				(i == 0 && moneyEqual(t.AmountWithBreakdown.GrossAmount, setupFee))) {
+				report.Issues = append(report.Issues, SubscriptionChargeIssue{
+					Type:          ChargeIssueAmountMismatch,
+					CycleSequence: s.sequence,
+					TenureType:    s.tenureType,
+					BillingTime:   &billingTime,
+					Expected:      s.price,
+					Transactions:  []*Transaction{t},
+				})
+			}
+		}
+	}
+
+	if len(unexpected) > 0 {
+		report.Issues = append(report.Issues, SubscriptionChargeIssue{
+			Type:         ChargeIssueUnexpected,
+			Transactions: unexpected,
+		})
+	}
+
+	for _, e := range params.Events {
+		if e == nil || e.EventType != EventPaymentSaleCompleted {
+			continue
+		}
+		sale, err := e.SaleResource()
+		if err != nil {
+			return nil, err
+		}
+		if sale.BillingAgreementID != subscription.ID || transactionIDs[sale.ID] {
+			continue
+		}
+		report.Issues = append(report.Issues, SubscriptionChargeIssue{
+			Type:   ChargeIssueUnrecorded,
+			SaleID: sale.ID,
+		})
+	}
+
+	return report, nil
+}
+
+// billingSchedule expands the plan billing cycles into billing times starting at start until end
+func billingSchedule(plan *Plan, start, end time.Time) ([]scheduledCharge, error) {
+	cycles := make([]*BillingCycle, 0, len(plan.BillingCycles))
+	for _, cycle := range plan.BillingCycles {
+		if cycle != nil {
+			cycles = append(cycles, cycle)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].Sequence < cycles[j].Sequence })
+
+	var schedule []scheduledCharge
+	t := start
+	for _, cycle := range cycles {
+		if cycle.Frequency == nil {
+			return nil, fmt.Errorf("paypal: billing cycle %d of plan %s has no frequency", cycle.Sequence, plan.ID)
+		}
+
+		var price *Money
+		if cycle.PricingScheme != nil {
+			price = cycle.PricingScheme.FixedPrice
+		}
+
+		for n := uint64(0); cycle.TotalCycles == 0 || n < cycle.TotalCycles; n++ {
+			if t.After(end) {
+				return schedule, nil
+			}
+			next, err := addBillingInterval(t, cycle.Frequency)
+			if err != nil {
+				return nil, err
+			}
+			schedule = append(schedule, scheduledCharge{
+				time:       t,
+				next:       next,
+				sequence:   cycle.Sequence,
+				tenureType: cycle.TenureType,
+				price:      price,
+			})
+			t = next
+		}
+	}
+
+	return schedule, nil
+}
+
+// addBillingInterval moves t forward by one billing interval of frequency
+func addBillingInterval(t time.Time, frequency *Frequency) (time.Time, error) {
+	count := int(frequency.IntervalCount)
+	if count == 0 {
+		count = 1
+	}
+
+	switch frequency.IntervalUnit {
+	case IntervalUnitDay:
+		return t.AddDate(0, 0, count), nil
+	case IntervalUnitWeek:
+		return t.AddDate(0, 0, 7*count), nil
+	case IntervalUnitMonth:
+		return t.AddDate(0, count, 0), nil
+	case IntervalUnitYear:
+		return t.AddDate(count, 0, 0), nil
+	}
+
+	return t, fmt.Errorf("paypal: invalid interval_unit %q", frequency.IntervalUnit)
+}
+
+// isChargedTransaction reports whether money was actually collected for the subscription transaction
+func isChargedTransaction(t *Transaction) bool {
+	return CaptureStatus(t.Status).IsSuccessful()
+}
+
+// moneyEqual compares two amounts numerically, so "10" equals "10.00"
+func moneyEqual(a, b *Money) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Currency != b.Currency {
+		return false
+	}
+
+	x, ok := parseSignedDecimal(a.Value)
+	if !ok {
+		return false
+	}
+	y, ok := parseSignedDecimal(b.Value)
+	if !ok {
+		return false
+	}
+
+	return x.Cmp(y) == 0
+}
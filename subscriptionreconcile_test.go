@@ -0,0 +1,187 @@
+package paypal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReconciliationPlan() *Plan {
+	return &Plan{
+		ID: "P-5ML4271244454362WXNWU5NQ",
+		BillingCycles: []*BillingCycle{
+			{
+				Frequency:   &Frequency{IntervalUnit: IntervalUnitMonth, IntervalCount: 1},
+				TenureType:  TenureTypeTrial,
+				Sequence:    1,
+				TotalCycles: 1,
+			},
+			{
+				PricingScheme: &PricingScheme{FixedPrice: &Money{Currency: "USD", Value: "10.00"}},
+				Frequency:     &Frequency{IntervalUnit: IntervalUnitMonth, IntervalCount: 1},
+				TenureType:    TenureTypeRegular,
+				Sequence:      2,
+			},
+		},
+	}
+}
+
+func testReconciliationTransaction(id, status, tm, value string) *Transaction {
+	return &Transaction{
+		ID:     id,
+		Status: status,
+		Time:   tm,
+		AmountWithBreakdown: &AmountWithBreakdown{
+			GrossAmount: &Money{Currency: "USD", Value: value},
+		},
+	}
+}
+
+func TestReconcileSubscriptionCharges(t *testing.T) {
+	subscription := &Subscription{ID: "I-BW452GLLEP1G", PlanID: "P-5ML4271244454362WXNWU5NQ", StartTime: "2020-01-01T00:00:00Z"}
+	params := &SubscriptionReconciliationParams{
+		StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2020, 5, 3, 0, 0, 0, 0, time.UTC),
+		Grace:     5 * 24 * time.Hour,
+		Events: []*Event{
+			{EventType: EventPaymentSaleCompleted, Resource: []byte(`{"id":"SALE-UNKNOWN","billing_agreement_id":"I-BW452GLLEP1G"}`)},
+			{EventType: EventPaymentSaleCompleted, Resource: []byte(`{"id":"TX-2","billing_agreement_id":"I-BW452GLLEP1G"}`)},
+		},
+	}
+
+	transactions := []*Transaction{
+		// Feb cycle: charged twice
+		testReconciliationTransaction("TX-2", "COMPLETED", "2020-02-01T10:00:00Z", "10.00"),
+		testReconciliationTransaction("TX-3", "COMPLETED", "2020-02-03T10:00:00Z", "10"),
+		// Mar cycle: declined only, so missed
+		testReconciliationTransaction("TX-4", "DECLINED", "2020-03-01T10:00:00Z", "10.00"),
+		// Apr cycle: wrong amount
+		testReconciliationTransaction("TX-5", "COMPLETED", "2020-04-01T10:00:00Z", "12.00"),
+		// May cycle is still within grace period
+	}
+
+	report, err := ReconcileSubscriptionCharges(subscription, testReconciliationPlan(), transactions, params)
+	if err != nil {
+		t.Fatalf("Not expected error, got %v", err)
+	}
+
+	if report.ExpectedCharges != 4 || report.CompletedCharges != 3 {
+		t.Errorf("expected 4 expected and 3 completed charges, got %d and %d", report.ExpectedCharges, report.CompletedCharges)
+	}
+
+	var types []string
+	for _, issue := range report.Issues {
+		types = append(types, issue.Type)
+	}
+	expected := []string{ChargeIssueDuplicate, ChargeIssueMissed, ChargeIssueAmountMismatch, ChargeIssueUnrecorded}
+	if len(types) != len(expected) {
+		t.Fatalf("expected issues %v, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Errorf("expected issues %v, got %v", expected, types)
+		}
+	}
+
+	if report.Issues[3].SaleID != "SALE-UNKNOWN" {
+		t.Errorf("expected unrecorded sale SALE-UNKNOWN, got %s", report.Issues[3].SaleID)
+	}
+	if report.OK() {
+		t.Errorf("expected report with issues not to be OK")
+	}
+}
+
+func TestReconcileSubscriptionChargesClean(t *testing.T) {
+	subscription := &Subscription{ID: "I-BW452GLLEP1G", StartTime: "2020-01-01T00:00:00Z"}
+	params := &SubscriptionReconciliationParams{
+		StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2020, 3, 10, 0, 0, 0, 0, time.UTC),
+	}
+	transactions := []*Transaction{
+		testReconciliationTransaction("TX-2", "COMPLETED", "2020-02-01T10:00:00Z", "10.00"),
+		testReconciliationTransaction("TX-3", "PARTIALLY_REFUNDED", "2020-03-01T10:00:00Z", "10.00"),
+	}
+
+	report, err := ReconcileSubscriptionCharges(subscription, testReconciliationPlan(), transactions, params)
+	if err != nil {
+		t.Fatalf("Not expected error, got %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("expected no issues, got %+v", report.Issues)
+	}
+}
+
+func TestReconcileSubscriptionChargesSetupFee(t *testing.T) {
+	subscription := &Subscription{ID: "I-BW452GLLEP1G", StartTime: "2020-01-01T00:00:00Z"}
+	plan := testReconciliationPlan()
+	plan.PaymentPreferences = &PaymentPreferences{SetupFee: &Money{Currency: "USD", Value: "5.00"}}
+	params := &SubscriptionReconciliationParams{
+		StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2020, 2, 10, 0, 0, 0, 0, time.UTC),
+	}
+	transactions := []*Transaction{
+		testReconciliationTransaction("TX-1", "COMPLETED", "2020-01-01T00:05:00Z", "5.00"),
+		testReconciliationTransaction("TX-2", "COMPLETED", "2020-02-01T10:00:00Z", "10.00"),
+		testReconciliationTransaction("TX-3", "COMPLETED", "2020-02-02T10:00:00Z", "10.00"),
+	}
+
+	report, err := ReconcileSubscriptionCharges(subscription, plan, transactions, params)
+	if err != nil {
+		t.Fatalf("Not expected error, got %v", err)
+	}
+	if report.ExpectedCharges != 2 || report.CompletedCharges != 3 {
+		t.Errorf("expected the setup fee to be an expected charge, got %d expected and %d completed", report.ExpectedCharges, report.CompletedCharges)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Type != ChargeIssueDuplicate || report.Issues[0].BillingTime == nil ||
+		!report.Issues[0].BillingTime.Equal(time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the February charge to be duplicated, got %+v", report.Issues)
+	}
+
+	data, _ := json.Marshal(SubscriptionChargeIssue{Type: ChargeIssueUnrecorded, SaleID: "80021663DE681814L"})
+	if strings.Contains(string(data), "billing_time") {
+		t.Errorf("expected billing_time to be omitted, got %s", data)
+	}
+}
+
+func TestReconcileSubscription(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/billing/subscriptions/I-BW452GLLEP1G":
+			fmt.Fprint(w, `{"id":"I-BW452GLLEP1G","plan_id":"P-5ML4271244454362WXNWU5NQ","status":"ACTIVE","start_time":"2020-01-01T00:00:00Z"}`)
+		case "/v1/billing/plans/P-5ML4271244454362WXNWU5NQ":
+			json.NewEncoder(w).Encode(testReconciliationPlan())
+		case "/v1/billing/subscriptions/I-BW452GLLEP1G/transactions":
+			if r.URL.Query().Get("start_time") != "2020-01-01T00:00:00Z" || r.URL.Query().Get("end_time") != "2020-03-10T00:00:00Z" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, `{"transactions":[{"id":"TX-3","status":"COMPLETED","time":"2020-03-01T10:00:00Z","amount_with_breakdown":{"gross_amount":{"currency_code":"USD","value":"10.00"}}}],"total_items":2,"total_pages":2}`)
+				return
+			}
+			fmt.Fprintf(w, `{"transactions":[{"id":"TX-2","status":"COMPLETED","time":"2020-02-01T10:00:00Z","amount_with_breakdown":{"gross_amount":{"currency_code":"USD","value":"10.00"}}}],"total_items":2,"total_pages":2,"links":[{"href":"%s%s?%s&page=2","rel":"next","method":"GET"}]}`, ts.URL, r.URL.Path, r.URL.RawQuery)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	report, err := c.ReconcileSubscription("I-BW452GLLEP1G", &SubscriptionReconciliationParams{
+		StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2020, 3, 10, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Not expected error for ReconcileSubscription, got %v", err)
+	}
+	if report.CompletedCharges != 2 || !report.OK() {
+		t.Errorf("expected the charges of both pages to be reconciled, got %+v", report)
+	}
+}
@@ -1,12 +1,23 @@
 package paypal
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
 
 // CreateSubscription - Use this call to create a subscription
 // Endpoint: POST /v1/billing/subscriptions
 func (c *Client) CreateSubscription(subscription *CreateSubscriptionRequest) (*Subscription, error) {
 	resp := &Subscription{}
 
+	if subscription == nil {
+		return nil, fmt.Errorf("paypal: a subscription is required to create a subscription")
+	}
+	if err := c.validate(subscription); err != nil {
+		return nil, err
+	}
+
 	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/subscriptions"), subscription)
 	if err != nil {
 		return nil, err
@@ -22,6 +33,12 @@ func (c *Client) CreateSubscription(subscription *CreateSubscriptionRequest) (*S
 // ShowSubscription shows details for a subscription by ID
 // Endpoint: GET /v1/billing/subscriptions/{subscription_id}
 func (c *Client) ShowSubscription(subscriptionID string, params *ShowSubscriptionRequest) (*Subscription, error) {
+	return c.ShowSubscriptionContext(context.Background(), subscriptionID, params)
+}
+
+// ShowSubscriptionContext is ShowSubscription with the request canceled with ctx
+// Endpoint: GET /v1/billing/subscriptions/{subscription_id}
+func (c *Client) ShowSubscriptionContext(ctx context.Context, subscriptionID string, params *ShowSubscriptionRequest) (*Subscription, error) {
 	resp := &Subscription{}
 
 	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/subscriptions/"+subscriptionID), nil)
@@ -32,7 +49,7 @@ func (c *Client) ShowSubscription(subscriptionID string, params *ShowSubscriptio
 	q := req.URL.Query()
 	q.Add("fields", params.Fields)
 
-	if err = c.SendWithBasicAuth(req, resp); err != nil {
+	if err = c.SendWithBasicAuth(req.WithContext(ctx), resp); err != nil {
 		return nil, err
 	}
 
@@ -42,6 +59,10 @@ func (c *Client) ShowSubscription(subscriptionID string, params *ShowSubscriptio
 // ActivatePlan activates subscription by ID
 // Endpoint: POST /v1/billing/subscriptions/{subscription_id}/activate
 func (c *Client) ActivateSubscription(subscriptionID string, body UpdateSubscriptionStatusRequest) error {
+	if err := c.validate((*activateSubscriptionRequest)(&body)); err != nil {
+		return err
+	}
+
 	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/subscriptions/"+subscriptionID+"/activate"), body)
 	if err != nil {
 		return err
@@ -53,23 +74,43 @@ func (c *Client) ActivateSubscription(subscriptionID string, body UpdateSubscrip
 // CancelSubscription cancels subscription by ID
 // Endpoint: POST /v1/billing/subscriptions/{subscription_id}/cancel
 func (c *Client) CancelSubscription(subscriptionID string, body *UpdateSubscriptionStatusRequest) error {
+	return c.CancelSubscriptionContext(context.Background(), subscriptionID, body)
+}
+
+// CancelSubscriptionContext is CancelSubscription with the request canceled with ctx
+// Endpoint: POST /v1/billing/subscriptions/{subscription_id}/cancel
+func (c *Client) CancelSubscriptionContext(ctx context.Context, subscriptionID string, body *UpdateSubscriptionStatusRequest) error {
+	if err := c.validate(body); err != nil {
+		return err
+	}
+
 	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/subscriptions/"+subscriptionID+"/cancel"), body)
 	if err != nil {
 		return err
 	}
 
-	return c.SendWithBasicAuth(req, nil)
+	return c.SendWithBasicAuth(req.WithContext(ctx), nil)
 }
 
 // SuspendSubscription suspends subscription by ID
 // Endpoint: POST /v1/billing/subscriptions/{subscription_id}/suspend
 func (c *Client) SuspendSubscription(subscriptionID string, body *UpdateSubscriptionStatusRequest) error {
+	return c.SuspendSubscriptionContext(context.Background(), subscriptionID, body)
+}
+
+// SuspendSubscriptionContext is SuspendSubscription with the request canceled with ctx
+// Endpoint: POST /v1/billing/subscriptions/{subscription_id}/suspend
+func (c *Client) SuspendSubscriptionContext(ctx context.Context, subscriptionID string, body *UpdateSubscriptionStatusRequest) error {
+	if err := c.validate(body); err != nil {
+		return err
+	}
+
 	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/billing/subscriptions/"+subscriptionID+"/suspend"), body)
 	if err != nil {
 		return err
 	}
 
-	return c.SendWithBasicAuth(req, nil)
+	return c.SendWithBasicAuth(req.WithContext(ctx), nil)
 }
 
 // UpdateSubscription updates subscription by ID
@@ -114,6 +155,7 @@ func (c *Client) ListTransactionsForSubscription(subscriptionID string, params *
 	q := req.URL.Query()
 	q.Add("start_time", params.StartTime)
 	q.Add("end_time", params.EndTime)
+	req.URL.RawQuery = q.Encode()
 
 	if err = c.SendWithBasicAuth(req, resp); err != nil {
 		return nil, err
@@ -138,4 +180,54 @@ func (c *Client) ReviseSubscription(subscriptionID string, body *ReviseSubscript
 	}
 
 	return resp, nil
-}
\ No newline at end of file
+}
+
+// Validate checks the subscription against the constraints documented by PayPal
+func (r *CreateSubscriptionRequest) Validate() error {
+	if err := checkLength("plan_id", r.PlanID, 3, 50); err != nil {
+		return err
+	}
+	if _, err := parseTimestamp(r.StartTime); err != nil {
+		return &ValidationError{Field: "start_time", Reason: fmt.Sprintf("%q is not a valid timestamp", r.StartTime)}
+	}
+	if r.Quantity != "" {
+		if quantity, ok := new(big.Int).SetString(r.Quantity, 10); !ok || quantity.Sign() <= 0 || len(r.Quantity) > 32 {
+			return &ValidationError{Field: "quantity", Reason: fmt.Sprintf("%q is not a positive whole number of at most 32 digits", r.Quantity)}
+		}
+	}
+	if r.ShippingAmount != nil {
+		if err := checkMoney("shipping_amount", r.ShippingAmount); err != nil {
+			return err
+		}
+	}
+	if r.Subscriber != nil {
+		if err := checkLength("subscriber.email_address", r.Subscriber.EmailAddress, 0, 254); err != nil {
+			return err
+		}
+		if r.Subscriber.ShippingAddress != nil && r.Subscriber.ShippingAddress.Address != nil {
+			if err := r.Subscriber.ShippingAddress.Address.Validate(); err != nil {
+				return nestedError("subscriber.shipping_address.address", err)
+			}
+		}
+	}
+	if r.ApplicationContext != nil {
+		return nestedError("application_context", r.ApplicationContext.Validate())
+	}
+	return nil
+}
+
+// Validate checks the reason PayPal requires to suspend or cancel a subscription
+func (r *UpdateSubscriptionStatusRequest) Validate() error {
+	if r == nil {
+		return &ValidationError{Field: "reason", Reason: "is required"}
+	}
+	return checkLength("reason", r.Reason, 1, 128)
+}
+
+// activateSubscriptionRequest is the body of ActivateSubscription, its reason is optional
+type activateSubscriptionRequest UpdateSubscriptionStatusRequest
+
+// Validate checks the length of the reason
+func (r *activateSubscriptionRequest) Validate() error {
+	return checkLength("reason", r.Reason, 0, 128)
+}
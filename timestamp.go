@@ -0,0 +1,176 @@
+package paypal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampError is returned when a response carries a timestamp in a format PayPal is not known to send.
+// Field is the path of the timestamp in the response, e.g. purchase_units[0].payments.captures[0].create_time
+type TimestampError struct {
+	Field string
+	Value string
+}
+
+// timestampLayouts lists the timestamp formats PayPal sends: RFC3339 with or without fractional seconds,
+// offsets without a colon as sent by the v1 billing agreements, timestamps without zone and plain dates
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func (e *TimestampError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("paypal: invalid timestamp %q", e.Value)
+	}
+	return fmt.Sprintf("paypal: invalid timestamp %q in %s", e.Value, e.Field)
+}
+
+// parseTimestamp parses a PayPal timestamp, timestamps without zone are taken as UTC
+func parseTimestamp(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	var err error
+	for _, layout := range timestampLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// decodeJSON decodes data into v, a TimestampError is completed with the path of the invalid timestamp
+func decodeJSON(data []byte, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	if tsErr, ok := err.(*TimestampError); ok && tsErr.Field == "" {
+		tsErr.Field = timestampPath(json.NewDecoder(bytes.NewReader(data)), reflect.TypeOf(v), "")
+	}
+	return err
+}
+
+var jsonTimeType = reflect.TypeOf(JSONTime{})
+
+// timestampPath reads the next value from dec as json.Unmarshal decodes it into t, and returns the path of
+// the first JSONTime that fails to decode, or an empty string. The value is walked along with its type so
+// the path names the field JSONTime.UnmarshalJSON failed for, not another field with the same value
+func timestampPath(dec *json.Decoder, t reflect.Type, path string) string {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return ""
+	}
+	if t == jsonTimeType {
+		if s, ok := tok.(string); ok {
+			if _, err := parseTimestamp(s); err == nil {
+				return ""
+			}
+		} else if tok == nil {
+			return ""
+		}
+		return path
+	}
+
+	switch tok {
+	case json.Delim('['):
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for i := 0; dec.More(); i++ {
+			if p := timestampPath(dec, elem, path+"["+strconv.Itoa(i)+"]"); p != "" {
+				return p
+			}
+		}
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return ""
+			}
+			name, _ := key.(string)
+
+			var field reflect.Type
+			if t != nil && t.Kind() == reflect.Struct {
+				field = jsonFieldType(t, name)
+			} else if t != nil && t.Kind() == reflect.Map {
+				field = t.Elem()
+			}
+
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			if p := timestampPath(dec, field, fieldPath); p != "" {
+				return p
+			}
+		}
+	default:
+		return ""
+	}
+
+	// The closing delimiter
+	dec.Token()
+	return ""
+}
+
+// jsonFieldType returns the type of the field of struct t that json.Unmarshal decodes the key into,
+// fields of embedded structs are looked up after the fields of t. It returns nil when there is none
+func jsonFieldType(t reflect.Type, key string) reflect.Type {
+	var folded reflect.Type
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		if name == key {
+			return f.Type
+		}
+		if folded == nil && strings.EqualFold(name, key) {
+			folded = f.Type
+		}
+	}
+	if folded != nil {
+		return folded
+	}
+
+	for _, e := range embedded {
+		if ft := jsonFieldType(e, key); ft != nil {
+			return ft
+		}
+	}
+	return nil
+}
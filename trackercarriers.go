@@ -0,0 +1,263 @@
+package paypal
+
+import "fmt"
+
+// Possible values for `status` in Tracker
+const (
+	TrackerStatusShipped     string = "SHIPPED"
+	TrackerStatusOnHold      string = "ON_HOLD"
+	TrackerStatusDelivered   string = "DELIVERED"
+	TrackerStatusCancelled   string = "CANCELLED"
+	TrackerStatusLocalPickup string = "LOCAL_PICKUP"
+)
+
+// Possible values for `tracking_number_type` in Tracker
+const (
+	TrackingNumberTypeCarrierProvided    string = "CARRIER_PROVIDED"
+	TrackingNumberTypeE2EPartnerProvided string = "E2E_PARTNER_PROVIDED"
+)
+
+// Possible values for `shipment_direction` in Tracker
+const (
+	ShipmentDirectionForward string = "FORWARD"
+	ShipmentDirectionReturn  string = "RETURN"
+)
+
+// Possible values for `carrier` in Tracker, carriers missing from the list are sent as TrackerCarrierOther
+// with their name in `carrier_name_other`
+const (
+	TrackerCarrierAcommerce           string = "ACOMMERCE"
+	TrackerCarrierAirborneExpress     string = "AIRBORNE_EXPRESS"
+	TrackerCarrierAramex              string = "ARAMEX"
+	TrackerCarrierAsendiaHK           string = "ASENDIA_HK"
+	TrackerCarrierAsendiaUK           string = "ASENDIA_UK"
+	TrackerCarrierAsendiaUSA          string = "ASENDIA_USA"
+	TrackerCarrierAupostCN            string = "AUPOST_CN"
+	TrackerCarrierAUAustraliaPost     string = "AU_AUSTRALIA_POST"
+	TrackerCarrierAUAUPost            string = "AU_AU_POST"
+	TrackerCarrierAUCourierPlease     string = "AU_COURIER_PLEASE"
+	TrackerCarrierAUFastway           string = "AU_FASTWAY"
+	TrackerCarrierAUStarTrack         string = "AU_STAR_TRACK"
+	TrackerCarrierAUTNT               string = "AU_TNT"
+	TrackerCarrierBEBpost             string = "BE_BPOST"
+	TrackerCarrierBEKiala             string = "BE_KIALA"
+	TrackerCarrierBGBulgarianPost     string = "BG_BULGARIAN_POST"
+	TrackerCarrierBluedart            string = "BLUEDART"
+	TrackerCarrierBpostInt            string = "BPOST_INT"
+	TrackerCarrierBRTIT               string = "BRT_IT"
+	TrackerCarrierCACanadaPost        string = "CA_CANADA_POST"
+	TrackerCarrierCACanpar            string = "CA_CANPAR"
+	TrackerCarrierCALoomis            string = "CA_LOOMIS"
+	TrackerCarrierCAPurolator         string = "CA_PUROLATOR"
+	TrackerCarrierCBLLogistica        string = "CBL_LOGISTICA"
+	TrackerCarrierCHSwissPostPriority string = "CH_SWISS_POST_PRIORITY"
+	TrackerCarrierCNChinaPostEMS      string = "CN_CHINA_POST_EMS"
+	TrackerCarrierCNEMS               string = "CN_EMS"
+	TrackerCarrierCNSFExpress         string = "CN_SF_EXPRESS"
+	TrackerCarrierCNYunexpress        string = "CN_YUNEXPRESS"
+	TrackerCarrierCollectplus         string = "COLLECTPLUS"
+	TrackerCarrierCorreosDeMexico     string = "CORREOS_DE_MEXICO"
+	TrackerCarrierCZCzechPost         string = "CZ_CZECH_POST"
+	TrackerCarrierDEDeutsche          string = "DE_DEUTSCHE"
+	TrackerCarrierDEDHLDeutschepost   string = "DE_DHL_DEUTSCHEPOST"
+	TrackerCarrierDEDPDDelistrack     string = "DE_DPD_DELISTRACK"
+	TrackerCarrierDEGLS               string = "DE_GLS"
+	TrackerCarrierDEHermes            string = "DE_HERMES"
+	TrackerCarrierDHL                 string = "DHL"
+	TrackerCarrierDHLAPI              string = "DHL_API"
+	TrackerCarrierDHLEcommerce        string = "DHL_ECOMMERCE"
+	TrackerCarrierDHLGlobalEcommerce  string = "DHL_GLOBAL_ECOMMERCE"
+	TrackerCarrierDHLGlobalMail       string = "DHL_GLOBAL_MAIL"
+	TrackerCarrierDKPostnord          string = "DK_POSTNORD"
+	TrackerCarrierDPD                 string = "DPD"
+	TrackerCarrierDPDUK               string = "DPD_UK"
+	TrackerCarrierEMS                 string = "EMS"
+	TrackerCarrierESCorreosDeEspana   string = "ES_CORREOS_DE_ESPANA"
+	TrackerCarrierESGLS               string = "ES_GLS"
+	TrackerCarrierFedex               string = "FEDEX"
+	TrackerCarrierFIItella            string = "FI_ITELLA"
+	TrackerCarrierFRChronopost        string = "FR_CHRONOPOST"
+	TrackerCarrierFRColiposte         string = "FR_COLIPOSTE"
+	TrackerCarrierFRColissimo         string = "FR_COLISSIMO"
+	TrackerCarrierFRGLS               string = "FR_GLS"
+	TrackerCarrierFRLaposte           string = "FR_LAPOSTE"
+	TrackerCarrierFRMondial           string = "FR_MONDIAL"
+	TrackerCarrierGBAPC               string = "GB_APC"
+	TrackerCarrierGBArrow             string = "GB_ARROW"
+	TrackerCarrierGBCollectplus       string = "GB_COLLECTPLUS"
+	TrackerCarrierGBDPD               string = "GB_DPD"
+	TrackerCarrierGBHermesworld       string = "GB_HERMESWORLD"
+	TrackerCarrierGBParcelforce       string = "GB_PARCELFORCE"
+	TrackerCarrierGBRoyalMail         string = "GB_ROYAL_MAIL"
+	TrackerCarrierGBTNT               string = "GB_TNT"
+	TrackerCarrierGBYodel             string = "GB_YODEL"
+	TrackerCarrierGLS                 string = "GLS"
+	TrackerCarrierHKHongkongPost      string = "HK_HONGKONG_POST"
+	TrackerCarrierIEAnPost            string = "IE_AN_POST"
+	TrackerCarrierINBluedart          string = "IN_BLUEDART"
+	TrackerCarrierINDelhivery         string = "IN_DELHIVERY"
+	TrackerCarrierINDTDC              string = "IN_DTDC"
+	TrackerCarrierINIndiapost         string = "IN_INDIAPOST"
+	TrackerCarrierITBRT               string = "IT_BRT"
+	TrackerCarrierITGLS               string = "IT_GLS"
+	TrackerCarrierITPosteItaliane     string = "IT_POSTE_ITALIANE"
+	TrackerCarrierITSDA               string = "IT_SDA"
+	TrackerCarrierJPJapanpost         string = "JP_JAPANPOST"
+	TrackerCarrierJPSagawa            string = "JP_SAGAWA"
+	TrackerCarrierJPYamato            string = "JP_YAMATO"
+	TrackerCarrierKRKoreaPost         string = "KR_KOREA_POST"
+	TrackerCarrierMXEstafeta          string = "MX_ESTAFETA"
+	TrackerCarrierNLDHL               string = "NL_DHL"
+	TrackerCarrierNLGLS               string = "NL_GLS"
+	TrackerCarrierNLPostnl            string = "NL_POSTNL"
+	TrackerCarrierNZCourierPost       string = "NZ_COURIER_POST"
+	TrackerCarrierNZNZPost            string = "NZ_NZ_POST"
+	TrackerCarrierOntrac              string = "ONTRAC"
+	TrackerCarrierPLPocztaPolska      string = "PL_POCZTA_POLSKA"
+	TrackerCarrierPTCTT               string = "PT_CTT"
+	TrackerCarrierRURussianPost       string = "RU_RUSSIAN_POST"
+	TrackerCarrierSEPostnord          string = "SE_POSTNORD"
+	TrackerCarrierSGSingpost          string = "SG_SINGPOST"
+	TrackerCarrierTNT                 string = "TNT"
+	TrackerCarrierUPS                 string = "UPS"
+	TrackerCarrierUPSMailInnovations  string = "UPS_MAIL_INNOVATIONS"
+	TrackerCarrierUSPS                string = "USPS"
+	TrackerCarrierYanwen              string = "YANWEN"
+	TrackerCarrierYunexpress          string = "YUNEXPRESS"
+	TrackerCarrierOther               string = "OTHER"
+)
+
+var trackerStatuses = map[string]bool{
+	TrackerStatusShipped:     true,
+	TrackerStatusOnHold:      true,
+	TrackerStatusDelivered:   true,
+	TrackerStatusCancelled:   true,
+	TrackerStatusLocalPickup: true,
+}
+
+var trackerCarriers = map[string]bool{
+	TrackerCarrierAcommerce:           true,
+	TrackerCarrierAirborneExpress:     true,
+	TrackerCarrierAramex:              true,
+	TrackerCarrierAsendiaHK:           true,
+	TrackerCarrierAsendiaUK:           true,
+	TrackerCarrierAsendiaUSA:          true,
+	TrackerCarrierAupostCN:            true,
+	TrackerCarrierAUAustraliaPost:     true,
+	TrackerCarrierAUAUPost:            true,
+	TrackerCarrierAUCourierPlease:     true,
+	TrackerCarrierAUFastway:           true,
+	TrackerCarrierAUStarTrack:         true,
+	TrackerCarrierAUTNT:               true,
+	TrackerCarrierBEBpost:             true,
+	TrackerCarrierBEKiala:             true,
+	TrackerCarrierBGBulgarianPost:     true,
+	TrackerCarrierBluedart:            true,
+	TrackerCarrierBpostInt:            true,
+	TrackerCarrierBRTIT:               true,
+	TrackerCarrierCACanadaPost:        true,
+	TrackerCarrierCACanpar:            true,
+	TrackerCarrierCALoomis:            true,
+	TrackerCarrierCAPurolator:         true,
+	TrackerCarrierCBLLogistica:        true,
+	TrackerCarrierCHSwissPostPriority: true,
+	TrackerCarrierCNChinaPostEMS:      true,
+	TrackerCarrierCNEMS:               true,
+	TrackerCarrierCNSFExpress:         true,
+	TrackerCarrierCNYunexpress:        true,
+	TrackerCarrierCollectplus:         true,
+	TrackerCarrierCorreosDeMexico:     true,
+	TrackerCarrierCZCzechPost:         true,
+	TrackerCarrierDEDeutsche:          true,
+	TrackerCarrierDEDHLDeutschepost:   true,
+	TrackerCarrierDEDPDDelistrack:     true,
+	TrackerCarrierDEGLS:               true,
+	TrackerCarrierDEHermes:            true,
+	TrackerCarrierDHL:                 true,
+	TrackerCarrierDHLAPI:              true,
+	TrackerCarrierDHLEcommerce:        true,
+	TrackerCarrierDHLGlobalEcommerce:  true,
+	TrackerCarrierDHLGlobalMail:       true,
+	TrackerCarrierDKPostnord:          true,
+	TrackerCarrierDPD:                 true,
+	TrackerCarrierDPDUK:               true,
+	TrackerCarrierEMS:                 true,
+	TrackerCarrierESCorreosDeEspana:   true,
+	TrackerCarrierESGLS:               true,
+	TrackerCarrierFedex:               true,
+	TrackerCarrierFIItella:            true,
+	TrackerCarrierFRChronopost:        true,
+	TrackerCarrierFRColiposte:         true,
+	TrackerCarrierFRColissimo:         true,
+	TrackerCarrierFRGLS:               true,
+	TrackerCarrierFRLaposte:           true,
+	TrackerCarrierFRMondial:           true,
+	TrackerCarrierGBAPC:               true,
+	TrackerCarrierGBArrow:             true,
+	TrackerCarrierGBCollectplus:       true,
+	TrackerCarrierGBDPD:               true,
+	TrackerCarrierGBHermesworld:       true,
+	TrackerCarrierGBParcelforce:       true,
+	TrackerCarrierGBRoyalMail:         true,
+	TrackerCarrierGBTNT:               true,
+	TrackerCarrierGBYodel:             true,
+	TrackerCarrierGLS:                 true,
+	TrackerCarrierHKHongkongPost:      true,
+	TrackerCarrierIEAnPost:            true,
+	TrackerCarrierINBluedart:          true,
+	TrackerCarrierINDelhivery:         true,
+	TrackerCarrierINDTDC:              true,
+	TrackerCarrierINIndiapost:         true,
+	TrackerCarrierITBRT:               true,
+	TrackerCarrierITGLS:               true,
+	TrackerCarrierITPosteItaliane:     true,
+	TrackerCarrierITSDA:               true,
+	TrackerCarrierJPJapanpost:         true,
+	TrackerCarrierJPSagawa:            true,
+	TrackerCarrierJPYamato:            true,
+	TrackerCarrierKRKoreaPost:         true,
+	TrackerCarrierMXEstafeta:          true,
+	TrackerCarrierNLDHL:               true,
+	TrackerCarrierNLGLS:               true,
+	TrackerCarrierNLPostnl:            true,
+	TrackerCarrierNZCourierPost:       true,
+	TrackerCarrierNZNZPost:            true,
+	TrackerCarrierOntrac:              true,
+	TrackerCarrierPLPocztaPolska:      true,
+	TrackerCarrierPTCTT:               true,
+	TrackerCarrierRURussianPost:       true,
+	TrackerCarrierSEPostnord:          true,
+	TrackerCarrierSGSingpost:          true,
+	TrackerCarrierTNT:                 true,
+	TrackerCarrierUPS:                 true,
+	TrackerCarrierUPSMailInnovations:  true,
+	TrackerCarrierUSPS:                true,
+	TrackerCarrierYanwen:              true,
+	TrackerCarrierYunexpress:          true,
+	TrackerCarrierOther:               true,
+}
+
+// IsTrackerCarrier reports whether PayPal accepts the carrier in trackers
+func IsTrackerCarrier(carrier string) bool {
+	return trackerCarriers[carrier]
+}
+
+// Validate checks the status and the carrier of the tracker before it is sent to PayPal
+func (t *Tracker) Validate() error {
+	if t.TransactionID == "" {
+		return fmt.Errorf("paypal: tracker transaction ID is required")
+	}
+	if !trackerStatuses[t.Status] {
+		return fmt.Errorf("paypal: invalid tracker status %q", t.Status)
+	}
+	if t.Carrier == "" {
+		return nil
+	}
+	if !trackerCarriers[t.Carrier] {
+		return fmt.Errorf("paypal: unsupported tracker carrier %q, use %s and set the carrier name", t.Carrier, TrackerCarrierOther)
+	}
+	if t.Carrier == TrackerCarrierOther && t.CarrierNameOther == "" {
+		return fmt.Errorf("paypal: carrier_name_other is required with carrier %s", TrackerCarrierOther)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package paypal
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type (
+	// Tracker represents the shipment tracking information of a PayPal transaction
+	Tracker struct {
+		TransactionID           string  `json:"transaction_id"`
+		TrackingNumber          string  `json:"tracking_number,omitempty"`
+		TrackingNumberType      string  `json:"tracking_number_type,omitempty"`
+		Status                  string  `json:"status"`
+		ShipmentDate            string  `json:"shipment_date,omitempty"`
+		Carrier                 string  `json:"carrier,omitempty"`
+		CarrierNameOther        string  `json:"carrier_name_other,omitempty"`
+		PostagePaymentID        string  `json:"postage_payment_id,omitempty"`
+		NotifyBuyer             bool    `json:"notify_buyer,omitempty"`
+		Quantity                int     `json:"quantity,omitempty"`
+		TrackingNumberValidated bool    `json:"tracking_number_validated,omitempty"` //Read only
+		LastUpdatedTime         string  `json:"last_updated_time,omitempty"`         //Read only
+		ShipmentDirection       string  `json:"shipment_direction,omitempty"`
+		ShipmentUploader        string  `json:"shipment_uploader,omitempty"`
+		Links                   []*Link `json:"links,omitempty"` //Read only
+	}
+)
+
+// TrackerID returns the ID PayPal identifies a tracker with, the transaction ID and the tracking number
+// joined with a hyphen
+func TrackerID(transactionID, trackingNumber string) string {
+	return transactionID + "-" + trackingNumber
+}
+
+// GetTracker shows the tracking information of a shipment, use TrackerID to build the ID
+// Endpoint: GET /v1/shipping/trackers/ID
+func (c *Client) GetTracker(trackerID string) (*Tracker, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/shipping/trackers/", url.PathEscape(trackerID)), nil)
+	resp := &Tracker{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// UpdateTracker updates or cancels the tracking information of a shipment, e.g. to mark the shipment DELIVERED.
+// trackerID identifies the tracker as it was added, so a wrong tracking number can be corrected in tracker
+// Endpoint: PUT /v1/shipping/trackers/ID
+func (c *Client) UpdateTracker(trackerID string, tracker *Tracker) error {
+	if trackerID == "" || tracker == nil {
+		return fmt.Errorf("paypal: tracker ID and tracker are required to update a tracker")
+	}
+	if err := c.validate(tracker); err != nil {
+		return err
+	}
+
+	req, err := c.NewRequest("PUT", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/shipping/trackers/", url.PathEscape(trackerID)), tracker)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
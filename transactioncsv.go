@@ -0,0 +1,155 @@
+package paypal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TransactionCSVColumn is a column of a transaction CSV export, Field is the transaction search field
+// the value is read from so only the needed fields are requested
+type TransactionCSVColumn struct {
+	Header string
+	Field  string
+	Value  func(details *SearchTransactionDetails) string
+}
+
+// Columns available for ExportTransactionsCSV
+var (
+	TransactionCSVTransactionID = TransactionCSVColumn{Header: "Transaction ID", Field: TransactionFieldsTransactionInfo, Value: func(d *SearchTransactionDetails) string {
+		return d.TransactionInfo.TransactionID
+	}}
+	TransactionCSVEventCode = TransactionCSVColumn{Header: "Event Code", Field: TransactionFieldsTransactionInfo, Value: func(d *SearchTransactionDetails) string {
+		return d.TransactionInfo.TransactionEventCode
+	}}
+	TransactionCSVDate = TransactionCSVColumn{Header: "Date", Field: TransactionFieldsTransactionInfo, Value: func(d *SearchTransactionDetails) string {
+		return d.TransactionInfo.TransactionInitiationDate
+	}}
+	TransactionCSVGross = TransactionCSVColumn{Header: "Gross", Field: TransactionFieldsTransactionInfo, Value: func(d *SearchTransactionDetails) string {
+		return moneyValue(d.TransactionInfo.TransactionAmount)
+	}}
+	TransactionCSVFee = TransactionCSVColumn{Header: "Fee", Field: TransactionFieldsTransactionInfo, Value: func(d *SearchTransactionDetails) string {
+		return moneyValue(d.TransactionInfo.FeeAmount)
+	}}
+	TransactionCSVNet = TransactionCSVColumn{Header: "Net", Field: TransactionFieldsTransactionInfo, Value: func(d *SearchTransactionDetails) string {
+		return transactionNet(d.TransactionInfo)
+	}}
+	TransactionCSVCurrency = TransactionCSVColumn{Header: "Currency", Field: TransactionFieldsTransactionInfo, Value: func(d *SearchTransactionDetails) string {
+		if d.TransactionInfo.TransactionAmount == nil {
+			return ""
+		}
+		return d.TransactionInfo.TransactionAmount.Currency
+	}}
+	TransactionCSVInvoiceID = TransactionCSVColumn{Header: "Invoice ID", Field: TransactionFieldsTransactionInfo, Value: func(d *SearchTransactionDetails) string {
+		return d.TransactionInfo.InvoiceID
+	}}
+	TransactionCSVCustomID = TransactionCSVColumn{Header: "Custom ID", Field: TransactionFieldsTransactionInfo, Value: func(d *SearchTransactionDetails) string {
+		return d.TransactionInfo.CustomField
+	}}
+	TransactionCSVPayerEmail = TransactionCSVColumn{Header: "Payer Email", Field: TransactionFieldsPayerInfo, Value: func(d *SearchTransactionDetails) string {
+		if d.PayerInfo == nil {
+			return ""
+		}
+		return d.PayerInfo.EmailAddress
+	}}
+)
+
+// DefaultTransactionCSVColumns are the columns exported when none are given
+var DefaultTransactionCSVColumns = []TransactionCSVColumn{
+	TransactionCSVTransactionID,
+	TransactionCSVEventCode,
+	TransactionCSVGross,
+	TransactionCSVFee,
+	TransactionCSVNet,
+	TransactionCSVCurrency,
+	TransactionCSVCustomID,
+	TransactionCSVPayerEmail,
+}
+
+// ExportTransactionsCSV writes the transactions matching params to w as CSV with a header row as the responses
+// are read, so a month of transactions is never held in memory. Periods longer than the 31 days a search can cover
+// are searched in consecutive windows. It returns the number of transactions written
+func (c *Client) ExportTransactionsCSV(w io.Writer, params *TransactionSearchRequest, columns []TransactionCSVColumn) (int, error) {
+	if params == nil || params.StartDate.IsZero() || params.EndDate.IsZero() || params.EndDate.Before(params.StartDate) {
+		return 0, fmt.Errorf("paypal: start_date and end_date are required to export transactions")
+	}
+	if len(columns) == 0 {
+		columns = DefaultTransactionCSVColumns
+	}
+
+	search := *params
+	if search.Fields == "" {
+		search.Fields = transactionCSVFields(columns)
+	}
+
+	out := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Header
+	}
+	if err := out.Write(header); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	record := make([]string, len(columns))
+	err := c.ForEachTransaction(&search, func(details *SearchTransactionDetails) error {
+		if details.TransactionInfo == nil {
+			return nil
+		}
+		for i, column := range columns {
+			record[i] = column.Value(details)
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	// The rows counted are flushed to w even when a later page fails
+	out.Flush()
+	if err != nil {
+		return count, err
+	}
+	return count, out.Error()
+}
+
+// transactionCSVFields returns the search fields the columns read from
+func transactionCSVFields(columns []TransactionCSVColumn) string {
+	fields := []string{TransactionFieldsTransactionInfo}
+	seen := map[string]bool{TransactionFieldsTransactionInfo: true}
+	for _, column := range columns {
+		if column.Field != "" && !seen[column.Field] {
+			seen[column.Field] = true
+			fields = append(fields, column.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// transactionNet returns the amount credited to the account, the fee is reported as a negative amount
+func transactionNet(info *SearchTransactionInfo) string {
+	if info.TransactionAmount == nil {
+		return ""
+	}
+	gross, ok := parseSignedDecimal(info.TransactionAmount.Value)
+	if !ok {
+		return ""
+	}
+	if info.FeeAmount != nil {
+		fee, ok := parseSignedDecimal(info.FeeAmount.Value)
+		if !ok {
+			return ""
+		}
+		gross.Add(gross, fee)
+	}
+	return formatCurrency(gross, info.TransactionAmount.Currency)
+}
+
+func moneyValue(m *Money) string {
+	if m == nil {
+		return ""
+	}
+	return m.Value
+}
@@ -0,0 +1,89 @@
+package paypal
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExportTransactionsCSV(t *testing.T) {
+	var windows []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("fields") != "transaction_info,payer_info" {
+			t.Errorf("unexpected fields %s", q.Get("fields"))
+		}
+		windows = append(windows, q.Get("start_date")+"/"+q.Get("end_date"))
+
+		if len(windows) == 1 {
+			fmt.Fprint(w, `{"transaction_details":[
+				{"transaction_info":{"transaction_id":"5TY05013RG002845M","transaction_event_code":"T0006","transaction_amount":{"currency_code":"USD","value":"10.00"},"fee_amount":{"currency_code":"USD","value":"-0.59"},"transaction_status":"S","custom_field":"order-1001"},"payer_info":{"email_address":"buyer@example.com"}},
+				{"transaction_info":{"transaction_id":"1Y107995YT783435V","transaction_event_code":"T1107","transaction_amount":{"currency_code":"JPY","value":"-1000"},"fee_amount":{"currency_code":"JPY","value":"29"},"transaction_status":"S","custom_field":"order, \"1002\""}}
+			],"page":1,"total_items":2,"total_pages":1}`)
+			return
+		}
+		fmt.Fprint(w, `{"transaction_details":[],"page":1,"total_items":0,"total_pages":0}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	buf := &bytes.Buffer{}
+	n, err := c.ExportTransactionsCSV(buf, &TransactionSearchRequest{
+		StartDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, 2, 15, 0, 0, 0, 0, time.UTC),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Not expected error for ExportTransactionsCSV, got %v", err)
+	}
+
+	expected := "Transaction ID,Event Code,Gross,Fee,Net,Currency,Custom ID,Payer Email\n" +
+		"5TY05013RG002845M,T0006,10.00,-0.59,9.41,USD,order-1001,buyer@example.com\n" +
+		"1Y107995YT783435V,T1107,-1000,29,-971,JPY,\"order, \"\"1002\"\"\",\n"
+	if n != 2 || buf.String() != expected {
+		t.Errorf("unexpected export of %d transactions:\n%s", n, buf.String())
+	}
+	if len(windows) != 2 || windows[1] != "2020-02-01T00:00:00Z/2020-02-15T00:00:00Z" {
+		t.Errorf("expected the period to be split in 31 days windows, got %v", windows)
+	}
+
+	buf.Reset()
+	_, err = c.ExportTransactionsCSV(buf, &TransactionSearchRequest{
+		StartDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Fields:    "transaction_info,payer_info",
+	}, []TransactionCSVColumn{TransactionCSVCustomID})
+	if err != nil || buf.String() != "Custom ID\n" {
+		t.Errorf("unexpected export %q, %v", buf.String(), err)
+	}
+}
+
+func TestExportTransactionsCSV_FailedPage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(transactionPage(1, 2, 2))
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	buf := &bytes.Buffer{}
+	n, err := c.ExportTransactionsCSV(buf, &TransactionSearchRequest{
+		StartDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC),
+	}, []TransactionCSVColumn{TransactionCSVTransactionID})
+	if err == nil {
+		t.Fatalf("expected the error of the second page")
+	}
+	if expected := "Transaction ID\n5TY05013RG0000002\n5TY05013RG0000003\n"; n != 2 || buf.String() != expected {
+		t.Errorf("expected the %d rows of the first page to be flushed, got %q", n, buf.String())
+	}
+}
@@ -0,0 +1,371 @@
+package paypal
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Possible values for `transaction_status` in TransactionSearchRequest and SearchTransactionInfo
+const (
+	TransactionStatusDenied    string = "D"
+	TransactionStatusPending   string = "P"
+	TransactionStatusSuccess   string = "S"
+	TransactionStatusReversed  string = "V"
+	TransactionStatusCancelled string = "C"
+)
+
+// Possible values for `Fields` in TransactionSearchRequest, combine them with commas
+const (
+	TransactionFieldsAll             string = "all"
+	TransactionFieldsTransactionInfo string = "transaction_info"
+	TransactionFieldsPayerInfo       string = "payer_info"
+	TransactionFieldsShippingInfo    string = "shipping_info"
+	TransactionFieldsAuctionInfo     string = "auction_info"
+	TransactionFieldsCartInfo        string = "cart_info"
+	TransactionFieldsIncentiveInfo   string = "incentive_info"
+	TransactionFieldsStoreInfo       string = "store_info"
+)
+
+// maxTransactionSearchRange is the longest period a single transaction search can cover
+const maxTransactionSearchRange = 31 * 24 * time.Hour
+
+type (
+	// TransactionSearchRequest represents the filters of a transaction search, StartDate and EndDate are required
+	// and can be at most 31 days apart. Transactions show up in the search up to three hours after they happen
+	TransactionSearchRequest struct {
+		TransactionID         string
+		TransactionType       string // a T-code, e.g. T0006
+		TransactionStatus     string
+		TransactionAmountFrom string // in the currency's smallest unit, e.g. 1000 for 10.00 USD
+		TransactionAmountTo   string
+		TransactionCurrency   string
+		StartDate             time.Time
+		EndDate               time.Time
+		PaymentInstrumentType string // CREDITCARD or DEBITCARD
+		StoreID               string
+		TerminalID            string
+		Fields                string //default: transaction_info
+		// BalanceAffectingRecordsOnly leaves out the records not changing the balance, e.g. authorizations
+		BalanceAffectingRecordsOnly bool
+		PageSize                    uint64 //default: 100, max: 500
+		Page                        uint64 //default: 1
+	}
+
+	// TransactionSearchResponse represents a page of the transaction search
+	TransactionSearchResponse struct {
+		TransactionDetails    []*SearchTransactionDetails `json:"transaction_details"`
+		AccountNumber         string                      `json:"account_number"`
+		StartDate             string                      `json:"start_date"`
+		EndDate               string                      `json:"end_date"`
+		LastRefreshedDatetime string                      `json:"last_refreshed_datetime"`
+		Page                  int                         `json:"page"`
+		TotalItems            int                         `json:"total_items"`
+		TotalPages            int                         `json:"total_pages"`
+		Links                 []*Link                     `json:"links"`
+	}
+
+	// SearchTransactionDetails represents a transaction, only TransactionInfo is returned unless Fields asks for more
+	SearchTransactionDetails struct {
+		TransactionInfo *SearchTransactionInfo `json:"transaction_info"`
+		PayerInfo       *SearchPayerInfo       `json:"payer_info,omitempty"`
+		ShippingInfo    *SearchShippingInfo    `json:"shipping_info,omitempty"`
+		CartInfo        *SearchCartInfo        `json:"cart_info,omitempty"`
+		StoreInfo       *SearchStoreInfo       `json:"store_info,omitempty"`
+		AuctionInfo     *SearchAuctionInfo     `json:"auction_info,omitempty"`
+		IncentiveInfo   *SearchIncentiveInfo   `json:"incentive_info,omitempty"`
+	}
+
+	// SearchTransactionInfo represents the money movement of a transaction
+	SearchTransactionInfo struct {
+		PayPalAccountID           string `json:"paypal_account_id,omitempty"`
+		TransactionID             string `json:"transaction_id"`
+		PayPalReferenceID         string `json:"paypal_reference_id,omitempty"`
+		PayPalReferenceIDType     string `json:"paypal_reference_id_type,omitempty"`
+		TransactionEventCode      string `json:"transaction_event_code"`
+		TransactionInitiationDate string `json:"transaction_initiation_date"`
+		TransactionUpdatedDate    string `json:"transaction_updated_date"`
+		TransactionAmount         *Money `json:"transaction_amount"`
+		FeeAmount                 *Money `json:"fee_amount,omitempty"`
+		InsuranceAmount           *Money `json:"insurance_amount,omitempty"`
+		ShippingAmount            *Money `json:"shipping_amount,omitempty"`
+		ShippingDiscountAmount    *Money `json:"shipping_discount_amount,omitempty"`
+		SalesTaxAmount            *Money `json:"sales_tax_amount,omitempty"`
+		TransactionStatus         string `json:"transaction_status"`
+		TransactionSubject        string `json:"transaction_subject,omitempty"`
+		TransactionNote           string `json:"transaction_note,omitempty"`
+		InvoiceID                 string `json:"invoice_id,omitempty"`
+		CustomField               string `json:"custom_field,omitempty"`
+		ProtectionEligibility     string `json:"protection_eligibility,omitempty"`
+		EndingBalance             *Money `json:"ending_balance,omitempty"`
+		AvailableBalance          *Money `json:"available_balance,omitempty"`
+		PaymentTrackingID         string `json:"payment_tracking_id,omitempty"`
+		BankReferenceID           string `json:"bank_reference_id,omitempty"`
+		InstrumentType            string `json:"instrument_type,omitempty"`
+		InstrumentSubType         string `json:"instrument_sub_type,omitempty"`
+	}
+
+	// SearchPayerInfo represents the payer of a transaction
+	SearchPayerInfo struct {
+		AccountID     string                 `json:"account_id,omitempty"`
+		EmailAddress  string                 `json:"email_address,omitempty"`
+		PhoneNumber   *SearchPhoneNumber     `json:"phone_number,omitempty"`
+		AddressStatus string                 `json:"address_status,omitempty"`
+		PayerStatus   string                 `json:"payer_status,omitempty"`
+		PayerName     *SearchPayerName       `json:"payer_name,omitempty"`
+		CountryCode   string                 `json:"country_code,omitempty"`
+		Address       *SearchShippingAddress `json:"address,omitempty"`
+	}
+
+	// SearchPhoneNumber represents the phone number of a payer
+	SearchPhoneNumber struct {
+		CountryCode     string `json:"country_code,omitempty"`
+		NationalNumber  string `json:"national_number,omitempty"`
+		ExtensionNumber string `json:"extension_number,omitempty"`
+	}
+
+	// SearchPayerName represents the name of a payer
+	SearchPayerName struct {
+		GivenName         string `json:"given_name,omitempty"`
+		Surname           string `json:"surname,omitempty"`
+		AlternateFullName string `json:"alternate_full_name,omitempty"`
+	}
+
+	// SearchShippingInfo represents where the items of a transaction are shipped
+	SearchShippingInfo struct {
+		Name    string                 `json:"name,omitempty"`
+		Method  string                 `json:"method,omitempty"`
+		Address *SearchShippingAddress `json:"address,omitempty"`
+	}
+
+	// SearchShippingAddress represents an address in the transaction search
+	SearchShippingAddress struct {
+		Line1       string `json:"line1,omitempty"`
+		Line2       string `json:"line2,omitempty"`
+		City        string `json:"city,omitempty"`
+		State       string `json:"state,omitempty"`
+		CountryCode string `json:"country_code,omitempty"`
+		PostalCode  string `json:"postal_code,omitempty"`
+	}
+
+	// SearchCartInfo represents the items of a transaction
+	SearchCartInfo struct {
+		ItemDetails     []*SearchItemDetail `json:"item_details,omitempty"`
+		TaxInclusive    bool                `json:"tax_inclusive,omitempty"`
+		PayPalInvoiceID string              `json:"paypal_invoice_id,omitempty"`
+	}
+
+	// SearchAuctionInfo represents the auction a transaction is for
+	SearchAuctionInfo struct {
+		AuctionSite        string `json:"auction_site,omitempty"`
+		AuctionItemSite    string `json:"auction_item_site,omitempty"`
+		AuctionBuyerID     string `json:"auction_buyer_id,omitempty"`
+		AuctionClosingDate string `json:"auction_closing_date,omitempty"`
+	}
+
+	// SearchIncentiveInfo represents the incentives applied to a transaction
+	SearchIncentiveInfo struct {
+		IncentiveDetails []*SearchIncentiveDetail `json:"incentive_details,omitempty"`
+	}
+
+	// SearchIncentiveDetail represents an incentive, e.g. a coupon
+	SearchIncentiveDetail struct {
+		IncentiveType        string `json:"incentive_type,omitempty"`
+		IncentiveCode        string `json:"incentive_code,omitempty"`
+		IncentiveAmount      *Money `json:"incentive_amount,omitempty"`
+		IncentiveProgramCode string `json:"incentive_program_code,omitempty"`
+	}
+
+	// SearchItemDetail represents an item of a transaction
+	SearchItemDetail struct {
+		ItemCode         string             `json:"item_code,omitempty"`
+		ItemName         string             `json:"item_name,omitempty"`
+		ItemDescription  string             `json:"item_description,omitempty"`
+		ItemOptions      string             `json:"item_options,omitempty"`
+		ItemQuantity     string             `json:"item_quantity,omitempty"`
+		ItemUnitPrice    *Money             `json:"item_unit_price,omitempty"`
+		ItemAmount       *Money             `json:"item_amount,omitempty"`
+		DiscountAmount   *Money             `json:"discount_amount,omitempty"`
+		AdjustmentAmount *Money             `json:"adjustment_amount,omitempty"`
+		TaxPercentage    string             `json:"tax_percentage,omitempty"`
+		TaxAmounts       []*SearchTaxAmount `json:"tax_amounts,omitempty"`
+		TotalItemAmount  *Money             `json:"total_item_amount,omitempty"`
+		InvoiceNumber    string             `json:"invoice_number,omitempty"`
+	}
+
+	// SearchTaxAmount represents a tax of an item
+	SearchTaxAmount struct {
+		TaxAmount *Money `json:"tax_amount,omitempty"`
+	}
+
+	// SearchStoreInfo represents the store a point of sale transaction happened in
+	SearchStoreInfo struct {
+		StoreID    string `json:"store_id,omitempty"`
+		TerminalID string `json:"terminal_id,omitempty"`
+	}
+
+	// TransactionSearchIterator iterates over the pages of a transaction search
+	//
+	//	it := c.IterateTransactions(&paypal.TransactionSearchRequest{StartDate: start, EndDate: end})
+	//	for it.Next() {
+	//		for _, transaction := range it.Page().TransactionDetails { ... }
+	//	}
+	//	if err := it.Err(); err != nil { ... }
+	TransactionSearchIterator struct {
+		client *Client
+		params TransactionSearchRequest
+		page   *TransactionSearchResponse
+		err    error
+		done   bool
+	}
+)
+
+// ListTransactions searches the transactions of the account, a page at a time
+// Endpoint: GET /v1/reporting/transactions
+func (c *Client) ListTransactions(params *TransactionSearchRequest) (*TransactionSearchResponse, error) {
+	resp := &TransactionSearchResponse{}
+
+	req, err := c.transactionsRequest(params)
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// transactionsRequest validates params and returns the request of a transaction search
+func (c *Client) transactionsRequest(params *TransactionSearchRequest) (*http.Request, error) {
+	if params == nil || params.StartDate.IsZero() || params.EndDate.IsZero() {
+		return nil, fmt.Errorf("paypal: start_date and end_date are required to search transactions")
+	}
+	if params.EndDate.Before(params.StartDate) || params.EndDate.Sub(params.StartDate) > maxTransactionSearchRange {
+		return nil, fmt.Errorf("paypal: end_date must be after start_date and at most 31 days apart")
+	}
+	if (params.TransactionAmountFrom == "") != (params.TransactionAmountTo == "") {
+		return nil, fmt.Errorf("paypal: both ends of the transaction_amount range are required")
+	}
+
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/reporting/transactions"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("start_date", params.StartDate.UTC().Format(time.RFC3339))
+	q.Add("end_date", params.EndDate.UTC().Format(time.RFC3339))
+	if params.TransactionID != "" {
+		q.Add("transaction_id", params.TransactionID)
+	}
+	if params.TransactionType != "" {
+		q.Add("transaction_type", params.TransactionType)
+	}
+	if params.TransactionStatus != "" {
+		q.Add("transaction_status", params.TransactionStatus)
+	}
+	if params.TransactionAmountFrom != "" {
+		q.Add("transaction_amount", fmt.Sprintf("%s TO %s", params.TransactionAmountFrom, params.TransactionAmountTo))
+	}
+	if params.TransactionCurrency != "" {
+		q.Add("transaction_currency", params.TransactionCurrency)
+	}
+	if params.PaymentInstrumentType != "" {
+		q.Add("payment_instrument_type", params.PaymentInstrumentType)
+	}
+	if params.StoreID != "" {
+		q.Add("store_id", params.StoreID)
+	}
+	if params.TerminalID != "" {
+		q.Add("terminal_id", params.TerminalID)
+	}
+	if params.Fields != "" {
+		q.Add("fields", params.Fields)
+	}
+	if params.BalanceAffectingRecordsOnly {
+		q.Add("balance_affecting_records_only", "Y")
+	}
+	if params.PageSize > 0 {
+		q.Add("page_size", strconv.FormatUint(params.PageSize, 10))
+	}
+	if params.Page > 0 {
+		q.Add("page", strconv.FormatUint(params.Page, 10))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	return req, nil
+}
+
+// IterateTransactions returns an iterator over all the pages of a transaction search, starting at params.Page
+func (c *Client) IterateTransactions(params *TransactionSearchRequest) *TransactionSearchIterator {
+	it := &TransactionSearchIterator{client: c}
+	if params != nil {
+		it.params = *params
+	}
+	return it
+}
+
+// Next fetches the next page, it returns false when there are no more pages or the request failed
+func (it *TransactionSearchIterator) Next() bool {
+	if it.done {
+		return false
+	}
+
+	if it.page != nil {
+		if it.page.Page >= it.page.TotalPages {
+			it.done = true
+			return false
+		}
+		it.params.Page = uint64(it.page.Page) + 1
+	}
+
+	it.page, it.err = it.client.ListTransactions(&it.params)
+	if it.err != nil {
+		it.done = true
+		return false
+	}
+	return true
+}
+
+// Page returns the page fetched by the last call to Next
+func (it *TransactionSearchIterator) Page() *TransactionSearchResponse {
+	return it.page
+}
+
+// Err returns the error that stopped the iteration
+func (it *TransactionSearchIterator) Err() error {
+	return it.err
+}
+
+// searchTransactionPages calls fn with every page of the search, periods longer than the 31 days
+// a single search can cover are searched in consecutive windows
+func (c *Client) searchTransactionPages(params *TransactionSearchRequest, fn func(page *TransactionSearchResponse) error) error {
+	return transactionWindows(params, func(window *TransactionSearchRequest) error {
+		it := c.IterateTransactions(window)
+		for it.Next() {
+			if err := fn(it.Page()); err != nil {
+				return err
+			}
+		}
+		return it.Err()
+	})
+}
+
+// transactionWindows calls fn with a copy of params for each consecutive window of at most 31 days
+// of its period, every copy starts at the page of params
+func transactionWindows(params *TransactionSearchRequest, fn func(window *TransactionSearchRequest) error) error {
+	for start := params.StartDate; start.Before(params.EndDate); {
+		window := *params
+		window.StartDate = start
+		window.EndDate = start.Add(maxTransactionSearchRange)
+		if window.EndDate.After(params.EndDate) {
+			window.EndDate = params.EndDate
+		}
+		start = window.EndDate
+
+		if err := fn(&window); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 )
@@ -20,6 +22,12 @@ const (
 	RequestNewTokenBeforeExpiresIn = time.Duration(60) * time.Second
 )
 
+// Possible values for `token_type_hint` in TerminateToken
+const (
+	TokenTypeHintAccessToken  string = "ACCESS_TOKEN"
+	TokenTypeHintRefreshToken string = "REFRESH_TOKEN"
+)
+
 // Possible values for `no_shipping` in InputFields
 //
 // https://developer.paypal.com/docs/api/payment-experience/#definition-input_fields
@@ -45,6 +53,21 @@ const (
 	LandingPageTypeLogin   string = "Login"
 )
 
+// Possible values for `path` in PaymentPatch used with PatchWebProfile
+//
+// https://developer.paypal.com/docs/api/payment-experience/v1/#web-profiles_partial-update
+const (
+	WebProfilePathName                      string = "/name"
+	WebProfilePathPresentationBrandName     string = "/presentation/brand_name"
+	WebProfilePathPresentationLogoImage     string = "/presentation/logo_image"
+	WebProfilePathPresentationLocaleCode    string = "/presentation/locale_code"
+	WebProfilePathInputFieldsAllowNote      string = "/input_fields/allow_note"
+	WebProfilePathInputFieldsNoShipping     string = "/input_fields/no_shipping"
+	WebProfilePathInputFieldsAddrOverride   string = "/input_fields/address_override"
+	WebProfilePathFlowConfigLandingPageType string = "/flow_config/landing_page_type"
+	WebProfilePathFlowConfigUserAction      string = "/flow_config/user_action"
+)
+
 // Possible value for `allowed_payment_method` in PaymentOptions
 //
 // https://developer.paypal.com/docs/api/payments/#definition-payment_options
@@ -58,8 +81,8 @@ const (
 //
 // https://developer.paypal.com/docs/api/orders/v2/#orders_create
 const (
-	OrderIntentCapture   string = "CAPTURE"
-	OrderIntentAuthorize string = "AUTHORIZE"
+	OrderIntentCapture   OrderIntent = "CAPTURE"
+	OrderIntentAuthorize OrderIntent = "AUTHORIZE"
 )
 
 // Possible values for `category` in Item
@@ -70,25 +93,42 @@ const (
 	ItemCategoryPhysicalGood string = "PHYSICAL_GOODS"
 )
 
+// Possible values for `recipient_type` in PayoutItem
+//
+// https://developer.paypal.com/docs/api/payments.payouts-batch/v1/#definition-payout_item
+const (
+	PayoutRecipientTypeEmail    string = "EMAIL"
+	PayoutRecipientTypePhone    string = "PHONE"
+	PayoutRecipientTypePayPalID string = "PAYPAL_ID"
+)
+
+// Possible values for `type` in PaymentSourceToken
+//
+// https://developer.paypal.com/docs/api/orders/v2/#definition-token
+const (
+	PaymentSourceTokenTypeBillingAgreement string = "BILLING_AGREEMENT"
+)
+
 // Possible values for `shipping_preference` in ApplicationContext
 const (
-	ShippingPreferenceGetFromFile        string = "GET_FROM_FILE"
-	ShippingPreferenceNoShipping         string = "NO_SHIPPING"
-	ShippingPreferenceSetProvidedAddress string = "SET_PROVIDED_ADDRESS"
+	ShippingPreferenceGetFromFile        ShippingPreference = "GET_FROM_FILE"
+	ShippingPreferenceNoShipping         ShippingPreference = "NO_SHIPPING"
+	ShippingPreferenceSetProvidedAddress ShippingPreference = "SET_PROVIDED_ADDRESS"
 )
 
-// Possible values for `user_action` in ApplicationContext
+// Possible values for `landing_page` in ApplicationContext
 const (
-	UserActionContinue     string = "CONTINUE"
-	UserActionSubscribeNow string = "SUBSCRIBE_NOW"
+	LandingPageLogin         LandingPage = "LOGIN"
+	LandingPageBilling       LandingPage = "BILLING"
+	LandingPageNoPreference  LandingPage = "NO_PREFERENCE"
+	LandingPageGuestCheckout LandingPage = "GUEST_CHECKOUT"
 )
 
+// Possible values for `user_action` in ApplicationContext
 const (
-	EventPaymentCaptureCompleted       string = "PAYMENT.CAPTURE.COMPLETED"
-	EventPaymentCaptureDenied          string = "PAYMENT.CAPTURE.DENIED"
-	EventPaymentCaptureRefunded        string = "PAYMENT.CAPTURE.REFUNDED"
-	EventMerchantOnboardingCompleted   string = "MERCHANT.ONBOARDING.COMPLETED"
-	EventMerchantPartnerConsentRevoked string = "MERCHANT.PARTNER-CONSENT.REVOKED"
+	UserActionContinue     string = "CONTINUE"
+	UserActionPayNow       string = "PAY_NOW"
+	UserActionSubscribeNow string = "SUBSCRIBE_NOW"
 )
 
 const (
@@ -113,8 +153,12 @@ const (
 )
 
 const (
-	LinkRelSelf      string = "self"
-	LinkRelActionURL string = "action_url"
+	LinkRelSelf        string = "self"
+	LinkRelActionURL   string = "action_url"
+	LinkRelNext        string = "next"
+	LinkRelApprovalURL string = "approval_url"
+	LinkRelApprove     string = "approve"
+	LinkRelUp          string = "up"
 )
 
 // Possible values for `operation` in PatchObject
@@ -124,6 +168,14 @@ const (
 	OperationRemove  string = "remove"
 )
 
+// Possible values for `path` in PatchObject used with UpdateProduct
+const (
+	ProductPathDescription string = "/description"
+	ProductPathCategory    string = "/category"
+	ProductPathImageURL    string = "/image_url"
+	ProductPathHomeURL     string = "/home_url"
+)
+
 // Possible values for `type` in CreateProduct
 const (
 	ProductTypePhysical string = "PHYSICAL"
@@ -597,10 +649,60 @@ const (
 	SubscriptionStatusExpired         string = "EXPIRED"
 )
 
+// Possible values for `status` in Order, AuthorizeOrderResponse and CaptureOrderResponse
+const (
+	OrderStatusCreated             OrderStatus = "CREATED"
+	OrderStatusSaved               OrderStatus = "SAVED"
+	OrderStatusApproved            OrderStatus = "APPROVED"
+	OrderStatusVoided              OrderStatus = "VOIDED"
+	OrderStatusCompleted           OrderStatus = "COMPLETED"
+	OrderStatusPayerActionRequired OrderStatus = "PAYER_ACTION_REQUIRED"
+)
+
+// Possible values for `status` in Capture and PaymentCaptureResponse
+const (
+	CaptureStatusCompleted         CaptureStatus = "COMPLETED"
+	CaptureStatusDeclined          CaptureStatus = "DECLINED"
+	CaptureStatusPartiallyRefunded CaptureStatus = "PARTIALLY_REFUNDED"
+	CaptureStatusPending           CaptureStatus = "PENDING"
+	CaptureStatusRefunded          CaptureStatus = "REFUNDED"
+	CaptureStatusFailed            CaptureStatus = "FAILED"
+)
+
+// Possible values for `status` in Refund and RefundResponse
+const (
+	RefundStatusCancelled RefundStatus = "CANCELLED"
+	RefundStatusFailed    RefundStatus = "FAILED"
+	RefundStatusPending   RefundStatus = "PENDING"
+	RefundStatusCompleted RefundStatus = "COMPLETED"
+)
+
+// Possible values for `batch_status` in BatchHeader
+const (
+	PayoutBatchStatusDenied     PayoutBatchStatus = "DENIED"
+	PayoutBatchStatusPending    PayoutBatchStatus = "PENDING"
+	PayoutBatchStatusProcessing PayoutBatchStatus = "PROCESSING"
+	PayoutBatchStatusSuccess    PayoutBatchStatus = "SUCCESS"
+	PayoutBatchStatusCanceled   PayoutBatchStatus = "CANCELED"
+)
+
+// Possible values for `transaction_status` in PayoutItemResponse
+const (
+	PayoutTransactionStatusSuccess   PayoutTransactionStatus = "SUCCESS"
+	PayoutTransactionStatusFailed    PayoutTransactionStatus = "FAILED"
+	PayoutTransactionStatusPending   PayoutTransactionStatus = "PENDING"
+	PayoutTransactionStatusUnclaimed PayoutTransactionStatus = "UNCLAIMED"
+	PayoutTransactionStatusReturned  PayoutTransactionStatus = "RETURNED"
+	PayoutTransactionStatusOnHold    PayoutTransactionStatus = "ONHOLD"
+	PayoutTransactionStatusBlocked   PayoutTransactionStatus = "BLOCKED"
+	PayoutTransactionStatusRefunded  PayoutTransactionStatus = "REFUNDED"
+	PayoutTransactionStatusReversed  PayoutTransactionStatus = "REVERSED"
+)
+
 // Possible values for `tenure_type` in BillingCycle and CycleExecution
 const (
-	TenureTypeRegular string = "REGULAR"
-	TenureTypeTrial   string = "TRIAL"
+	TenureTypeRegular TenureType = "REGULAR"
+	TenureTypeTrial   TenureType = "TRIAL"
 )
 
 // Possible values for `interval_unit` in Frequency
@@ -701,13 +803,13 @@ type (
 	// AgreementDetails struct
 	AgreementDetails struct {
 		OutstandingBalance AmountPayout `json:"outstanding_balance"`
-		CyclesRemaining    int          `json:"cycles_remaining, string"`
-		CyclesCompleted    int          `json:"cycles_completed, string"`
-		NextBillingDate    time.Time    `json:"next_billing_date"`
-		LastPaymentDate    time.Time    `json:"last_payment_date"`
+		CyclesRemaining    int          `json:"cycles_remaining,string"`
+		CyclesCompleted    int          `json:"cycles_completed,string"`
+		NextBillingDate    JSONTime     `json:"next_billing_date"`
+		LastPaymentDate    JSONTime     `json:"last_payment_date"`
 		LastPaymentAmount  AmountPayout `json:"last_payment_amount"`
-		FinalPaymentDate   time.Time    `json:"final_payment_date"`
-		FailedPaymentCount int          `json:"failed_payment_count, string"`
+		FinalPaymentDate   JSONTime     `json:"final_payment_date"`
+		FailedPaymentCount int          `json:"failed_payment_count,string"`
 	}
 
 	// Amount struct
@@ -745,38 +847,40 @@ type (
 	// |               | the subscription.																	|
 	// ------------------------------------------------------------------------------------------------------
 	ApplicationContext struct {
-		BrandName          string         `json:"brand_name,omitempty"`
-		Locale             string         `json:"locale,omitempty"`
-		LandingPage        string         `json:"landing_page,omitempty"`
-		ShippingPreference string         `json:"shipping_preference,omitempty"` //default: GET_FROM_FILE
-		UserAction         string         `json:"user_action,omitempty"`         //default: SUBSCRIBE_NOW
-		PaymentMethod      *PaymentMethod `json:"payment_method,omitempty"`
-		ReturnURL          string         `json:"return_url"`
-		CancelURL          string         `json:"cancel_url"`
+		BrandName          string             `json:"brand_name,omitempty"`
+		Locale             string             `json:"locale,omitempty"`
+		LandingPage        LandingPage        `json:"landing_page,omitempty"`
+		ShippingPreference ShippingPreference `json:"shipping_preference,omitempty"` //default: GET_FROM_FILE
+		UserAction         string             `json:"user_action,omitempty"`         //default: SUBSCRIBE_NOW
+		PaymentMethod      *PaymentMethod     `json:"payment_method,omitempty"`
+		ReturnURL          string             `json:"return_url"`
+		CancelURL          string             `json:"cancel_url"`
 	}
 
 	// Authorization struct
 	Authorization struct {
-		ID               string                `json:"id,omitempty"`
-		CustomID         string                `json:"custom_id,omitempty"`
-		InvoiceID        string                `json:"invoice_id,omitempty"`
-		Status           string                `json:"status,omitempty"`
-		StatusDetails    *CaptureStatusDetails `json:"status_details,omitempty"`
-		Amount           *PurchaseUnitAmount   `json:"amount,omitempty"`
-		SellerProtection *SellerProtection     `json:"seller_protection,omitempty"`
-		CreateTime       *time.Time            `json:"create_time,omitempty"`
-		UpdateTime       *time.Time            `json:"update_time,omitempty"`
-		ExpirationTime   *time.Time            `json:"expiration_time,omitempty"`
-		Links            []Link                `json:"links,omitempty"`
+		ID                          string                       `json:"id,omitempty"`
+		CustomID                    string                       `json:"custom_id,omitempty"`
+		InvoiceID                   string                       `json:"invoice_id,omitempty"`
+		Status                      string                       `json:"status,omitempty"`
+		StatusDetails               *CaptureStatusDetails        `json:"status_details,omitempty"`
+		Amount                      *PurchaseUnitAmount          `json:"amount,omitempty"`
+		SellerProtection            *SellerProtection            `json:"seller_protection,omitempty"`
+		ProcessorResponse           *ProcessorResponse           `json:"processor_response,omitempty"`            //Read only
+		NetworkTransactionReference *NetworkTransactionReference `json:"network_transaction_reference,omitempty"` //Read only
+		CreateTime                  *JSONTime                    `json:"create_time,omitempty"`
+		UpdateTime                  *JSONTime                    `json:"update_time,omitempty"`
+		ExpirationTime              *JSONTime                    `json:"expiration_time,omitempty"`
+		Links                       []Link                       `json:"links,omitempty"`
 	}
 
 	// AuthorizeOrderResponse .
 	AuthorizeOrderResponse struct {
-		CreateTime    *time.Time             `json:"create_time,omitempty"`
-		UpdateTime    *time.Time             `json:"update_time,omitempty"`
+		CreateTime    *JSONTime              `json:"create_time,omitempty"`
+		UpdateTime    *JSONTime              `json:"update_time,omitempty"`
 		ID            string                 `json:"id,omitempty"`
-		Status        string                 `json:"status,omitempty"`
-		Intent        string                 `json:"intent,omitempty"`
+		Status        OrderStatus            `json:"status,omitempty"`
+		Intent        OrderIntent            `json:"intent,omitempty"`
 		PurchaseUnits []PurchaseUnitRequest  `json:"purchase_units,omitempty"`
 		Payer         *PayerWithNameAndPhone `json:"payer,omitempty"`
 	}
@@ -802,8 +906,8 @@ type (
 
 	// https://developer.paypal.com/docs/api/payments/v2/#definition-payment_instruction
 	PaymentInstruction struct {
-		PlatformFees     []PlatformFee `json:"platform_fees,omitempty"`
-		DisbursementMode string        `json:"disbursement_mode,omitempty"`
+		PlatformFees     []PlatformFee    `json:"platform_fees,omitempty"`
+		DisbursementMode DisbursementMode `json:"disbursement_mode,omitempty"`
 	}
 
 	// https://developer.paypal.com/docs/api/payments/v2/#authorizations_capture
@@ -867,14 +971,16 @@ type (
 	}
 
 	PaymentCaptureResponse struct {
-		Status           string                `json:"status,omitempty"`
-		StatusDetails    *CaptureStatusDetails `json:"status_details,omitempty"`
-		ID               string                `json:"id,omitempty"`
-		Amount           *Money                `json:"amount,omitempty"`
-		InvoiceID        string                `json:"invoice_id,omitempty"`
-		FinalCapture     bool                  `json:"final_capture,omitempty"`
-		DisbursementMode string                `json:"disbursement_mode,omitempty"`
-		Links            []Link                `json:"links,omitempty"`
+		Status                      CaptureStatus                `json:"status,omitempty"`
+		StatusDetails               *CaptureStatusDetails        `json:"status_details,omitempty"`
+		ID                          string                       `json:"id,omitempty"`
+		Amount                      *Money                       `json:"amount,omitempty"`
+		InvoiceID                   string                       `json:"invoice_id,omitempty"`
+		FinalCapture                bool                         `json:"final_capture,omitempty"`
+		DisbursementMode            DisbursementMode             `json:"disbursement_mode,omitempty"`
+		ProcessorResponse           *ProcessorResponse           `json:"processor_response,omitempty"`            //Read only
+		NetworkTransactionReference *NetworkTransactionReference `json:"network_transaction_reference,omitempty"` //Read only
+		Links                       []Link                       `json:"links,omitempty"`
 	}
 
 	// CaptureOrderRequest - https://developer.paypal.com/docs/api/orders/v2/#orders_capture
@@ -887,9 +993,9 @@ type (
 		Amount            *AmountPayout      `json:"amount,omitempty"`
 		Fees              *AmountPayout      `json:"fees,omitempty"`
 		PayoutBatchID     string             `json:"payout_batch_id,omitempty"`
-		BatchStatus       string             `json:"batch_status,omitempty"`
-		TimeCreated       *time.Time         `json:"time_created,omitempty"`
-		TimeCompleted     *time.Time         `json:"time_completed,omitempty"`
+		BatchStatus       PayoutBatchStatus  `json:"batch_status,omitempty"`
+		TimeCreated       *JSONTime          `json:"time_created,omitempty"`
+		TimeCompleted     *JSONTime          `json:"time_completed,omitempty"`
 		SenderBatchHeader *SenderBatchHeader `json:"sender_batch_header,omitempty"`
 	}
 
@@ -923,19 +1029,21 @@ type (
 	// | 		 | disbursed automatically after the specified duration.											   |
 	// -----------------------------------------------------------------------------------------------------------------
 	Capture struct {
-		ID                        string                     `json:"id,omitempty"`                          //Read only
-		Status                    string                     `json:"status,omitempty"`                      //Read only
-		StatusDetails             *CaptureStatusDetails      `json:"status_details,omitempty"`              //Read only
-		Amount                    *Money                     `json:"amount,omitempty"`                      //Read only
-		InvoiceID                 string                     `json:"invoice_id,omitempty"`                  //Read only
-		CustomID                  string                     `json:"custom_id,omitempty"`                   //Read only
-		SellerProtection          *SellerProtection          `json:"seller_protection,omitempty"`           //Read only
-		FinalCapture              bool                       `json:"final_capture,omitempty"`               //Read only
-		SellerReceivableBreakdown *SellerReceivableBreakdown `json:"seller_receivable_breakdown,omitempty"` //Read only
-		DisbursementMode          string                     `json:"disbursement_mode,omitempty"`
-		CreateTime                string                     `json:"create_time,omitempty"` //Read only
-		UpdateTime                string                     `json:"update_time,omitempty"` //Read only
-		Links                     []*Link                    `json:"links,omitempty"`       //Read only
+		ID                          string                       `json:"id,omitempty"`                          //Read only
+		Status                      CaptureStatus                `json:"status,omitempty"`                      //Read only
+		StatusDetails               *CaptureStatusDetails        `json:"status_details,omitempty"`              //Read only
+		Amount                      *Money                       `json:"amount,omitempty"`                      //Read only
+		InvoiceID                   string                       `json:"invoice_id,omitempty"`                  //Read only
+		CustomID                    string                       `json:"custom_id,omitempty"`                   //Read only
+		SellerProtection            *SellerProtection            `json:"seller_protection,omitempty"`           //Read only
+		FinalCapture                bool                         `json:"final_capture,omitempty"`               //Read only
+		SellerReceivableBreakdown   *SellerReceivableBreakdown   `json:"seller_receivable_breakdown,omitempty"` //Read only
+		DisbursementMode            DisbursementMode             `json:"disbursement_mode,omitempty"`
+		ProcessorResponse           *ProcessorResponse           `json:"processor_response,omitempty"`            //Read only
+		NetworkTransactionReference *NetworkTransactionReference `json:"network_transaction_reference,omitempty"` //Read only
+		CreateTime                  string                       `json:"create_time,omitempty"`                   //Read only
+		UpdateTime                  string                       `json:"update_time,omitempty"`                   //Read only
+		Links                       []*Link                      `json:"links,omitempty"`                         //Read only
 	}
 
 	// SellerReceivableBreakdown represents the detailed breakdown of the captured payment.
@@ -974,7 +1082,15 @@ type (
 		Log                  io.Writer // If user set log file name all requests will be logged there
 		Token                *TokenResponse
 		tokenExpiresAt       time.Time
+		clock                Clock
+		onTokenRefresh       func(token *TokenResponse)
+		onUnknownFields      func(req *http.Request, v interface{}, fields []string)
+		cache                ResponseCache
+		cacheTTL             time.Duration
 		returnRepresentation bool
+		skipValidation       bool
+		refreshToken         string // set on merchant clients, see NewMerchantClient
+		authAssertion        string
 	}
 
 	// CreditCard struct
@@ -1042,10 +1158,15 @@ type (
 	}
 
 	// ErrorResponseDetail struct
+	// Field is a path such as items[0].recipient_type for the v1 APIs and a JSON pointer such as
+	// /purchase_units/@reference_id=='default'/amount/value for the v2 ones, see ErrorResponse.DetailsFor
 	ErrorResponseDetail struct {
-		Field string `json:"field"`
-		Issue string `json:"issue"`
-		Links []Link `json:"link"`
+		Field       string `json:"field"`
+		Value       string `json:"value,omitempty"`
+		Location    string `json:"location,omitempty"`
+		Issue       string `json:"issue"`
+		Description string `json:"description,omitempty"`
+		Links       []Link `json:"link"`
 	}
 
 	// ErrorResponse https://developer.paypal.com/docs/api/errors/
@@ -1065,7 +1186,7 @@ type (
 		Description      string           `json:"description,omitempty"`
 		Payer            Payer            `json:"payer"`
 		Plan             BillingPlan      `json:"plan"`
-		StartDate        time.Time        `json:"start_date"`
+		StartDate        JSONTime         `json:"start_date"`
 		ShippingAddress  ShippingAddress  `json:"shipping_address"`
 		AgreementDetails AgreementDetails `json:"agreement_details"`
 		Links            []Link           `json:"links"`
@@ -1147,6 +1268,13 @@ type (
 	PurchaseUnit struct {
 		ReferenceID string              `json:"reference_id"`
 		Amount      *PurchaseUnitAmount `json:"amount,omitempty"`
+		Payments    *OrderPayments      `json:"payments,omitempty"` //Read only
+	}
+
+	// OrderPayments has the captures of a purchase unit of an order
+	OrderPayments struct {
+		Captures []Capture `json:"captures,omitempty"`
+		Refunds  []Refund  `json:"refunds,omitempty"`
 	}
 
 	// TaxInfo used for orders.
@@ -1185,15 +1313,16 @@ type (
 
 	// PurchaseUnitRequest struct
 	PurchaseUnitRequest struct {
-		ReferenceID    string              `json:"reference_id,omitempty"`
-		Amount         *PurchaseUnitAmount `json:"amount"`
-		Payee          *PayeeForOrders     `json:"payee,omitempty"`
-		Description    string              `json:"description,omitempty"`
-		CustomID       string              `json:"custom_id,omitempty"`
-		InvoiceID      string              `json:"invoice_id,omitempty"`
-		SoftDescriptor string              `json:"soft_descriptor,omitempty"`
-		Items          []Item              `json:"items,omitempty"`
-		Shipping       *ShippingDetail     `json:"shipping,omitempty"`
+		ReferenceID        string              `json:"reference_id,omitempty"`
+		Amount             *PurchaseUnitAmount `json:"amount"`
+		Payee              *PayeeForOrders     `json:"payee,omitempty"`
+		Description        string              `json:"description,omitempty"`
+		CustomID           string              `json:"custom_id,omitempty"`
+		InvoiceID          string              `json:"invoice_id,omitempty"`
+		SoftDescriptor     string              `json:"soft_descriptor,omitempty"`
+		Items              []Item              `json:"items,omitempty"`
+		Shipping           *ShippingDetail     `json:"shipping,omitempty"`
+		PaymentInstruction *PaymentInstruction `json:"payment_instruction,omitempty"`
 	}
 
 	// MerchantPreferences struct
@@ -1209,19 +1338,21 @@ type (
 	// Order struct
 	Order struct {
 		ID            string         `json:"id,omitempty"`
-		Status        string         `json:"status,omitempty"`
-		Intent        string         `json:"intent,omitempty"`
+		Status        OrderStatus    `json:"status,omitempty"`
+		Intent        OrderIntent    `json:"intent,omitempty"`
 		PurchaseUnits []PurchaseUnit `json:"purchase_units,omitempty"`
 		Links         []Link         `json:"links,omitempty"`
-		CreateTime    *time.Time     `json:"create_time,omitempty"`
-		UpdateTime    *time.Time     `json:"update_time,omitempty"`
+		CreateTime    *JSONTime      `json:"create_time,omitempty"`
+		UpdateTime    *JSONTime      `json:"update_time,omitempty"`
 	}
 
 	// CaptureAmount struct
 	CaptureAmount struct {
-		ID       string              `json:"id,omitempty"`
-		CustomID string              `json:"custom_id,omitempty"`
-		Amount   *PurchaseUnitAmount `json:"amount,omitempty"`
+		ID                          string                       `json:"id,omitempty"`
+		CustomID                    string                       `json:"custom_id,omitempty"`
+		Amount                      *PurchaseUnitAmount          `json:"amount,omitempty"`
+		ProcessorResponse           *ProcessorResponse           `json:"processor_response,omitempty"`            //Read only
+		NetworkTransactionReference *NetworkTransactionReference `json:"network_transaction_reference,omitempty"` //Read only
 	}
 
 	// CapturedPayments has the amounts for a captured order
@@ -1254,7 +1385,7 @@ type (
 	// CaptureOrderResponse is the response for capture order
 	CaptureOrderResponse struct {
 		ID            string                 `json:"id,omitempty"`
-		Status        string                 `json:"status,omitempty"`
+		Status        OrderStatus            `json:"status,omitempty"`
 		Payer         *PayerWithNameAndPhone `json:"payer,omitempty"`
 		PurchaseUnits []CapturedPurchaseUnit `json:"purchase_units,omitempty"`
 	}
@@ -1323,8 +1454,8 @@ type (
 
 	// PaymentSource represents the payment source definitions
 	PaymentSource struct {
-		Card  *PaymentSourceCard  `json:"card"`
-		Token *PaymentSourceToken `json:"token"`
+		Card  *PaymentSourceCard  `json:"card,omitempty"`
+		Token *PaymentSourceToken `json:"token,omitempty"`
 	}
 
 	// PaymentSourceCard represents card details
@@ -1377,15 +1508,15 @@ type (
 
 	// PayoutItemResponse struct
 	PayoutItemResponse struct {
-		PayoutItemID      string        `json:"payout_item_id"`
-		TransactionID     string        `json:"transaction_id"`
-		TransactionStatus string        `json:"transaction_status"`
-		PayoutBatchID     string        `json:"payout_batch_id,omitempty"`
-		PayoutItemFee     *AmountPayout `json:"payout_item_fee,omitempty"`
-		PayoutItem        *PayoutItem   `json:"payout_item"`
-		TimeProcessed     *time.Time    `json:"time_processed,omitempty"`
-		Links             []Link        `json:"links"`
-		Error             ErrorResponse `json:"errors,omitempty"`
+		PayoutItemID      string                  `json:"payout_item_id"`
+		TransactionID     string                  `json:"transaction_id"`
+		TransactionStatus PayoutTransactionStatus `json:"transaction_status"`
+		PayoutBatchID     string                  `json:"payout_batch_id,omitempty"`
+		PayoutItemFee     *AmountPayout           `json:"payout_item_fee,omitempty"`
+		PayoutItem        *PayoutItem             `json:"payout_item"`
+		TimeProcessed     *JSONTime               `json:"time_processed,omitempty"`
+		Links             []Link                  `json:"links"`
+		Error             ErrorResponse           `json:"errors,omitempty"`
 	}
 
 	// PayoutResponse struct
@@ -1404,15 +1535,16 @@ type (
 	// Refund represents refund details
 	Refund struct {
 		ID                     string                  `json:"id,omitempty"`                       // Read only
-		Status                 string                  `json:"status,omitempty"`                   // Read only
+		Status                 RefundStatus            `json:"status,omitempty"`                   // Read only
 		StatusDetails          *RefundStatusDetails    `json:"status_details,omitempty"`           // Read only
 		Amount                 *Money                  `json:"amount,omitempty"`                   // Read only
 		InvoiceID              string                  `json:"invoice_id,omitempty"`               // Read only
+		CustomID               string                  `json:"custom_id,omitempty"`                // Read only
 		NoteToPayer            string                  `json:"note_to_payer,omitempty"`            // Read only
 		SellerPayableBreakdown *SellerPayableBreakdown `json:"seller_payable_breakdown,omitempty"` // Read only
 		Links                  []*Link                 `json:"links,omitempty"`                    // Read only
-		CreateTime             *time.Time              `json:"create_time,omitempty"`
-		UpdateTime             *time.Time              `json:"update_time,omitempty"`
+		CreateTime             *JSONTime               `json:"create_time,omitempty"`
+		UpdateTime             *JSONTime               `json:"update_time,omitempty"`
 	}
 
 	// RefundStatusDetails represents the details of the refund status.
@@ -1445,7 +1577,7 @@ type (
 	RefundResponse struct {
 		ID     string              `json:"id,omitempty"`
 		Amount *PurchaseUnitAmount `json:"amount,omitempty"`
-		Status string              `json:"status,omitempty"`
+		Status RefundStatus        `json:"status,omitempty"`
 	}
 
 	// Related struct
@@ -1490,6 +1622,8 @@ type (
 		ParentPayment             string               `json:"parent_payment,omitempty"` //Read only
 		ProcessorResponse         *ProcessorResponse   `json:"processor_response,omitempty"`
 		InvoiceNumber             string               `json:"invoice_number,omitempty"`       //Read only
+		Custom                    string               `json:"custom,omitempty"`               //Read only
+		SoftDescriptor            string               `json:"soft_descriptor,omitempty"`      //Read only
 		BillingAgreementID        string               `json:"billing_agreement_id,omitempty"` //Read only
 		CreateTime                string               `json:"create_time,omitempty"`          //Read only
 		UpdateTime                string               `json:"update_time,omitempty"`          //Read only
@@ -1552,13 +1686,27 @@ type (
 	// | 21_DO_NOT_TRY_AGAIN_CARD_HOLDER_CANCELLED_RECURRRING_CHARGE | 21 Do not try again. Card holder cancelled recurring charge. |
 	// | 21_CANCEL_ALL_RECURRING_PAYMENTS 							 | 21 Cancel all recurring payments. 							|
 	// ------------------------------------------------------------------------------------------------------------------------------
+	// The v2 captures and authorizations report the advice as PaymentAdviceCode, e.g. 01, 02, 03 or 21.
+	// For more information visit https://developer.paypal.com/docs/api/payments/v2/#definition-processor_response
 	ProcessorResponse struct {
-		ResponseCode string `json:"response_code"`           //Read only
-		AvsCode      string `json:"avs_code,omitempty"`      //Read only
-		CvvCode      string `json:"cvv_code,omitempty"`      //Read only
-		AdviceCode   string `json:"advice_code,omitempty"`   //Read only
-		EciSubmitted string `json:"eci_submitted,omitempty"` //Read only
-		Vpas         string `json:"vpas,omitempty"`          //Read only
+		ResponseCode      string `json:"response_code"`                 //Read only
+		AvsCode           string `json:"avs_code,omitempty"`            //Read only
+		CvvCode           string `json:"cvv_code,omitempty"`            //Read only
+		AdviceCode        string `json:"advice_code,omitempty"`         //Read only
+		PaymentAdviceCode string `json:"payment_advice_code,omitempty"` //Read only
+		EciSubmitted      string `json:"eci_submitted,omitempty"`       //Read only
+		Vpas              string `json:"vpas,omitempty"`                //Read only
+	}
+
+	// NetworkTransactionReference identifies the transaction at the card network, merchant initiated
+	// follow-up payments pass it back so the network links them to the customer initiated one.
+	// Date is the MMDD date of the transaction, only returned by some networks
+	// For more information visit https://developer.paypal.com/docs/api/payments/v2/#definition-network_transaction_reference
+	NetworkTransactionReference struct {
+		ID                      string `json:"id"`                                  //Read only
+		Date                    string `json:"date,omitempty"`                      //Read only
+		Network                 string `json:"network,omitempty"`                   //Read only
+		AcquirerReferenceNumber string `json:"acquirer_reference_number,omitempty"` //Read only
 	}
 
 	// SenderBatchHeader struct
@@ -1602,14 +1750,19 @@ type (
 		Address *ShippingDetailAddressPortable `json:"address,omitempty"`
 	}
 
-	expirationTime int64
+	// ExpirationTime is the lifetime of a token in seconds, as PayPal sends it in expires_in
+	ExpirationTime int64
 
 	// TokenResponse is for API response for the /oauth2/token endpoint
 	TokenResponse struct {
 		RefreshToken string         `json:"refresh_token"`
 		Token        string         `json:"access_token"`
 		Type         string         `json:"token_type"`
-		ExpiresIn    expirationTime `json:"expires_in"`
+		ExpiresIn    ExpirationTime `json:"expires_in"`
+		Scope        string         `json:"scope,omitempty"`    // space separated
+		IDToken      string         `json:"id_token,omitempty"` // only for the openid scope
+		Nonce        string         `json:"nonce,omitempty"`
+		AppID        string         `json:"app_id,omitempty"`
 	}
 
 	// Since it is not used i change it @gligor
@@ -1643,24 +1796,42 @@ type (
 		MerchantID   string `json:"merchant_id,omitempty"`
 	}
 
-	// UserInfo struct
+	// UserInfo represents the profile attributes of a user, each attribute is only returned when the user
+	// consented to the scope noted next to it
 	UserInfo struct {
-		ID              string   `json:"user_id"`
-		Name            string   `json:"name"`
-		GivenName       string   `json:"given_name"`
-		FamilyName      string   `json:"family_name"`
-		Email           string   `json:"email"`
-		Verified        bool     `json:"verified,omitempty, string"`
-		Gender          string   `json:"gender,omitempty"`
-		BirthDate       string   `json:"birthdate,omitempty"`
-		ZoneInfo        string   `json:"zoneinfo,omitempty"`
-		Locale          string   `json:"locale,omitempty"`
-		Phone           string   `json:"phone_number,omitempty"`
-		Address         *Address `json:"address,omitempty"`
-		VerifiedAccount bool     `json:"verified_account,omitempty, string"`
-		AccountType     string   `json:"account_type,omitempty"`
-		AgeRange        string   `json:"age_range,omitempty"`
-		PayerID         string   `json:"payer_id,omitempty"`
+		ID              string           `json:"user_id"`                           // openid
+		Name            string           `json:"name"`                              // profile
+		GivenName       string           `json:"given_name"`                        // profile
+		FamilyName      string           `json:"family_name"`                       // profile
+		Email           string           `json:"email"`                             // email, openid schema only
+		Emails          []*UserInfoEmail `json:"emails,omitempty"`                  // email, paypalv1.1 schema only
+		Verified        bool             `json:"verified,omitempty,string"`         // email
+		Gender          string           `json:"gender,omitempty"`                  // profile
+		BirthDate       string           `json:"birthdate,omitempty"`               // profile
+		ZoneInfo        string           `json:"zoneinfo,omitempty"`                // profile
+		Locale          string           `json:"locale,omitempty"`                  // profile
+		Phone           string           `json:"phone_number,omitempty"`            // phone
+		Address         *UserInfoAddress `json:"address,omitempty"`                 // address
+		VerifiedAccount bool             `json:"verified_account,omitempty,string"` // paypalattributes
+		AccountType     string           `json:"account_type,omitempty"`            // paypalattributes
+		AgeRange        string           `json:"age_range,omitempty"`               // paypalattributes
+		PayerID         string           `json:"payer_id,omitempty"`                // paypalattributes
+	}
+
+	// UserInfoEmail represents an email address of a user
+	UserInfoEmail struct {
+		Value     string `json:"value"`
+		Primary   bool   `json:"primary,omitempty"`
+		Confirmed bool   `json:"confirmed,omitempty"`
+	}
+
+	// UserInfoAddress represents the address of a user as returned by the userinfo endpoint
+	UserInfoAddress struct {
+		StreetAddress string `json:"street_address,omitempty"`
+		Locality      string `json:"locality,omitempty"`
+		Region        string `json:"region,omitempty"`
+		PostalCode    string `json:"postal_code,omitempty"`
+		Country       string `json:"country,omitempty"`
 	}
 
 	// WebProfile represents the configuration of the payment web payment experience
@@ -1669,6 +1840,7 @@ type (
 	WebProfile struct {
 		ID           string       `json:"id,omitempty"`
 		Name         string       `json:"name"`
+		Temporary    bool         `json:"temporary,omitempty"`
 		Presentation Presentation `json:"presentation,omitempty"`
 		InputFields  InputFields  `json:"input_fields,omitempty"`
 		FlowConfig   FlowConfig   `json:"flow_config,omitempty"`
@@ -1679,9 +1851,11 @@ type (
 	//
 	// https://developer.paypal.com/docs/api/payment-experience/#definition-presentation
 	Presentation struct {
-		BrandName  string `json:"brand_name,omitempty"`
-		LogoImage  string `json:"logo_image,omitempty"`
-		LocaleCode string `json:"locale_code,omitempty"`
+		BrandName         string `json:"brand_name,omitempty"`
+		LogoImage         string `json:"logo_image,omitempty"`
+		LocaleCode        string `json:"locale_code,omitempty"`
+		ReturnURLLabel    string `json:"return_url_label,omitempty"`
+		NoteToSellerLabel string `json:"note_to_seller_label,omitempty"`
 	}
 
 	// InputFields represents the fields that are displayed to a customer on
@@ -1707,16 +1881,20 @@ type (
 		VerificationStatus string `json:"verification_status,omitempty"`
 	}
 
+	// WebhookEvent represents a webhook event with a generic Resource.
+	// Resource keeps only the fields shared by payment resources, use Event() to decode the complete resource
 	WebhookEvent struct {
-		ID              string    `json:"id"`
-		CreateTime      time.Time `json:"create_time"`
-		ResourceType    string    `json:"resource_type"`
-		EventType       string    `json:"event_type"`
-		Summary         string    `json:"summary,omitempty"`
-		Resource        Resource  `json:"resource"`
-		Links           []Link    `json:"links"`
-		EventVersion    string    `json:"event_version,omitempty"`
-		ResourceVersion string    `json:"resource_version,omitempty"`
+		ID              string   `json:"id"`
+		CreateTime      JSONTime `json:"create_time"`
+		ResourceType    string   `json:"resource_type"`
+		EventType       string   `json:"event_type"`
+		Summary         string   `json:"summary,omitempty"`
+		Resource        Resource `json:"resource"`
+		Links           []Link   `json:"links"`
+		EventVersion    string   `json:"event_version,omitempty"`
+		ResourceVersion string   `json:"resource_version,omitempty"`
+
+		event *Event
 	}
 
 	Resource struct {
@@ -1732,7 +1910,7 @@ type (
 		FinalCapture           bool                    `json:"final_capture,omitempty"`
 		SellerPayableBreakdown *CaptureSellerBreakdown `json:"seller_payable_breakdown,omitempty"`
 		NoteToPayer            string                  `json:"note_to_payer,omitempty"`
-		// merchant-onboarding Resource type
+		// merchant-onboarding and partner-consent Resource types
 		PartnerClientID string `json:"partner_client_id,omitempty"`
 		MerchantID      string `json:"merchant_id,omitempty"`
 		// Common
@@ -1748,7 +1926,7 @@ type (
 
 	ReferralRequest struct {
 		TrackingID            string                 `json:"tracking_id"`
-		PartnerConfigOverride *PartnerConfigOverride `json:"partner_config_override,omitemtpy"`
+		PartnerConfigOverride *PartnerConfigOverride `json:"partner_config_override,omitempty"`
 		Operations            []Operation            `json:"operations,omitempty"`
 		Products              []string               `json:"products,omitempty"`
 		LegalConsents         []Consent              `json:"legal_consents,omitempty"`
@@ -1824,9 +2002,9 @@ type (
 		Category    string  `json:"category,omitempty"`
 		ImageUrl    string  `json:"image_url,omitempty"`
 		HomeUrl     string  `json:"home_url,omitempty"`
-		CreateTime  string  `json:"creat_time,omitempty"` //Read only
-		UpdateTime  string  `json:"updat_time,omitempty"` //Read only
-		Links       []*Link `json:"links,omitempty"`      //Read only
+		CreateTime  string  `json:"create_time,omitempty"` //Read only
+		UpdateTime  string  `json:"update_time,omitempty"` //Read only
+		Links       []*Link `json:"links,omitempty"`       //Read only
 	}
 
 	// ListProductsRequest represents query params for list products call
@@ -1844,6 +2022,22 @@ type (
 		Links      []*Link    `json:"links"` //Read only
 	}
 
+	// ProductIterator iterates over the pages of products, following the `next` links
+	//
+	//	it := c.IterateProducts(&paypal.ListProductsRequest{PageSize: 20})
+	//	for it.Next() {
+	//		for _, product := range it.Page().Products { ... }
+	//	}
+	//	if err := it.Err(); err != nil { ... }
+	ProductIterator struct {
+		client  *Client
+		params  *ListProductsRequest
+		page    *ListProductsResponse
+		visited map[string]bool
+		err     error
+		done    bool
+	}
+
 	// PatchObject represents the object used for updating PayPal objects
 	PatchObject struct {
 		Operation string `json:"op"`
@@ -1851,6 +2045,21 @@ type (
 		Value     string `json:"value"`
 	}
 
+	// ProductPatchSet represents the patches to apply to a single product with BulkUpdateProducts
+	ProductPatchSet struct {
+		ProductID string
+		Patches   []*PatchObject
+	}
+
+	// ProductUpdateResult represents the outcome of updating a single product with BulkUpdateProducts
+	ProductUpdateResult struct {
+		ProductID string
+		Err       error
+	}
+
+	// ProductUpdateResults represents the outcome of BulkUpdateProducts, in the order of the patch sets
+	ProductUpdateResults []ProductUpdateResult
+
 	//Resource v1 for old hooks
 	SuspendBillingAgreementV1Response struct {
 		ID       string             `json:"id"`
@@ -1891,7 +2100,7 @@ type (
 	BillingCycle struct {
 		PricingScheme *PricingScheme `json:"pricing_scheme,omitempty"` //Free Trial Cycle doesn't require scheme
 		Frequency     *Frequency     `json:"frequency"`
-		TenureType    string         `json:"tenure_type"`
+		TenureType    TenureType     `json:"tenure_type"`
 		Sequence      uint64         `json:"sequence"`               //min: 0, max: 99
 		TotalCycles   uint64         `json:"total_cycles,omitempty"` //default: 1, min: 0, max: 999
 	}
@@ -2048,7 +2257,7 @@ type (
 
 	// SubscriberRequest represents the subscriber details
 	SubscriberRequest struct {
-		Name            *PayerName      `json:"name,omitempty,omitempty"`
+		Name            *PayerName      `json:"name,omitempty"`
 		EmailAddress    string          `json:"email_address,omitempty"`
 		PayerID         string          `json:"payer_id,omitempty"` //Read only
 		ShippingAddress *ShippingDetail `json:"shipping_address,omitempty"`
@@ -2057,7 +2266,7 @@ type (
 
 	// Subscriber represents the subscriber details
 	Subscriber struct {
-		Name            *Name                  `json:"name,omitempty,omitempty"`
+		Name            *Name                  `json:"name,omitempty"`
 		EmailAddress    string                 `json:"email_address,omitempty"`
 		PayerID         string                 `json:"payer_id,omitempty"` //Read only
 		ShippingAddress *ShippingDetail        `json:"shipping_address,omitempty"`
@@ -2108,9 +2317,9 @@ type (
 	// | UNKNOWN | Card type cannot be determined. |
 	// ---------------------------------------------
 	CardResponseWithBillingAddress struct {
-		LastDigit      string           `json:"last_digit,omitempty"` //Read only
-		Brand          string           `json:"brand,omitempty"`      //Read only
-		Type           string           `json:"type,omitempty"`       //Read only
+		LastDigit      string           `json:"last_digits,omitempty"` //Read only
+		Brand          string           `json:"brand,omitempty"`       //Read only
+		Type           string           `json:"type,omitempty"`        //Read only
 		Name           string           `json:"name,omitempty"`
 		BillingAddress *AddressPortable `json:"billing_address,omitempty"`
 	}
@@ -2133,7 +2342,7 @@ type (
 		ShippingAmount   *Money                   `json:"shipping_amount,omitempty"`
 		Subscriber       *Subscriber              `json:"subscriber,omitempty"`
 		BillingInfo      *SubscriptionBillingInfo `json:"billing_info,omitempty"` //Read only
-		CreateTime       string                   `json:"created_time"`           //Read only
+		CreateTime       string                   `json:"create_time"`            //Read only
 		UpdateTime       string                   `json:"update_time"`            //Read only
 		Links            []*Link                  `json:"links"`                  //Read only
 	}
@@ -2160,12 +2369,12 @@ type (
 	// | TRIAL   | A trial billing cycle.   |
 	// --------------------------------------
 	CycleExecution struct {
-		TenureType                  string `json:"tenure_type"`                              //Read only
-		Sequence                    uint64 `json:"sequence"`                                 //min: 0, max: 99
-		CyclesCompleted             uint64 `json:"cycles_completed"`                         //min: 0, max: 9999 Read only
-		CyclesRemaining             uint64 `json:"cycles_remaining,omitempty"`               //min: 0, max: 9999 Read only
-		CurrentPricingSchemeVersion uint64 `json:"current_pricing_scheme_version,omitempty"` //min: 0, max: 99 Read only
-		TotalCycles                 uint64 `json:"total_cycles,omitempty"`                   //min: 0, max: 999 Read only
+		TenureType                  TenureType `json:"tenure_type"`                              //Read only
+		Sequence                    uint64     `json:"sequence"`                                 //min: 0, max: 99
+		CyclesCompleted             uint64     `json:"cycles_completed"`                         //min: 0, max: 9999 Read only
+		CyclesRemaining             uint64     `json:"cycles_remaining,omitempty"`               //min: 0, max: 9999 Read only
+		CurrentPricingSchemeVersion uint64     `json:"current_pricing_scheme_version,omitempty"` //min: 0, max: 99 Read only
+		TotalCycles                 uint64     `json:"total_cycles,omitempty"`                   //min: 0, max: 999 Read only
 	}
 
 	// LastPaymentDetails represents details for the last payment
@@ -2271,19 +2480,92 @@ type (
 		Summary         string          `json:"summary"`
 		Resource        json.RawMessage `json:"resource"`
 		Links           []*Link         `json:"links"`
+
+		raw json.RawMessage
 	}
 
-	// RefundRequest represents body parameters for refund capture payment
+	// RefundRequest represents body parameters for refund capture payment.
+	// PaymentInstruction.PlatformFees is the part of the platform fees the partner returns with the refund,
+	// the partner keeps its fees when omitted, see Capture.PlatformFeeRefund
 	RefundRequest struct {
-		Amount      *Money `json:"amount,omitempty"`
-		InvoiceID   string `json:"invoice_id,omitempty"`
-		NoteToPayer string `json:"note_to_payer,omitempty"`
+		Amount             *Money              `json:"amount,omitempty"`
+		InvoiceID          string              `json:"invoice_id,omitempty"`
+		CustomID           string              `json:"custom_id,omitempty"`
+		NoteToPayer        string              `json:"note_to_payer,omitempty"`
+		PaymentInstruction *PaymentInstruction `json:"payment_instruction,omitempty"`
 	}
 )
 
-// Error method implementation for ErrorResponse struct
+// Error method implementation for ErrorResponse struct, the request and status are left out when the
+// error response was built without them
 func (r *ErrorResponse) Error() string {
-	return fmt.Sprintf("%v %v: %d %s, %+v", r.Response.Request.Method, r.Response.Request.URL, r.Response.StatusCode, r.Message, r.Details)
+	var b strings.Builder
+	if r.Response != nil {
+		if r.Response.Request != nil {
+			fmt.Fprintf(&b, "%v %v: ", r.Response.Request.Method, r.Response.Request.URL)
+		}
+		fmt.Fprintf(&b, "%d ", r.Response.StatusCode)
+	}
+
+	message := r.Message
+	if message == "" {
+		message = r.Name
+	}
+	b.WriteString(message)
+	if r.DebugID != "" {
+		fmt.Fprintf(&b, " (debug_id %s)", r.DebugID)
+	}
+
+	for i, detail := range r.Details {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		if detail.Field != "" {
+			b.WriteString(detail.Field + " ")
+		}
+		b.WriteString(detail.Issue)
+		if detail.Description != "" {
+			b.WriteString(" - " + detail.Description)
+		}
+	}
+	return b.String()
+}
+
+// DetailsFor returns the details of the error about the field. The field is written as in ValidationError, e.g.
+// purchase_units[0].amount.value, and also matches the JSON pointers of the v2 APIs, e.g. /purchase_units/0/amount/value
+func (r *ErrorResponse) DetailsFor(field string) []ErrorResponseDetail {
+	var details []ErrorResponseDetail
+	for _, detail := range r.Details {
+		if errorFieldPath(detail.Field) == errorFieldPath(field) {
+			details = append(details, detail)
+		}
+	}
+	return details
+}
+
+// errorFieldPath turns a JSON pointer into the dotted path of ValidationError, indexes and reference id
+// selectors become subscripts, e.g. /purchase_units/@reference_id=='default'/amount becomes
+// purchase_units[@reference_id=='default'].amount
+func errorFieldPath(field string) string {
+	if !strings.HasPrefix(field, "/") {
+		return field
+	}
+
+	var b strings.Builder
+	for _, segment := range strings.Split(field[1:], "/") {
+		switch {
+		case segment == "":
+		case strings.HasPrefix(segment, "@") || strings.Trim(segment, "0123456789") == "":
+			b.WriteString("[" + segment + "]")
+		case b.Len() > 0:
+			b.WriteString("." + segment)
+		default:
+			b.WriteString(segment)
+		}
+	}
+	return b.String()
 }
 
 // MarshalJSON for JSONTime
@@ -2292,7 +2574,126 @@ func (t JSONTime) MarshalJSON() ([]byte, error) {
 	return []byte(stamp), nil
 }
 
-func (e *expirationTime) UnmarshalJSON(b []byte) error {
+// UnmarshalJSON parses any of the timestamp formats PayPal sends, see parseTimestamp.
+// An empty string or null leaves the time zero
+func (t *JSONTime) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return &TimestampError{Value: string(b)}
+	}
+
+	parsed, err := parseTimestamp(s)
+	if err != nil {
+		return &TimestampError{Value: s}
+	}
+	*t = JSONTime(parsed)
+	return nil
+}
+
+// Time returns the timestamp as a time.Time
+func (t JSONTime) Time() time.Time {
+	return time.Time(t)
+}
+
+// MarshalJSON leaves out the application context when it is empty
+func (r AuthorizeOrderRequest) MarshalJSON() ([]byte, error) {
+	type authorizeOrderRequest AuthorizeOrderRequest
+	out := struct {
+		authorizeOrderRequest
+		ApplicationContext *ApplicationContext `json:"application_context,omitempty"`
+	}{authorizeOrderRequest: authorizeOrderRequest(r)}
+
+	if r.ApplicationContext != (ApplicationContext{}) {
+		out.ApplicationContext = &r.ApplicationContext
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON leaves out the start date, plan and payer when they are empty
+func (a BillingAgreement) MarshalJSON() ([]byte, error) {
+	type billingAgreement BillingAgreement
+	out := struct {
+		billingAgreement
+		StartDate *JSONTime    `json:"start_date,omitempty"`
+		Plan      *BillingPlan `json:"plan,omitempty"`
+		Payer     *Payer       `json:"payer,omitempty"`
+	}{billingAgreement: billingAgreement(a)}
+
+	if !a.StartDate.Time().IsZero() {
+		out.StartDate = &a.StartDate
+	}
+	if !isZero(a.Plan) {
+		out.Plan = &a.Plan
+	}
+	if !isZero(a.Payer) {
+		out.Payer = &a.Payer
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON leaves out the amount when it is empty
+func (m ChargeModel) MarshalJSON() ([]byte, error) {
+	type chargeModel ChargeModel
+	out := struct {
+		chargeModel
+		Amount *AmountPayout `json:"amount,omitempty"`
+	}{chargeModel: chargeModel(m)}
+
+	if m.Amount != (AmountPayout{}) {
+		out.Amount = &m.Amount
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON leaves out the amount when it is empty
+func (d PaymentDefinition) MarshalJSON() ([]byte, error) {
+	type paymentDefinition PaymentDefinition
+	out := struct {
+		paymentDefinition
+		Amount *AmountPayout `json:"amount,omitempty"`
+	}{paymentDefinition: paymentDefinition(d)}
+
+	if d.Amount != (AmountPayout{}) {
+		out.Amount = &d.Amount
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON leaves out the error when the item has none
+func (r PayoutItemResponse) MarshalJSON() ([]byte, error) {
+	type payoutItemResponse PayoutItemResponse
+	out := struct {
+		payoutItemResponse
+		Error *ErrorResponse `json:"errors,omitempty"`
+	}{payoutItemResponse: payoutItemResponse(r)}
+
+	if !isZero(r.Error) {
+		out.Error = &r.Error
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON leaves out the last payment when there is none
+func (i SubscriptionBillingInfo) MarshalJSON() ([]byte, error) {
+	type subscriptionBillingInfo SubscriptionBillingInfo
+	out := struct {
+		subscriptionBillingInfo
+		LastPayment *LastPaymentDetails `json:"last_payment,omitempty"`
+	}{subscriptionBillingInfo: subscriptionBillingInfo(i)}
+
+	if i.LastPayment != (LastPaymentDetails{}) {
+		out.LastPayment = &i.LastPayment
+	}
+	return json.Marshal(out)
+}
+
+// isZero reports whether v is the zero value of its type, omitempty has no effect on struct values
+func isZero(v interface{}) bool {
+	return reflect.DeepEqual(v, reflect.Zero(reflect.TypeOf(v)).Interface())
+}
+
+// UnmarshalJSON decodes the number of seconds
+func (e *ExpirationTime) UnmarshalJSON(b []byte) error {
 	var n json.Number
 	err := json.Unmarshal(b, &n)
 	if err != nil {
@@ -2302,6 +2703,21 @@ func (e *expirationTime) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	*e = expirationTime(i)
+	*e = ExpirationTime(i)
+	return nil
+}
+
+// Duration returns the lifetime as a time.Duration
+func (e ExpirationTime) Duration() time.Duration {
+	return time.Duration(e) * time.Second
+}
+
+// findLink returns the first link with the given rel, or nil
+func findLink(links []*Link, rel string) *Link {
+	for _, l := range links {
+		if l != nil && l.Rel == rel {
+			return l
+		}
+	}
 	return nil
 }
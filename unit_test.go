@@ -1,11 +1,22 @@
 package paypal
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type webprofileTestServer struct {
@@ -140,6 +151,70 @@ func TestTypeErrorResponseTwo(t *testing.T) {
 	}
 }
 
+func TestErrorResponseError(t *testing.T) {
+	errResp := &ErrorResponse{
+		Name:    "UNPROCESSABLE_ENTITY",
+		Message: "The requested action could not be performed, semantically incorrect, or failed business validation.",
+		DebugID: "b5be3d1bf2ee8",
+		Details: []ErrorResponseDetail{{
+			Field:       "/purchase_units/@reference_id=='default'/amount/value",
+			Issue:       "AMOUNT_MISMATCH",
+			Description: "Should equal item_total + tax_total + shipping + handling + insurance - shipping_discount - discount.",
+		}, {
+			Issue: "PAYEE_ACCOUNT_RESTRICTED",
+		}},
+	}
+
+	expected := "The requested action could not be performed, semantically incorrect, or failed business validation. (debug_id b5be3d1bf2ee8): " +
+		"/purchase_units/@reference_id=='default'/amount/value AMOUNT_MISMATCH - Should equal item_total + tax_total + shipping + handling + insurance - shipping_discount - discount.; " +
+		"PAYEE_ACCOUNT_RESTRICTED"
+	if msg := errResp.Error(); msg != expected {
+		t.Errorf("Expected %q, got %q", expected, msg)
+	}
+
+	errResp.Response = &http.Response{StatusCode: http.StatusUnprocessableEntity}
+	if msg := errResp.Error(); !strings.HasPrefix(msg, "422 The requested action") {
+		t.Errorf("Expected the status without the request, got %q", msg)
+	}
+
+	errResp.Response.Request = httptest.NewRequest("POST", "https://api.sandbox.paypal.com/v2/checkout/orders", nil)
+	if msg := errResp.Error(); !strings.HasPrefix(msg, "POST https://api.sandbox.paypal.com/v2/checkout/orders: 422 The requested action") {
+		t.Errorf("Expected the request and status, got %q", msg)
+	}
+
+	if msg := (&ErrorResponse{Name: "INTERNAL_SERVER_ERROR"}).Error(); msg != "INTERNAL_SERVER_ERROR" {
+		t.Errorf("Expected the name without a message, got %q", msg)
+	}
+}
+
+func TestErrorResponseDetailsFor(t *testing.T) {
+	errResp := &ErrorResponse{Details: []ErrorResponseDetail{
+		{Field: "/purchase_units/@reference_id=='default'/amount/value", Issue: "AMOUNT_MISMATCH"},
+		{Field: "/purchase_units/0/items/1/quantity", Issue: "INVALID_PARAMETER_VALUE"},
+		{Field: "items[0].recipient_type", Issue: "Value is invalid"},
+	}}
+
+	tests := []struct {
+		field string
+		issue string
+	}{
+		{"purchase_units[@reference_id=='default'].amount.value", "AMOUNT_MISMATCH"},
+		{"/purchase_units/@reference_id=='default'/amount/value", "AMOUNT_MISMATCH"},
+		{"purchase_units[0].items[1].quantity", "INVALID_PARAMETER_VALUE"},
+		{"items[0].recipient_type", "Value is invalid"},
+	}
+	for _, tt := range tests {
+		details := errResp.DetailsFor(tt.field)
+		if len(details) != 1 || details[0].Issue != tt.issue {
+			t.Errorf("Expected %s for %s, got %+v", tt.issue, tt.field, details)
+		}
+	}
+
+	if details := errResp.DetailsFor("purchase_units[0].amount"); len(details) != 0 {
+		t.Errorf("Expected no details, got %+v", details)
+	}
+}
+
 func TestTypePayoutResponse(t *testing.T) {
 	response := `{
 		"batch_header":{
@@ -376,6 +451,9 @@ func (ts *webprofileTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request
 		if r.Method == "PUT" {
 			ts.updatevalid(w, r)
 		}
+		if r.Method == "PATCH" {
+			ts.patchvalid(w, r)
+		}
 		if r.Method == "DELETE" {
 			ts.deletevalid(w, r)
 		}
@@ -387,6 +465,9 @@ func (ts *webprofileTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request
 		if r.Method == "PUT" {
 			ts.updateinvalid(w, r)
 		}
+		if r.Method == "PATCH" {
+			ts.updateinvalid(w, r)
+		}
 		if r.Method == "DELETE" {
 			ts.deleteinvalid(w, r)
 		}
@@ -472,6 +553,19 @@ func (ts *webprofileTestServer) updatevalid(w http.ResponseWriter, r *http.Reque
 
 }
 
+func (ts *webprofileTestServer) patchvalid(w http.ResponseWriter, r *http.Request) {
+	var data []map[string]interface{}
+
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil || len(data) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"name":"VALIDATION_ERROR","message":"invalid patch"}`))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (ts *webprofileTestServer) updateinvalid(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNotFound)
@@ -686,6 +780,30 @@ func TestSetWebProfile_valid(t *testing.T) {
 
 }
 
+func TestPatchWebProfile(t *testing.T) {
+	ts := httptest.NewServer(&webprofileTestServer{t: t})
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	patch := []*PaymentPatch{
+		{Operation: OperationReplace, Path: WebProfilePathName, Value: "Shop T-Shirt YeowZa!"},
+		{Operation: OperationReplace, Path: WebProfilePathInputFieldsNoShipping, Value: NoShippingHide},
+	}
+
+	if err := c.PatchWebProfile("XP-CP6S-W9DY-96H8-MVN2", patch); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.PatchWebProfile("foobar", patch); err == nil {
+		t.Fatalf("expecting an error got nil")
+	}
+
+	if err := c.PatchWebProfile("", patch); err == nil {
+		t.Fatalf("expecting an error got nil")
+	}
+}
+
 func TestSetWebProfile_invalid(t *testing.T) {
 	ts := httptest.NewServer(&webprofileTestServer{t: t})
 	defer ts.Close()
@@ -743,3 +861,2265 @@ func TestDeleteWebProfile_invalid(t *testing.T) {
 	}
 
 }
+
+func TestValidateCreatePlan(t *testing.T) {
+	plan := &CreatePlan{
+		ProductID: "PROD-XXCD1234QWER65782",
+		Name:      "Video Streaming Service Plan",
+		Status:    PlanStatusActive,
+		BillingCycles: []*BillingCycle{
+			{TenureType: TenureTypeTrial, Sequence: 1, TotalCycles: 1, Frequency: &Frequency{IntervalUnit: IntervalUnitMonth, IntervalCount: 1}},
+			{TenureType: TenureTypeRegular, Sequence: 2, Frequency: &Frequency{IntervalUnit: IntervalUnitMonth, IntervalCount: 1},
+				PricingScheme: &PricingScheme{FixedPrice: &Money{Currency: "USD", Value: "10.00"}}},
+		},
+		PaymentPreferences: &PaymentPreferences{SetupFeeFailureAction: FailureActionContinue},
+	}
+	if err := plan.Validate(); err != nil {
+		t.Errorf("Not expected error for valid plan, got %v", err)
+	}
+
+	plan.BillingCycles[1].TenureType = "regular"
+	if err := plan.Validate(); err == nil {
+		t.Errorf("Expected error for invalid tenure_type")
+	}
+
+	plan.BillingCycles[1].TenureType = TenureTypeRegular
+	plan.PaymentPreferences.SetupFeeFailureAction = "RETRY"
+	if err := plan.Validate(); err == nil {
+		t.Errorf("Expected error for invalid setup_fee_failure_action")
+	}
+
+	plan.PaymentPreferences.SetupFeeFailureAction = ""
+	plan.Status = PlanStatusInactive
+	if err := plan.Validate(); err == nil {
+		t.Errorf("Expected error for INACTIVE initial plan status")
+	}
+	plan.Status = PlanStatusActive
+
+	tests := []struct {
+		field  string
+		modify func(p *CreatePlan)
+	}{
+		{"product_id", func(p *CreatePlan) { p.ProductID = "" }},
+		{"name", func(p *CreatePlan) { p.Name = strings.Repeat("a", 128) }},
+		{"billing_cycles", func(p *CreatePlan) { p.BillingCycles[0].Sequence = 3 }},
+		{"billing_cycles[1].sequence", func(p *CreatePlan) { p.BillingCycles[1].Sequence = 1 }},
+		{"billing_cycles[0].total_cycles", func(p *CreatePlan) { p.BillingCycles[0].TotalCycles = 0 }},
+		{"billing_cycles[1].frequency.interval_count", func(p *CreatePlan) { p.BillingCycles[1].Frequency.IntervalCount = 13 }},
+		{"billing_cycles[1].pricing_scheme.fixed_price", func(p *CreatePlan) { p.BillingCycles[1].PricingScheme = nil }},
+		{"billing_cycles[1].pricing_scheme.fixed_price.value", func(p *CreatePlan) { p.BillingCycles[1].PricingScheme.FixedPrice.Value = "10.001" }},
+		{"billing_cycles", func(p *CreatePlan) {
+			p.BillingCycles[0].TenureType = TenureTypeRegular
+			p.BillingCycles[0].PricingScheme = p.BillingCycles[1].PricingScheme
+		}},
+	}
+	for _, tt := range tests {
+		p := *plan
+		p.BillingCycles = []*BillingCycle{}
+		for _, cycle := range plan.BillingCycles {
+			c := *cycle
+			c.Frequency = &Frequency{IntervalUnit: cycle.Frequency.IntervalUnit, IntervalCount: cycle.Frequency.IntervalCount}
+			if cycle.PricingScheme != nil {
+				c.PricingScheme = &PricingScheme{FixedPrice: &Money{Currency: "USD", Value: "10.00"}}
+			}
+			p.BillingCycles = append(p.BillingCycles, &c)
+		}
+		tt.modify(&p)
+
+		err := p.Validate()
+		if vErr, ok := err.(*ValidationError); !ok || vErr.Field != tt.field {
+			t.Errorf("expected a validation error for %s, got %v", tt.field, err)
+		}
+	}
+}
+
+func TestEventSubscriptionResource(t *testing.T) {
+	response := `{
+		"id": "WH-77687562XN25889J8-8Y6T55435R66168T6",
+		"create_time": "2018-19-12T22:20:32.000Z",
+		"resource_type": "subscription",
+		"event_type": "BILLING.SUBSCRIPTION.ACTIVATED",
+		"summary": "A billing agreement was activated.",
+		"resource": {
+			"id": "I-BW452GLLEP1G",
+			"plan_id": "P-5ML4271244454362WXNWU5NQ",
+			"status": "ACTIVE",
+			"quantity": "20",
+			"billing_info": {
+				"outstanding_balance": {"currency_code": "USD", "value": "1.00"},
+				"failed_payments_count": 2
+			}
+		}
+	}`
+
+	e := &Event{}
+	if err := json.Unmarshal([]byte(response), e); err != nil {
+		t.Fatalf("Event Unmarshal failed: %v", err)
+	}
+
+	s, err := e.SubscriptionResource()
+	if err != nil {
+		t.Fatalf("Not expected error for SubscriptionResource(), got %v", err)
+	}
+	if s.ID != "I-BW452GLLEP1G" ||
+		s.Status != SubscriptionStatusActive ||
+		s.BillingInfo.FailedPaymentsCount != 2 {
+		t.Errorf("Subscription decoded result is incorrect, Given: %+v", s)
+	}
+
+	if _, err := e.SaleResource(); err == nil {
+		t.Errorf("Expected error for SaleResource() on subscription event")
+	}
+}
+
+func TestEventSaleResource(t *testing.T) {
+	response := `{
+		"id": "WH-2WR32451HC0233532-67976317FL4543714",
+		"event_type": "PAYMENT.SALE.COMPLETED",
+		"resource_type": "sale",
+		"resource": {
+			"id": "80021663DE681814L",
+			"state": "completed",
+			"amount": {"total": "10.00", "currency": "USD", "details": {"subtotal": "10.00"}},
+			"billing_agreement_id": "I-BW452GLLEP1G",
+			"custom": "internal-42",
+			"transaction_fee": {"value": "0.59", "currency": "USD"}
+		}
+	}`
+
+	e := &Event{}
+	if err := json.Unmarshal([]byte(response), e); err != nil {
+		t.Fatalf("Event Unmarshal failed: %v", err)
+	}
+
+	s, err := e.SaleResource()
+	if err != nil {
+		t.Fatalf("Not expected error for SaleResource(), got %v", err)
+	}
+	if s.ID != "80021663DE681814L" ||
+		s.BillingAgreementID != "I-BW452GLLEP1G" ||
+		s.Custom != "internal-42" ||
+		s.Amount.Total != "10.00" ||
+		s.TransactionFee.Value != "0.59" {
+		t.Errorf("Sale decoded result is incorrect, Given: %+v", s)
+	}
+}
+
+func TestValidateProductPatch(t *testing.T) {
+	valid := []*PatchObject{
+		ProductPatchReplace(ProductPathDescription, "Premium video streaming service"),
+		ProductPatchAdd(ProductPathImageURL, "https://example.com/streaming.jpg"),
+		ProductPatchRemove(ProductPathHomeURL),
+	}
+	if err := validateProductPatch(valid); err != nil {
+		t.Errorf("Not expected error for valid product patch, got %v", err)
+	}
+
+	invalid := [][]*PatchObject{
+		nil,
+		{ProductPatchReplace("/name", "New name")},
+		{{Operation: "move", Path: ProductPathCategory, Value: CategorySoftware}},
+		{ProductPatchReplace(ProductPathCategory, "")},
+	}
+	for _, body := range invalid {
+		if err := validateProductPatch(body); err == nil {
+			t.Errorf("Expected error for product patch %+v", body)
+		}
+	}
+}
+
+func TestIterateProducts(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "1":
+			if r.URL.Query().Get("page_size") != "2" || r.URL.Query().Get("total_required") != "true" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"products":[{"id":"PROD-1"},{"id":"PROD-2"}],"links":[{"href":"` + ts.URL + `/v1/catalogs/products?page_size=2&page=2","rel":"next","method":"GET"}]}`))
+		case "2":
+			w.Write([]byte(`{"products":[{"id":"PROD-3"}],"links":[{"href":"` + ts.URL + `/v1/catalogs/products?page_size=2&page=1","rel":"prev","method":"GET"}]}`))
+		default:
+			t.Errorf("unexpected page %s", r.URL.RawQuery)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	var products []*Product
+	it := c.IterateProducts(&ListProductsRequest{PageSize: 2, Page: 1, TotalRequired: true})
+	for it.Next() {
+		products = append(products, it.Page().Products...)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(products) != 3 || products[2].ID != "PROD-3" {
+		t.Errorf("expected 3 products, got %+v", products)
+	}
+}
+
+func TestBulkUpdateProducts(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inFlight int
+		maxSeen  int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxSeen {
+			maxSeen = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		if r.Method != "PATCH" {
+			t.Errorf("expected PATCH, got %s", r.Method)
+		}
+		if r.URL.Path == "/v1/catalogs/products/PROD-MISSING" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"name":"RESOURCE_NOT_FOUND","message":"The specified resource does not exist."}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	var sets []ProductPatchSet
+	for i := 0; i < 10; i++ {
+		sets = append(sets, ProductPatchSet{
+			ProductID: fmt.Sprintf("PROD-%d", i),
+			Patches:   []*PatchObject{ProductPatchReplace(ProductPathImageURL, "https://example.com/image.jpg")},
+		})
+	}
+	sets = append(sets,
+		ProductPatchSet{ProductID: "PROD-MISSING", Patches: []*PatchObject{ProductPatchRemove(ProductPathHomeURL)}},
+		ProductPatchSet{ProductID: "PROD-INVALID", Patches: []*PatchObject{ProductPatchReplace("/name", "x")}},
+	)
+
+	results := c.BulkUpdateProducts(sets, 3)
+
+	if len(results) != len(sets) {
+		t.Fatalf("expected %d results, got %d", len(sets), len(results))
+	}
+	for i, result := range results {
+		if result.ProductID != sets[i].ProductID {
+			t.Errorf("result %d is for %s, expected %s", i, result.ProductID, sets[i].ProductID)
+		}
+	}
+
+	failed := results.Failed()
+	if len(failed) != 2 || failed[0].ProductID != "PROD-MISSING" || failed[1].ProductID != "PROD-INVALID" {
+		t.Errorf("expected PROD-MISSING and PROD-INVALID to fail, got %+v", failed)
+	}
+	if maxSeen > 3 {
+		t.Errorf("expected at most 3 concurrent requests, got %d", maxSeen)
+	}
+}
+
+func TestCreateBillingAgreementToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/billing-agreements/agreement-tokens" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		body := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		plan := body["plan"].(map[string]interface{})
+		if plan["type"] != AgreementPlanTypeMerchantInitiatedBilling {
+			t.Errorf("unexpected plan type %v", plan["type"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{
+			"links": [
+				{"href": "https://www.sandbox.paypal.com/agreements/approve?ba_token=BA-8A802366G0648845Y", "rel": "approval_url", "method": "POST"},
+				{"href": "https://api.sandbox.paypal.com/v1/billing-agreements/agreement-tokens/BA-8A802366G0648845Y", "rel": "self", "method": "GET"}
+			],
+			"token_id": "BA-8A802366G0648845Y"
+		}`))
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	token, err := c.CreateBillingAgreementToken(&CreateBillingAgreementTokenRequest{
+		Description: "Billing Agreement",
+		Payer:       &Payer{PaymentMethod: "PAYPAL"},
+		Plan: &BillingAgreementTokenPlan{
+			Type: AgreementPlanTypeMerchantInitiatedBilling,
+			MerchantPreferences: &BillingAgreementMerchantPreferences{
+				ReturnURL:           "https://example.com/return",
+				CancelURL:           "https://example.com/cancel",
+				AcceptedPaymentType: AcceptedPaymentTypeInstant,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.TokenID != "BA-8A802366G0648845Y" ||
+		token.ApprovalURL() != "https://www.sandbox.paypal.com/agreements/approve?ba_token=BA-8A802366G0648845Y" {
+		t.Errorf("BillingAgreementToken decoded result is incorrect, Given: %+v", token)
+	}
+}
+
+func TestAgreementStateChanges(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer foo" {
+			t.Errorf("expected the access token, got %q", r.Header.Get("Authorization"))
+		}
+
+		agr := AgreementRequest{}
+		json.NewDecoder(r.Body).Decode(&agr)
+		if agr.Note != "Customer requested" && len(paths) <= 3 {
+			t.Errorf("unexpected note %q", agr.Note)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+	agr := AgreementRequest{Note: "Customer requested"}
+
+	if _, err := c.SuspendAgreement("I-0LN988D3JACS", agr); err != nil {
+		t.Errorf("Not expected error for SuspendAgreement, got %v", err)
+	}
+	if _, err := c.ReActivateAgreement("I-0LN988D3JACS", agr); err != nil {
+		t.Errorf("Not expected error for ReActivateAgreement, got %v", err)
+	}
+	if _, err := c.CancelAgreement("I-0LN988D3JACS", agr); err != nil {
+		t.Errorf("Not expected error for CancelAgreement, got %v", err)
+	}
+	if _, err := c.CancelAgreement("I-0LN988D3JACS", AgreementRequest{}); err == nil {
+		t.Errorf("Expected error for CancelAgreement without note")
+	}
+
+	// PayPal decides about the note without validation
+	c.DisableValidation()
+	agr.Note = ""
+	if _, err := c.SuspendAgreement("I-0LN988D3JACS", agr); err != nil {
+		t.Errorf("Not expected error for SuspendAgreement without validation, got %v", err)
+	}
+
+	expected := []string{
+		"/v1/payments/billing-agreements/I-0LN988D3JACS/suspend",
+		"/v1/payments/billing-agreements/I-0LN988D3JACS/re-activate",
+		"/v1/payments/billing-agreements/I-0LN988D3JACS/cancel",
+		"/v1/payments/billing-agreements/I-0LN988D3JACS/suspend",
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected requests %v, got %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("expected requests %v, got %v", expected, paths)
+		}
+	}
+}
+
+func TestGetAgreementAndTransactions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/payments/billing-agreements/B-50V812176H0783741":
+			fmt.Fprint(w, `{"id":"B-50V812176H0783741","state":"ACTIVE","description":"Billing Agreement","plan":{"type":"MERCHANT_INITIATED_BILLING"}}`)
+		case "/v1/payments/billing-agreements/B-50V812176H0783741/transactions":
+			if r.URL.Query().Get("start_date") != "2020-01-01" || r.URL.Query().Get("end_date") != "2020-01-31" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"agreement_transaction_list":[{"transaction_id":"9EP22596VU4085001","status":"Completed","transaction_type":"Recurring Payment","amount":{"currency":"USD","value":"10.00"},"time_stamp":"2020-01-15T10:00:00Z"}]}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	agreement, err := c.GetAgreement("B-50V812176H0783741")
+	if err != nil {
+		t.Fatalf("Not expected error for GetAgreement, got %v", err)
+	}
+	if agreement.State != "ACTIVE" || agreement.Plan == nil || agreement.Plan.Type != AgreementPlanTypeMerchantInitiatedBilling {
+		t.Errorf("unexpected agreement %+v", agreement)
+	}
+
+	transactions, err := c.ListAgreementTransactions("B-50V812176H0783741", &AgreementTransactionsRequest{
+		StartDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Not expected error for ListAgreementTransactions, got %v", err)
+	}
+	if len(transactions.AgreementTransactionList) != 1 || transactions.AgreementTransactionList[0].Amount.Value != "10.00" {
+		t.Errorf("unexpected transactions %+v", transactions)
+	}
+
+	if _, err := c.ListAgreementTransactions("B-50V812176H0783741", &AgreementTransactionsRequest{}); err == nil {
+		t.Errorf("Expected error for ListAgreementTransactions without dates")
+	}
+}
+
+func TestChargeBillingAgreement(t *testing.T) {
+	var requestIDs, metadataIDs []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs = append(requestIDs, r.Header.Get("PayPal-Request-Id"))
+		metadataIDs = append(metadataIDs, r.Header.Get("PayPal-Client-Metadata-Id"))
+
+		switch r.URL.Path {
+		case "/v2/checkout/orders":
+			body := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["intent"] != string(OrderIntentCapture) {
+				t.Errorf("unexpected intent %v", body["intent"])
+			}
+			fmt.Fprint(w, `{"id":"5O190127TN364715T","status":"CREATED"}`)
+		case "/v2/checkout/orders/5O190127TN364715T/capture":
+			captureRequest := CaptureOrderRequest{}
+			json.NewDecoder(r.Body).Decode(&captureRequest)
+			token := captureRequest.PaymentSource.Token
+			if token == nil || token.ID != "B-50V812176H0783741" || token.Type != PaymentSourceTokenTypeBillingAgreement {
+				t.Errorf("unexpected payment source %+v", captureRequest.PaymentSource)
+			}
+			if captureRequest.PaymentSource.Card != nil {
+				t.Errorf("card payment source must be omitted")
+			}
+			fmt.Fprint(w, `{"id":"5O190127TN364715T","status":"COMPLETED"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	amount := &PurchaseUnitAmount{Currency: "USD", Value: "10.00"}
+	capture, err := c.ChargeBillingAgreement(context.Background(), "B-50V812176H0783741", amount, &ChargeBillingAgreementOptions{SoftDescriptor: "SUBSCRIPTION"})
+	if err != nil {
+		t.Fatalf("Not expected error for ChargeBillingAgreement, got %v", err)
+	}
+	if capture.Status != "COMPLETED" {
+		t.Errorf("expected COMPLETED capture, got %s", capture.Status)
+	}
+	if len(requestIDs) != 2 || requestIDs[0] == "" || requestIDs[1] == "" || requestIDs[0] == requestIDs[1] {
+		t.Errorf("expected distinct PayPal-Request-Id headers, got %v", requestIDs)
+	}
+	if metadataIDs[0] != "" || metadataIDs[1] != "" {
+		t.Errorf("expected no PayPal-Client-Metadata-Id header, got %v", metadataIDs)
+	}
+
+	// A retry with the same RequestID sends the same PayPal-Request-Id headers
+	opts := &ChargeBillingAgreementOptions{RequestID: "charge-1001", ClientMetadataID: "e2e7bd5a6bd14f3e"}
+	for i := 0; i < 2; i++ {
+		requestIDs, metadataIDs = nil, nil
+		if _, err := c.ChargeBillingAgreement(context.Background(), "B-50V812176H0783741", amount, opts); err != nil {
+			t.Fatalf("Not expected error for ChargeBillingAgreement, got %v", err)
+		}
+		if len(requestIDs) != 2 || requestIDs[0] != "charge-1001-create" || requestIDs[1] != "charge-1001-capture" {
+			t.Errorf("expected PayPal-Request-Id headers derived from the request ID, got %v", requestIDs)
+		}
+		if metadataIDs[0] != "e2e7bd5a6bd14f3e" || metadataIDs[1] != "e2e7bd5a6bd14f3e" {
+			t.Errorf("expected the PayPal-Client-Metadata-Id header on both requests, got %v", metadataIDs)
+		}
+	}
+}
+
+func TestWebhookCRUD(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/notifications/webhooks":
+			webhook := &Webhook{}
+			json.NewDecoder(r.Body).Decode(webhook)
+			webhook.ID = "0EH40505U7160970P"
+			json.NewEncoder(w).Encode(webhook)
+		case r.Method == "GET" && r.URL.Path == "/v1/notifications/webhooks" && r.URL.Query().Get("anchor_type") == AnchorTypeAccount:
+			fmt.Fprint(w, `{"webhooks":[{"id":"1HG80537L4140544T","url":"https://example.com/platform","anchor_type":"ACCOUNT","event_types":[{"name":"*"}]}]}`)
+		case r.Method == "GET" && r.URL.Path == "/v1/notifications/webhooks":
+			fmt.Fprint(w, `{"webhooks":[{"id":"0EH40505U7160970P","url":"https://example.com/hook","event_types":[{"name":"PAYMENT.SALE.COMPLETED","description":"A sale completes."}]}]}`)
+		case r.Method == "GET" && r.URL.Path == "/v1/notifications/webhooks/0EH40505U7160970P":
+			fmt.Fprint(w, `{"id":"0EH40505U7160970P","url":"https://example.com/hook","event_types":[{"name":"PAYMENT.SALE.COMPLETED"}]}`)
+		case r.Method == "PATCH" && r.URL.Path == "/v1/notifications/webhooks/0EH40505U7160970P":
+			var fields []map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&fields)
+			if len(fields) != 1 || fields[0]["path"] != WebhookPathURL || fields[0]["value"] != "https://example.com/new" {
+				t.Errorf("unexpected patch %v", fields)
+			}
+			fmt.Fprint(w, `{"id":"0EH40505U7160970P","url":"https://example.com/new","event_types":[{"name":"PAYMENT.SALE.COMPLETED"}]}`)
+		case r.Method == "DELETE" && r.URL.Path == "/v1/notifications/webhooks/0EH40505U7160970P":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	created, err := c.CreateWebhook(&CreateWebhookRequest{
+		URL:        "https://example.com/hook",
+		EventTypes: []*EventType{{Name: EventPaymentSaleCompleted}},
+		AnchorType: AnchorTypeAccount,
+	})
+	if err != nil || created.ID != "0EH40505U7160970P" || len(created.EventTypes) != 1 || created.AnchorType != AnchorTypeAccount {
+		t.Errorf("unexpected CreateWebhook result %+v, %v", created, err)
+	}
+
+	list, err := c.ListWebhooks()
+	if err != nil || len(list.Webhooks) != 1 || list.Webhooks[0].EventTypes[0].Description != "A sale completes." {
+		t.Errorf("unexpected ListWebhooks result %+v, %v", list, err)
+	}
+
+	list, err = c.ListWebhooksByAnchorType(AnchorTypeAccount)
+	if err != nil || len(list.Webhooks) != 1 || list.Webhooks[0].AnchorType != AnchorTypeAccount {
+		t.Errorf("unexpected ListWebhooksByAnchorType result %+v, %v", list, err)
+	}
+
+	webhook, err := c.GetWebhook("0EH40505U7160970P")
+	if err != nil || webhook.URL != "https://example.com/hook" {
+		t.Errorf("unexpected GetWebhook result %+v, %v", webhook, err)
+	}
+
+	updated, err := c.UpdateWebhook("0EH40505U7160970P", []WebhookField{
+		{Operation: OperationReplace, Path: WebhookPathURL, Value: "https://example.com/new"},
+	})
+	if err != nil || updated.URL != "https://example.com/new" {
+		t.Errorf("unexpected UpdateWebhook result %+v, %v", updated, err)
+	}
+
+	if err := c.DeleteWebhook("0EH40505U7160970P"); err != nil {
+		t.Errorf("Not expected error for DeleteWebhook, got %v", err)
+	}
+}
+
+func TestEnsureWebhook(t *testing.T) {
+	var created, patched int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1/notifications/webhooks" && r.URL.Query().Get("anchor_type") == AnchorTypeApplication:
+			fmt.Fprint(w, `{"webhooks":[{"id":"0EH40505U7160970P","url":"https://example.com/hook","event_types":[{"name":"PAYMENT.SALE.COMPLETED"},{"name":"PAYMENT.SALE.REFUNDED"}]}]}`)
+		case r.Method == "GET" && r.URL.Path == "/v1/notifications/webhooks" && r.URL.Query().Get("anchor_type") == AnchorTypeAccount:
+			fmt.Fprint(w, `{"webhooks":[{"id":"4TY8239612309481K","url":"https://example.com/platform","anchor_type":"ACCOUNT","event_types":[{"name":"MERCHANT.ONBOARDING.COMPLETED"}]}]}`)
+		case r.Method == "PATCH" && r.URL.Path == "/v1/notifications/webhooks/0EH40505U7160970P":
+			patched++
+			var fields []struct {
+				Path  string       `json:"path"`
+				Value []*EventType `json:"value"`
+			}
+			json.NewDecoder(r.Body).Decode(&fields)
+			if len(fields) != 1 || fields[0].Path != WebhookPathEventTypes || len(fields[0].Value) != 1 || fields[0].Value[0].Name != EventPaymentCaptureCompleted {
+				t.Errorf("unexpected patch %+v", fields)
+			}
+			fmt.Fprint(w, `{"id":"0EH40505U7160970P","url":"https://example.com/hook","event_types":[{"name":"PAYMENT.CAPTURE.COMPLETED"}]}`)
+		case r.Method == "POST" && r.URL.Path == "/v1/notifications/webhooks":
+			created++
+			fmt.Fprint(w, `{"id":"8PT597110X687430LKGECATA","url":"https://example.com/other","event_types":[{"name":"PAYMENT.SALE.COMPLETED"}]}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	id, err := c.EnsureWebhook(context.Background(), "https://example.com/hook", []string{EventPaymentSaleRefunded, EventPaymentSaleCompleted})
+	if err != nil || id != "0EH40505U7160970P" || created != 0 || patched != 0 {
+		t.Errorf("expected an unchanged webhook, got %s, %v, %d created, %d patched", id, err, created, patched)
+	}
+
+	id, err = c.EnsureWebhook(context.Background(), "https://example.com/hook", []string{EventPaymentCaptureCompleted})
+	if err != nil || id != "0EH40505U7160970P" || created != 0 || patched != 1 {
+		t.Errorf("expected a patched webhook, got %s, %v, %d created, %d patched", id, err, created, patched)
+	}
+
+	id, err = c.EnsureWebhook(context.Background(), "https://example.com/platform", []string{EventMerchantOnboardingCompleted})
+	if err != nil || id != "4TY8239612309481K" || created != 0 || patched != 1 {
+		t.Errorf("expected the unchanged account webhook, got %s, %v, %d created, %d patched", id, err, created, patched)
+	}
+
+	id, err = c.EnsureWebhook(context.Background(), "https://example.com/other", []string{EventPaymentSaleCompleted})
+	if err != nil || id != "8PT597110X687430LKGECATA" || created != 1 {
+		t.Errorf("expected a created webhook, got %s, %v, %d created", id, err, created)
+	}
+}
+
+func TestListWebhookEventTypes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/notifications/webhooks-event-types":
+			fmt.Fprint(w, `{"event_types":[{"name":"PAYMENT.SALE.COMPLETED","description":"A sale completes.","status":"ENABLED"},{"name":"BILLING.SUBSCRIPTION.CREATED","description":"A subscription is created.","status":"ENABLED"}]}`)
+		case "/v1/notifications/webhooks/0EH40505U7160970P/event-types":
+			fmt.Fprint(w, `{"event_types":[{"name":"PAYMENT.SALE.COMPLETED","description":"A sale completes.","status":"ENABLED"}]}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	all, err := c.ListWebhookEventTypes()
+	if err != nil || len(all.EventTypes) != 2 || all.EventTypes[0].Status != "ENABLED" {
+		t.Fatalf("unexpected ListWebhookEventTypes result %+v, %v", all, err)
+	}
+
+	unsupported := all.Unsupported(EventPaymentSaleCompleted, "*", "PAYMENT.SALE.EXPLODED")
+	if len(unsupported) != 1 || unsupported[0] != "PAYMENT.SALE.EXPLODED" {
+		t.Errorf("unexpected unsupported event types %v", unsupported)
+	}
+
+	subscribed, err := c.ListEventTypesForWebhook("0EH40505U7160970P")
+	if err != nil || len(subscribed.EventTypes) != 1 || subscribed.EventTypes[0].Name != EventPaymentSaleCompleted {
+		t.Errorf("unexpected ListEventTypesForWebhook result %+v, %v", subscribed, err)
+	}
+}
+
+func TestListWebhookEvents(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/notifications/webhooks-events":
+			q := r.URL.Query()
+			if q.Get("page") == "2" {
+				fmt.Fprint(w, `{"events":[{"id":"WH-2","event_type":"PAYMENT.SALE.COMPLETED"}],"count":1}`)
+				return
+			}
+			if q.Get("start_time") != "2020-01-01T00:00:00Z" || q.Get("event_type") != EventPaymentSaleCompleted || q.Get("page_size") != "1" || q.Get("end_time") != "" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			fmt.Fprintf(w, `{"events":[{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED"}],"count":1,"links":[{"href":"%s/v1/notifications/webhooks-events?page=2","rel":"next"}]}`, ts.URL)
+		case "/v1/notifications/webhooks-events/WH-1":
+			fmt.Fprint(w, `{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED","resource":{"id":"80021663DE681814L"}}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	events, err := c.ListAllWebhookEvents(&ListWebhookEventsRequest{
+		StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EventType: EventPaymentSaleCompleted,
+		PageSize:  1,
+	})
+	if err != nil || len(events) != 2 || events[1].ID != "WH-2" {
+		t.Errorf("unexpected ListAllWebhookEvents result %+v, %v", events, err)
+	}
+
+	event, err := c.GetWebhookEvent("WH-1")
+	if err != nil {
+		t.Fatalf("Not expected error for GetWebhookEvent, got %v", err)
+	}
+	sale, err := event.SaleResource()
+	if err != nil || sale.ID != "80021663DE681814L" {
+		t.Errorf("unexpected sale %+v, %v", sale, err)
+	}
+}
+
+func TestEventTypedResources(t *testing.T) {
+	refundEvent := &Event{
+		EventType:    EventPaymentCaptureRefunded,
+		ResourceType: "refund",
+		Resource:     []byte(`{"id":"1Y107995YT783435V","status":"COMPLETED","note_to_payer":"Defective product","amount":{"currency_code":"USD","value":"10.00"}}`),
+	}
+	refund, err := refundEvent.RefundResource()
+	if err != nil || refund.NoteToPayer != "Defective product" || refund.Amount.Value != "10.00" {
+		t.Errorf("unexpected refund %+v, %v", refund, err)
+	}
+	if _, err := refundEvent.CaptureResource(); err == nil {
+		t.Errorf("Expected error decoding a refund event as capture")
+	}
+
+	orderEvent := &Event{EventType: EventCheckoutOrderApproved, Resource: []byte(`{"id":"5O190127TN364715T","status":"APPROVED","intent":"CAPTURE"}`)}
+	order, err := orderEvent.OrderResource()
+	if err != nil || order.Status != "APPROVED" {
+		t.Errorf("unexpected order %+v, %v", order, err)
+	}
+
+	disputeEvent := &Event{EventType: EventCustomerDisputeResolved, Resource: []byte(`{"dispute_id":"PP-D-4012","status":"RESOLVED","dispute_outcome":{"outcome_code":"RESOLVED_BUYER_FAVOUR","amount_refunded":{"currency_code":"USD","value":"3.00"}}}`)}
+	dispute, err := disputeEvent.DisputeResource()
+	if err != nil || dispute.DisputeOutcome == nil || dispute.DisputeOutcome.AmountRefunded.Value != "3.00" {
+		t.Errorf("unexpected dispute %+v, %v", dispute, err)
+	}
+
+	// Unknown event types are decoded by their resource type
+	authorizationEvent := &Event{EventType: "PAYMENT.AUTHORIZATION.REAUTHORIZED", ResourceType: "authorization", Resource: []byte(`{"id":"0VF52814937998046","status":"CREATED"}`)}
+	authorization, err := authorizationEvent.AuthorizationResource()
+	if err != nil || authorization.ID != "0VF52814937998046" {
+		t.Errorf("unexpected authorization %+v, %v", authorization, err)
+	}
+
+	// v1 captures have another shape than Capture
+	v1CaptureEvent := &Event{EventType: EventPaymentCaptureCompleted, ResourceType: ResourceTypeCapture, ResourceVersion: ResourceVersion1, Resource: []byte(`{"id":"8F148933LY9388354","state":"completed","amount":{"total":"10.00","currency":"USD"}}`)}
+	if _, err := v1CaptureEvent.CaptureResource(); err == nil || !strings.Contains(err.Error(), "version 1.0") {
+		t.Errorf("Expected error decoding a 1.0 capture, got %v", err)
+	}
+	v1CaptureEvent.ResourceVersion = ResourceVersion2
+	if _, err := v1CaptureEvent.CaptureResource(); err != nil {
+		t.Errorf("Not expected error decoding a 2.0 capture, got %v", err)
+	}
+}
+
+func TestSimulateWebhookEvent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/notifications/simulate-event" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"webhook_id":"0EH40505U7160970P","event_type":"PAYMENT.CAPTURE.COMPLETED","resource_version":"2.0"}` {
+			t.Errorf("unexpected body %s", body)
+		}
+		fmt.Fprint(w, `{"id":"WH-4M0448861G563140B-9EX36365822141321","event_version":"1.0","resource_type":"capture","resource_version":"2.0","event_type":"PAYMENT.CAPTURE.COMPLETED","resource":{"id":"42311647XV020574X","status":"COMPLETED"}}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.SetAccessToken("A21AAF0")
+
+	eventType := &EventType{Name: EventPaymentCaptureCompleted, ResourceVersions: []string{"1.0", "2.0"}}
+	event, err := c.SimulateWebhookEvent(&SimulateWebhookEventRequest{
+		WebhookID:       "0EH40505U7160970P",
+		EventType:       eventType.Name,
+		ResourceVersion: eventType.LatestResourceVersion(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capture, err := event.CaptureResource(); err != nil || capture.Status != CaptureStatusCompleted {
+		t.Errorf("unexpected capture %+v, %v", capture, err)
+	}
+
+	if latest := (&EventType{ResourceVersions: []string{"2.0", "1.0"}}).LatestResourceVersion(); latest != ResourceVersion2 {
+		t.Errorf("expected 2.0, got %s", latest)
+	}
+	if latest := (&EventType{}).LatestResourceVersion(); latest != "" {
+		t.Errorf("expected no version, got %s", latest)
+	}
+}
+
+func TestWebhookEvent_Event(t *testing.T) {
+	data := []byte(`{"id":"WH-58D329510W468432D-8HN650336L201105X","create_time":"2019-02-14T21:50:07.940Z","resource_type":"capture","event_type":"PAYMENT.CAPTURE.COMPLETED","resource":{"id":"42311647XV020574X","custom_id":"order-1234","status":"COMPLETED","seller_receivable_breakdown":{"gross_amount":{"currency_code":"USD","value":"1.00"}}}}`)
+
+	webhookEvent := &WebhookEvent{}
+	if err := json.Unmarshal(data, webhookEvent); err != nil {
+		t.Fatal(err)
+	}
+	if webhookEvent.Resource.ID != "42311647XV020574X" {
+		t.Errorf("expected the generic resource to be decoded, got %+v", webhookEvent.Resource)
+	}
+
+	capture, err := webhookEvent.Event().CaptureResource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capture.CustomID != "order-1234" || capture.SellerReceivableBreakdown == nil {
+		t.Errorf("expected fields dropped by Resource to be decoded, got %+v", capture)
+	}
+
+	if webhookEvent.CreateTime.Time() != time.Date(2019, 2, 14, 21, 50, 7, 940000000, time.UTC) {
+		t.Errorf("unexpected create_time %v", webhookEvent.CreateTime.Time())
+	}
+
+	err = json.Unmarshal([]byte(`{"id":"WH-1","create_time":"2018-19-12T22:20:32.000Z","event_type":"PAYMENT.CAPTURE.COMPLETED"}`), &WebhookEvent{})
+	if tsErr, ok := err.(*TimestampError); !ok || tsErr.Field != "create_time" || tsErr.Value != "2018-19-12T22:20:32.000Z" {
+		t.Errorf("expected an error naming the invalid create_time, got %v", err)
+	}
+
+	built := (&WebhookEvent{EventType: EventPaymentCaptureCompleted, Resource: Resource{ID: "42311647XV020574X"}}).Event()
+	if capture, err := built.CaptureResource(); err != nil || capture.ID != "42311647XV020574X" {
+		t.Errorf("unexpected capture from built event %+v, %v", capture, err)
+	}
+}
+
+func TestEventResourceType(t *testing.T) {
+	tests := map[string]string{
+		EventPaymentCaptureCompleted:        "capture",
+		EventPaymentCaptureRefunded:         "refund",
+		EventBillingSubscriptionReActivated: "subscription",
+		EventPaymentPayoutsItemUnclaimed:    "payouts_item",
+		EventVaultPaymentTokenCreated:       "payment_token",
+	}
+	for eventType, expected := range tests {
+		if resourceType, ok := EventResourceType(eventType); !ok || resourceType != expected {
+			t.Errorf("expected resource type %s for %s, got %s", expected, eventType, resourceType)
+		}
+	}
+
+	if _, ok := EventResourceType("PAYMENT.SALE.EXPLODED"); ok {
+		t.Errorf("Expected unknown event type")
+	}
+
+	eventTypes := EventTypes()
+	if len(eventTypes) < 50 || !sort.StringsAreSorted(eventTypes) {
+		t.Errorf("expected a sorted catalog of event types, got %v", eventTypes)
+	}
+}
+
+// webhookPayloads returns the captured webhook deliveries in testdata/webhooks by file name
+func webhookPayloads(t testing.TB) map[string][]byte {
+	files, err := filepath.Glob(filepath.Join("testdata", "webhooks", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("expected captured webhook payloads, got %v, %v", files, err)
+	}
+
+	payloads := map[string][]byte{}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payloads[filepath.Base(file)] = bytes.TrimSpace(data)
+	}
+	return payloads
+}
+
+func TestWebhookEventPayloads(t *testing.T) {
+	for name, data := range webhookPayloads(t) {
+		event := &Event{}
+		if err := json.Unmarshal(data, event); err != nil {
+			t.Errorf("%s: not expected error for Event, got %v", name, err)
+			continue
+		}
+		if event.ID == "" || event.EventType == "" || len(event.Resource) == 0 {
+			t.Errorf("%s: expected id, event type and resource, got %+v", name, event)
+		}
+		if _, err := time.Parse(time.RFC3339Nano, event.CreateTime); err != nil {
+			t.Errorf("%s: expected a valid create_time, got %v", name, err)
+		}
+		if resourceType, ok := EventResourceType(event.EventType); ok && event.ResourceType != resourceType {
+			t.Errorf("%s: expected resource_type %s, got %s", name, resourceType, event.ResourceType)
+		}
+		if string(event.Raw()) != string(data) {
+			t.Errorf("%s: expected the raw delivery to be kept", name)
+		}
+
+		webhookEvent := &WebhookEvent{}
+		if err := json.Unmarshal(data, webhookEvent); err != nil {
+			t.Errorf("%s: not expected error for WebhookEvent, got %v", name, err)
+		}
+		if webhookEvent.Event().CreateTime != event.CreateTime {
+			t.Errorf("%s: expected create_time %s to be kept, got %s", name, event.CreateTime, webhookEvent.Event().CreateTime)
+		}
+	}
+
+	payloads := webhookPayloads(t)
+	decoders := map[string]func(e *Event) (interface{}, error){
+		"payment-sale-completed.json":         func(e *Event) (interface{}, error) { return e.SaleResource() },
+		"payment-capture-completed.json":      func(e *Event) (interface{}, error) { return e.CaptureResource() },
+		"billing-subscription-activated.json": func(e *Event) (interface{}, error) { return e.SubscriptionResource() },
+		"customer-dispute-created.json":       func(e *Event) (interface{}, error) { return e.DisputeResource() },
+		"checkout-order-approved.json":        func(e *Event) (interface{}, error) { return e.OrderResource() },
+		"invoicing-invoice-paid.json":         func(e *Event) (interface{}, error) { return e.InvoiceResource() },
+	}
+	for name, decode := range decoders {
+		event := &Event{}
+		json.Unmarshal(payloads[name], event)
+		if _, err := decode(event); err != nil {
+			t.Errorf("%s: not expected error decoding the resource, got %v", name, err)
+		}
+	}
+
+	// Fields added by PayPal later are ignored and kept in Raw
+	event := &Event{}
+	if err := json.Unmarshal(payloads["unknown-event-type.json"], event); err != nil {
+		t.Fatal(err)
+	}
+	if _, known := EventResourceType(event.EventType); known {
+		t.Errorf("expected %s to be unknown", event.EventType)
+	}
+	var extensions struct {
+		Extensions map[string]string `json:"extensions"`
+	}
+	if err := json.Unmarshal(event.Raw(), &extensions); err != nil || extensions.Extensions["correlation_id"] != "f1a2b3c4" {
+		t.Errorf("expected unknown fields in Raw, got %+v, %v", extensions, err)
+	}
+}
+
+func TestListDisputes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/customer/disputes/PP-D-4012" {
+			fmt.Fprint(w, `{"dispute_id":"PP-D-4012","status":"RESOLVED","dispute_outcome":{"outcome_code":"RESOLVED_BUYER_FAVOUR","amount_refunded":{"currency_code":"USD","value":"10.00"}}}`)
+			return
+		}
+		if r.URL.Path != "/v1/customer/disputes" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		q := r.URL.Query()
+		switch q.Get("next_page_token") {
+		case "":
+			if q.Get("dispute_state") != "REQUIRED_ACTION" || q.Get("page_size") != "1" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			fmt.Fprintf(w, `{"items":[{"dispute_id":"PP-D-4012","reason":"MERCHANDISE_OR_SERVICE_NOT_RECEIVED","status":"WAITING_FOR_SELLER_RESPONSE","dispute_state":"REQUIRED_ACTION","dispute_amount":{"currency_code":"USD","value":"10.00"}}],"links":[{"href":"%s/v1/customer/disputes?page_size=1&dispute_state=REQUIRED_ACTION&next_page_token=NDQ1","rel":"next","method":"GET"}]}`, "http://"+r.Host)
+		case "NDQ1":
+			fmt.Fprint(w, `{"items":[{"dispute_id":"PP-D-4013","dispute_state":"REQUIRED_ACTION"}],"links":[]}`)
+		default:
+			t.Errorf("unexpected next_page_token %s", q.Get("next_page_token"))
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	params := &ListDisputesRequest{DisputeState: "REQUIRED_ACTION", PageSize: 1}
+	page, err := c.ListDisputes(params)
+	if err != nil || len(page.Items) != 1 || page.Items[0].DisputeAmount.Value != "10.00" || page.NextPageToken() != "NDQ1" {
+		t.Fatalf("unexpected ListDisputes result %+v, %v", page, err)
+	}
+
+	var ids []string
+	it := c.IterateDisputes(params)
+	for it.Next() {
+		for _, dispute := range it.Page().Items {
+			ids = append(ids, dispute.DisputeID)
+		}
+	}
+	if it.Err() != nil || len(ids) != 2 || ids[1] != "PP-D-4013" {
+		t.Errorf("expected both pages to be iterated, got %v, %v", ids, it.Err())
+	}
+
+	dispute, err := c.GetDispute("PP-D-4012")
+	if err != nil || dispute.DisputeOutcome == nil || dispute.DisputeOutcome.AmountRefunded.Value != "10.00" {
+		t.Errorf("unexpected GetDispute result %+v, %v", dispute, err)
+	}
+
+	if _, err := c.ListDisputes(&ListDisputesRequest{StartTime: time.Now(), DisputedTransactionID: "42311647XV020574X"}); err == nil {
+		t.Errorf("Expected error for start_time combined with disputed_transaction_id")
+	}
+}
+
+func TestAcceptClaim(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/customer/disputes/PP-D-4012/accept-claim" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body := &AcceptClaimRequest{}
+		json.NewDecoder(r.Body).Decode(body)
+		if body.Note != "Refunded by policy" || body.AcceptClaimReason != AcceptClaimReasonCompanyPolicy || body.RefundAmount.Value != "5.00" {
+			t.Errorf("unexpected body %+v", body)
+		}
+		fmt.Fprint(w, `{"links":[{"href":"https://api.paypal.com/v1/customer/disputes/PP-D-4012","rel":"self","method":"GET"}]}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	resp, err := c.AcceptClaim("PP-D-4012", &AcceptClaimRequest{
+		Note:              "Refunded by policy",
+		AcceptClaimReason: AcceptClaimReasonCompanyPolicy,
+		RefundAmount:      &Money{Currency: "USD", Value: "5.00"},
+	})
+	if err != nil || len(resp.Links) != 1 {
+		t.Errorf("unexpected AcceptClaim result %+v, %v", resp, err)
+	}
+
+	if _, err := c.AcceptClaim("PP-D-4012", &AcceptClaimRequest{}); err == nil {
+		t.Errorf("Expected error for AcceptClaim without note")
+	}
+}
+
+func TestAppealDispute(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/customer/disputes/PP-D-4012/appeal" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+
+		input := &DisputeEvidenceRequest{}
+		if err := json.Unmarshal([]byte(r.FormValue("input")), input); err != nil || len(input.Evidences) != 1 ||
+			input.Evidences[0].EvidenceInfo.TrackingInfo[0].TrackingNumber != "1Z999AA10123456784" {
+			t.Errorf("unexpected input %s, %v", r.FormValue("input"), err)
+		}
+
+		files := r.MultipartForm.File["evidence-file"]
+		if len(files) != 1 || files[0].Filename != "receipt.pdf" {
+			t.Errorf("unexpected files %+v", files)
+		}
+		fmt.Fprint(w, `{"links":[{"href":"https://api.paypal.com/v1/customer/disputes/PP-D-4012","rel":"self","method":"GET"}]}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	resp, err := c.AppealDispute("PP-D-4012", &DisputeEvidenceRequest{
+		Evidences: []*Evidence{{
+			EvidenceType: EvidenceTypeProofOfFulfillment,
+			EvidenceInfo: &EvidenceInfo{TrackingInfo: []*DisputeTrackingInfo{{CarrierName: "UPS", TrackingNumber: "1Z999AA10123456784"}}},
+			Notes:        "Delivered to the buyer's address",
+		}},
+		Files: []EvidenceFile{{Name: "receipt.pdf", Content: strings.NewReader("%PDF-1.4")}},
+	})
+	if err != nil || len(resp.Links) != 1 {
+		t.Errorf("unexpected AppealDispute result %+v, %v", resp, err)
+	}
+
+	if _, err := c.AppealDispute("PP-D-4012", &DisputeEvidenceRequest{}); err == nil {
+		t.Errorf("Expected error for AppealDispute without evidences")
+	}
+}
+
+func TestDisputeOffers(t *testing.T) {
+	var actions []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/v1/customer/disputes/PP-D-4012/make-offer":
+			amount, _ := body["offer_amount"].(map[string]interface{})
+			if body["offer_type"] == OfferTypeRefund && (amount == nil || amount["value"] != "5.00") {
+				t.Errorf("unexpected offer %v", body)
+			}
+		case "/v1/customer/disputes/PP-D-4012/accept-offer", "/v1/customer/disputes/PP-D-4012/deny-offer":
+			if body["note"] == "" {
+				t.Errorf("expected a note, got %v", body)
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		actions = append(actions, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		fmt.Fprint(w, `{"links":[{"href":"https://api.paypal.com/v1/customer/disputes/PP-D-4012","rel":"self","method":"GET"}]}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	if _, err := c.MakeOffer("PP-D-4012", &MakeOfferRequest{
+		Note:        "Half refund, keep the item",
+		OfferType:   OfferTypeRefund,
+		OfferAmount: &Money{Currency: "USD", Value: "5.00"},
+	}); err != nil {
+		t.Errorf("Not expected error for MakeOffer, got %v", err)
+	}
+	if _, err := c.AcceptOffer("PP-D-4012", "Accepted"); err != nil {
+		t.Errorf("Not expected error for AcceptOffer, got %v", err)
+	}
+	if _, err := c.DenyOffer("PP-D-4012", "Denied"); err != nil {
+		t.Errorf("Not expected error for DenyOffer, got %v", err)
+	}
+
+	if _, err := c.MakeOffer("PP-D-4012", &MakeOfferRequest{Note: "Refund", OfferType: OfferTypeRefund}); err == nil {
+		t.Errorf("Expected error for refund offer without amount")
+	}
+	if _, err := c.MakeOffer("PP-D-4012", &MakeOfferRequest{Note: "New one", OfferType: OfferTypeReplacementWithoutRefund}); err != nil {
+		t.Errorf("Not expected error for replacement offer without amount, got %v", err)
+	}
+	if _, err := c.DenyOffer("PP-D-4012", ""); err == nil {
+		t.Errorf("Expected error for DenyOffer without note")
+	}
+	if strings.Join(actions, ",") != "make-offer,accept-offer,deny-offer,make-offer" {
+		t.Errorf("unexpected actions %v", actions)
+	}
+}
+
+func TestDisputeMessages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/customer/disputes/PP-D-4012/send-message":
+			body := &SendMessageRequest{}
+			json.NewDecoder(r.Body).Decode(body)
+			if body.Message != "The parcel was delivered on Monday" {
+				t.Errorf("unexpected message %+v", body)
+			}
+			fmt.Fprint(w, `{"links":[{"href":"https://api.paypal.com/v1/customer/disputes/PP-D-4012","rel":"self","method":"GET"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/v1/customer/disputes/PP-D-4012":
+			fmt.Fprint(w, `{"dispute_id":"PP-D-4012","messages":[{"posted_by":"BUYER","time_posted":"2020-01-20T10:00:00.000Z","content":"Where is my parcel?"},{"posted_by":"SELLER","time_posted":"2020-01-21T10:00:00.000Z","content":"The parcel was delivered on Monday"}]}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	if _, err := c.SendDisputeMessage("PP-D-4012", "The parcel was delivered on Monday"); err != nil {
+		t.Errorf("Not expected error for SendDisputeMessage, got %v", err)
+	}
+	if _, err := c.SendDisputeMessage("PP-D-4012", ""); err == nil {
+		t.Errorf("Expected error for empty message")
+	}
+
+	messages, err := c.ListDisputeMessages("PP-D-4012")
+	if err != nil || len(messages) != 2 || messages[0].PostedBy != DisputeMessagePostedByBuyer || messages[1].Content != "The parcel was delivered on Monday" {
+		t.Errorf("unexpected ListDisputeMessages result %+v, %v", messages, err)
+	}
+}
+
+func TestEscalateAndRequireEvidence(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/v1/customer/disputes/PP-D-4012/escalate":
+			if body["note"] != "Buyer is not responding" {
+				t.Errorf("unexpected body %v", body)
+			}
+		case "/v1/customer/disputes/PP-D-4012/require-evidence":
+			if body["action"] != RequireEvidenceActionSellerEvidence {
+				t.Errorf("unexpected body %v", body)
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"links":[{"href":"https://api.paypal.com/v1/customer/disputes/PP-D-4012","rel":"self","method":"GET"}]}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	if _, err := c.EscalateDispute("PP-D-4012", "Buyer is not responding"); err != nil {
+		t.Errorf("Not expected error for EscalateDispute, got %v", err)
+	}
+	if _, err := c.EscalateDispute("PP-D-4012", ""); err == nil {
+		t.Errorf("Expected error for EscalateDispute without note")
+	}
+	if _, err := c.RequireEvidence("PP-D-4012", RequireEvidenceActionSellerEvidence); err != nil {
+		t.Errorf("Not expected error for RequireEvidence, got %v", err)
+	}
+	if _, err := c.RequireEvidence("PP-D-4012", "ANY_EVIDENCE"); err == nil {
+		t.Errorf("Expected error for invalid require evidence action")
+	}
+}
+
+func TestUpdateAndDeleteInvoice(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v2/invoicing/invoices/INV2-DRAFT":
+			fmt.Fprint(w, `{"id":"INV2-DRAFT","status":"DRAFT","detail":{"currency_code":"USD"}}`)
+		case r.Method == "GET" && r.URL.Path == "/v2/invoicing/invoices/INV2-SENT":
+			fmt.Fprint(w, `{"id":"INV2-SENT","status":"SENT","detail":{"currency_code":"USD"}}`)
+		case r.Method == "PUT" && r.URL.Path == "/v2/invoicing/invoices/INV2-DRAFT":
+			if r.URL.Query().Get("send_to_recipient") != "false" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			invoice := &Invoice{}
+			json.NewDecoder(r.Body).Decode(invoice)
+			if invoice.Detail == nil || invoice.Detail.Note != "Corrected note" {
+				t.Errorf("unexpected invoice %+v", invoice)
+			}
+			json.NewEncoder(w).Encode(invoice)
+		case r.Method == "DELETE" && r.URL.Path == "/v2/invoicing/invoices/INV2-DRAFT":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	invoice, err := c.GetInvoice("INV2-DRAFT")
+	if err != nil {
+		t.Fatalf("Not expected error for GetInvoice, got %v", err)
+	}
+	invoice.Detail.Note = "Corrected note"
+	updated, err := c.UpdateInvoice(invoice, false)
+	if err != nil || updated.Detail.Note != "Corrected note" {
+		t.Errorf("Not expected error for UpdateInvoice, got %v, %+v", err, updated)
+	}
+	if _, err := c.UpdateInvoice(&Invoice{ID: "INV2-PAID", Status: InvoiceStatusPaid}, false); err == nil {
+		t.Errorf("Expected error for UpdateInvoice of a paid invoice")
+	}
+	if _, err := c.UpdateInvoice(&Invoice{}, false); err == nil {
+		t.Errorf("Expected error for UpdateInvoice without ID")
+	}
+
+	if err := c.DeleteInvoice("INV2-DRAFT"); err != nil {
+		t.Errorf("Not expected error for DeleteInvoice, got %v", err)
+	}
+	if err := c.DeleteInvoice("INV2-SENT"); err == nil {
+		t.Errorf("Expected error for DeleteInvoice of a sent invoice")
+	}
+}
+
+func TestRemindAndCancelInvoice(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notification := &InvoiceNotification{}
+		json.NewDecoder(r.Body).Decode(notification)
+
+		switch r.URL.Path {
+		case "/v2/invoicing/invoices/INV2-Z56S/remind":
+			if notification.Subject != "Reminder: payment due" || !notification.SendToRecipient ||
+				len(notification.AdditionalRecipients) != 1 || notification.AdditionalRecipients[0] != "billing@example.com" {
+				t.Errorf("unexpected notification %+v", notification)
+			}
+		case "/v2/invoicing/invoices/INV2-Z56S/cancel":
+			if notification.Note != "" || notification.SendToRecipient {
+				t.Errorf("unexpected notification %+v", notification)
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	err := c.RemindInvoice("INV2-Z56S", &InvoiceNotification{
+		Subject:              "Reminder: payment due",
+		SendToRecipient:      true,
+		AdditionalRecipients: []string{"billing@example.com"},
+	})
+	if err != nil {
+		t.Errorf("Not expected error for RemindInvoice, got %v", err)
+	}
+	if err := c.CancelInvoice("INV2-Z56S", nil); err != nil {
+		t.Errorf("Not expected error for CancelInvoice, got %v", err)
+	}
+	if err := c.RemindInvoice("INV2-Z56S", &InvoiceNotification{Note: strings.Repeat("a", 4001)}); err == nil {
+		t.Errorf("Expected error for a too long note")
+	}
+}
+
+func TestUploadInvoiceAttachment(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v2/invoicing/files" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("expected a file part, got %v", err)
+		}
+		defer file.Close()
+		content, _ := ioutil.ReadAll(file)
+		if header.Filename != "work-order.pdf" || string(content) != "%PDF-1.4 signed" {
+			t.Errorf("unexpected file %s %q", header.Filename, content)
+		}
+		fmt.Fprint(w, `{"id":"work-order.pdf","reference_url":"https://example.com/invoice/payerview/attachments/RkLs.png","content_type":"application/pdf","size":"15"}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	ref, err := c.UploadInvoiceAttachment("work-order.pdf", strings.NewReader("%PDF-1.4 signed"))
+	if err != nil {
+		t.Fatalf("Not expected error for UploadInvoiceAttachment, got %v", err)
+	}
+	if ref.ReferenceURL == "" || ref.Size != "15" {
+		t.Errorf("unexpected file reference %+v", ref)
+	}
+	if _, err := c.UploadInvoiceAttachment("", nil); err == nil {
+		t.Errorf("Expected error for UploadInvoiceAttachment without a file")
+	}
+}
+
+func TestListTransactions(t *testing.T) {
+	var pages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/reporting/transactions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("start_date") != "2020-01-01T00:00:00Z" || q.Get("end_date") != "2020-01-31T23:59:59Z" ||
+			q.Get("transaction_status") != TransactionStatusSuccess || q.Get("transaction_amount") != "1000 TO 5000" ||
+			q.Get("fields") != "transaction_info,payer_info,cart_info" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		pages = append(pages, q.Get("page"))
+
+		page := 1
+		if q.Get("page") == "2" {
+			page = 2
+		}
+		fmt.Fprintf(w, `{"transaction_details":[{"transaction_info":{"transaction_id":"5TY05013RG002845M-%d","transaction_event_code":"T0006","transaction_initiation_date":"2020-01-15T10:00:00+0000","transaction_amount":{"currency_code":"USD","value":"10.00"},"fee_amount":{"currency_code":"USD","value":"-0.59"},"transaction_status":"S","invoice_id":"INV-1001","custom_field":"order-1001"},"payer_info":{"account_id":"6JD2P3FKMRJ8E","email_address":"buyer@example.com","phone_number":{"country_code":"1","national_number":"4085551234"},"payer_name":{"given_name":"John","surname":"Doe"},"country_code":"US"},"cart_info":{"item_details":[{"item_code":"PROD-1","item_name":"Video Streaming Service","item_quantity":"1","item_unit_price":{"currency_code":"USD","value":"10.00"},"tax_amounts":[{"tax_amount":{"currency_code":"USD","value":"0.00"}}]}]}}],"account_number":"XZXSPECPDZHZU","start_date":"2020-01-01T00:00:00+0000","end_date":"2020-01-31T23:59:59+0000","page":%d,"total_items":2,"total_pages":2}`, page, page)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	params := &TransactionSearchRequest{
+		StartDate:             time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:               time.Date(2020, 1, 31, 23, 59, 59, 0, time.UTC),
+		TransactionStatus:     TransactionStatusSuccess,
+		TransactionAmountFrom: "1000",
+		TransactionAmountTo:   "5000",
+		Fields:                "transaction_info,payer_info,cart_info",
+	}
+
+	resp, err := c.ListTransactions(params)
+	if err != nil {
+		t.Fatalf("Not expected error for ListTransactions, got %v", err)
+	}
+	details := resp.TransactionDetails[0]
+	if details.TransactionInfo.TransactionEventCode != "T0006" || details.TransactionInfo.FeeAmount.Value != "-0.59" ||
+		details.PayerInfo.PhoneNumber.NationalNumber != "4085551234" || details.PayerInfo.PayerName.Surname != "Doe" ||
+		details.CartInfo.ItemDetails[0].ItemCode != "PROD-1" {
+		t.Errorf("unexpected transaction %+v", details)
+	}
+
+	pages = nil
+	var ids []string
+	it := c.IterateTransactions(params)
+	for it.Next() {
+		for _, details := range it.Page().TransactionDetails {
+			ids = append(ids, details.TransactionInfo.TransactionID)
+		}
+	}
+	if it.Err() != nil || len(ids) != 2 || len(pages) != 2 || pages[1] != "2" {
+		t.Errorf("expected 2 pages, got %v %v %v", ids, pages, it.Err())
+	}
+
+	if _, err := c.ListTransactions(&TransactionSearchRequest{StartDate: params.StartDate}); err == nil {
+		t.Errorf("Expected error for ListTransactions without end_date")
+	}
+	if _, err := c.ListTransactions(&TransactionSearchRequest{StartDate: params.StartDate, EndDate: params.StartDate.AddDate(0, 2, 0)}); err == nil {
+		t.Errorf("Expected error for ListTransactions over more than 31 days")
+	}
+}
+
+func TestListTransactions_StoreFilters(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("fields") != TransactionFieldsAll || q.Get("balance_affecting_records_only") != "Y" ||
+			q.Get("store_id") != "STORE-7" || q.Get("terminal_id") != "TERMINAL-2" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"transaction_details":[{"transaction_info":{"transaction_id":"5TY05013RG002845M","transaction_event_code":"T0006","transaction_status":"S"},"store_info":{"store_id":"STORE-7","terminal_id":"TERMINAL-2"},"incentive_info":{"incentive_details":[{"incentive_type":"Coupon","incentive_code":"SPRING10","incentive_amount":{"currency_code":"USD","value":"-1.00"}}]}}],"page":1,"total_items":1,"total_pages":1}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	resp, err := c.ListTransactions(&TransactionSearchRequest{
+		StartDate:                   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:                     time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		StoreID:                     "STORE-7",
+		TerminalID:                  "TERMINAL-2",
+		Fields:                      TransactionFieldsAll,
+		BalanceAffectingRecordsOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("Not expected error for ListTransactions, got %v", err)
+	}
+	details := resp.TransactionDetails[0]
+	if details.StoreInfo.TerminalID != "TERMINAL-2" || details.IncentiveInfo.IncentiveDetails[0].IncentiveCode != "SPRING10" {
+		t.Errorf("unexpected transaction %+v", details)
+	}
+}
+
+func TestGenerateClientToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/identity/generate-token" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer foo" {
+			t.Errorf("expected the access token, got %q", r.Header.Get("Authorization"))
+		}
+		body := &ClientTokenRequest{}
+		json.NewDecoder(r.Body).Decode(body)
+		if body.CustomerID != "customer_1234" {
+			t.Errorf("unexpected body %+v", body)
+		}
+		fmt.Fprint(w, `{"client_token":"eyJicmFpbnRyZWUiOnsiYXV0aG9yaXphdGlvbkZpbmdlcnByaW50IjoiYjA0MWE2M2JlMTM4M2NlZGUxZTI3OWFlNDlhMWIyNzZlY2FjOTYzOWU2NjlhMGIzODQyYTdkMTY3NzcwYmY0OHxtZXJjaGFudF9pZD1yd3dua3FnMnhnNTZobTJuJnB1YmxpY19rZXk9czlic3BuaGtxMmYzaDk0NCZjcmVhdGVkX2F0PTIwMTgtMTEtMTRUMTE6MTg6MDAuMTU3WiIsInZlcnNpb24iOiIzLXBheXBhbCJ9LCJwYXlwYWwiOnsiYWNjZXNzVG9rZW4iOiJBMjFBQUhNVExyMmctVDlhSTJacUZHUmlFZ0ZFZGRHTGwxTzRlX0lvdk9ESVg2Q3pSdW5BVy02TzI2MjdiWUJHX1JuaERPMGE2QVJEWmYzWlJwSmR2UUdmcWFYSHlvTTlhX1BnIn19","expires_in":3600}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	token, err := c.GenerateClientToken("customer_1234")
+	if err != nil {
+		t.Fatalf("Not expected error for GenerateClientToken, got %v", err)
+	}
+	if token.ClientToken == "" || token.ExpiresIn != 3600 {
+		t.Errorf("unexpected client token %+v", token)
+	}
+}
+
+func TestGrantNewAccessToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/identity/openidconnect/tokenservice" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "foo" || pass != "bar" {
+			t.Errorf("expected the client credentials")
+		}
+		if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Errorf("unexpected content type %s", r.Header.Get("Content-Type"))
+		}
+		r.ParseForm()
+
+		switch r.PostForm.Get("grant_type") {
+		case "authorization_code":
+			if r.PostForm.Get("code") != "C21AAH4" || r.PostForm.Get("redirect_uri") != "https://example.com/paypal/return" {
+				t.Errorf("unexpected form %v", r.PostForm)
+			}
+			fmt.Fprint(w, `{"token_type":"Bearer","expires_in":"28800","refresh_token":"R23AAEX","id_token":"eyJhbGciOiJIUzI1NiJ9","access_token":"A21AAF0","scope":"openid email","nonce":"2020-01-15T10:00:00Z"}`)
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") != "R23AAEX" {
+				t.Errorf("unexpected form %v", r.PostForm)
+			}
+			fmt.Fprint(w, `{"token_type":"Bearer","expires_in":"28800","access_token":"A21AAF1","scope":"openid email"}`)
+		default:
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	token, err := c.GrantNewAccessTokenFromAuthCode("C21AAH4", "https://example.com/paypal/return")
+	if err != nil {
+		t.Fatalf("Not expected error for GrantNewAccessTokenFromAuthCode, got %v", err)
+	}
+	if token.Token != "A21AAF0" || token.RefreshToken != "R23AAEX" || token.IDToken == "" || token.Scope != "openid email" || token.ExpiresIn != 28800 {
+		t.Errorf("unexpected token %+v", token)
+	}
+
+	token, err = c.GrantNewAccessTokenFromRefreshToken(token.RefreshToken)
+	if err != nil {
+		t.Fatalf("Not expected error for GrantNewAccessTokenFromRefreshToken, got %v", err)
+	}
+	if token.Token != "A21AAF1" || token.RefreshToken != "R23AAEX" {
+		t.Errorf("unexpected token %+v", token)
+	}
+
+	if _, err := c.GrantNewAccessTokenFromRefreshToken(""); err == nil {
+		t.Errorf("Expected error without refresh token")
+	}
+	if c.Token != nil {
+		t.Errorf("expected the client token to be left untouched, got %+v", c.Token)
+	}
+}
+
+func TestTerminateAccessToken(t *testing.T) {
+	var terminated []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/oauth2/token/terminate" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if _, _, ok := r.BasicAuth(); !ok {
+			t.Errorf("expected the client credentials")
+		}
+		r.ParseForm()
+		terminated = append(terminated, r.PostForm.Get("token")+" "+r.PostForm.Get("token_type_hint"))
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.SetAccessToken("A21AAF0")
+
+	if err := c.TerminateAccessToken(); err != nil {
+		t.Fatalf("Not expected error for TerminateAccessToken, got %v", err)
+	}
+	if c.Token != nil {
+		t.Errorf("expected the token to be cleared, got %+v", c.Token)
+	}
+	// Nothing to terminate
+	if err := c.TerminateAccessToken(); err != nil {
+		t.Errorf("Not expected error for TerminateAccessToken without token, got %v", err)
+	}
+	if err := c.TerminateToken("R23AAEX", TokenTypeHintRefreshToken); err != nil {
+		t.Errorf("Not expected error for TerminateToken, got %v", err)
+	}
+
+	if len(terminated) != 2 || terminated[0] != "A21AAF0 ACCESS_TOKEN" || terminated[1] != "R23AAEX REFRESH_TOKEN" {
+		t.Errorf("unexpected terminated tokens %v", terminated)
+	}
+}
+
+func TestTokenRefreshCallback(t *testing.T) {
+	var issued int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/oauth2/token" {
+			issued++
+			// shorter than RequestNewTokenBeforeExpiresIn so SendWithAuth refreshes it
+			fmt.Fprintf(w, `{"access_token":"A21AAF%d","token_type":"Bearer","expires_in":30}`, issued)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	if expiresIn := c.TokenExpiresIn(); expiresIn != 0 {
+		t.Errorf("expected no lifetime without token, got %v", expiresIn)
+	}
+
+	var refreshed []string
+	c.SetTokenRefreshCallback(func(token *TokenResponse) {
+		refreshed = append(refreshed, token.Token)
+	})
+
+	if _, err := c.GetAccessToken(); err != nil {
+		t.Fatal(err)
+	}
+	if expiresIn := c.TokenExpiresIn(); expiresIn <= 0 || expiresIn > 30*time.Second {
+		t.Errorf("expected a lifetime of at most 30s, got %v", expiresIn)
+	}
+
+	req, _ := c.NewRequest("GET", ts.URL+"/v1/notifications/webhooks", nil)
+	if err := c.SendWithAuth(req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(refreshed) != 2 || refreshed[0] != "A21AAF1" || refreshed[1] != "A21AAF2" {
+		t.Errorf("expected the callback for both tokens, got %v", refreshed)
+	}
+
+	c.SetAccessToken("A21AAF0")
+	if expiresIn := c.TokenExpiresIn(); expiresIn != 0 {
+		t.Errorf("expected an unknown lifetime after SetAccessToken, got %v", expiresIn)
+	}
+}
+
+func TestGetUserInfoSchemas(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/identity/openidconnect/userinfo/" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		switch r.URL.Query().Get("schema") {
+		case UserInfoSchemaPayPalV11:
+			fmt.Fprint(w, `{"user_id":"https://www.paypal.com/webapps/auth/identity/user/mWq6_1sU85v5EG9yHdPxJRrhGHrnMJ-1PQKtX6pcsmA","name":"identity test","given_name":"identity","family_name":"test","payer_id":"WDJJHEBZ4X2LY","address":{"street_address":"1 Main St","locality":"San Jose","region":"CA","postal_code":"95131","country":"US"},"verified_account":"true","emails":[{"value":"user1@example.com","primary":true,"confirmed":true}]}`)
+		case UserInfoSchemaOpenID:
+			fmt.Fprint(w, `{"user_id":"https://www.paypal.com/webapps/auth/identity/user/mWq6_1sU85v5EG9yHdPxJRrhGHrnMJ-1PQKtX6pcsmA","email":"user1@example.com","verified":true,"verified_account":false}`)
+		default:
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	u, err := c.GetUserInfo(UserInfoSchemaPayPalV11)
+	if err != nil {
+		t.Fatalf("Not expected error for GetUserInfo, got %v", err)
+	}
+	if u.PayerID != "WDJJHEBZ4X2LY" || !u.VerifiedAccount || len(u.Emails) != 1 || !u.Emails[0].Primary ||
+		u.Address == nil || u.Address.Locality != "San Jose" {
+		t.Errorf("unexpected user info %+v", u)
+	}
+
+	u, err = c.GetUserInfo(UserInfoSchemaOpenID)
+	if err != nil || u.Email != "user1@example.com" || !u.Verified || u.VerifiedAccount {
+		t.Errorf("unexpected user info %+v, %v", u, err)
+	}
+
+	if _, err := c.GetUserInfo("paypalv2"); err == nil {
+		t.Errorf("Expected error for an unsupported schema")
+	}
+
+	if err := json.Unmarshal([]byte(`{"verified_account":"maybe"}`), &UserInfo{}); err == nil {
+		t.Errorf("Expected error for an invalid verified_account")
+	}
+}
+
+func TestConnectURL(t *testing.T) {
+	c, _ := NewClient("AdAx", "bar", APIBaseSandBox)
+
+	state, err := NewConnectState()
+	if err != nil || len(state) < 16 {
+		t.Fatalf("unexpected state %q, %v", state, err)
+	}
+
+	u, err := c.ConnectURL(&ConnectURLParams{
+		Scopes:      []string{ScopeOpenID, ScopeEmail, ScopePayPalAttributes},
+		RedirectURI: "https://example.com/paypal/return",
+		State:       state,
+	})
+	if err != nil {
+		t.Fatalf("Not expected error for ConnectURL, got %v", err)
+	}
+	if !strings.HasPrefix(u, "https://www.sandbox.paypal.com/connect?") ||
+		!strings.Contains(u, "scope=openid%20email%20https%3A%2F%2Furi.paypal.com%2Fservices%2Fpaypalattributes") {
+		t.Errorf("unexpected connect URL %s", u)
+	}
+	parsed, _ := url.Parse(u)
+	q := parsed.Query()
+	if q.Get("flowEntry") != ConnectFlowEntryStatic || q.Get("client_id") != "AdAx" || q.Get("response_type") != "code" ||
+		q.Get("redirect_uri") != "https://example.com/paypal/return" || q.Get("state") != state {
+		t.Errorf("unexpected connect URL query %v", q)
+	}
+
+	if _, err := c.ConnectURL(&ConnectURLParams{RedirectURI: "https://example.com/paypal/return"}); err == nil {
+		t.Errorf("Expected error for ConnectURL without state")
+	}
+
+	tests := []struct {
+		query string
+		code  string
+	}{
+		{query: "code=C21AAH4&scope=openid&state=" + state, code: "C21AAH4"},
+		{query: "code=C21AAH4&state=forged"},
+		{query: "code=C21AAH4"},
+		{query: "error=access_denied&error_description=consent+declined&state=" + state},
+		{query: "state=" + state},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/paypal/return?"+tt.query, nil)
+		code, err := ParseConnectReturn(r, state)
+		if code != tt.code || (tt.code == "") != (err != nil) {
+			t.Errorf("%s: unexpected code %q, %v", tt.query, code, err)
+		}
+	}
+}
+
+func TestNewMerchantClient(t *testing.T) {
+	var grants int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/identity/openidconnect/tokenservice":
+			r.ParseForm()
+			if r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "R23AAEX" {
+				t.Errorf("unexpected form %v", r.PostForm)
+			}
+			grants++
+			fmt.Fprintf(w, `{"token_type":"Bearer","expires_in":"%d","access_token":"A21AAM%d"}`, 28800, grants)
+		case "/v1/oauth2/token":
+			t.Errorf("expected the merchant client not to use client credentials")
+		default:
+			if r.Header.Get("Authorization") != "Bearer A21AAM1" {
+				t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+			}
+			assertion := strings.Split(r.Header.Get("PayPal-Auth-Assertion"), ".")
+			claims, _ := base64.StdEncoding.DecodeString(assertion[1])
+			if len(assertion) != 3 || string(claims) != `{"iss":"foo","payer_id":"WDJJHEBZ4X2LY"}` {
+				t.Errorf("unexpected auth assertion %v", assertion)
+			}
+			fmt.Fprint(w, `{"id":"INV2-Z56S-5LLA-Q52L-CPZ5","status":"DRAFT"}`)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	merchant, err := c.NewMerchantClient("R23AAEX", "WDJJHEBZ4X2LY")
+	if err != nil {
+		t.Fatalf("Not expected error for NewMerchantClient, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := merchant.GetInvoice("INV2-Z56S-5LLA-Q52L-CPZ5"); err != nil {
+			t.Errorf("Not expected error for GetInvoice, got %v", err)
+		}
+	}
+	if grants != 1 {
+		t.Errorf("expected the access token to be cached, got %d grants", grants)
+	}
+	if c.Token != nil {
+		t.Errorf("expected the partner client to be left untouched")
+	}
+
+	clock := &testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c.SetClock(clock)
+	if merchant, _ = c.NewMerchantClient("R23AAEX", ""); merchant.clock != clock {
+		t.Errorf("expected the merchant client to use the clock of the partner client")
+	}
+
+	var reported []string
+	c.SetUnknownFieldsCallback(func(req *http.Request, v interface{}, fields []string) { reported = fields })
+	if merchant, _ = c.NewMerchantClient("R23AAEX", ""); merchant.onUnknownFields == nil {
+		t.Errorf("expected the merchant client to report unknown fields")
+	} else if merchant.onUnknownFields(nil, nil, []string{"status_details"}); len(reported) != 1 {
+		t.Errorf("expected the callback of the partner client, got %v", reported)
+	}
+
+	if _, err := c.NewMerchantClient("", ""); err == nil {
+		t.Errorf("Expected error without refresh token")
+	}
+}
+
+func TestGetMerchantIDByTrackingID(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v1/customer/partners/6LKMD2ML4NJYU/merchant-integrations" || r.URL.Query().Get("tracking_id") != "seller-1001" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.String())
+		}
+		fmt.Fprint(w, `{"merchant_id":"8LQLM2ML4ZTYU","links":[{"href":"https://api.paypal.com/v1/customer/partners/6LKMD2ML4NJYU/merchant-integrations/8LQLM2ML4ZTYU","rel":"read","method":"GET"}]}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	lookup, err := c.GetMerchantIDByTrackingID("6LKMD2ML4NJYU", "seller-1001")
+	if err != nil || lookup.MerchantID != "8LQLM2ML4ZTYU" {
+		t.Errorf("unexpected lookup %+v, %v", lookup, err)
+	}
+	if _, err := c.GetMerchantIDByTrackingID("6LKMD2ML4NJYU", ""); err == nil {
+		t.Errorf("Expected error without tracking ID")
+	}
+}
+
+func TestGetReferralData(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v2/customer/partner-referrals/ZjcyODU4ZWYtYTA1OC00ODIwLTk2M2EtOTZkZWQ4NmQwYzI3RU12cE5xa0xMRmk1NWxFSVJIT1JlTHdSZ2hkMzdhdVNqZzc1cUIwTHdFVkc1c1dOQlVQbUxadVJtYW1rbkdDVzVvNGNCbmxTRU9pOWRnTmJFemNDWA==" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"partner_referral_id":"ZjcyODU4ZWYtYTA1OC00ODIwLTk2M2EtOTZkZWQ4NmQwYzI3RU12cE5xa0xMRmk1NWxFSVJIT1JlTHdSZ2hkMzdhdVNqZzc1cUIwTHdFVkc1c1dOQlVQbUxadVJtYW1rbkdDVzVvNGNCbmxTRU9pOWRnTmJFemNDWA==","submitter_payer_id":"RFYUH2QQDGUQU","referral_data":{"tracking_id":"seller-1001","operations":[{"operation":"API_INTEGRATION"}],"products":["EXPRESS_CHECKOUT"],"legal_consents":[{"type":"SHARE_DATA_CONSENT","granted":true}]},"links":[{"href":"https://www.paypal.com/bizsignup/partner/entry?referralToken=abc","rel":"action_url","method":"GET"}]}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	data, err := c.GetReferralData("ZjcyODU4ZWYtYTA1OC00ODIwLTk2M2EtOTZkZWQ4NmQwYzI3RU12cE5xa0xMRmk1NWxFSVJIT1JlTHdSZ2hkMzdhdVNqZzc1cUIwTHdFVkc1c1dOQlVQbUxadVJtYW1rbkdDVzVvNGNCbmxTRU9pOWRnTmJFemNDWA==")
+	if err != nil {
+		t.Fatalf("Not expected error for GetReferralData, got %v", err)
+	}
+	if data.SubmitterPayerID != "RFYUH2QQDGUQU" || data.ReferralData.TrackingID != "seller-1001" || len(data.Links) != 1 {
+		t.Errorf("unexpected referral data %+v", data)
+	}
+}
+
+func TestCreateReferral(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body ReferralRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.Method != "POST" || r.URL.Path != "/v2/customer/partner-referrals" || body.TrackingID != "seller-1001" {
+			t.Errorf("unexpected request %s %s %+v", r.Method, r.URL.Path, body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"links":[{"href":"https://api-m.sandbox.paypal.com/v2/customer/partner-referrals/ZjcyODU4","rel":"self","method":"GET"},{"href":"https://www.sandbox.paypal.com/bizsignup/partner/entry?referralToken=ZjcyODU4","rel":"action_url","method":"GET"}]}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	referral, err := c.CreateReferral(&ReferralRequest{TrackingID: "seller-1001"})
+	if err != nil {
+		t.Fatalf("Not expected error for CreateReferral, got %v", err)
+	}
+	if u := referral.ActionURL(OnboardingDisplayModeFullPage); u != "https://www.sandbox.paypal.com/bizsignup/partner/entry?referralToken=ZjcyODU4" {
+		t.Errorf("unexpected full page action URL %s", u)
+	}
+	if u := referral.ActionURL(OnboardingDisplayModeMiniBrowser); u != "https://www.sandbox.paypal.com/bizsignup/partner/entry?displayMode=minibrowser&referralToken=ZjcyODU4" {
+		t.Errorf("unexpected mini browser action URL %s", u)
+	}
+	if u := (&CreateReferralResponse{}).ActionURL(OnboardingDisplayModeFullPage); u != "" {
+		t.Errorf("expected no action URL, got %s", u)
+	}
+}
+
+func TestParseOnboardingReturn(t *testing.T) {
+	tests := []struct {
+		query     string
+		completed bool
+		err       bool
+	}{
+		{"merchantId=seller-1001&merchantIdInPayPal=C7CYMKZDG8D6E&permissionsGranted=true&consentStatus=true&productIntentId=addipmt&isEmailConfirmed=true&accountStatus=BUSINESS_ACCOUNT", true, false},
+		{"merchantId=seller-1001&merchantIdInPayPal=C7CYMKZDG8D6E&permissionsGranted=false&consentStatus=true", false, false},
+		{"merchantId=seller-1001&merchantIdInPayPal=C7CYMKZDG8D6E", false, false},
+		{"merchantId=seller-1001&permissionsGranted=true&consentStatus=true", false, true},
+		{"merchantId=seller-1001&merchantIdInPayPal=C7CYMKZDG8D6E&permissionsGranted=yes", false, true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/paypal/onboarded?"+tt.query, nil)
+		ret, err := ParseOnboardingReturn(r)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: unexpected error %v", tt.query, err)
+			continue
+		}
+		if err == nil && (ret.Completed() != tt.completed || ret.MerchantID != "seller-1001" || ret.MerchantIDInPayPal != "C7CYMKZDG8D6E") {
+			t.Errorf("%s: unexpected return %+v", tt.query, ret)
+		}
+	}
+}
+
+func TestCreateSetupToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body SetupTokenRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.Method != "POST" || r.URL.Path != "/v3/vault/setup-tokens" || r.Header.Get("PayPal-Request-Id") == "" ||
+			body.Customer.ID != "customer_4029352050" || body.PaymentSource.PayPal == nil || body.PaymentSource.PayPal.UsageType != VaultUsageTypeMerchant {
+			t.Errorf("unexpected request %s %s %+v", r.Method, r.URL.Path, body)
+		}
+		fmt.Fprint(w, `{"id":"5C991763VB2781612","customer":{"id":"customer_4029352050"},"status":"PAYER_ACTION_REQUIRED","payment_source":{"paypal":{"usage_type":"MERCHANT"}},"links":[{"href":"https://www.sandbox.paypal.com/agreements/approve?approval_session_id=5C991763VB2781612","rel":"approve","method":"GET"},{"href":"https://api-m.sandbox.paypal.com/v3/vault/setup-tokens/5C991763VB2781612","rel":"self","method":"GET"}]}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	setupToken, err := c.CreateSetupToken(&SetupTokenRequest{
+		Customer:      &VaultCustomer{ID: "customer_4029352050"},
+		PaymentSource: &SetupTokenPaymentSource{PayPal: &VaultWalletRequest{UsageType: VaultUsageTypeMerchant}},
+	})
+	if err != nil {
+		t.Fatalf("Not expected error for CreateSetupToken, got %v", err)
+	}
+	if setupToken.Status != SetupTokenStatusPayerActionRequired ||
+		setupToken.ApproveURL() != "https://www.sandbox.paypal.com/agreements/approve?approval_session_id=5C991763VB2781612" {
+		t.Errorf("unexpected setup token %+v", setupToken)
+	}
+
+	if _, err := c.CreateSetupToken(&SetupTokenRequest{}); err == nil {
+		t.Errorf("expected an error creating a setup token without payment source")
+	}
+}
+
+func TestGetSetupToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v3/vault/setup-tokens/5C991763VB2781612" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"id":"5C991763VB2781612","customer":{"id":"customer_4029352050"},"status":"APPROVED","payment_source":{"paypal":{"email_address":"buyer@example.com","payer_id":"QYR5Z8XDVJNXQ","usage_type":"MERCHANT"}},"links":[{"href":"https://api-m.sandbox.paypal.com/v3/vault/setup-tokens/5C991763VB2781612","rel":"self","method":"GET"}]}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	setupToken, err := c.GetSetupToken("5C991763VB2781612")
+	if err != nil {
+		t.Fatalf("Not expected error for GetSetupToken, got %v", err)
+	}
+	if !setupToken.Approved() || setupToken.PaymentSource.PayPal.PayerID != "QYR5Z8XDVJNXQ" || setupToken.ApproveURL() != "" {
+		t.Errorf("unexpected setup token %+v", setupToken)
+	}
+}
+
+func TestPaymentTokens(t *testing.T) {
+	var deleted []string
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v3/vault/payment-tokens":
+			if r.URL.Query().Get("customer_id") != "customer_4029352050" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, `{"customer":{"id":"customer_4029352050"},"payment_tokens":[{"id":"9sk6234m","payment_source":{"paypal":{"email_address":"buyer@example.com"}}}],"links":[]}`)
+				return
+			}
+			fmt.Fprintf(w, `{"customer":{"id":"customer_4029352050"},"payment_tokens":[{"id":"8kk8451t","payment_source":{"card":{"brand":"VISA","last_digits":"1111","expiry":"2027-02"}}}],"links":[{"href":"%s/v3/vault/payment-tokens?customer_id=customer_4029352050&page=2","rel":"next","method":"GET"}]}`, ts.URL)
+		case r.Method == "GET" && r.URL.Path == "/v3/vault/payment-tokens/8kk8451t":
+			fmt.Fprint(w, `{"id":"8kk8451t","customer":{"id":"customer_4029352050"},"payment_source":{"card":{"brand":"VISA","last_digits":"1111","expiry":"2027-02"}}}`)
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	tokens, err := c.ListAllPaymentTokens("customer_4029352050")
+	if err != nil || len(tokens) != 2 || tokens[0].PaymentSource.Card.LastDigits != "1111" || tokens[1].PaymentSource.PayPal == nil {
+		t.Errorf("unexpected payment tokens %+v, %v", tokens, err)
+	}
+
+	token, err := c.GetPaymentToken("8kk8451t")
+	if err != nil || token.Customer.ID != "customer_4029352050" || token.PaymentSource.Card.Brand != "VISA" {
+		t.Errorf("unexpected payment token %+v, %v", token, err)
+	}
+
+	if err := c.DeletePaymentToken("8kk8451t"); err != nil || len(deleted) != 1 || deleted[0] != "/v3/vault/payment-tokens/8kk8451t" {
+		t.Errorf("unexpected delete %v, %v", deleted, err)
+	}
+
+	if _, err := c.ListPaymentTokens(&ListPaymentTokensRequest{}); err == nil {
+		t.Errorf("expected an error listing payment tokens without customer ID")
+	}
+}
+
+func TestTrackers(t *testing.T) {
+	var updated Tracker
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/shipping/trackers/8MC585209K746392H-443844607820" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `{"transaction_id":"8MC585209K746392H","tracking_number":"443844607820","status":"SHIPPED","carrier":"FEDEX","shipment_date":"2018-05-31","tracking_number_validated":true,"links":[{"href":"https://api-m.sandbox.paypal.com/v1/shipping/trackers/8MC585209K746392H-443844607820","rel":"self","method":"GET"}]}`)
+		case "PUT":
+			json.NewDecoder(r.Body).Decode(&updated)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	id := TrackerID("8MC585209K746392H", "443844607820")
+	tracker, err := c.GetTracker(id)
+	if err != nil || tracker.Status != "SHIPPED" || !tracker.TrackingNumberValidated {
+		t.Errorf("unexpected tracker %+v, %v", tracker, err)
+	}
+
+	tracker.Status = "DELIVERED"
+	if err := c.UpdateTracker(id, tracker); err != nil {
+		t.Fatalf("Not expected error for UpdateTracker, got %v", err)
+	}
+	if updated.TransactionID != "8MC585209K746392H" || updated.Status != "DELIVERED" {
+		t.Errorf("unexpected update %+v", updated)
+	}
+
+	if err := c.UpdateTracker("", tracker); err == nil {
+		t.Errorf("expected an error updating a tracker without ID")
+	}
+}
+
+func TestTracker_Validate(t *testing.T) {
+	tests := []struct {
+		tracker *Tracker
+		valid   bool
+	}{
+		{&Tracker{TransactionID: "8MC585209K746392H", Status: TrackerStatusShipped, Carrier: TrackerCarrierFedex}, true},
+		{&Tracker{TransactionID: "8MC585209K746392H", Status: TrackerStatusDelivered}, true},
+		{&Tracker{TransactionID: "8MC585209K746392H", Status: TrackerStatusShipped, Carrier: TrackerCarrierOther, CarrierNameOther: "Local Courier"}, true},
+		{&Tracker{TransactionID: "8MC585209K746392H", Status: TrackerStatusShipped, Carrier: TrackerCarrierOther}, false},
+		{&Tracker{TransactionID: "8MC585209K746392H", Status: TrackerStatusShipped, Carrier: "FedEx"}, false},
+		{&Tracker{TransactionID: "8MC585209K746392H", Status: "SENT"}, false},
+		{&Tracker{Status: TrackerStatusShipped}, false},
+	}
+	for i, tt := range tests {
+		if err := tt.tracker.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%d: unexpected validation result %v", i, err)
+		}
+	}
+
+	if !IsTrackerCarrier(TrackerCarrierGBRoyalMail) || IsTrackerCarrier("ROYAL_MAIL") {
+		t.Errorf("unexpected carrier lookup")
+	}
+}
+
+func TestTransactionContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/risk/transaction-contexts/C7CYMKZDG8D6E/b7a1d3c9e5f04a2b8c6d" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		switch r.Method {
+		case "PUT":
+			var body TransactionContext
+			json.NewDecoder(r.Body).Decode(&body)
+			if len(body.AdditionalData) != 2 || body.AdditionalData[0].Key != TransactionContextKeySenderAccountID {
+				t.Errorf("unexpected body %+v", body)
+			}
+			fmt.Fprint(w, `{"additional_data":[{"key":"sender_account_id","value":"A12345N343"},{"key":"sender_create_date","value":"2012-12-09T19:14:55.277-0:00"}]}`)
+		case "GET":
+			fmt.Fprint(w, `{"tracking_id":"b7a1d3c9e5f04a2b8c6d","additional_data":[{"key":"sender_account_id","value":"A12345N343"},{"key":"sender_create_date","value":"2012-12-09T19:14:55.277-0:00"}]}`)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	_, err := c.SetTransactionContext("C7CYMKZDG8D6E", "b7a1d3c9e5f04a2b8c6d", []*TransactionContextData{
+		{Key: TransactionContextKeySenderAccountID, Value: "A12345N343"},
+		{Key: TransactionContextKeySenderCreateDate, Value: "2012-12-09T19:14:55.277-0:00"},
+	})
+	if err != nil {
+		t.Fatalf("Not expected error for SetTransactionContext, got %v", err)
+	}
+
+	stc, err := c.GetTransactionContext("C7CYMKZDG8D6E", "b7a1d3c9e5f04a2b8c6d")
+	if err != nil || stc.TrackingID != "b7a1d3c9e5f04a2b8c6d" || stc.Value(TransactionContextKeySenderAccountID) != "A12345N343" || stc.Value(TransactionContextKeySenderEmail) != "" {
+		t.Errorf("unexpected transaction context %+v, %v", stc, err)
+	}
+
+	if _, err := c.SetTransactionContext("C7CYMKZDG8D6E", "0123456789abcdef0123456789abcdef0", nil); err == nil {
+		t.Errorf("expected an error with a tracking ID longer than 32 characters")
+	}
+}
+
+func TestV1Payments(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1/payments/payment":
+			q := r.URL.Query()
+			if q.Get("count") != "2" || q.Get("start_time") != "2019-01-01T00:00:00Z" || q.Get("sort_by") != PaymentSortByCreateTime {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"payments":[{"id":"PAY-0J356327TH335450NK56Y2PQ","intent":"sale","state":"approved","create_time":"2019-01-21T09:12:32Z"}],"count":1,"next_id":"PAY-5TU010975T094876HLKGY7DY"}`)
+		case r.Method == "GET" && r.URL.Path == "/v1/payments/payment/PAY-0J356327TH335450NK56Y2PQ":
+			fmt.Fprint(w, `{"id":"PAY-0J356327TH335450NK56Y2PQ","intent":"sale","state":"approved","payer":{"payment_method":"paypal","payer_info":{"email":"buyer@example.com","payer_id":"QYR5Z8XDVJNXQ"}},"transactions":[{"amount":{"total":"30.11","currency":"USD"},"invoice_number":"48787589673","related_resources":[{"sale":{"id":"4RR959492F879224U","state":"completed","amount":{"total":"30.11","currency":"USD"},"parent_payment":"PAY-0J356327TH335450NK56Y2PQ"}}]}]}`)
+		case r.Method == "POST" && r.URL.Path == "/v1/payments/sale/4RR959492F879224U/refund":
+			var body RefundSaleRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Amount.Total != "2.34" || body.Reason != "Damaged item" {
+				t.Errorf("unexpected refund request %+v", body)
+			}
+			fmt.Fprint(w, `{"id":"1JU08902781691411","state":"completed","amount":{"total":"2.34","currency":"USD"},"sale_id":"4RR959492F879224U","parent_payment":"PAY-0J356327TH335450NK56Y2PQ","reason":"Damaged item","create_time":"2019-01-21T09:31:21Z"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	payments, err := c.ListPayments(&ListPaymentsRequest{Count: 2, StartTime: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), SortBy: PaymentSortByCreateTime})
+	if err != nil || len(payments.Payments) != 1 || payments.NextID != "PAY-5TU010975T094876HLKGY7DY" || payments.Payments[0].CreateTime == nil {
+		t.Errorf("unexpected payments %+v, %v", payments, err)
+	}
+
+	payment, err := c.GetPayment("PAY-0J356327TH335450NK56Y2PQ")
+	if err != nil || len(payment.Transactions) != 1 || payment.Transactions[0].Amount.Total != "30.11" ||
+		len(payment.Transactions[0].RelatedResources) != 1 || payment.Transactions[0].RelatedResources[0].Sale.ID != "4RR959492F879224U" {
+		t.Errorf("unexpected payment %+v, %v", payment, err)
+	}
+
+	refund, err := c.RefundSaleWithDetails("4RR959492F879224U", &RefundSaleRequest{Amount: &Amount{Total: "2.34", Currency: "USD"}, Reason: "Damaged item"})
+	if err != nil || refund.State != "completed" || refund.Amount.Total != "2.34" || refund.ParentPayment != "PAY-0J356327TH335450NK56Y2PQ" {
+		t.Errorf("unexpected refund %+v, %v", refund, err)
+	}
+}
+
+func TestUnknownFieldsCallback(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"5O190127TN364715T","status":"APPROVED","processing_instruction":"NO_INSTRUCTION","purchase_units":[{"reference_id":"default","amount":{"currency_code":"USD","value":"10.00"},"payee":{"merchant_id":"C7CYMKZDG8D6E"}},{"reference_id":"second","payee":{"merchant_id":"C7CYMKZDG8D6E"}}],"Intent":"CAPTURE","create_time":"2020-01-15T10:00:00Z"}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	var reported []string
+	c.SetUnknownFieldsCallback(func(req *http.Request, v interface{}, fields []string) {
+		if req.URL.Path != "/v2/checkout/orders/5O190127TN364715T" {
+			t.Errorf("unexpected request %s", req.URL.Path)
+		}
+		if _, ok := v.(*Order); !ok {
+			t.Errorf("unexpected value %T", v)
+		}
+		reported = fields
+	})
+
+	order, err := c.GetOrder("5O190127TN364715T")
+	if err != nil || order.Intent != OrderIntentCapture || len(order.PurchaseUnits) != 2 {
+		t.Fatalf("expected the order to decode, got %+v, %v", order, err)
+	}
+	if len(reported) != 2 || reported[0] != "processing_instruction" || reported[1] != "purchase_units[].payee" {
+		t.Errorf("unexpected unknown fields %v", reported)
+	}
+
+	reported = nil
+	c.SetUnknownFieldsCallback(nil)
+	c.GetOrder("5O190127TN364715T")
+	if reported != nil {
+		t.Errorf("expected no report without a callback, got %v", reported)
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	calls := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.Method+" "+r.URL.Path]++
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1/billing/plans/P-5ML4271244454362WXNWU5NQ":
+			fmt.Fprintf(w, `{"id":"P-5ML4271244454362WXNWU5NQ","name":"Monthly plan %d","status":"ACTIVE"}`, calls[r.Method+" "+r.URL.Path])
+		case r.Method == "GET" && r.URL.Path == "/v1/catalogs/products/PROD-XXCD1234QWER65782":
+			fmt.Fprint(w, `{"id":"PROD-XXCD1234QWER65782","name":"Video Streaming Service"}`)
+		case r.Method == "GET" && r.URL.Path == "/v1/notifications/webhooks":
+			fmt.Fprint(w, `{"webhooks":[{"id":"40Y916089Y8324740","url":"https://example.com/webhook"}]}`)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+	clock := &testClock{now: time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC)}
+	c.SetResponseCache(&MemoryResponseCache{Clock: clock}, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		plan, err := c.ShowPlan("P-5ML4271244454362WXNWU5NQ")
+		if err != nil || plan.Name != "Monthly plan 1" {
+			t.Fatalf("expected the cached plan, got %+v, %v", plan, err)
+		}
+		if product, err := c.ShowProduct("PROD-XXCD1234QWER65782"); err != nil || product.Name != "Video Streaming Service" {
+			t.Fatalf("unexpected product %+v, %v", product, err)
+		}
+		if list, err := c.ListWebhooks(); err != nil || len(list.Webhooks) != 1 {
+			t.Fatalf("unexpected webhooks %+v, %v", list, err)
+		}
+	}
+	if calls["GET /v1/billing/plans/P-5ML4271244454362WXNWU5NQ"] != 1 || calls["GET /v1/catalogs/products/PROD-XXCD1234QWER65782"] != 1 ||
+		calls["GET /v1/notifications/webhooks"] != 1 {
+		t.Errorf("expected a single call per resource, got %v", calls)
+	}
+
+	// updates through the client invalidate the cached responses
+	c.DeactivatePlan("P-5ML4271244454362WXNWU5NQ")
+	if plan, _ := c.ShowPlan("P-5ML4271244454362WXNWU5NQ"); plan.Name != "Monthly plan 2" {
+		t.Errorf("expected the plan to be fetched after the update, got %+v", plan)
+	}
+	c.DeleteWebhook("40Y916089Y8324740")
+	c.ListWebhooks()
+	if calls["GET /v1/notifications/webhooks"] != 2 {
+		t.Errorf("expected the webhooks to be listed after the deletion, got %v", calls)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if plan, _ := c.ShowPlan("P-5ML4271244454362WXNWU5NQ"); plan.Name != "Monthly plan 3" {
+		t.Errorf("expected the plan to be fetched once the TTL expired, got %+v", plan)
+	}
+}
+
+func TestStreamProducts(t *testing.T) {
+	var block bool
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			if r.URL.Query().Get("page_size") != "2" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			fmt.Fprintf(w, `{"products":[{"id":"PROD-1"},{"id":"PROD-2"}],"links":[{"href":"%s/v1/catalogs/products?page_size=2&page=2","rel":"next"}]}`, ts.URL)
+		case "2":
+			if block {
+				<-r.Context().Done()
+				return
+			}
+			fmt.Fprint(w, `{"products":[{"id":"PROD-3"}],"links":[]}`)
+		default:
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	products, errc := c.StreamProducts(context.Background(), &ListProductsRequest{PageSize: 2})
+	var ids []string
+	for product := range products {
+		ids = append(ids, product.ID)
+	}
+	if err := <-errc; err != nil || len(ids) != 3 || ids[2] != "PROD-3" {
+		t.Errorf("expected the products of both pages, got %v, %v", ids, err)
+	}
+
+	// the second page never arrives, canceling the context stops the request
+	block = true
+	ctx, cancel := context.WithCancel(context.Background())
+	products, errc = c.StreamProducts(ctx, &ListProductsRequest{PageSize: 2})
+	<-products
+	cancel()
+	for range products {
+	}
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("expected the stream to stop with the context, got %v", err)
+	}
+}
+
+func TestStreamPlansError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("product_id") != "PROD-XXCD1234QWER65782" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"name":"INTERNAL_SERVER_ERROR"}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	plans, errc := c.StreamPlans(context.Background(), &ListPlansParams{ProductID: "PROD-XXCD1234QWER65782"})
+	for range plans {
+		t.Error("expected no plans")
+	}
+	if errResp, ok := (<-errc).(*ErrorResponse); !ok || errResp.Name != "INTERNAL_SERVER_ERROR" {
+		t.Errorf("expected the API error, got %v", errResp)
+	}
+}
+
+func TestListAllProductsConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		q := r.URL.Query()
+		if q.Get("total_required") != "true" || q.Get("page_size") != "2" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		page, _ := strconv.Atoi(q.Get("page"))
+		if page > 1 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		fmt.Fprintf(w, `{"products":[{"id":"PROD-%d-1"},{"id":"PROD-%d-2"}],"total_items":10,"total_pages":5}`, page, page)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	products, err := c.ListAllProductsConcurrently(&ListProductsRequest{PageSize: 2}, 2)
+	if err != nil || len(products) != 10 {
+		t.Fatalf("expected the products of the 5 pages, got %d, %v", len(products), err)
+	}
+	for i, product := range products {
+		if want := fmt.Sprintf("PROD-%d-%d", i/2+1, i%2+1); product.ID != want {
+			t.Errorf("expected %s at %d, got %s", want, i, product.ID)
+		}
+	}
+	if maxInFlight != 2 {
+		t.Errorf("expected 2 concurrent requests, got %d", maxInFlight)
+	}
+}
+
+func TestListAllPlansConcurrentlyError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("product_id") != "PROD-XXCD1234QWER65782" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("page") == "3" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"plans":[{"id":"P-1"}],"total_pages":4}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+
+	plans, err := c.ListAllPlansConcurrently(&ListPlansParams{ProductID: "PROD-XXCD1234QWER65782"}, 0)
+	if errResp, ok := err.(*ErrorResponse); !ok || errResp.Response.StatusCode != http.StatusInternalServerError || plans != nil {
+		t.Errorf("expected the error of page 3, got %v, %v", plans, err)
+	}
+}
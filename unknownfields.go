@@ -0,0 +1,166 @@
+package paypal
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// unmarshalerType is the type of json.Unmarshaler, values implementing it decode themselves
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// SetUnknownFieldsCallback sets a function called after a response was decoded with the paths of its fields
+// that no struct field received, e.g. "purchase_units[].payee", so new API fields show up in logs or metrics
+// before they matter. The decoding doesn't fail on them, v is the value the response was decoded into.
+// Values that decode themselves, such as Event, are not inspected.
+// Set the callback before making calls, like the log, as the client reads it while it may be locked refreshing its token
+func (c *Client) SetUnknownFieldsCallback(callback func(req *http.Request, v interface{}, fields []string)) {
+	c.onUnknownFields = callback
+}
+
+// reportUnknownFields calls the unknown fields callback when the response data has fields v doesn't map
+func (c *Client) reportUnknownFields(req *http.Request, data []byte, v interface{}) {
+	if c.onUnknownFields == nil {
+		return
+	}
+
+	if fields := unknownFields(data, reflect.TypeOf(v)); len(fields) > 0 {
+		c.onUnknownFields(req, v, fields)
+	}
+}
+
+// unknownFieldsReporter returns a function passing the unknown fields of the response decoded into v to the
+// callback, for responses decoded as they are read. It returns nil when no callback is set
+func (c *Client) unknownFieldsReporter(req *http.Request, v interface{}) func(fields []string) {
+	if c.onUnknownFields == nil {
+		return nil
+	}
+	return func(fields []string) {
+		c.onUnknownFields(req, v, fields)
+	}
+}
+
+// unknownFields returns the sorted paths of the fields of data that decoding into a value of type t ignores
+func unknownFields(data []byte, t reflect.Type) []string {
+	found := map[string]bool{}
+	collectUnknownJSONFields(data, t, "", found)
+	return sortedFields(found)
+}
+
+// collectUnknownJSONFields adds the paths of the fields of data that decoding into a value of type t ignores
+// to found, prefixed with path
+func collectUnknownJSONFields(data []byte, t reflect.Type, path string, found map[string]bool) {
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return
+	}
+	collectUnknownFields(generic, t, path, found)
+}
+
+// sortedFields returns the paths of found, sorted
+func sortedFields(found map[string]bool) []string {
+	fields := make([]string, 0, len(found))
+	for field := range found {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// collectUnknownFields walks the generic JSON value alongside the type it is decoded into
+func collectUnknownFields(value interface{}, t reflect.Type, path string, found map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		if t.Implements(unmarshalerType) {
+			return
+		}
+		t = t.Elem()
+	}
+	if t.Implements(unmarshalerType) || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for key, v := range object {
+			field, ok := fields[key]
+			if !ok {
+				field, ok = fields[strings.ToLower(key)]
+			}
+			if !ok {
+				found[joinPath(path, key)] = true
+				continue
+			}
+			collectUnknownFields(v, field, joinPath(path, key), found)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, v := range object {
+			collectUnknownFields(v, t.Elem(), joinPath(path, key), found)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for _, v := range items {
+			collectUnknownFields(v, t.Elem(), path+"[]", found)
+		}
+	}
+}
+
+// jsonFields returns the types of the fields of the struct type by JSON name, including the promoted fields
+// of embedded structs. Names are also keyed in lower case, as encoding/json matches them case-insensitively
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, ft := range jsonFields(embedded) {
+					if _, ok := fields[key]; !ok {
+						fields[key] = ft
+					}
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+		if _, ok := fields[strings.ToLower(name)]; !ok {
+			fields[strings.ToLower(name)] = f.Type
+		}
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
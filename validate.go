@@ -0,0 +1,84 @@
+package paypal
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+type (
+	// ValidationError is returned when a request is rejected locally before it is sent to PayPal.
+	// Field is the path of the offending field in the request body, e.g. purchase_units[0].amount.value
+	ValidationError struct {
+		Field  string
+		Reason string
+	}
+
+	// validator is implemented by the request types checked before sending, see DisableValidation
+	validator interface {
+		Validate() error
+	}
+)
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("paypal: invalid %s: %s", e.Field, e.Reason)
+}
+
+// DisableValidation stops the client from validating requests before sending them,
+// PayPal then reports the invalid requests with a 400 response
+func (c *Client) DisableValidation() {
+	c.skipValidation = true
+}
+
+// validate validates v unless validation is disabled
+func (c *Client) validate(v validator) error {
+	if c.skipValidation {
+		return nil
+	}
+	return v.Validate()
+}
+
+// nestedError prefixes the field of a ValidationError with the path of the struct holding it
+func nestedError(prefix string, err error) error {
+	if vErr, ok := err.(*ValidationError); ok {
+		return &ValidationError{Field: prefix + "." + vErr.Field, Reason: vErr.Reason}
+	}
+	return err
+}
+
+// checkLength checks the number of characters of a field, a min of 1 makes the field required
+func checkLength(field, value string, min, max int) error {
+	n := utf8.RuneCountInString(value)
+	switch {
+	case n == 0 && min > 0:
+		return &ValidationError{Field: field, Reason: "is required"}
+	case n < min:
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at least %d characters", min)}
+	case n > max:
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at most %d characters", max)}
+	}
+	return nil
+}
+
+// checkMoney checks the currency is one PayPal supports and the value is a positive amount with its precision
+func checkMoney(field string, m *Money) error {
+	if m == nil {
+		return &ValidationError{Field: field, Reason: "is required"}
+	}
+	if err := checkCurrency(field+".currency_code", m.Currency); err != nil {
+		return err
+	}
+	if _, err := m.Decimal(); err != nil {
+		return &ValidationError{Field: field + ".value", Reason: fmt.Sprintf("%q is not a valid %s amount", m.Value, m.Currency)}
+	}
+	return nil
+}
+
+// firstError returns the first non-nil error
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package paypal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func validOrder() *createOrderRequest {
+	return &createOrderRequest{
+		Intent: OrderIntentCapture,
+		PurchaseUnits: []PurchaseUnitRequest{{
+			ReferenceID: "default",
+			Amount: &PurchaseUnitAmount{
+				Currency:  "USD",
+				Value:     "39.98",
+				Breakdown: &PurchaseUnitAmountBreakdown{ItemTotal: &Money{Currency: "USD", Value: "39.98"}},
+			},
+			Items: []Item{{
+				Name:       "Yoga Mat",
+				Quantity:   "2",
+				UnitAmount: &Money{Currency: "USD", Value: "19.99"},
+				Category:   ItemCategoryPhysicalGood,
+			}},
+		}},
+		ApplicationContext: &ApplicationContext{ShippingPreference: ShippingPreferenceNoShipping},
+	}
+}
+
+func TestValidateCreateOrder(t *testing.T) {
+	if err := validOrder().Validate(); err != nil {
+		t.Fatalf("Not expected error for valid order, got %v", err)
+	}
+
+	tests := []struct {
+		field  string
+		modify func(o *createOrderRequest)
+	}{
+		{"intent", func(o *createOrderRequest) { o.Intent = "SALE" }},
+		{"purchase_units", func(o *createOrderRequest) { o.PurchaseUnits = nil }},
+		{"purchase_units[0].amount", func(o *createOrderRequest) { o.PurchaseUnits[0].Amount = nil }},
+		{"purchase_units[0].amount.value", func(o *createOrderRequest) { o.PurchaseUnits[0].Amount.Value = "39.985" }},
+		{"purchase_units[0].soft_descriptor", func(o *createOrderRequest) { o.PurchaseUnits[0].SoftDescriptor = strings.Repeat("a", 23) }},
+		{"purchase_units[0].items[0].name", func(o *createOrderRequest) { o.PurchaseUnits[0].Items[0].Name = "" }},
+		{"purchase_units[0].items[0].quantity", func(o *createOrderRequest) { o.PurchaseUnits[0].Items[0].Quantity = "1.5" }},
+		{"purchase_units[0].items[0].unit_amount.currency_code", func(o *createOrderRequest) { o.PurchaseUnits[0].Items[0].UnitAmount.Currency = "EUR" }},
+		{"purchase_units[1].reference_id", func(o *createOrderRequest) { o.PurchaseUnits = append(o.PurchaseUnits, o.PurchaseUnits[0]) }},
+		{"application_context.landing_page", func(o *createOrderRequest) { o.ApplicationContext.LandingPage = "Login" }},
+	}
+	for _, tt := range tests {
+		order := validOrder()
+		tt.modify(order)
+
+		err := order.Validate()
+		if vErr, ok := err.(*ValidationError); !ok || vErr.Field != tt.field {
+			t.Errorf("expected a validation error for %s, got %v", tt.field, err)
+		}
+	}
+}
+
+func TestValidateBreakdown(t *testing.T) {
+	unit := func() *PurchaseUnitRequest {
+		return &PurchaseUnitRequest{
+			Amount: &PurchaseUnitAmount{
+				Currency: "USD",
+				Value:    "44.77",
+				Breakdown: &PurchaseUnitAmountBreakdown{
+					ItemTotal: &Money{Currency: "USD", Value: "39.98"},
+					TaxTotal:  &Money{Currency: "USD", Value: "3.20"},
+					Shipping:  &Money{Currency: "USD", Value: "4.99"},
+					Discount:  &Money{Currency: "USD", Value: "3.40"},
+				},
+			},
+			Items: []Item{{
+				Name:       "Yoga Mat",
+				Quantity:   "2",
+				UnitAmount: &Money{Currency: "USD", Value: "19.99"},
+				Tax:        &Money{Currency: "USD", Value: "1.60"},
+			}},
+		}
+	}
+	if err := unit().ValidateBreakdown(); err != nil {
+		t.Fatalf("Not expected error for consistent breakdown, got %v", err)
+	}
+
+	tests := []struct {
+		field  string
+		reason string
+		modify func(u *PurchaseUnitRequest)
+	}{
+		{"amount.value", "is 45.77 but the breakdown item_total 39.98 + tax_total 3.20 + shipping 4.99 - discount 3.40 add up to 44.77, 1.00 too high",
+			func(u *PurchaseUnitRequest) { u.Amount.Value = "45.77" }},
+		{"amount.breakdown.item_total", "is 19.99 but the items add up to 39.98, 19.99 too low",
+			func(u *PurchaseUnitRequest) { u.Amount.Breakdown.ItemTotal.Value = "19.99" }},
+		{"amount.breakdown.tax_total", "is required to match the item taxes adding up to 3.20",
+			func(u *PurchaseUnitRequest) { u.Amount.Breakdown.TaxTotal = nil }},
+		{"amount.breakdown.item_total", "is required when the purchase unit has items",
+			func(u *PurchaseUnitRequest) { u.Amount.Breakdown = nil }},
+		{"amount.breakdown.shipping.currency_code", "EUR does not match the amount currency USD",
+			func(u *PurchaseUnitRequest) { u.Amount.Breakdown.Shipping.Currency = "EUR" }},
+		{"amount.breakdown.discount.value", `"3.405" is not a valid USD amount`,
+			func(u *PurchaseUnitRequest) { u.Amount.Breakdown.Discount.Value = "3.405" }},
+	}
+	for _, tt := range tests {
+		u := unit()
+		tt.modify(u)
+
+		err := u.ValidateBreakdown()
+		if vErr, ok := err.(*ValidationError); !ok || vErr.Field != tt.field || vErr.Reason != tt.reason {
+			t.Errorf("expected %s %s, got %v", tt.field, tt.reason, err)
+		}
+	}
+
+	noItems := &PurchaseUnitRequest{Amount: &PurchaseUnitAmount{Currency: "JPY", Value: "3000"}}
+	if err := noItems.ValidateBreakdown(); err != nil {
+		t.Errorf("Not expected error for an amount without breakdown, got %v", err)
+	}
+}
+
+func TestValidatePayout(t *testing.T) {
+	payout := &Payout{
+		SenderBatchHeader: &SenderBatchHeader{SenderBatchID: "Payouts_2018_100007", EmailSubject: "You have a payout!"},
+		Items: []PayoutItem{{
+			RecipientType: PayoutRecipientTypeEmail,
+			Receiver:      "receiver@example.com",
+			Amount:        &AmountPayout{Currency: "USD", Value: "9.87"},
+		}},
+	}
+	if err := payout.Validate(); err != nil {
+		t.Fatalf("Not expected error for valid payout, got %v", err)
+	}
+
+	payout.Items[0].Amount.Value = "-1"
+	if err, ok := payout.Validate().(*ValidationError); !ok || err.Field != "items[0].amount.value" {
+		t.Errorf("expected a validation error for the item amount, got %v", err)
+	}
+
+	payout.Items[0].Amount.Value = "9.87"
+	payout.Items[0].RecipientType = "IBAN"
+	if err, ok := payout.Validate().(*ValidationError); !ok || err.Field != "items[0].recipient_type" {
+		t.Errorf("expected a validation error for the recipient type, got %v", err)
+	}
+
+	payout.Items = nil
+	if err, ok := payout.Validate().(*ValidationError); !ok || err.Field != "items" {
+		t.Errorf("expected a validation error for the missing items, got %v", err)
+	}
+}
+
+func TestValidateCreateSubscriptionRequest(t *testing.T) {
+	subscription := &CreateSubscriptionRequest{PlanID: "P-5ML4271244454362WXNWU5NQ", StartTime: "2018-11-01T00:00:00Z", Quantity: "20"}
+	if err := subscription.Validate(); err != nil {
+		t.Fatalf("Not expected error for valid subscription, got %v", err)
+	}
+
+	subscription.Quantity = "0"
+	if err, ok := subscription.Validate().(*ValidationError); !ok || err.Field != "quantity" {
+		t.Errorf("expected a validation error for the quantity, got %v", err)
+	}
+
+	subscription.Quantity = ""
+	subscription.StartTime = "tomorrow"
+	if err, ok := subscription.Validate().(*ValidationError); !ok || err.Field != "start_time" {
+		t.Errorf("expected a validation error for the start time, got %v", err)
+	}
+}
+
+func TestValidateUpdateSubscriptionStatusRequest(t *testing.T) {
+	if err := (&UpdateSubscriptionStatusRequest{Reason: "Customer request"}).Validate(); err != nil {
+		t.Fatalf("Not expected error for valid request, got %v", err)
+	}
+
+	var missing *UpdateSubscriptionStatusRequest
+	for _, r := range []*UpdateSubscriptionStatusRequest{missing, {}, {Reason: strings.Repeat("a", 129)}} {
+		if err, ok := r.Validate().(*ValidationError); !ok || err.Field != "reason" {
+			t.Errorf("expected a validation error for the reason, got %v", err)
+		}
+	}
+
+	if err := (&activateSubscriptionRequest{}).Validate(); err != nil {
+		t.Errorf("Not expected error for an activation without reason, got %v", err)
+	}
+}
+
+func TestValidateCreateProductRequest(t *testing.T) {
+	product := &CreateProductRequest{Name: "Video Streaming Service", Type: ProductTypeService}
+	if err := product.Validate(); err != nil {
+		t.Fatalf("Not expected error for valid product, got %v", err)
+	}
+
+	product.Type = "SOFTWARE"
+	if err, ok := product.Validate().(*ValidationError); !ok || err.Field != "type" {
+		t.Errorf("expected a validation error for the type, got %v", err)
+	}
+}
+
+func TestDisableValidation(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"name":"INVALID_REQUEST","message":"Request is not well-formed, syntactically incorrect, or violates schema."}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+
+	if _, err := c.CreateOrder(OrderIntentCapture, nil, nil, nil); err == nil {
+		t.Fatalf("expected a validation error")
+	} else if _, ok := err.(*ValidationError); !ok || requests != 0 {
+		t.Errorf("expected the order to be rejected before sending, got %v after %d requests", err, requests)
+	}
+
+	c.DisableValidation()
+	if _, err := c.CreateOrder(OrderIntentCapture, nil, nil, nil); err == nil {
+		t.Fatalf("expected an error response")
+	} else if _, ok := err.(*ErrorResponse); !ok || requests != 1 {
+		t.Errorf("expected the order to be sent, got %v after %d requests", err, requests)
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		address *ShippingDetailAddressPortable
+		field   string
+	}{
+		{&ShippingDetailAddressPortable{AddressLine1: "2211 N First Street", AdminArea2: "San Jose", AdminArea1: "CA", PostalCode: "95131", CountryCode: CountryCodeUS}, ""},
+		{&ShippingDetailAddressPortable{AddressLine1: "2211 N First Street", AdminArea1: "CA", PostalCode: "95131-1234", CountryCode: CountryCodeUS}, ""},
+		{&ShippingDetailAddressPortable{AddressLine1: "2211 N First Street", PostalCode: "95131", CountryCode: CountryCodeUS}, "admin_area_1"},
+		{&ShippingDetailAddressPortable{AdminArea1: "CA", PostalCode: "9513", CountryCode: CountryCodeUS}, "postal_code"},
+		{&ShippingDetailAddressPortable{AdminArea1: "ON", PostalCode: "K1A 0B1", CountryCode: CountryCodeCA}, ""},
+		{&ShippingDetailAddressPortable{AdminArea1: "ON", PostalCode: "K1A0B", CountryCode: CountryCodeCA}, "postal_code"},
+		{&ShippingDetailAddressPortable{PostalCode: "SW1A 1AA", CountryCode: CountryCodeGB}, ""},
+		{&ShippingDetailAddressPortable{CountryCode: CountryCodeHK}, ""},
+		{&ShippingDetailAddressPortable{CountryCode: "UK"}, "country_code"},
+	}
+	for _, tt := range tests {
+		err := tt.address.Validate()
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("Not expected error for %+v, got %v", tt.address, err)
+			}
+			continue
+		}
+		if vErr, ok := err.(*ValidationError); !ok || vErr.Field != tt.field {
+			t.Errorf("expected a validation error for %s of %+v, got %v", tt.field, tt.address, err)
+		}
+	}
+
+	if err := (&Address{Line1: "1 Main St", City: "Berlin", CountryCode: CountryCodeDE, PostalCode: "1011"}).Validate(); err == nil {
+		t.Errorf("expected an error for a German postal code with four digits")
+	}
+
+	order := validOrder()
+	order.PurchaseUnits[0].Shipping = &ShippingDetail{Address: &ShippingDetailAddressPortable{CountryCode: CountryCodeUS, PostalCode: "95131"}}
+	if err, ok := order.Validate().(*ValidationError); !ok || err.Field != "purchase_units[0].shipping.address.admin_area_1" {
+		t.Errorf("expected the shipping address to be validated with the order, got %v", err)
+	}
+}
+
+func TestValidateLocale(t *testing.T) {
+	if err := (&ApplicationContext{Locale: "fr-CA"}).Validate(); err != nil {
+		t.Errorf("Not expected error for fr-CA, got %v", err)
+	}
+
+	err := (&ApplicationContext{Locale: "en_us"}).Validate()
+	if vErr, ok := err.(*ValidationError); !ok || vErr.Field != "locale" || !strings.Contains(vErr.Reason, "did you mean en-US?") {
+		t.Errorf("expected en-US to be suggested, got %v", err)
+	}
+
+	if got := SuggestCheckoutLocales("fr"); strings.Join(got, ",") != "fr-BE,fr-CA,fr-CH,fr-FR,fr-XC" {
+		t.Errorf("unexpected suggestions for fr: %v", got)
+	}
+	if got := SuggestCheckoutLocales("xx-YY"); len(got) != 0 {
+		t.Errorf("expected no suggestions for xx-YY, got %v", got)
+	}
+
+	setupToken := &SetupTokenRequest{PaymentSource: &SetupTokenPaymentSource{
+		PayPal: &VaultWalletRequest{ExperienceContext: &VaultExperienceContext{Locale: "de"}},
+	}}
+	if err, ok := setupToken.Validate().(*ValidationError); !ok || err.Field != "payment_source.paypal.experience_context.locale" {
+		t.Errorf("expected a validation error for the experience context locale, got %v", err)
+	}
+
+	wp := &WebProfile{Name: "YeowZa! T-Shirt Shop", Presentation: Presentation{LocaleCode: "en-US"}}
+	if err, ok := wp.Validate().(*ValidationError); !ok || err.Field != "presentation.locale_code" {
+		t.Errorf("expected a validation error for the web profile locale, got %v", err)
+	}
+	wp.Presentation.LocaleCode = "zh_HK"
+	if err := wp.Validate(); err != nil {
+		t.Errorf("Not expected error for zh_HK, got %v", err)
+	}
+}
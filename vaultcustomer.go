@@ -0,0 +1,140 @@
+package paypal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// VaultCustomerStore keeps the vault customer ID of the merchant's users, the same customer ID must be passed
+	// to CreateSetupToken, GenerateClientToken and orders vaulting payment methods so a returning buyer
+	// finds their saved payment methods
+	VaultCustomerStore interface {
+		// CustomerID returns the customer ID of the user, or an empty string when none was saved
+		CustomerID(userID string) (string, error)
+		// SaveCustomerIDIfAbsent saves the customer ID unless the user already has one and returns the customer ID
+		// stored for the user. Concurrent calls for the same user must all return the same customer ID
+		SaveCustomerIDIfAbsent(userID, customerID string) (string, error)
+	}
+
+	// MemoryVaultCustomerStore is a VaultCustomerStore keeping the customer IDs in memory, it is meant for tests
+	// and development
+	MemoryVaultCustomerStore struct {
+		mu          sync.RWMutex
+		customerIDs map[string]string
+	}
+)
+
+// NewVaultCustomerID returns a random customer ID in the format PayPal accepts, 22 alphanumeric characters
+func NewVaultCustomerID() (string, error) {
+	b := make([]byte, 11)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// VaultCustomerID returns the customer ID of the user, generating and saving one on the first call.
+// When concurrent first calls race, all of them return the customer ID that was saved first
+func VaultCustomerID(store VaultCustomerStore, userID string) (string, error) {
+	if userID == "" {
+		return "", fmt.Errorf("paypal: a user ID is required to look up the vault customer ID")
+	}
+
+	customerID, err := store.CustomerID(userID)
+	if err != nil || customerID != "" {
+		return customerID, err
+	}
+
+	if customerID, err = NewVaultCustomerID(); err != nil {
+		return "", err
+	}
+	return store.SaveCustomerIDIfAbsent(userID, customerID)
+}
+
+// NewMemoryVaultCustomerStore returns an empty MemoryVaultCustomerStore
+func NewMemoryVaultCustomerStore() *MemoryVaultCustomerStore {
+	return &MemoryVaultCustomerStore{customerIDs: map[string]string{}}
+}
+
+// CustomerID returns the customer ID saved for the user
+func (s *MemoryVaultCustomerStore) CustomerID(userID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.customerIDs[userID], nil
+}
+
+// SaveCustomerID saves the customer ID of the user
+func (s *MemoryVaultCustomerStore) SaveCustomerID(userID, customerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.customerIDs[userID] = customerID
+	return nil
+}
+
+// SaveCustomerIDIfAbsent saves the customer ID unless the user already has one and returns the saved customer ID
+func (s *MemoryVaultCustomerStore) SaveCustomerIDIfAbsent(userID, customerID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if saved, ok := s.customerIDs[userID]; ok && saved != "" {
+		return saved, nil
+	}
+	s.customerIDs[userID] = customerID
+	return customerID, nil
+}
+
+// Expired reports whether the card behind the payment token expired before now, wallets never expire.
+// Cards expire at the end of their expiry month
+func (t *PaymentToken) Expired(now time.Time) bool {
+	if t.PaymentSource == nil {
+		return false
+	}
+
+	var expiry string
+	switch {
+	case t.PaymentSource.Card != nil:
+		expiry = t.PaymentSource.Card.Expiry
+	case t.PaymentSource.ApplePay != nil && t.PaymentSource.ApplePay.Card != nil:
+		expiry = t.PaymentSource.ApplePay.Card.Expiry
+	}
+
+	month, err := time.Parse("2006-01", expiry)
+	if err != nil {
+		return false
+	}
+	return !now.Before(month.AddDate(0, 1, 0))
+}
+
+// UsablePaymentTokens lists the payment tokens of the customer and deletes the ones of expired cards,
+// the remaining tokens are returned. Expired tokens are left out even when deleting them fails,
+// the first error is returned along with the usable tokens
+func (c *Client) UsablePaymentTokens(customerID string) ([]*PaymentToken, error) {
+	tokens, err := c.ListAllPaymentTokens(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleteErr error
+	now := clockNow(c.clock).UTC()
+	usable := make([]*PaymentToken, 0, len(tokens))
+	for _, token := range tokens {
+		if token == nil {
+			continue
+		}
+		if !token.Expired(now) {
+			usable = append(usable, token)
+			continue
+		}
+		if err := c.DeletePaymentToken(token.ID); err != nil && deleteErr == nil {
+			deleteErr = err
+		}
+	}
+
+	return usable, deleteErr
+}
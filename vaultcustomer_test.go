@@ -0,0 +1,107 @@
+package paypal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVaultCustomerID(t *testing.T) {
+	store := NewMemoryVaultCustomerStore()
+
+	customerID, err := VaultCustomerID(store, "user-1001")
+	if err != nil || !regexp.MustCompile(`^[0-9a-zA-Z]{22}$`).MatchString(customerID) {
+		t.Fatalf("unexpected customer ID %q, %v", customerID, err)
+	}
+
+	again, err := VaultCustomerID(store, "user-1001")
+	if err != nil || again != customerID {
+		t.Errorf("expected the saved customer ID %q, got %q, %v", customerID, again, err)
+	}
+
+	other, err := VaultCustomerID(store, "user-1002")
+	if err != nil || other == customerID {
+		t.Errorf("expected another customer ID for another user, got %q, %v", other, err)
+	}
+
+	if _, err := VaultCustomerID(store, ""); err == nil {
+		t.Errorf("expected an error without user ID")
+	}
+}
+
+func TestVaultCustomerID_Concurrent(t *testing.T) {
+	store := NewMemoryVaultCustomerStore()
+
+	customerIDs := make([]string, 8)
+	var wg sync.WaitGroup
+	for i := range customerIDs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			customerIDs[i], _ = VaultCustomerID(store, "user-1001")
+		}(i)
+	}
+	wg.Wait()
+
+	saved, _ := store.CustomerID("user-1001")
+	for _, customerID := range customerIDs {
+		if customerID == "" || customerID != saved {
+			t.Errorf("expected every call to return the saved customer ID %q, got %v", saved, customerIDs)
+			break
+		}
+	}
+}
+
+func TestPaymentToken_Expired(t *testing.T) {
+	now := time.Date(2024, time.March, 31, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		source  *VaultPaymentSource
+		expired bool
+	}{
+		{&VaultPaymentSource{Card: &VaultCard{Expiry: "2024-02"}}, true},
+		{&VaultPaymentSource{Card: &VaultCard{Expiry: "2024-03"}}, false},
+		{&VaultPaymentSource{ApplePay: &VaultApplePay{Card: &VaultApplePayCard{Expiry: "2023-12"}}}, true},
+		{&VaultPaymentSource{PayPal: &VaultWallet{EmailAddress: "buyer@example.com"}}, false},
+		{&VaultPaymentSource{Card: &VaultCard{}}, false},
+		{nil, false},
+	}
+	for i, tt := range tests {
+		token := &PaymentToken{ID: "8kk8451t", PaymentSource: tt.source}
+		if token.Expired(now) != tt.expired {
+			t.Errorf("%d: expected expired %v", i, tt.expired)
+		}
+	}
+}
+
+func TestUsablePaymentTokens(t *testing.T) {
+	var deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		fmt.Fprint(w, `{"customer":{"id":"customer_4029352050"},"payment_tokens":[{"id":"8kk8451t","payment_source":{"card":{"brand":"VISA","last_digits":"1111","expiry":"2029-12"}}},{"id":"9sk6234m","payment_source":{"card":{"brand":"VISA","last_digits":"4242","expiry":"2999-12"}}},{"id":"3nr7561q","payment_source":{"paypal":{"email_address":"buyer@example.com"}}}]}`)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient("foo", "bar", ts.URL)
+	c.Token = &TokenResponse{Token: "foo"}
+	c.SetClock(&testClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+	tokens, err := c.UsablePaymentTokens("customer_4029352050")
+	if err != nil {
+		t.Fatalf("Not expected error for UsablePaymentTokens, got %v", err)
+	}
+	if len(tokens) != 2 || tokens[0].ID != "9sk6234m" || tokens[1].ID != "3nr7561q" {
+		t.Errorf("unexpected usable tokens %+v", tokens)
+	}
+	if len(deleted) != 1 || deleted[0] != "/v3/vault/payment-tokens/8kk8451t" {
+		t.Errorf("expected the expired token to be deleted, got %v", deleted)
+	}
+}
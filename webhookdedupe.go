@@ -0,0 +1,74 @@
+package paypal
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultDedupeTTL is how long handled event IDs are remembered, PayPal redelivers events for up to 3 days
+const DefaultDedupeTTL = 72 * time.Hour
+
+type (
+	// DedupeStore remembers the IDs of handled webhook events so redelivered events are skipped
+	DedupeStore interface {
+		// Seen reports whether the event was marked as seen and its TTL has not expired
+		Seen(eventID string) (bool, error)
+		// MarkSeen marks the event as seen for ttl
+		MarkSeen(eventID string, ttl time.Duration) error
+	}
+
+	// MemoryDedupeStore is a DedupeStore keeping event IDs in memory, it is only suitable for a single instance
+	MemoryDedupeStore struct {
+		// Clock expires the event IDs, the system clock is used when nil
+		Clock Clock
+
+		mu    sync.Mutex
+		seen  map[string]time.Time
+		marks int
+	}
+)
+
+// NewMemoryDedupeStore returns an empty MemoryDedupeStore
+func NewMemoryDedupeStore() *MemoryDedupeStore {
+	return &MemoryDedupeStore{seen: map[string]time.Time{}}
+}
+
+// Seen reports whether the event was marked as seen and its TTL has not expired
+func (s *MemoryDedupeStore) Seen(eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.seen[eventID]
+	return ok && clockNow(s.Clock).Before(expiresAt), nil
+}
+
+// MarkSeen marks the event as seen for ttl, expired events are purged every 1024 marks
+func (s *MemoryDedupeStore) MarkSeen(eventID string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := clockNow(s.Clock)
+	if s.seen == nil {
+		s.seen = map[string]time.Time{}
+	}
+	s.seen[eventID] = now.Add(ttl)
+
+	s.marks++
+	if s.marks%1024 == 0 {
+		for id, expiresAt := range s.seen {
+			if !now.Before(expiresAt) {
+				delete(s.seen, id)
+			}
+		}
+	}
+
+	return nil
+}
+
+// dedupeTTL returns ttl or DefaultDedupeTTL when it is not set
+func dedupeTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return DefaultDedupeTTL
+	}
+	return ttl
+}
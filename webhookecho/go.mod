@@ -0,0 +1,10 @@
+module github.com/inplayer-org/paypal/webhookecho
+
+go 1.12
+
+require (
+	github.com/inplayer-org/paypal v0.0.0-20261016075348-86e871964e24
+	github.com/labstack/echo/v4 v4.11.4
+)
+
+replace github.com/inplayer-org/paypal => ../
@@ -0,0 +1,40 @@
+// Package webhookecho mounts the webhook handler of the paypal package on echo routers.
+package webhookecho
+
+import (
+	"net/http"
+
+	"github.com/inplayer-org/paypal"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler returns an echo.HandlerFunc verifying webhook deliveries for the webhook and passing them to the
+// callbacks of opts, it responds like paypal.WebhookHandler
+//
+//	e.POST("/paypal/webhook", webhookecho.Handler(c, webhookID, opts))
+func Handler(c *paypal.Client, webhookID string, opts *paypal.WebhookHandlerOptions) echo.HandlerFunc {
+	handler := paypal.WebhookHandler(c, webhookID, opts)
+	return func(ctx echo.Context) error {
+		handler.ServeHTTP(ctx.Response(), ctx.Request())
+		return nil
+	}
+}
+
+// Middleware returns an echo.MiddlewareFunc verifying and decoding webhook deliveries for the webhook before
+// next, the event is read with paypal.EventFromContext(ctx.Request().Context()).
+// Rejected deliveries are answered like paypal.WebhookMiddleware and never reach next
+//
+//	e.POST("/paypal/webhook", handler, webhookecho.Middleware(c, webhookID, nil))
+func Middleware(c *paypal.Client, webhookID string, opts *paypal.WebhookHandlerOptions) echo.MiddlewareFunc {
+	middleware := paypal.WebhookMiddleware(c, webhookID, opts)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			var err error
+			middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx.SetRequest(r)
+				err = next(ctx)
+			})).ServeHTTP(ctx.Response(), ctx.Request())
+			return err
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package webhookecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/inplayer-org/paypal"
+	"github.com/inplayer-org/paypal/paypaltest"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandler(t *testing.T) {
+	signer, err := paypaltest.NewWebhookSigner("1JE4291016473214C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+
+	var handled []string
+	opts := &paypal.WebhookHandlerOptions{Verifier: signer.Verifier()}
+	opts.On(paypal.EventPaymentSaleCompleted, func(r *http.Request, event *paypal.Event) error {
+		handled = append(handled, event.ID)
+		return nil
+	})
+
+	e := echo.New()
+	e.POST("/webhook", Handler(nil, "1JE4291016473214C", opts))
+
+	event, err := signer.Event(paypal.EventPaymentSaleCompleted, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := signer.Request("/webhook", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || len(handled) != 1 || handled[0] != event.ID {
+		t.Errorf("expected the event to be handled, got %d and %v", rec.Code, handled)
+	}
+
+	tampered := httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"id":"WH-1"}`))
+	tampered.Header = req.Header
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, tampered)
+	if rec.Code != http.StatusBadRequest || len(handled) != 1 {
+		t.Errorf("expected 400 for a tampered delivery, got %d and %v", rec.Code, handled)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	signer, err := paypaltest.NewWebhookSigner("1JE4291016473214C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+
+	var received *paypal.Event
+	e := echo.New()
+	e.POST("/webhook", func(ctx echo.Context) error {
+		received = paypal.EventFromContext(ctx.Request().Context())
+		return ctx.NoContent(http.StatusAccepted)
+	}, Middleware(nil, "1JE4291016473214C", &paypal.WebhookHandlerOptions{Verifier: signer.Verifier()}))
+
+	event, err := signer.Event(paypal.EventPaymentSaleCompleted, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := signer.Request("/webhook", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted || received == nil || received.ID != event.ID {
+		t.Errorf("expected the next handler to receive the event, got %d and %+v", rec.Code, received)
+	}
+
+	received = nil
+	tampered := httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"id":"WH-1"}`))
+	tampered.Header = req.Header
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, tampered)
+	if rec.Code != http.StatusBadRequest || received != nil {
+		t.Errorf("expected 400 without calling the next handler, got %d and %+v", rec.Code, received)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build go1.18
+// +build go1.18
+
+package paypal
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// FuzzEventDecoding makes sure no webhook delivery makes decoding panic, run it with
+// go test -fuzz=FuzzEventDecoding
+func FuzzEventDecoding(f *testing.F) {
+	for _, data := range webhookPayloads(f) {
+		f.Add(data)
+	}
+	f.Add([]byte(`{"event_type":"PAYMENT.SALE.COMPLETED","resource":null}`))
+	f.Add([]byte(`{"resource":"not an object","links":[null]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		webhookEvent := &WebhookEvent{}
+		if err := json.Unmarshal(data, webhookEvent); err == nil {
+			webhookEvent.Event()
+		}
+
+		event := &Event{}
+		if err := json.Unmarshal(data, event); err != nil {
+			return
+		}
+		if !bytes.Equal(event.Raw(), bytes.TrimSpace(data)) {
+			t.Errorf("expected the raw delivery to be kept")
+		}
+
+		event.SubscriptionResource()
+		event.SaleResource()
+		event.CaptureResource()
+		event.RefundResource()
+		event.OrderResource()
+		event.AuthorizationResource()
+		event.DisputeResource()
+		event.InvoiceResource()
+		event.PayoutItemResource()
+		event.PaymentTokenResource()
+		event.MerchantOnboardingResource()
+	})
+}
@@ -0,0 +1,10 @@
+module github.com/inplayer-org/paypal/webhookgin
+
+go 1.12
+
+require (
+	github.com/gin-gonic/gin v1.9.1
+	github.com/inplayer-org/paypal v0.0.0-20261016075348-86e871964e24
+)
+
+replace github.com/inplayer-org/paypal => ../
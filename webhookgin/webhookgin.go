@@ -0,0 +1,41 @@
+// Package webhookgin mounts the webhook handler of the paypal package on gin routers.
+package webhookgin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inplayer-org/paypal"
+)
+
+// Handler returns a gin.HandlerFunc verifying webhook deliveries for the webhook and passing them to the
+// callbacks of opts, it responds like paypal.WebhookHandler
+//
+//	router.POST("/paypal/webhook", webhookgin.Handler(c, webhookID, opts))
+func Handler(c *paypal.Client, webhookID string, opts *paypal.WebhookHandlerOptions) gin.HandlerFunc {
+	handler := paypal.WebhookHandler(c, webhookID, opts)
+	return func(ctx *gin.Context) {
+		handler.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
+
+// Middleware returns a gin.HandlerFunc verifying and decoding webhook deliveries for the webhook before the
+// next handlers, the event is read with paypal.EventFromContext(ctx.Request.Context()).
+// Rejected deliveries are answered like paypal.WebhookMiddleware and abort the chain
+//
+//	router.POST("/paypal/webhook", webhookgin.Middleware(c, webhookID, nil), handler)
+func Middleware(c *paypal.Client, webhookID string, opts *paypal.WebhookHandlerOptions) gin.HandlerFunc {
+	middleware := paypal.WebhookMiddleware(c, webhookID, opts)
+	return func(ctx *gin.Context) {
+		verified := false
+		middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verified = true
+			ctx.Request = r
+			ctx.Next()
+		})).ServeHTTP(ctx.Writer, ctx.Request)
+
+		if !verified {
+			ctx.Abort()
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package paypal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// DefaultWebhookMaxBodyBytes limits the size of webhook deliveries read by WebhookHandler
+const DefaultWebhookMaxBodyBytes int64 = 1 << 20
+
+// Possible values for `verification_status` in VerifyWebhookResponse
+const (
+	VerificationStatusSuccess string = "SUCCESS"
+	VerificationStatusFailure string = "FAILURE"
+)
+
+type (
+	// WebhookCallback handles a verified webhook event, returning an error makes PayPal redeliver the event
+	WebhookCallback func(r *http.Request, event *Event) error
+
+	// WebhookHandlerOptions configures the handler returned by WebhookHandler
+	WebhookHandlerOptions struct {
+		// Verifier verifies the signatures locally, the client calls verify-webhook-signature when nil
+		Verifier *WebhookVerifier
+		// Callbacks by event type, the "*" callback receives event types without their own callback
+		Callbacks map[string]WebhookCallback
+		// MaxBodyBytes limits the size of a delivery, DefaultWebhookMaxBodyBytes when 0
+		MaxBodyBytes int64
+		// OnError is called with every rejected or failed delivery, e.g. for logging
+		OnError func(r *http.Request, err error)
+		// Dedupe skips events that were already handled, an event is marked as seen once all its callbacks succeed.
+		// Concurrent deliveries of the same event may still both be handled
+		Dedupe DedupeStore
+		// DedupeTTL is how long handled events are remembered, DefaultDedupeTTL when 0
+		DedupeTTL time.Duration
+		// Store records every decoded delivery with its verification result and outcome, e.g. for audits
+		Store EventStore
+		// OnUnknownEventType is called with verified events whose type is not in the EventTypes catalog,
+		// e.g. to log event types added by PayPal. The events are still passed to the "*" callback
+		OnUnknownEventType func(r *http.Request, event *Event)
+	}
+)
+
+// On registers the callback for the event type, use "*" for a fallback callback
+func (o *WebhookHandlerOptions) On(eventType string, callback WebhookCallback) *WebhookHandlerOptions {
+	if o.Callbacks == nil {
+		o.Callbacks = map[string]WebhookCallback{}
+	}
+	o.Callbacks[eventType] = callback
+	return o
+}
+
+// WebhookHandler returns an http.Handler receiving webhook deliveries for the webhook.
+// Every delivery is verified, decoded into an Event and passed to the callback registered for its event type.
+// It responds with
+//   - 200 when the event was handled or no callback is registered for it
+//   - 400 when the signature is invalid or too old for the MaxAge of opts.Verifier, or the body is not an event
+//   - 405 for other methods than POST, 413 when the body exceeds MaxBodyBytes
+//   - 500 when the verification or the callback failed, PayPal redelivers the event later.
+//     Deliveries are always answered with 500 when c and opts.Verifier are both nil
+func WebhookHandler(c *Client, webhookID string, opts *WebhookHandlerOptions) http.Handler {
+	if opts == nil {
+		opts = &WebhookHandlerOptions{}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := serveWebhook(c, webhookID, opts, r)
+		if err != nil && opts.OnError != nil {
+			opts.OnError(r, err)
+		}
+		w.WriteHeader(status)
+	})
+}
+
+// WebhookMiddleware returns a middleware verifying and decoding webhook deliveries for the webhook before
+// passing them to next, the event is available with EventFromContext. Rejected deliveries are answered with
+// the status codes of WebhookHandler and never reach next, opts.Callbacks are not used.
+// It has the signature of chi middlewares:
+//
+//	r.With(paypal.WebhookMiddleware(c, webhookID, nil)).Post("/webhook", handler)
+func WebhookMiddleware(c *Client, webhookID string, opts *WebhookHandlerOptions) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &WebhookHandlerOptions{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			event, _, status, err := readWebhookEvent(c, webhookID, opts, r)
+			if err != nil {
+				if opts.OnError != nil {
+					opts.OnError(r, err)
+				}
+				w.WriteHeader(status)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), webhookEventContextKey{}, event)))
+		})
+	}
+}
+
+// EventFromContext returns the event stored by WebhookMiddleware, nil when there is none
+func EventFromContext(ctx context.Context) *Event {
+	event, _ := ctx.Value(webhookEventContextKey{}).(*Event)
+	return event
+}
+
+// webhookEventContextKey is the context key of the event stored by WebhookMiddleware
+type webhookEventContextKey struct{}
+
+// serveWebhook handles a single delivery and returns the status code to respond with
+func serveWebhook(c *Client, webhookID string, opts *WebhookHandlerOptions, r *http.Request) (int, error) {
+	var clock Clock
+	if c != nil {
+		clock = c.clock
+	}
+	receivedAt := clockNow(clock)
+
+	event, body, status, err := readWebhookEvent(c, webhookID, opts, r)
+	if event == nil && body == nil {
+		return status, err
+	}
+
+	verified := event != nil
+	outcome := EventOutcomeRejected
+	if verified {
+		status, outcome, err = handleWebhookEvent(opts, r, event)
+	}
+
+	if opts.Store != nil {
+		// A rejected delivery is stored as received, its untrusted body is never decoded
+		stored := &StoredEvent{
+			ReceivedAt: receivedAt,
+			Payload:    body,
+			Verified:   verified,
+			Outcome:    outcome,
+		}
+		if verified {
+			stored.EventID, stored.EventType, stored.ResourceType = event.ID, event.EventType, event.ResourceType
+		}
+		if err != nil {
+			stored.Error = err.Error()
+		}
+		if storeErr := opts.Store.Save(stored); storeErr != nil && err == nil {
+			err = storeErr
+		}
+	}
+
+	return status, err
+}
+
+// handleWebhookEvent passes a verified event to its callback and returns the status code and outcome
+func handleWebhookEvent(opts *WebhookHandlerOptions, r *http.Request, event *Event) (int, string, error) {
+	if opts.Dedupe != nil {
+		seen, err := opts.Dedupe.Seen(event.ID)
+		if err != nil {
+			return http.StatusInternalServerError, EventOutcomeFailed, err
+		}
+		if seen {
+			return http.StatusOK, EventOutcomeDuplicate, nil
+		}
+	}
+
+	if opts.OnUnknownEventType != nil {
+		if _, known := EventResourceType(event.EventType); !known {
+			opts.OnUnknownEventType(r, event)
+		}
+	}
+
+	outcome := EventOutcomeUnhandled
+	callback, ok := opts.Callbacks[event.EventType]
+	if !ok {
+		callback, ok = opts.Callbacks["*"]
+	}
+	if ok {
+		if err := callback(r, event); err != nil {
+			return http.StatusInternalServerError, EventOutcomeFailed, fmt.Errorf("paypal: webhook event %s (%s) failed: %v", event.ID, event.EventType, err)
+		}
+		outcome = EventOutcomeHandled
+	}
+
+	// The event was handled, failing to remember it is reported without making PayPal redeliver it
+	if opts.Dedupe != nil {
+		if err := opts.Dedupe.MarkSeen(event.ID, dedupeTTL(opts.DedupeTTL)); err != nil {
+			return http.StatusOK, outcome, err
+		}
+	}
+
+	return http.StatusOK, outcome, nil
+}
+
+// readWebhookEvent reads, verifies and decodes a delivery, the status code to respond with is returned on failure.
+// The body is only decoded once its signature is verified, it is returned without an event along with the error
+// when the verification fails
+func readWebhookEvent(c *Client, webhookID string, opts *WebhookHandlerOptions, r *http.Request) (*Event, []byte, int, error) {
+	if r.Method != "POST" {
+		return nil, nil, http.StatusMethodNotAllowed, fmt.Errorf("paypal: webhook delivery with method %s", r.Method)
+	}
+
+	maxBodyBytes := opts.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultWebhookMaxBodyBytes
+	}
+
+	if r.ContentLength > maxBodyBytes {
+		return nil, nil, http.StatusRequestEntityTooLarge, fmt.Errorf("paypal: webhook delivery exceeds %d bytes", maxBodyBytes)
+	}
+	body, err := readBody(io.LimitReader(r.Body, maxBodyBytes+1), r.ContentLength)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+	if int64(len(body)) > maxBodyBytes {
+		return nil, nil, http.StatusRequestEntityTooLarge, fmt.Errorf("paypal: webhook delivery exceeds %d bytes", maxBodyBytes)
+	}
+
+	if opts.Verifier != nil {
+		if err := opts.Verifier.VerifySignature(r.Header, body); err != nil {
+			if err == ErrInvalidWebhookSignature || err == ErrWebhookTransmissionExpired {
+				return nil, body, http.StatusBadRequest, err
+			}
+			return nil, body, http.StatusInternalServerError, err
+		}
+	} else if c == nil {
+		return nil, nil, http.StatusInternalServerError, fmt.Errorf("paypal: a client or a verifier is required to verify webhook deliveries")
+	} else {
+		// verify-webhook-signature takes the event as JSON, a body that is not JSON can't be signed by PayPal
+		if !json.Valid(body) {
+			return nil, nil, http.StatusBadRequest, fmt.Errorf("paypal: invalid webhook event: body is not JSON")
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		verification, err := c.VerifyWebhookSignature(r, webhookID)
+		if err != nil {
+			return nil, body, http.StatusInternalServerError, err
+		}
+		if verification.VerificationStatus != VerificationStatusSuccess {
+			return nil, body, http.StatusBadRequest, ErrInvalidWebhookSignature
+		}
+	}
+
+	// The body is never modified, the event keeps it as its raw JSON instead of a copy
+	event, err := decodeEvent(body)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, fmt.Errorf("paypal: invalid webhook event: %v", err)
+	}
+
+	// Leave the body readable for handlers behind WebhookMiddleware
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return event, body, http.StatusOK, nil
+}
@@ -0,0 +1,387 @@
+package paypal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookHandler_Verifier(t *testing.T) {
+	signer := newTestWebhookSigner(t, "messageverificationcerts.paypal.com")
+	certServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(signer.chain)
+	}))
+	defer certServer.Close()
+
+	u, _ := url.Parse(certServer.URL)
+	certURL := certServer.URL + "/v1/notifications/certs/CERT-360caa42-fca2a594-a5cafa77"
+
+	verifier := NewWebhookVerifier("1JE4291016473214C")
+	verifier.HTTPClient = certServer.Client()
+	verifier.Roots = signer.roots
+	verifier.CertHosts = []string{u.Hostname()}
+
+	var handled []string
+	var errs []error
+	opts := &WebhookHandlerOptions{
+		Verifier: verifier,
+		OnError:  func(r *http.Request, err error) { errs = append(errs, err) },
+	}
+	opts.On(EventPaymentSaleCompleted, func(r *http.Request, event *Event) error {
+		sale, err := event.SaleResource()
+		if err != nil {
+			return err
+		}
+		handled = append(handled, sale.ID)
+		return nil
+	})
+	opts.On(EventPaymentCaptureDenied, func(r *http.Request, event *Event) error {
+		return fmt.Errorf("database unavailable")
+	})
+	handler := WebhookHandler(nil, "1JE4291016473214C", opts)
+
+	deliver := func(method string, body string, header http.Header) int {
+		req := httptest.NewRequest(method, "/webhook", strings.NewReader(body))
+		if header != nil {
+			req.Header = header
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	sale := `{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED","resource":{"id":"80021663DE681814L"}}`
+	if code := deliver("POST", sale, signer.sign(t, certURL, "1JE4291016473214C", []byte(sale))); code != http.StatusOK {
+		t.Errorf("expected 200 for handled event, got %d", code)
+	}
+	if len(handled) != 1 || handled[0] != "80021663DE681814L" {
+		t.Errorf("expected sale to be handled, got %v", handled)
+	}
+
+	if code := deliver("POST", sale, signer.sign(t, certURL, "OTHER", []byte(sale))); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid signature, got %d", code)
+	}
+
+	// The test signer transmits at 2019-10-27T17:38:37Z, a day later is a replay
+	verifier.MaxAge = time.Hour
+	verifier.Clock = &testClock{now: time.Date(2019, 10, 28, 17, 38, 37, 0, time.UTC)}
+	if code := deliver("POST", sale, signer.sign(t, certURL, "1JE4291016473214C", []byte(sale))); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a replayed delivery, got %d", code)
+	}
+	verifier.MaxAge = 0
+
+	unknown := `{"id":"WH-2","event_type":"CATALOG.PRODUCT.CREATED","resource":{}}`
+	if code := deliver("POST", unknown, signer.sign(t, certURL, "1JE4291016473214C", []byte(unknown))); code != http.StatusOK {
+		t.Errorf("expected 200 for event without callback, got %d", code)
+	}
+
+	denied := `{"id":"WH-3","event_type":"PAYMENT.CAPTURE.DENIED","resource":{}}`
+	if code := deliver("POST", denied, signer.sign(t, certURL, "1JE4291016473214C", []byte(denied))); code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for failed callback, got %d", code)
+	}
+
+	if code := deliver("GET", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET, got %d", code)
+	}
+
+	if len(errs) != 4 {
+		t.Errorf("expected OnError to be called 4 times, got %v", errs)
+	}
+}
+
+func TestWebhookHandler_VerifyWebhookSignature(t *testing.T) {
+	status := VerificationStatusSuccess
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/notifications/verify-webhook-signature" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"verification_status":"%s"}`, status)
+	}))
+	defer api.Close()
+
+	c, _ := NewClient("foo", "bar", api.URL)
+
+	called := 0
+	opts := (&WebhookHandlerOptions{MaxBodyBytes: 128}).On("*", func(r *http.Request, event *Event) error {
+		called++
+		return nil
+	})
+	handler := WebhookHandler(c, "1JE4291016473214C", opts)
+
+	deliver := func(body string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("POST", "/webhook", bytes.NewBufferString(body)))
+		return rec.Code
+	}
+
+	if code := deliver(`{"id":"WH-1","event_type":"CATALOG.PRODUCT.CREATED","resource":{}}`); code != http.StatusOK || called != 1 {
+		t.Errorf("expected 200 and fallback callback, got %d and %d calls", code, called)
+	}
+
+	if code := deliver(`{"id":"WH-1","resource":"` + strings.Repeat("x", 128) + `"}`); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for large body, got %d", code)
+	}
+
+	status = VerificationStatusFailure
+	if code := deliver(`{"id":"WH-1","event_type":"CATALOG.PRODUCT.CREATED","resource":{}}`); code != http.StatusBadRequest || called != 1 {
+		t.Errorf("expected 400 for failed verification, got %d and %d calls", code, called)
+	}
+}
+
+func TestWebhookMiddleware(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"verification_status":"SUCCESS"}`)
+	}))
+	defer api.Close()
+
+	c, _ := NewClient("foo", "bar", api.URL)
+
+	var received *Event
+	handler := WebhookMiddleware(c, "1JE4291016473214C", nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = EventFromContext(r.Context())
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED","resource":{}}`)))
+	if rec.Code != http.StatusAccepted || received == nil || received.ID != "WH-1" {
+		t.Errorf("expected next handler to receive the event, got %d and %+v", rec.Code, received)
+	}
+
+	received = nil
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/webhook", strings.NewReader(`not json`)))
+	if rec.Code != http.StatusBadRequest || received != nil {
+		t.Errorf("expected 400 without calling next, got %d and %+v", rec.Code, received)
+	}
+}
+
+func TestWebhookHandler_NoVerifier(t *testing.T) {
+	var errs []error
+	handler := WebhookHandler(nil, "1JE4291016473214C", &WebhookHandlerOptions{
+		OnError: func(r *http.Request, err error) { errs = append(errs, err) },
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED","resource":{}}`)))
+	if rec.Code != http.StatusInternalServerError || len(errs) != 1 {
+		t.Errorf("expected 500 without a client and a verifier, got %d and %v", rec.Code, errs)
+	}
+}
+
+func TestWebhookHandler_Dedupe(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"verification_status":"SUCCESS"}`)
+	}))
+	defer api.Close()
+
+	c, _ := NewClient("foo", "bar", api.URL)
+
+	calls := 0
+	fail := true
+	opts := (&WebhookHandlerOptions{Dedupe: NewMemoryDedupeStore()}).On("*", func(r *http.Request, event *Event) error {
+		calls++
+		if fail {
+			return fmt.Errorf("temporary failure")
+		}
+		return nil
+	})
+	handler := WebhookHandler(c, "1JE4291016473214C", opts)
+
+	deliver := func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED","resource":{}}`)))
+		return rec.Code
+	}
+
+	if code := deliver(); code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for failed callback, got %d", code)
+	}
+	fail = false
+	if code := deliver(); code != http.StatusOK {
+		t.Errorf("expected 200 for redelivered event, got %d", code)
+	}
+	if code := deliver(); code != http.StatusOK {
+		t.Errorf("expected 200 for duplicate event, got %d", code)
+	}
+	if calls != 2 {
+		t.Errorf("expected the callback to run until it succeeded, got %d calls", calls)
+	}
+}
+
+func TestMemoryDedupeStore(t *testing.T) {
+	store := NewMemoryDedupeStore()
+
+	store.MarkSeen("WH-1", time.Hour)
+	store.MarkSeen("WH-2", -time.Second)
+
+	if seen, _ := store.Seen("WH-1"); !seen {
+		t.Errorf("expected WH-1 to be seen")
+	}
+	if seen, _ := store.Seen("WH-2"); seen {
+		t.Errorf("expected WH-2 to be expired")
+	}
+	if seen, _ := store.Seen("WH-3"); seen {
+		t.Errorf("expected WH-3 not to be seen")
+	}
+
+	router := NewEventRouter().SetDedupeStore(store, 0)
+	calls := 0
+	router.OnUnknown(func(ctx context.Context, event *Event) error {
+		calls++
+		return nil
+	})
+	router.Dispatch(context.Background(), &Event{ID: "WH-1"})
+	router.Dispatch(context.Background(), &Event{ID: "WH-4"})
+	router.Dispatch(context.Background(), &Event{ID: "WH-4"})
+	if calls != 1 {
+		t.Errorf("expected only WH-4 to be dispatched once, got %d calls", calls)
+	}
+
+	clock := &testClock{now: time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC)}
+	store = &MemoryDedupeStore{Clock: clock}
+	store.MarkSeen("WH-5", time.Hour)
+	if seen, _ := store.Seen("WH-5"); !seen {
+		t.Errorf("expected WH-5 to be seen")
+	}
+	clock.now = clock.now.Add(time.Hour)
+	if seen, _ := store.Seen("WH-5"); seen {
+		t.Errorf("expected WH-5 to expire on the clock")
+	}
+}
+
+// testClock is a Clock standing still until now is changed
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func TestWebhookHandler_Store(t *testing.T) {
+	status := VerificationStatusSuccess
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"verification_status":"%s"}`, status)
+	}))
+	defer api.Close()
+
+	c, _ := NewClient("foo", "bar", api.URL)
+	start := time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC)
+	c.SetClock(&testClock{now: start})
+
+	store := NewMemoryEventStore()
+	opts := (&WebhookHandlerOptions{Store: store, Dedupe: NewMemoryDedupeStore()}).
+		On(EventPaymentSaleCompleted, func(r *http.Request, event *Event) error { return nil }).
+		On(EventPaymentCaptureDenied, func(r *http.Request, event *Event) error { return fmt.Errorf("ledger unavailable") })
+	handler := WebhookHandler(c, "1JE4291016473214C", opts)
+
+	deliver := func(body string) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/webhook", strings.NewReader(body)))
+	}
+
+	deliver(`{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED","resource":{}}`)
+	deliver(`{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED","resource":{}}`)
+	deliver(`{"id":"WH-2","event_type":"PAYMENT.CAPTURE.DENIED","resource":{}}`)
+	deliver(`{"id":"WH-3","event_type":"CATALOG.PRODUCT.CREATED","resource":{}}`)
+	status = VerificationStatusFailure
+	deliver(`{"id":"WH-4","event_type":"PAYMENT.SALE.COMPLETED","resource":{}}`)
+	deliver(`not json`)
+
+	all, _ := store.Query(nil)
+	if len(all) != 5 {
+		t.Fatalf("expected 5 stored deliveries, got %d", len(all))
+	}
+
+	expected := []string{EventOutcomeHandled, EventOutcomeDuplicate, EventOutcomeFailed, EventOutcomeUnhandled, EventOutcomeRejected}
+	for i, outcome := range expected {
+		if all[i].Outcome != outcome {
+			t.Errorf("expected outcome %s for delivery %d, got %s", outcome, i, all[i].Outcome)
+		}
+	}
+	if all[4].Verified || !all[0].Verified || !all[0].ReceivedAt.Equal(start) || string(all[0].Payload) != `{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED","resource":{}}` {
+		t.Errorf("unexpected stored deliveries %+v %+v", all[0], all[4])
+	}
+
+	history, _ := EventHistory(store, "WH-1")
+	if len(history) != 2 {
+		t.Errorf("expected 2 deliveries of WH-1, got %d", len(history))
+	}
+
+	failed, _ := FailedEvents(store, start)
+	if len(failed) != 2 || failed[0].EventID != "WH-2" || failed[0].Error == "" ||
+		failed[1].EventID != "" || string(failed[1].Payload) != `{"id":"WH-4","event_type":"PAYMENT.SALE.COMPLETED","resource":{}}` {
+		t.Errorf("unexpected failed events %+v", failed)
+	}
+
+	limited, _ := store.Query(&EventQuery{EventType: EventPaymentSaleCompleted, Limit: 1})
+	if len(limited) != 1 {
+		t.Errorf("expected the limit to apply, got %d events", len(limited))
+	}
+}
+
+func TestMemoryEventStore_Limit(t *testing.T) {
+	store := &MemoryEventStore{Limit: 2}
+	for _, id := range []string{"WH-1", "WH-2", "WH-3"} {
+		store.Save(&StoredEvent{EventID: id})
+	}
+
+	events, _ := store.Query(nil)
+	if len(events) != 2 || events[0].EventID != "WH-2" || events[1].EventID != "WH-3" {
+		t.Errorf("expected the oldest event to be dropped, got %+v", events)
+	}
+}
+
+func TestWebhookHandler_UnknownEventType(t *testing.T) {
+	signer := newTestWebhookSigner(t, "messageverificationcerts.paypal.com")
+	certServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(signer.chain)
+	}))
+	defer certServer.Close()
+
+	u, _ := url.Parse(certServer.URL)
+	certURL := certServer.URL + "/v1/notifications/certs/CERT-360caa42-fca2a594-a5cafa77"
+
+	verifier := NewWebhookVerifier("1JE4291016473214C")
+	verifier.HTTPClient = certServer.Client()
+	verifier.Roots = signer.roots
+	verifier.CertHosts = []string{u.Hostname()}
+
+	var unknown, fallback []string
+	opts := &WebhookHandlerOptions{
+		Verifier: verifier,
+		OnUnknownEventType: func(r *http.Request, event *Event) {
+			unknown = append(unknown, event.EventType)
+		},
+	}
+	opts.On("*", func(r *http.Request, event *Event) error {
+		fallback = append(fallback, string(event.Raw()))
+		return nil
+	})
+	handler := WebhookHandler(nil, "1JE4291016473214C", opts)
+
+	payloads := webhookPayloads(t)
+	for _, name := range []string{"payment-sale-completed.json", "unknown-event-type.json"} {
+		body := payloads[name]
+		req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+		req.Header = signer.sign(t, certURL, "1JE4291016473214C", body)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", name, rec.Code)
+		}
+	}
+
+	if len(unknown) != 1 || unknown[0] != "WALLET.INSTRUMENT.LINKED" {
+		t.Errorf("expected OnUnknownEventType for the unknown event only, got %v", unknown)
+	}
+	if len(fallback) != 2 || fallback[1] != string(payloads["unknown-event-type.json"]) {
+		t.Errorf("expected both events with their raw delivery in the fallback callback, got %v", fallback)
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/inplayer-org/paypal/webhookredis
+
+go 1.12
+
+require (
+	github.com/alicebob/miniredis/v2 v2.31.1
+	github.com/inplayer-org/paypal v0.0.0-20261016075348-86e871964e24
+	github.com/redis/go-redis/v9 v9.5.1
+)
+
+replace github.com/inplayer-org/paypal => ../
@@ -0,0 +1,45 @@
+// Package webhookredis shares the handled webhook events of the paypal package between instances through Redis.
+package webhookredis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultKeyPrefix is the prefix of the keys of the event IDs when KeyPrefix is not set
+const DefaultKeyPrefix = "paypal:event:"
+
+// DedupeStore is a paypal.DedupeStore keeping the IDs of handled events in Redis, the keys expire with their TTL
+//
+//	opts.Dedupe = webhookredis.NewDedupeStore(redis.NewClient(&redis.Options{Addr: "localhost:6379"}))
+type DedupeStore struct {
+	Client redis.UniversalClient
+	// KeyPrefix is prepended to the event IDs, DefaultKeyPrefix when empty
+	KeyPrefix string
+}
+
+// NewDedupeStore returns a DedupeStore keeping the event IDs with client
+func NewDedupeStore(client redis.UniversalClient) *DedupeStore {
+	return &DedupeStore{Client: client}
+}
+
+// Seen reports whether the event was marked as seen and its key has not expired
+func (s *DedupeStore) Seen(eventID string) (bool, error) {
+	n, err := s.Client.Exists(context.Background(), s.key(eventID)).Result()
+	return n > 0, err
+}
+
+// MarkSeen marks the event as seen for ttl
+func (s *DedupeStore) MarkSeen(eventID string, ttl time.Duration) error {
+	return s.Client.Set(context.Background(), s.key(eventID), 1, ttl).Err()
+}
+
+// key returns the Redis key of the event ID
+func (s *DedupeStore) key(eventID string) string {
+	if s.KeyPrefix == "" {
+		return DefaultKeyPrefix + eventID
+	}
+	return s.KeyPrefix + eventID
+}
@@ -0,0 +1,45 @@
+package webhookredis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/inplayer-org/paypal"
+	"github.com/redis/go-redis/v9"
+)
+
+var _ paypal.DedupeStore = &DedupeStore{}
+
+func TestDedupeStore(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	store := NewDedupeStore(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+
+	if seen, err := store.Seen("WH-1"); err != nil || seen {
+		t.Errorf("expected an unseen event, got %v, %v", seen, err)
+	}
+	if err := store.MarkSeen("WH-1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if seen, err := store.Seen("WH-1"); err != nil || !seen {
+		t.Errorf("expected a seen event, got %v, %v", seen, err)
+	}
+	if !server.Exists("paypal:event:WH-1") {
+		t.Errorf("expected the key with the default prefix, got %v", server.Keys())
+	}
+
+	server.FastForward(time.Hour)
+	if seen, err := store.Seen("WH-1"); err != nil || seen {
+		t.Errorf("expected the event to expire with its TTL, got %v, %v", seen, err)
+	}
+
+	store.KeyPrefix = "shop:webhook:"
+	if err := store.MarkSeen("WH-2", time.Hour); err != nil || !server.Exists("shop:webhook:WH-2") {
+		t.Errorf("expected the key with the custom prefix, got %v, %v", server.Keys(), err)
+	}
+}
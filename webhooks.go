@@ -2,12 +2,417 @@ package paypal
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+// Possible values for `path` in WebhookField used with UpdateWebhook
+const (
+	WebhookPathURL        string = "/url"
+	WebhookPathEventTypes string = "/event_types"
+	WebhookPathAnchorType string = "/anchor_type"
+)
+
+// Possible values for `anchor_type` in Webhook, CreateWebhookRequest and ListWebhooksByAnchorType
+const (
+	// AnchorTypeApplication webhooks receive the events of the application's own account
+	AnchorTypeApplication string = "APPLICATION"
+	// AnchorTypeAccount webhooks receive the events of all merchants onboarded by a platform
+	AnchorTypeAccount string = "ACCOUNT"
+)
+
+type (
+	// Webhook represents a webhook subscription of the application
+	// https://developer.paypal.com/docs/api/webhooks/v1/#definition-webhook
+	Webhook struct {
+		ID         string       `json:"id,omitempty"` //Read only
+		URL        string       `json:"url"`
+		EventTypes []*EventType `json:"event_types"`
+		AnchorType string       `json:"anchor_type,omitempty"`
+		Links      []*Link      `json:"links,omitempty"` //Read only
+	}
+
+	// EventType represents a webhook event type, only Name is required when subscribing a webhook
+	// https://developer.paypal.com/docs/api/webhooks/v1/#definition-event_type
+	EventType struct {
+		Name             string   `json:"name"`
+		Description      string   `json:"description,omitempty"`       //Read only
+		Status           string   `json:"status,omitempty"`            //Read only
+		ResourceVersions []string `json:"resource_versions,omitempty"` //Read only
+	}
+
+	// CreateWebhookRequest represents body parameters needed to create a webhook
+	CreateWebhookRequest struct {
+		URL        string       `json:"url"`
+		EventTypes []*EventType `json:"event_types"`
+		AnchorType string       `json:"anchor_type,omitempty"` //default: APPLICATION
+	}
+
+	// SimulateWebhookEventRequest represents the body of simulate webhook event, WebhookID or URL is required.
+	// ResourceVersion picks the version of the sample resource, PayPal sends 1.0 when it is empty,
+	// see EventType.LatestResourceVersion
+	SimulateWebhookEventRequest struct {
+		WebhookID       string `json:"webhook_id,omitempty"`
+		URL             string `json:"url,omitempty"`
+		EventType       string `json:"event_type"`
+		ResourceVersion string `json:"resource_version,omitempty"`
+	}
+
+	// ListWebhooksResponse represents the response of list webhooks
+	ListWebhooksResponse struct {
+		Webhooks []*Webhook `json:"webhooks"`
+	}
+
+	// ListEventTypesResponse represents the response of list event types
+	ListEventTypesResponse struct {
+		EventTypes []*EventType `json:"event_types"`
+	}
+
+	// ListWebhookEventsRequest represents the filters of list event notifications, zero values are not sent
+	ListWebhookEventsRequest struct {
+		StartTime     time.Time
+		EndTime       time.Time
+		TransactionID string
+		EventType     string
+		PageSize      uint64 //default: 10, max: 300
+	}
+
+	// ListWebhookEventsResponse represents a page of event notifications
+	ListWebhookEventsResponse struct {
+		Events []*Event `json:"events"`
+		Count  int      `json:"count"`
+		Links  []*Link  `json:"links,omitempty"`
+	}
+
+	// WebhookField represents a JSON patch operation used to update a webhook
+	// Value is a string for WebhookPathURL and WebhookPathAnchorType, []*EventType for WebhookPathEventTypes
+	WebhookField struct {
+		Operation string      `json:"op"`
+		Path      string      `json:"path"`
+		Value     interface{} `json:"value,omitempty"`
+	}
+)
+
+// CreateWebhook subscribes the URL to the event types, use "*" as event type name to receive all events
+// Endpoint: POST /v1/notifications/webhooks
+func (c *Client) CreateWebhook(createWebhookRequest *CreateWebhookRequest) (*Webhook, error) {
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks"), createWebhookRequest)
+	webhook := &Webhook{}
+	if err != nil {
+		return webhook, err
+	}
+
+	defer c.invalidateWebhooksCache()
+	err = c.SendWithAuth(req, webhook)
+	return webhook, err
+}
+
+// GetWebhook shows details for a webhook
+// Endpoint: GET /v1/notifications/webhooks/ID
+func (c *Client) GetWebhook(webhookID string) (*Webhook, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/notifications/webhooks/", webhookID), nil)
+	webhook := &Webhook{}
+	if err != nil {
+		return webhook, err
+	}
+
+	err = c.SendWithAuth(req, webhook)
+	return webhook, err
+}
+
+// UpdateWebhook replaces the URL or the event types of a webhook
+// Endpoint: PATCH /v1/notifications/webhooks/ID
+func (c *Client) UpdateWebhook(webhookID string, fields []WebhookField) (*Webhook, error) {
+	req, err := c.NewRequest("PATCH", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/notifications/webhooks/", webhookID), fields)
+	webhook := &Webhook{}
+	if err != nil {
+		return webhook, err
+	}
+
+	defer c.invalidateWebhooksCache()
+	err = c.SendWithAuth(req, webhook)
+	return webhook, err
+}
+
+// ListWebhooks lists the webhooks of the application
+// Endpoint: GET /v1/notifications/webhooks
+func (c *Client) ListWebhooks() (*ListWebhooksResponse, error) {
+	return c.ListWebhooksByAnchorType("")
+}
+
+// ListWebhooksByAnchorType lists the webhooks with the anchor type, AnchorTypeAccount lists the webhooks of
+// a platform receiving the events of its onboarded merchants. PayPal defaults to AnchorTypeApplication when empty
+// Endpoint: GET /v1/notifications/webhooks?anchor_type=ANCHOR_TYPE
+func (c *Client) ListWebhooksByAnchorType(anchorType string) (*ListWebhooksResponse, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks"), nil)
+	resp := &ListWebhooksResponse{}
+	if err != nil {
+		return resp, err
+	}
+
+	if anchorType != "" {
+		q := req.URL.Query()
+		q.Add("anchor_type", anchorType)
+		req.URL.RawQuery = q.Encode()
+	}
+
+	err = c.sendCached(req, resp, c.SendWithAuth)
+	return resp, err
+}
+
+// DeleteWebhook deletes a webhook, PayPal stops sending events to its URL
+// Endpoint: DELETE /v1/notifications/webhooks/ID
+func (c *Client) DeleteWebhook(webhookID string) error {
+	req, err := c.NewRequest("DELETE", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/notifications/webhooks/", webhookID), nil)
+	if err != nil {
+		return err
+	}
+
+	defer c.invalidateWebhooksCache()
+	return c.SendWithAuth(req, nil)
+}
+
+// EnsureWebhook makes the webhook for url subscribe to exactly eventTypes and returns its ID.
+// The webhooks of both anchor types are looked up, the webhook is created when there is none for url and its
+// event types are replaced when they drifted. The requests are canceled with ctx.
+// Call it on startup to converge the webhook configuration without manual steps in the dashboard
+// Endpoint: GET /v1/notifications/webhooks, POST /v1/notifications/webhooks, PATCH /v1/notifications/webhooks/ID
+func (c *Client) EnsureWebhook(ctx context.Context, url string, eventTypes []string) (string, error) {
+	types := make([]*EventType, 0, len(eventTypes))
+	for _, name := range eventTypes {
+		types = append(types, &EventType{Name: name})
+	}
+
+	for _, anchorType := range []string{AnchorTypeApplication, AnchorTypeAccount} {
+		req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks?anchor_type="+anchorType), nil)
+		if err != nil {
+			return "", err
+		}
+
+		list := &ListWebhooksResponse{}
+		if err = c.SendWithAuth(req.WithContext(ctx), list); err != nil {
+			return "", err
+		}
+
+		for _, webhook := range list.Webhooks {
+			if webhook == nil || webhook.URL != url {
+				continue
+			}
+
+			if sameEventTypes(webhook.EventTypes, eventTypes) {
+				return webhook.ID, nil
+			}
+
+			req, err := c.NewRequest("PATCH", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/notifications/webhooks/", webhook.ID), []WebhookField{
+				{Operation: OperationReplace, Path: WebhookPathEventTypes, Value: types},
+			})
+			if err != nil {
+				return "", err
+			}
+
+			err = c.SendWithAuth(req.WithContext(ctx), &Webhook{})
+			c.invalidateWebhooksCache()
+			if err != nil {
+				return "", err
+			}
+			return webhook.ID, nil
+		}
+	}
+
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks"), &CreateWebhookRequest{URL: url, EventTypes: types})
+	if err != nil {
+		return "", err
+	}
+
+	defer c.invalidateWebhooksCache()
+	webhook := &Webhook{}
+	if err = c.SendWithAuth(req.WithContext(ctx), webhook); err != nil {
+		return "", err
+	}
+	return webhook.ID, nil
+}
+
+// sameEventTypes reports whether the event types have the same names regardless of their order
+func sameEventTypes(eventTypes []*EventType, names []string) bool {
+	current := map[string]bool{}
+	for _, eventType := range eventTypes {
+		if eventType != nil {
+			current[eventType.Name] = true
+		}
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	if len(current) != len(wanted) {
+		return false
+	}
+	for name := range wanted {
+		if !current[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// ListWebhookEventTypes lists all event types PayPal can send to a webhook
+// Endpoint: GET /v1/notifications/webhooks-event-types
+func (c *Client) ListWebhookEventTypes() (*ListEventTypesResponse, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks-event-types"), nil)
+	resp := &ListEventTypesResponse{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// ListEventTypesForWebhook lists the event types a webhook is subscribed to
+// Endpoint: GET /v1/notifications/webhooks/ID/event-types
+func (c *Client) ListEventTypesForWebhook(webhookID string) (*ListEventTypesResponse, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s%s", c.APIBase, "/v1/notifications/webhooks/", webhookID, "/event-types"), nil)
+	resp := &ListEventTypesResponse{}
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// Unsupported returns the names that are not in the event types list, the wildcard "*" is always supported.
+// Use it with ListWebhookEventTypes to validate event types before subscribing a webhook
+func (r *ListEventTypesResponse) Unsupported(names ...string) []string {
+	available := make(map[string]bool, len(r.EventTypes))
+	for _, eventType := range r.EventTypes {
+		if eventType != nil {
+			available[eventType.Name] = true
+		}
+	}
+
+	var unsupported []string
+	for _, name := range names {
+		if name != "*" && !available[name] {
+			unsupported = append(unsupported, name)
+		}
+	}
+
+	return unsupported
+}
+
+// ListWebhookEvents lists a single page of event notifications, newest first
+// Endpoint: GET /v1/notifications/webhooks-events
+func (c *Client) ListWebhookEvents(params *ListWebhookEventsRequest) (*ListWebhookEventsResponse, error) {
+	resp := &ListWebhookEventsResponse{}
+
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/webhooks-events"), nil)
+	if err != nil {
+		return resp, err
+	}
+
+	if params != nil {
+		q := req.URL.Query()
+		if !params.StartTime.IsZero() {
+			q.Add("start_time", params.StartTime.UTC().Format(time.RFC3339))
+		}
+		if !params.EndTime.IsZero() {
+			q.Add("end_time", params.EndTime.UTC().Format(time.RFC3339))
+		}
+		if params.TransactionID != "" {
+			q.Add("transaction_id", params.TransactionID)
+		}
+		if params.EventType != "" {
+			q.Add("event_type", params.EventType)
+		}
+		if params.PageSize > 0 {
+			q.Add("page_size", strconv.FormatUint(params.PageSize, 10))
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	err = c.SendWithAuth(req, resp)
+	return resp, err
+}
+
+// ListAllWebhookEvents lists all event notifications matching params, following the `next` links
+// until there are no more pages. Use it to replay events missed while the webhook endpoint was down
+// Endpoint: GET /v1/notifications/webhooks-events
+func (c *Client) ListAllWebhookEvents(params *ListWebhookEventsRequest) ([]*Event, error) {
+	page, err := c.ListWebhookEvents(params)
+	if err != nil {
+		return nil, err
+	}
+
+	events := page.Events
+	visited := map[string]bool{}
+	for {
+		next := findLink(page.Links, LinkRelNext)
+		if next == nil || visited[next.Href] {
+			return events, nil
+		}
+		visited[next.Href] = true
+
+		req, err := c.NewRequest("GET", next.Href, nil)
+		if err != nil {
+			return events, err
+		}
+
+		page = &ListWebhookEventsResponse{}
+		if err = c.SendWithAuth(req, page); err != nil {
+			return events, err
+		}
+
+		events = append(events, page.Events...)
+	}
+}
+
+// GetWebhookEvent shows details for an event notification
+// Endpoint: GET /v1/notifications/webhooks-events/ID
+func (c *Client) GetWebhookEvent(eventID string) (*Event, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("%s%s%s", c.APIBase, "/v1/notifications/webhooks-events/", eventID), nil)
+	event := &Event{}
+	if err != nil {
+		return event, err
+	}
+
+	err = c.SendWithAuth(req, event)
+	return event, err
+}
+
+// SimulateWebhookEvent sends a sample event of the event type to the webhook and returns it.
+// Events are simulated in the sandbox only and are sent without signature
+// Endpoint: POST /v1/notifications/simulate-event
+func (c *Client) SimulateWebhookEvent(simulateRequest *SimulateWebhookEventRequest) (*Event, error) {
+	req, err := c.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/v1/notifications/simulate-event"), simulateRequest)
+	event := &Event{}
+	if err != nil {
+		return event, err
+	}
+
+	err = c.SendWithAuth(req, event)
+	return event, err
+}
+
+// LatestResourceVersion returns the newest of the resource versions the event type is available in,
+// or "" when ResourceVersions is empty
+func (t *EventType) LatestResourceVersion() string {
+	latest, latestNumber := "", -1.0
+	for _, version := range t.ResourceVersions {
+		if n, err := strconv.ParseFloat(version, 64); err == nil && n > latestNumber {
+			latest, latestNumber = version, n
+		}
+	}
+	return latest
+}
+
 // VerifyWebhookSignature - Use this to verify the signature of a webhook recieved from paypal.
 // Endpoint: POST /v1/notifications/verify-webhook-signature
 func (c *Client) VerifyWebhookSignature(httpReq *http.Request, webhookID string) (*VerifyWebhookResponse, error) {
@@ -52,3 +457,227 @@ func (c *Client) VerifyWebhookSignature(httpReq *http.Request, webhookID string)
 
 	return response, nil
 }
+
+// UnmarshalJSON decodes the webhook event and keeps the complete resource for Event()
+func (e *WebhookEvent) UnmarshalJSON(data []byte) error {
+	type webhookEvent WebhookEvent
+	if err := decodeJSON(data, (*webhookEvent)(e)); err != nil {
+		return err
+	}
+
+	e.event = &Event{}
+	return json.Unmarshal(data, e.event)
+}
+
+// UnmarshalJSON decodes the event and keeps the JSON it was decoded from, see Raw.
+// Unknown fields and event types are ignored, decoding fails for invalid JSON only
+func (e *Event) UnmarshalJSON(data []byte) error {
+	type event Event
+	if err := json.Unmarshal(data, (*event)(e)); err != nil {
+		return err
+	}
+
+	e.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// decodeEvent decodes an event from data like UnmarshalJSON, but keeps data as its raw JSON instead of a copy,
+// data must not be modified afterwards
+func decodeEvent(data []byte) (*Event, error) {
+	type event Event
+	e := &Event{}
+	if err := json.Unmarshal(data, (*event)(e)); err != nil {
+		return nil, err
+	}
+
+	e.raw = data
+	return e, nil
+}
+
+// Raw returns the JSON the event was decoded from, including fields this package does not know about yet.
+// Events that were not decoded from JSON are encoded
+func (e *Event) Raw() json.RawMessage {
+	if e.raw != nil {
+		return e.raw
+	}
+
+	type event Event
+	raw, _ := json.Marshal((*event)(e))
+	return raw
+}
+
+// Event returns the webhook event as an Event, whose XResource methods decode the resource into its concrete type.
+// Events that were not decoded from JSON carry the generic Resource only
+func (e *WebhookEvent) Event() *Event {
+	if e.event != nil {
+		return e.event
+	}
+
+	resource, _ := json.Marshal(e.Resource)
+	links := make([]*Link, len(e.Links))
+	for i := range e.Links {
+		links[i] = &e.Links[i]
+	}
+
+	return &Event{
+		ID:              e.ID,
+		EventVersion:    e.EventVersion,
+		CreateTime:      e.CreateTime.Time().Format(time.RFC3339),
+		ResourceType:    e.ResourceType,
+		ResourceVersion: e.ResourceVersion,
+		EventType:       e.EventType,
+		Summary:         e.Summary,
+		Resource:        resource,
+		Links:           links,
+	}
+}
+
+// decodeResource unmarshals the resource into v when the event carries the resource type,
+// events without `resource_type` are looked up in the event type catalog.
+// When version is set, resources of other versions are rejected as their shape differs from v,
+// events without `resource_version` are decoded
+func (e *Event) decodeResource(v interface{}, resourceType, version string) error {
+	actual := e.ResourceType
+	if actual == "" {
+		actual, _ = EventResourceType(e.EventType)
+	}
+	if actual != resourceType {
+		return fmt.Errorf("paypal: event %s does not carry a %s resource", e.EventType, resourceType)
+	}
+	if version != "" && e.ResourceVersion != "" && e.ResourceVersion != version {
+		return fmt.Errorf("paypal: event %s carries a %s resource of version %s, only version %s is supported", e.EventType, resourceType, e.ResourceVersion, version)
+	}
+
+	return decodeJSON(e.Resource, v)
+}
+
+// SubscriptionResource decodes the resource of a BILLING.SUBSCRIPTION.* event into a Subscription
+func (e *Event) SubscriptionResource() (*Subscription, error) {
+	subscription := &Subscription{}
+	if err := e.decodeResource(subscription, ResourceTypeSubscription, ""); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// SaleResource decodes the resource of a PAYMENT.SALE.COMPLETED, DENIED or PENDING event into a Sale.
+// For subscription payments Sale.BillingAgreementID holds the subscription ID
+func (e *Event) SaleResource() (*Sale, error) {
+	sale := &Sale{}
+	if err := e.decodeResource(sale, ResourceTypeSale, ""); err != nil {
+		return nil, err
+	}
+
+	return sale, nil
+}
+
+// CaptureResource decodes the resource of a PAYMENT.CAPTURE.COMPLETED, PENDING, DENIED or DECLINED event into a Capture.
+// Version 1.0 resources, sent for captures of v1 payments, are rejected
+func (e *Event) CaptureResource() (*Capture, error) {
+	capture := &Capture{}
+	if err := e.decodeResource(capture, ResourceTypeCapture, ResourceVersion2); err != nil {
+		return nil, err
+	}
+
+	return capture, nil
+}
+
+// RefundResource decodes the resource of a PAYMENT.CAPTURE.REFUNDED or PAYMENT.CAPTURE.REVERSED event into a Refund.
+// PAYMENT.SALE.REFUNDED and PAYMENT.SALE.REVERSED carry a v1 refund, which only partially fits Refund
+func (e *Event) RefundResource() (*Refund, error) {
+	refund := &Refund{}
+	if err := e.decodeResource(refund, ResourceTypeRefund, ""); err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// OrderResource decodes the resource of a CHECKOUT.ORDER.* event into an Order, version 1.0 resources are rejected
+func (e *Event) OrderResource() (*Order, error) {
+	order := &Order{}
+	if err := e.decodeResource(order, ResourceTypeCheckoutOrder, ResourceVersion2); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// AuthorizationResource decodes the resource of a PAYMENT.AUTHORIZATION.CREATED or PAYMENT.AUTHORIZATION.VOIDED
+// event into an Authorization. Version 1.0 resources, sent for authorizations of v1 payments, are rejected
+func (e *Event) AuthorizationResource() (*Authorization, error) {
+	authorization := &Authorization{}
+	if err := e.decodeResource(authorization, ResourceTypeAuthorization, ResourceVersion2); err != nil {
+		return nil, err
+	}
+
+	return authorization, nil
+}
+
+// DisputeResource decodes the resource of a CUSTOMER.DISPUTE.CREATED, UPDATED or RESOLVED event into a Dispute
+func (e *Event) DisputeResource() (*Dispute, error) {
+	dispute := &Dispute{}
+	if err := e.decodeResource(dispute, ResourceTypeDispute, ""); err != nil {
+		return nil, err
+	}
+
+	return dispute, nil
+}
+
+// PayoutItemResource decodes the resource of a PAYMENT.PAYOUTS-ITEM.* event into a PayoutItemResponse
+func (e *Event) PayoutItemResource() (*PayoutItemResponse, error) {
+	item := &PayoutItemResponse{}
+	if err := e.decodeResource(item, ResourceTypePayoutsItem, ""); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// InvoiceResource decodes the resource of an INVOICING.INVOICE.* event into an Invoice,
+// PayPal nests v2 invoices in an `invoice` field while older deliveries carry the invoice itself
+func (e *Event) InvoiceResource() (*Invoice, error) {
+	wrapper := &struct {
+		Invoice *Invoice `json:"invoice"`
+	}{}
+	if err := e.decodeResource(wrapper, ResourceTypeInvoices, ""); err != nil {
+		return nil, err
+	}
+	if wrapper.Invoice != nil {
+		return wrapper.Invoice, nil
+	}
+
+	invoice := &Invoice{}
+	if err := e.decodeResource(invoice, ResourceTypeInvoices, ""); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// PaymentTokenResource decodes the resource of a VAULT.PAYMENT-TOKEN.* event into a PaymentToken
+func (e *Event) PaymentTokenResource() (*PaymentToken, error) {
+	token := &PaymentToken{}
+	if err := e.decodeResource(token, ResourceTypePaymentToken, ""); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// MerchantOnboardingResource decodes the resource of a MERCHANT.ONBOARDING.COMPLETED or MERCHANT.PARTNER-CONSENT.REVOKED
+// event into a MerchantOnboarding
+func (e *Event) MerchantOnboardingResource() (*MerchantOnboarding, error) {
+	resourceType := ResourceTypeMerchantOnboarding
+	if e.EventType == EventMerchantPartnerConsentRevoked {
+		resourceType = ResourceTypePartnerConsent
+	}
+
+	onboarding := &MerchantOnboarding{}
+	if err := e.decodeResource(onboarding, resourceType, ""); err != nil {
+		return nil, err
+	}
+
+	return onboarding, nil
+}
@@ -0,0 +1,135 @@
+package paypal
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryEventStoreLimit is the number of events kept by the store returned by NewMemoryEventStore
+const DefaultMemoryEventStoreLimit = 10000
+
+// Possible values for `Outcome` in StoredEvent
+const (
+	EventOutcomeHandled   string = "HANDLED"   // The callback of the event succeeded
+	EventOutcomeUnhandled string = "UNHANDLED" // No callback is registered for the event type
+	EventOutcomeDuplicate string = "DUPLICATE" // The event was already handled and skipped
+	EventOutcomeFailed    string = "FAILED"    // The callback failed, PayPal redelivers the event
+	EventOutcomeRejected  string = "REJECTED"  // The signature could not be verified
+)
+
+type (
+	// StoredEvent represents a webhook delivery as recorded by WebhookHandler. The body of a rejected delivery
+	// is not decoded, only its Payload is set
+	StoredEvent struct {
+		EventID      string    `json:"event_id"`
+		EventType    string    `json:"event_type"`
+		ResourceType string    `json:"resource_type,omitempty"`
+		ReceivedAt   time.Time `json:"received_at"`
+		Payload      []byte    `json:"payload"`
+		Verified     bool      `json:"verified"`
+		Outcome      string    `json:"outcome"`
+		Error        string    `json:"error,omitempty"`
+	}
+
+	// EventQuery represents the filters of EventStore.Query, zero values match every event
+	EventQuery struct {
+		EventID   string
+		EventType string
+		Outcome   string
+		From      time.Time // Inclusive
+		To        time.Time // Exclusive
+		Limit     int
+	}
+
+	// EventStore records webhook deliveries so it can be audited which events were received and how they were handled.
+	// Every delivery of an event is saved, redeliveries included
+	EventStore interface {
+		Save(event *StoredEvent) error
+		// Query returns the matching events ordered by ReceivedAt
+		Query(query *EventQuery) ([]*StoredEvent, error)
+	}
+
+	// MemoryEventStore is an EventStore keeping the events in memory, it is meant for tests and development.
+	// Long-running processes auditing deliveries should save them to a database instead
+	MemoryEventStore struct {
+		// Limit is the number of events kept, the oldest events are dropped beyond it. Every event is kept when 0
+		Limit int
+
+		mu     sync.RWMutex
+		events []*StoredEvent
+	}
+)
+
+// Matches reports whether the stored event matches the query
+func (q *EventQuery) Matches(event *StoredEvent) bool {
+	return (q.EventID == "" || q.EventID == event.EventID) &&
+		(q.EventType == "" || q.EventType == event.EventType) &&
+		(q.Outcome == "" || q.Outcome == event.Outcome) &&
+		(q.From.IsZero() || !event.ReceivedAt.Before(q.From)) &&
+		(q.To.IsZero() || event.ReceivedAt.Before(q.To))
+}
+
+// NewMemoryEventStore returns an empty MemoryEventStore keeping the last DefaultMemoryEventStoreLimit events
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{Limit: DefaultMemoryEventStoreLimit}
+}
+
+// Save appends the event to the store, dropping the oldest event when the store is full
+func (s *MemoryEventStore) Save(event *StoredEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+	for s.Limit > 0 && len(s.events) > s.Limit {
+		// The dropped events are released once append moves the kept ones to a new array
+		s.events[0] = nil
+		s.events = s.events[1:]
+	}
+	return nil
+}
+
+// Query returns the matching events in the order they were saved
+func (s *MemoryEventStore) Query(query *EventQuery) ([]*StoredEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if query == nil {
+		query = &EventQuery{}
+	}
+
+	var events []*StoredEvent
+	for _, event := range s.events {
+		if !query.Matches(event) {
+			continue
+		}
+		events = append(events, event)
+		if query.Limit > 0 && len(events) == query.Limit {
+			break
+		}
+	}
+
+	return events, nil
+}
+
+// FailedEvents returns the events whose callback failed or whose signature was rejected since the given time
+func FailedEvents(store EventStore, since time.Time) ([]*StoredEvent, error) {
+	failed, err := store.Query(&EventQuery{Outcome: EventOutcomeFailed, From: since})
+	if err != nil {
+		return nil, err
+	}
+
+	rejected, err := store.Query(&EventQuery{Outcome: EventOutcomeRejected, From: since})
+	if err != nil {
+		return nil, err
+	}
+
+	events := append(failed, rejected...)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].ReceivedAt.Before(events[j].ReceivedAt) })
+	return events, nil
+}
+
+// EventHistory returns every recorded delivery of the event
+func EventHistory(store EventStore, eventID string) ([]*StoredEvent, error) {
+	return store.Query(&EventQuery{EventID: eventID})
+}
@@ -0,0 +1,427 @@
+package paypal
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers PayPal sets on every webhook delivery
+const (
+	HeaderAuthAlgo         string = "PAYPAL-AUTH-ALGO"
+	HeaderCertURL          string = "PAYPAL-CERT-URL"
+	HeaderTransmissionID   string = "PAYPAL-TRANSMISSION-ID"
+	HeaderTransmissionSig  string = "PAYPAL-TRANSMISSION-SIG"
+	HeaderTransmissionTime string = "PAYPAL-TRANSMISSION-TIME"
+)
+
+// Possible values for the PAYPAL-AUTH-ALGO header
+const (
+	AuthAlgoSHA256WithRSA string = "SHA256withRSA"
+)
+
+// ErrInvalidWebhookSignature is returned when a webhook delivery is not signed by PayPal for the webhook
+var ErrInvalidWebhookSignature = errors.New("paypal: invalid webhook signature")
+
+// ErrWebhookTransmissionExpired is returned when a webhook delivery is signed by PayPal but was transmitted
+// longer ago than the MaxAge of the WebhookVerifier, e.g. when it is replayed
+var ErrWebhookTransmissionExpired = errors.New("paypal: webhook transmission time is too old")
+
+// The canonical forms of the webhook headers, they are looked up directly instead of canonicalizing the
+// header names on every delivery
+var (
+	canonicalAuthAlgo         = textproto.CanonicalMIMEHeaderKey(HeaderAuthAlgo)
+	canonicalCertURL          = textproto.CanonicalMIMEHeaderKey(HeaderCertURL)
+	canonicalTransmissionID   = textproto.CanonicalMIMEHeaderKey(HeaderTransmissionID)
+	canonicalTransmissionSig  = textproto.CanonicalMIMEHeaderKey(HeaderTransmissionSig)
+	canonicalTransmissionTime = textproto.CanonicalMIMEHeaderKey(HeaderTransmissionTime)
+)
+
+// DefaultWebhookCertHosts are the hosts PayPal serves webhook signing certificates from
+var DefaultWebhookCertHosts = []string{"api.paypal.com", "api-m.paypal.com", "api.sandbox.paypal.com", "api-m.sandbox.paypal.com"}
+
+// DefaultWebhookCertNames are the names PayPal webhook signing certificates are issued for
+var DefaultWebhookCertNames = []string{"messageverificationcerts.paypal.com", "messageverificationcerts.sandbox.paypal.com"}
+
+// WebhookCertSource supplies verified webhook signing certificates by their PAYPAL-CERT-URL.
+// Implement it to serve certificates from your own store instead of downloading them from PayPal
+type WebhookCertSource interface {
+	Certificate(certURL string) (*x509.Certificate, error)
+}
+
+// DefaultWebhookCertCacheSize is the number of certificates kept by a WebhookCertCache when MaxSize is not set
+const DefaultWebhookCertCacheSize = 64
+
+// WebhookCertCache is a WebhookCertSource keeping certificates returned by Fetch until they expire
+type WebhookCertCache struct {
+	Fetch func(certURL string) (*x509.Certificate, error)
+	// Clock expires the certificates, the system clock is used when nil
+	Clock Clock
+	// MaxSize is the number of certificates kept, DefaultWebhookCertCacheSize when 0.
+	// Expired certificates are dropped first, then the ones expiring soonest
+	MaxSize int
+
+	mu    sync.Mutex
+	certs map[string]*x509.Certificate
+}
+
+// NewWebhookCertCache returns a WebhookCertCache fetching missing or expired certificates with fetch
+func NewWebhookCertCache(fetch func(certURL string) (*x509.Certificate, error)) *WebhookCertCache {
+	return &WebhookCertCache{Fetch: fetch, certs: map[string]*x509.Certificate{}}
+}
+
+// Certificate returns the cached certificate for certURL, fetching it when missing or expired
+func (c *WebhookCertCache) Certificate(certURL string) (*x509.Certificate, error) {
+	now := clockNow(c.Clock)
+
+	c.mu.Lock()
+	cert, ok := c.certs[certURL]
+	c.mu.Unlock()
+	if ok && now.After(cert.NotBefore) && now.Before(cert.NotAfter) {
+		return cert, nil
+	}
+
+	cert, err := c.Fetch(certURL)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.certs == nil {
+		c.certs = map[string]*x509.Certificate{}
+	}
+	if _, ok := c.certs[certURL]; !ok {
+		c.evict(now)
+	}
+	c.certs[certURL] = cert
+	c.mu.Unlock()
+
+	return cert, nil
+}
+
+// evict makes room for a certificate when the cache is full, c.mu must be held
+func (c *WebhookCertCache) evict(now time.Time) {
+	maxSize := c.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultWebhookCertCacheSize
+	}
+	if len(c.certs) < maxSize {
+		return
+	}
+
+	for certURL, cert := range c.certs {
+		if !now.Before(cert.NotAfter) {
+			delete(c.certs, certURL)
+		}
+	}
+
+	for len(c.certs) >= maxSize {
+		var soonest string
+		for certURL, cert := range c.certs {
+			if soonest == "" || cert.NotAfter.Before(c.certs[soonest].NotAfter) {
+				soonest = certURL
+			}
+		}
+		delete(c.certs, soonest)
+	}
+}
+
+// WebhookVerifier verifies webhook signatures locally, without calling
+// POST /v1/notifications/verify-webhook-signature for every delivery.
+// The signature is checked against "<transmission id>|<transmission time>|<webhook id>|<crc32 of body>"
+// with the public key of the signing certificate referenced by the PAYPAL-CERT-URL header.
+type WebhookVerifier struct {
+	WebhookID string
+
+	// HTTPClient downloads the signing certificates, http.DefaultClient is used when nil
+	HTTPClient *http.Client
+	// Roots verifies the certificate chain, the system roots are used when nil
+	Roots *x509.CertPool
+	// CertHosts restricts where certificates are downloaded from, DefaultWebhookCertHosts when empty
+	CertHosts []string
+	// CertNames restricts the names of the signing certificate, DefaultWebhookCertNames when empty
+	CertNames []string
+	// CheckCertificate is called with the verified chain, leaf first, of every downloaded certificate.
+	// Return an error to reject it, e.g. to pin the issuer or the public key
+	CheckCertificate func(chain []*x509.Certificate) error
+	// Certs supplies the signing certificates, they are downloaded with FetchCertificate on every delivery when nil
+	Certs WebhookCertSource
+	// MaxBodyBytes limits the size of the deliveries read by Verify, DefaultWebhookMaxBodyBytes when 0
+	MaxBodyBytes int64
+	// MaxAge rejects the deliveries whose PAYPAL-TRANSMISSION-TIME is further than MaxAge from the Clock,
+	// so a captured delivery can't be replayed later on. The transmission time is not checked when 0
+	MaxAge time.Duration
+	// Clock is the time the transmission time is checked against, the system clock is used when nil
+	Clock Clock
+}
+
+// NewWebhookVerifier returns a WebhookVerifier for the webhook with the default certificate restrictions
+// and a WebhookCertCache in front of FetchCertificate
+func NewWebhookVerifier(webhookID string) *WebhookVerifier {
+	v := &WebhookVerifier{WebhookID: webhookID}
+	v.Certs = NewWebhookCertCache(v.FetchCertificate)
+	return v
+}
+
+// Verify verifies the signature of a webhook delivery, the request body is restored so it can be read again
+// Deliveries larger than MaxBodyBytes are rejected
+func (v *WebhookVerifier) Verify(httpReq *http.Request) error {
+	maxBodyBytes := v.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultWebhookMaxBodyBytes
+	}
+
+	var body []byte
+	if httpReq.Body != nil {
+		if httpReq.ContentLength > maxBodyBytes {
+			return fmt.Errorf("paypal: webhook delivery exceeds %d bytes", maxBodyBytes)
+		}
+		var err error
+		if body, err = readBody(io.LimitReader(httpReq.Body, maxBodyBytes+1), httpReq.ContentLength); err != nil {
+			return err
+		}
+		if int64(len(body)) > maxBodyBytes {
+			return fmt.Errorf("paypal: webhook delivery exceeds %d bytes", maxBodyBytes)
+		}
+	}
+	httpReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return v.VerifySignature(httpReq.Header, body)
+}
+
+// VerifySignature verifies the signature headers of a webhook delivery against its raw body.
+// It returns ErrInvalidWebhookSignature when the signature does not match, and ErrWebhookTransmissionExpired
+// when it matches but the transmission time is further than MaxAge from now.
+// Apart from fetching the certificate and the RSA check itself, it does not allocate
+func (v *WebhookVerifier) VerifySignature(header http.Header, body []byte) error {
+	if v.WebhookID == "" {
+		return fmt.Errorf("paypal: webhook ID is required to verify a webhook signature")
+	}
+
+	if algo := headerValue(header, canonicalAuthAlgo); algo != AuthAlgoSHA256WithRSA {
+		return fmt.Errorf("paypal: unsupported webhook auth algorithm %q", algo)
+	}
+
+	transmissionID := headerValue(header, canonicalTransmissionID)
+	transmissionTime := headerValue(header, canonicalTransmissionTime)
+	if transmissionID == "" || transmissionTime == "" {
+		return ErrInvalidWebhookSignature
+	}
+
+	// Signatures of keys up to 4096 bits are copied and decoded on the stack
+	var encodedBuf [684]byte
+	var signatureBuf [512]byte
+	encoded := append(encodedBuf[:0], headerValue(header, canonicalTransmissionSig)...)
+	signature := signatureBuf[:]
+	if n := base64.StdEncoding.DecodedLen(len(encoded)); n > len(signature) {
+		signature = make([]byte, n)
+	}
+	n, err := base64.StdEncoding.Decode(signature, encoded)
+	if err != nil || n == 0 {
+		return ErrInvalidWebhookSignature
+	}
+	signature = signature[:n]
+
+	var cert *x509.Certificate
+	certURL := headerValue(header, canonicalCertURL)
+	if v.Certs != nil {
+		cert, err = v.Certs.Certificate(certURL)
+	} else {
+		cert, err = v.FetchCertificate(certURL)
+	}
+	if err != nil {
+		return err
+	}
+
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("paypal: webhook signing certificate does not hold an RSA key")
+	}
+
+	var messageBuf [256]byte
+	message := append(messageBuf[:0], transmissionID...)
+	message = append(message, '|')
+	message = append(message, transmissionTime...)
+	message = append(message, '|')
+	message = append(message, v.WebhookID...)
+	message = append(message, '|')
+	message = strconv.AppendUint(message, uint64(crc32.ChecksumIEEE(body)), 10)
+	hashed := sha256.Sum256(message)
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signature); err != nil {
+		return ErrInvalidWebhookSignature
+	}
+
+	// The transmission time is signed, it is only checked once it is known to come from PayPal
+	if v.MaxAge > 0 {
+		transmitted, err := parseTimestamp(transmissionTime)
+		if err != nil {
+			return ErrInvalidWebhookSignature
+		}
+		if age := clockNow(v.Clock).Sub(transmitted); age > v.MaxAge || age < -v.MaxAge {
+			return ErrWebhookTransmissionExpired
+		}
+	}
+
+	return nil
+}
+
+// FetchCertificate downloads the certificate chain from certURL and returns the verified signing certificate
+func (v *WebhookVerifier) FetchCertificate(certURL string) (*x509.Certificate, error) {
+	u, err := url.Parse(certURL)
+	if err != nil || u.Scheme != "https" || !containsString(v.certHosts(), u.Hostname()) {
+		return nil, fmt.Errorf("paypal: untrusted webhook certificate URL %q", certURL)
+	}
+
+	httpClient := v.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Get(certURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("paypal: unable to download webhook certificate %s: %s", certURL, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.verifyChain(data)
+}
+
+// verifyChain parses a PEM encoded chain, leaf first, and verifies the leaf against the roots and names
+func (v *WebhookVerifier) verifyChain(data []byte) (*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, cert)
+	}
+
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("paypal: webhook certificate chain holds no certificates")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	leaf := chain[0]
+	verified, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         v.Roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("paypal: untrusted webhook certificate: %v", err)
+	}
+
+	// PayPal signing certificates may carry the name in the common name only, which VerifyHostname ignores
+	named := false
+	for _, name := range v.certNames() {
+		if leaf.Subject.CommonName == name || containsString(leaf.DNSNames, name) {
+			named = true
+			break
+		}
+	}
+	if !named {
+		return nil, fmt.Errorf("paypal: webhook certificate %q is not issued for PayPal", leaf.Subject.CommonName)
+	}
+
+	if v.CheckCertificate != nil {
+		if err := v.CheckCertificate(verified[0]); err != nil {
+			return nil, fmt.Errorf("paypal: rejected webhook certificate: %v", err)
+		}
+	}
+
+	return leaf, nil
+}
+
+func (v *WebhookVerifier) certHosts() []string {
+	if len(v.CertHosts) > 0 {
+		return v.CertHosts
+	}
+	return DefaultWebhookCertHosts
+}
+
+func (v *WebhookVerifier) certNames() []string {
+	if len(v.CertNames) > 0 {
+		return v.CertNames
+	}
+	return DefaultWebhookCertNames
+}
+
+// containsString reports whether s is in list
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// headerValue returns the first value of the header with the canonical name key, without canonicalizing it again
+func headerValue(header http.Header, key string) string {
+	if values := header[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// readBody reads r to the end into a single buffer of sizeHint bytes when it is known, usually the
+// Content-Length, so reading the body of a webhook delivery does not copy it while growing the buffer
+func readBody(r io.Reader, sizeHint int64) ([]byte, error) {
+	size := int64(bytes.MinRead)
+	if sizeHint > 0 && sizeHint < DefaultWebhookMaxBodyBytes {
+		// one more byte so reading the end of r does not grow the buffer
+		size = sizeHint + 1
+	}
+
+	buf := make([]byte, 0, size)
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return buf, err
+		}
+	}
+}
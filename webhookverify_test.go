@@ -0,0 +1,372 @@
+package paypal
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testWebhookSigner issues a signing certificate from a throwaway CA and signs webhook deliveries with it
+type testWebhookSigner struct {
+	roots *x509.CertPool
+	chain []byte
+	key   *rsa.PrivateKey
+}
+
+func newTestWebhookSigner(t testing.TB, commonName string) *testWebhookSigner {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	return &testWebhookSigner{
+		roots: roots,
+		chain: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}),
+		key:   key,
+	}
+}
+
+// sign returns the headers PayPal would send with body for the webhook
+func (s *testWebhookSigner) sign(t testing.TB, certURL, webhookID string, body []byte) http.Header {
+	message := fmt.Sprintf("%s|%s|%s|%d", "b2384410-f8d2-11e9-8155-6be3b2c8cfc6", "2019-10-27T17:38:37Z", webhookID, crc32.ChecksumIEEE(body))
+	hashed := sha256.Sum256([]byte(message))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := http.Header{}
+	header.Set(HeaderAuthAlgo, AuthAlgoSHA256WithRSA)
+	header.Set(HeaderCertURL, certURL)
+	header.Set(HeaderTransmissionID, "b2384410-f8d2-11e9-8155-6be3b2c8cfc6")
+	header.Set(HeaderTransmissionSig, base64.StdEncoding.EncodeToString(signature))
+	header.Set(HeaderTransmissionTime, "2019-10-27T17:38:37Z")
+	return header
+}
+
+func TestWebhookVerifier_VerifySignature(t *testing.T) {
+	signer := newTestWebhookSigner(t, "messageverificationcerts.paypal.com")
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(signer.chain)
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	certURL := ts.URL + "/v1/notifications/certs/CERT-360caa42-fca2a594-a5cafa77"
+
+	v := NewWebhookVerifier("1JE4291016473214C")
+	v.HTTPClient = ts.Client()
+	v.Roots = signer.roots
+	v.CertHosts = []string{u.Hostname()}
+
+	body := []byte(`{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED"}`)
+	header := signer.sign(t, certURL, "1JE4291016473214C", body)
+
+	if err := v.VerifySignature(header, body); err != nil {
+		t.Errorf("Not expected error for valid signature, got %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+	req.Header = header
+	if err := v.Verify(req); err != nil {
+		t.Errorf("Not expected error for valid request, got %v", err)
+	}
+
+	if err := v.VerifySignature(header, []byte(`{"id":"WH-1","event_type":"PAYMENT.SALE.REFUNDED"}`)); err != ErrInvalidWebhookSignature {
+		t.Errorf("Expected ErrInvalidWebhookSignature for tampered body, got %v", err)
+	}
+
+	other := *v
+	other.WebhookID = "OTHER"
+	if err := other.VerifySignature(header, body); err != ErrInvalidWebhookSignature {
+		t.Errorf("Expected ErrInvalidWebhookSignature for another webhook, got %v", err)
+	}
+
+	untrusted := signer.sign(t, "https://evil.example.com/cert.pem", "1JE4291016473214C", body)
+	if err := v.VerifySignature(untrusted, body); err == nil {
+		t.Errorf("Expected error for untrusted certificate URL")
+	}
+
+	// The delivery was signed at 2019-10-27T17:38:37Z
+	v.MaxAge = 5 * time.Minute
+	v.Clock = &testClock{now: time.Date(2019, 10, 27, 17, 40, 0, 0, time.UTC)}
+	if err := v.VerifySignature(header, body); err != nil {
+		t.Errorf("Not expected error for a recent delivery, got %v", err)
+	}
+	v.Clock = &testClock{now: time.Date(2019, 10, 27, 18, 0, 0, 0, time.UTC)}
+	if err := v.VerifySignature(header, body); err != ErrWebhookTransmissionExpired {
+		t.Errorf("Expected ErrWebhookTransmissionExpired for a replayed delivery, got %v", err)
+	}
+	v.MaxAge = 0
+
+	v.MaxBodyBytes = 16
+	req = httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+	req.Header = header
+	if err := v.Verify(req); err == nil || err == ErrInvalidWebhookSignature {
+		t.Errorf("Expected error for a delivery exceeding MaxBodyBytes, got %v", err)
+	}
+	req.ContentLength = -1
+	if err := v.Verify(req); err == nil || err == ErrInvalidWebhookSignature {
+		t.Errorf("Expected error for a delivery of unknown length exceeding MaxBodyBytes, got %v", err)
+	}
+
+	v.Roots = x509.NewCertPool()
+	v.Certs = nil
+	if err := v.VerifySignature(header, body); err == nil {
+		t.Errorf("Expected error for certificate not issued by trusted roots")
+	}
+}
+
+func TestWebhookVerifier_CertNames(t *testing.T) {
+	signer := newTestWebhookSigner(t, "example.com")
+
+	v := NewWebhookVerifier("1JE4291016473214C")
+	v.Roots = signer.roots
+
+	if _, err := v.verifyChain(signer.chain); err == nil {
+		t.Errorf("Expected error for certificate not issued for PayPal")
+	}
+
+	v.CertNames = []string{"example.com"}
+	if _, err := v.verifyChain(signer.chain); err != nil {
+		t.Errorf("Not expected error for allowed certificate name, got %v", err)
+	}
+}
+
+func TestWebhookVerifier_CertCache(t *testing.T) {
+	signer := newTestWebhookSigner(t, "messageverificationcerts.paypal.com")
+
+	downloads := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		w.Write(signer.chain)
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	certURL := ts.URL + "/v1/notifications/certs/CERT-360caa42-fca2a594-a5cafa77"
+
+	v := NewWebhookVerifier("1JE4291016473214C")
+	v.HTTPClient = ts.Client()
+	v.Roots = signer.roots
+	v.CertHosts = []string{u.Hostname()}
+
+	body := []byte(`{"id":"WH-1","event_type":"PAYMENT.SALE.COMPLETED"}`)
+	header := signer.sign(t, certURL, "1JE4291016473214C", body)
+
+	for i := 0; i < 3; i++ {
+		if err := v.VerifySignature(header, body); err != nil {
+			t.Fatalf("Not expected error for valid signature, got %v", err)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("expected the certificate to be downloaded once, got %d downloads", downloads)
+	}
+
+	// Pinning hook rejecting every issuer but a different one
+	v.Certs = NewWebhookCertCache(v.FetchCertificate)
+	v.CheckCertificate = func(chain []*x509.Certificate) error {
+		if chain[len(chain)-1].Subject.CommonName != "DigiCert Global Root CA" {
+			return fmt.Errorf("unexpected issuer %s", chain[len(chain)-1].Subject.CommonName)
+		}
+		return nil
+	}
+	if err := v.VerifySignature(header, body); err == nil {
+		t.Errorf("Expected error for certificate rejected by CheckCertificate")
+	}
+
+	// Certificates supplied from a custom store are not downloaded
+	v.CheckCertificate = nil
+	leaf, err := v.verifyChain(signer.chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloads = 0
+	v.Certs = staticCertSource{certURL: leaf}
+	if err := v.VerifySignature(header, body); err != nil {
+		t.Errorf("Not expected error for certificate from custom source, got %v", err)
+	}
+	if downloads != 0 {
+		t.Errorf("expected no downloads with a custom source, got %d", downloads)
+	}
+}
+
+func TestWebhookCertCache_Expiry(t *testing.T) {
+	fetches := 0
+	cache := NewWebhookCertCache(func(certURL string) (*x509.Certificate, error) {
+		fetches++
+		return &x509.Certificate{NotBefore: time.Now().Add(-2 * time.Hour), NotAfter: time.Now().Add(-time.Hour)}, nil
+	})
+
+	cache.Certificate("https://api.paypal.com/cert")
+	cache.Certificate("https://api.paypal.com/cert")
+	if fetches != 2 {
+		t.Errorf("expected expired certificates to be fetched again, got %d fetches", fetches)
+	}
+}
+
+func TestWebhookCertCache_MaxSize(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := map[string]time.Time{
+		"https://api.paypal.com/expired": now.Add(-time.Hour),
+		"https://api.paypal.com/soon":    now.Add(time.Hour),
+		"https://api.paypal.com/later":   now.Add(48 * time.Hour),
+		"https://api.paypal.com/new":     now.Add(72 * time.Hour),
+	}
+	cache := NewWebhookCertCache(func(certURL string) (*x509.Certificate, error) {
+		return &x509.Certificate{NotBefore: now.Add(-48 * time.Hour), NotAfter: notAfter[certURL]}, nil
+	})
+	cache.Clock = &testClock{now: now}
+	cache.MaxSize = 2
+
+	cache.Certificate("https://api.paypal.com/expired")
+	cache.Certificate("https://api.paypal.com/soon")
+	cache.Certificate("https://api.paypal.com/later")
+	if _, ok := cache.certs["https://api.paypal.com/expired"]; ok || len(cache.certs) != 2 {
+		t.Errorf("expected the expired certificate to be dropped, got %v", cache.certs)
+	}
+
+	cache.Certificate("https://api.paypal.com/new")
+	if _, ok := cache.certs["https://api.paypal.com/soon"]; ok || len(cache.certs) != 2 {
+		t.Errorf("expected the certificate expiring soonest to be dropped, got %v", cache.certs)
+	}
+}
+
+type staticCertSource map[string]*x509.Certificate
+
+func (s staticCertSource) Certificate(certURL string) (*x509.Certificate, error) {
+	if cert, ok := s[certURL]; ok {
+		return cert, nil
+	}
+	return nil, fmt.Errorf("unknown certificate %s", certURL)
+}
+
+func TestReadBody(t *testing.T) {
+	body := strings.Repeat("x", 3000)
+	for _, hint := range []int64{-1, 0, 10, 3000, 5000} {
+		got, err := readBody(strings.NewReader(body), hint)
+		if err != nil || string(got) != body {
+			t.Errorf("expected the whole body with size hint %d, got %d bytes, %v", hint, len(got), err)
+		}
+	}
+
+	if got, err := readBody(strings.NewReader(body), 3000); err != nil || cap(got) != 3001 {
+		t.Errorf("expected a single buffer of the hinted size, got capacity %d, %v", cap(got), err)
+	}
+
+	if _, err := readBody(io.MultiReader(strings.NewReader(body), failingReader{}), 0); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected the read error, got %v", err)
+	}
+}
+
+// failingReader is a reader failing with io.ErrUnexpectedEOF
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, io.ErrUnexpectedEOF }
+
+// payoutDelivery is a PAYMENT.PAYOUTS-ITEM.SUCCEEDED delivery, the event sent for every item of a payout run
+var payoutDelivery = []byte(`{"id":"WH-7Y7254563A4550640-11V2185806837105M","event_version":"1.0","create_time":"2020-01-15T10:00:00.000Z","resource_type":"payouts_item","event_type":"PAYMENT.PAYOUTS-ITEM.SUCCEEDED","summary":"A payout item has succeeded","resource":{"payout_item_id":"8AELMXH8UB2P8","transaction_id":"0C413693MN970190K","transaction_status":"SUCCESS","payout_batch_id":"Q8KVJG9TZTNN4","payout_item_fee":{"currency":"USD","value":"0.25"},"payout_item":{"recipient_type":"EMAIL","amount":{"currency":"USD","value":"9.87"},"note":"Thanks for your patronage!","receiver":"receiver@example.com","sender_item_id":"14Feb_978"},"time_processed":"2020-01-15T10:00:00Z","links":[{"href":"https://api.paypal.com/v1/payments/payouts-item/8AELMXH8UB2P8","rel":"self","method":"GET"}]},"links":[{"href":"https://api.paypal.com/v1/notifications/webhooks-events/WH-7Y7254563A4550640-11V2185806837105M","rel":"self","method":"GET"},{"href":"https://api.paypal.com/v1/notifications/webhooks-events/WH-7Y7254563A4550640-11V2185806837105M/resend","rel":"resend","method":"POST"}]}`)
+
+// newBenchmarkVerifier returns a verifier with the signing certificate in memory and the headers of payoutDelivery
+func newBenchmarkVerifier(b *testing.B) (*WebhookVerifier, http.Header) {
+	signer := newTestWebhookSigner(b, "messageverificationcerts.paypal.com")
+	certURL := "https://api.paypal.com/v1/notifications/certs/CERT-360caa42-fca2a594-a5cafa77"
+
+	v := NewWebhookVerifier("1JE4291016473214C")
+	v.Roots = signer.roots
+	leaf, err := v.verifyChain(signer.chain)
+	if err != nil {
+		b.Fatal(err)
+	}
+	v.Certs = staticCertSource{certURL: leaf}
+
+	return v, signer.sign(b, certURL, "1JE4291016473214C", payoutDelivery)
+}
+
+// The benchmarks measure the work done for every delivery once the signing certificate is cached:
+//
+//	go test -bench 'VerifySignature|WebhookHandler' -benchmem
+func BenchmarkWebhookVerifier_VerifySignature(b *testing.B) {
+	v, header := newBenchmarkVerifier(b)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payoutDelivery)))
+	for i := 0; i < b.N; i++ {
+		if err := v.VerifySignature(header, payoutDelivery); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWebhookHandler(b *testing.B) {
+	v, header := newBenchmarkVerifier(b)
+	opts := &WebhookHandlerOptions{Verifier: v}
+	opts.On(EventPaymentPayoutsItemSucceeded, func(r *http.Request, event *Event) error { return nil })
+	handler := WebhookHandler(nil, "1JE4291016473214C", opts)
+
+	body := bytes.NewReader(payoutDelivery)
+	req := httptest.NewRequest("POST", "/webhook", body)
+	req.Header = header
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payoutDelivery)))
+	for i := 0; i < b.N; i++ {
+		body.Reset(payoutDelivery)
+		req.Body = ioutil.NopCloser(body)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			b.Fatalf("expected the delivery to be accepted, got %d", rec.Code)
+		}
+	}
+}
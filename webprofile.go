@@ -1,6 +1,7 @@
 package paypal
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -11,9 +12,13 @@ import (
 //
 // Endpoint: POST /v1/payment-experience/web-profiles
 func (c *Client) CreateWebProfile(wp WebProfile) (*WebProfile, error) {
+	response := &WebProfile{}
+	if err := c.validate(&wp); err != nil {
+		return response, err
+	}
+
 	url := fmt.Sprintf("%s%s", c.APIBase, "/v1/payment-experience/web-profiles")
 	req, err := c.NewRequest("POST", url, wp)
-	response := &WebProfile{}
 
 	if err != nil {
 		return response, err
@@ -72,12 +77,15 @@ func (c *Client) GetWebProfiles() ([]WebProfile, error) {
 
 // SetWebProfile sets a web experience profile in Paypal with given id
 //
-// Endpoint: PUT /v1/payment-experience/web-profiles
+// Endpoint: PUT /v1/payment-experience/web-profiles/<profile-id>
 func (c *Client) SetWebProfile(wp WebProfile) error {
 
 	if wp.ID == "" {
 		return fmt.Errorf("paypal: no ID specified for WebProfile")
 	}
+	if err := c.validate(&wp); err != nil {
+		return err
+	}
 
 	url := fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payment-experience/web-profiles/", wp.ID)
 
@@ -94,9 +102,34 @@ func (c *Client) SetWebProfile(wp WebProfile) error {
 	return nil
 }
 
+// PatchWebProfile partially updates a web experience profile in Paypal with given id,
+// use the WebProfilePath* constants as paths
+//
+// Endpoint: PATCH /v1/payment-experience/web-profiles/<profile-id>
+func (c *Client) PatchWebProfile(profileID string, patch []*PaymentPatch) error {
+
+	if profileID == "" {
+		return fmt.Errorf("paypal: no ID specified for WebProfile")
+	}
+
+	url := fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payment-experience/web-profiles/", profileID)
+
+	req, err := c.NewRequest("PATCH", url, patch)
+
+	if err != nil {
+		return err
+	}
+
+	if err = c.SendWithAuth(req, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // DeleteWebProfile deletes a web experience profile from Paypal with given id
 //
-// Endpoint: DELETE /v1/payment-experience/web-profiles
+// Endpoint: DELETE /v1/payment-experience/web-profiles/<profile-id>
 func (c *Client) DeleteWebProfile(profileID string) error {
 
 	url := fmt.Sprintf("%s%s%s", c.APIBase, "/v1/payment-experience/web-profiles/", profileID)
@@ -113,3 +146,26 @@ func (c *Client) DeleteWebProfile(profileID string) error {
 
 	return nil
 }
+
+// MarshalJSON leaves out the presentation, input fields and flow config when they are empty,
+// omitempty has no effect on struct values so they would be sent as {}
+func (wp WebProfile) MarshalJSON() ([]byte, error) {
+	type webProfile WebProfile
+	out := struct {
+		webProfile
+		Presentation *Presentation `json:"presentation,omitempty"`
+		InputFields  *InputFields  `json:"input_fields,omitempty"`
+		FlowConfig   *FlowConfig   `json:"flow_config,omitempty"`
+	}{webProfile: webProfile(wp)}
+
+	if wp.Presentation != (Presentation{}) {
+		out.Presentation = &wp.Presentation
+	}
+	if wp.InputFields != (InputFields{}) {
+		out.InputFields = &wp.InputFields
+	}
+	if wp.FlowConfig != (FlowConfig{}) {
+		out.FlowConfig = &wp.FlowConfig
+	}
+	return json.Marshal(out)
+}